@@ -0,0 +1,262 @@
+package api
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	"go.trulyao.dev/hubble/web/internal/job"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/internal/repository"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	authlib "go.trulyao.dev/hubble/web/pkg/lib/auth"
+	"go.trulyao.dev/hubble/web/pkg/rbac"
+	"go.trulyao.dev/robin"
+)
+
+type commentHandler struct {
+	*baseHandler
+}
+
+// List implements CommentHandler.
+func (c *commentHandler) List(
+	ctx *robin.Context,
+	request ListCommentsRequest,
+) (ListCommentsResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return ListCommentsResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return ListCommentsResponse{}, err
+	}
+
+	entry, err := c.findEntry(
+		auth.UserID,
+		request.WorkspaceSlug,
+		request.EntryID,
+		rbac.PermListComments,
+	)
+	if err != nil {
+		return ListCommentsResponse{}, err
+	}
+
+	comments, err := c.repos.CommentRepository().FindAll(entry.PublicID)
+	if err != nil {
+		return ListCommentsResponse{}, err
+	}
+
+	return ListCommentsResponse{Comments: comments}, nil
+}
+
+// Create implements CommentHandler.
+func (c *commentHandler) Create(
+	ctx *robin.Context,
+	request CreateCommentRequest,
+) (SaveCommentResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return SaveCommentResponse{}, err
+	}
+
+	request.Content = strings.TrimSpace(request.Content)
+	if err := lib.ValidateStruct(&request); err != nil {
+		return SaveCommentResponse{}, err
+	}
+
+	entry, err := c.findEntry(
+		auth.UserID,
+		request.WorkspaceSlug,
+		request.EntryID,
+		rbac.PermCreateComment,
+	)
+	if err != nil {
+		return SaveCommentResponse{}, err
+	}
+
+	var replyTo pgtype.UUID
+	if request.ReplyToID != "" {
+		if replyTo, err = lib.UUIDFromString(request.ReplyToID); err != nil {
+			return SaveCommentResponse{}, apperrors.BadRequest(
+				"invalid comment ID: " + request.ReplyToID,
+			)
+		}
+	}
+
+	comment, err := c.repos.CommentRepository().Create(&repository.CreateCommentArgs{
+		WorkspaceSlug: request.WorkspaceSlug,
+		Entry:         &entry,
+		ReplyTo:       replyTo,
+		UserID:        auth.UserID,
+		Content:       request.Content,
+	})
+	if err != nil {
+		return SaveCommentResponse{}, err
+	}
+
+	c.queueCommentEmbedding(&comment)
+
+	message := "Comment added"
+	if replyTo.Valid {
+		message = "Reply added"
+	}
+
+	return SaveCommentResponse{CommentID: comment.ID, Message: message}, nil
+}
+
+// Update implements CommentHandler.
+func (c *commentHandler) Update(
+	ctx *robin.Context,
+	request UpdateCommentRequest,
+) (SaveCommentResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return SaveCommentResponse{}, err
+	}
+
+	request.Content = strings.TrimSpace(request.Content)
+	if err := lib.ValidateStruct(&request); err != nil {
+		return SaveCommentResponse{}, err
+	}
+
+	commentID, ownership, err := c.commentOwnership(auth.UserID, request.CommentID)
+	if err != nil {
+		return SaveCommentResponse{}, err
+	}
+
+	if !ownership.IsOwner {
+		return SaveCommentResponse{}, apperrors.Forbidden(
+			"only the author of a comment can edit it",
+		)
+	}
+
+	comment, err := c.repos.CommentRepository().Update(&repository.UpdateCommentArgs{
+		WorkspaceSlug: request.WorkspaceSlug,
+		CommentID:     commentID,
+		UserID:        auth.UserID,
+		Content:       request.Content,
+	})
+	if err != nil {
+		return SaveCommentResponse{}, err
+	}
+
+	c.queueCommentEmbedding(&comment)
+
+	return SaveCommentResponse{CommentID: comment.ID, Message: "Comment updated"}, nil
+}
+
+// Delete implements CommentHandler.
+func (c *commentHandler) Delete(
+	ctx *robin.Context,
+	request DeleteCommentRequest,
+) (DeleteCommentResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return DeleteCommentResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return DeleteCommentResponse{}, err
+	}
+
+	commentID, ownership, err := c.commentOwnership(auth.UserID, request.CommentID)
+	if err != nil {
+		return DeleteCommentResponse{}, err
+	}
+
+	// Authors can always delete their own comments, moderators can delete anyone's
+	if !ownership.IsOwner && !ownership.UserRole.Can(rbac.PermDeleteComment) {
+		return DeleteCommentResponse{}, rbac.ErrPermissionDenied
+	}
+
+	err = c.repos.CommentRepository().Delete(request.WorkspaceSlug, commentID)
+	if err != nil {
+		return DeleteCommentResponse{}, err
+	}
+
+	return DeleteCommentResponse{Message: "Comment deleted"}, nil
+}
+
+// findEntry loads the entry comments are made on and checks that the current user has the given permission in its collection
+func (c *commentHandler) findEntry(
+	userID int32,
+	workspaceSlug string,
+	entryID string,
+	permission rbac.Permission,
+) (models.Entry, error) {
+	//nolint:exhaustruct
+	entry, err := c.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+		PublicID:  lib.PgUUIDString(entryID),
+		Workspace: repository.PublicIdOrSlug{Slug: workspaceSlug},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Entry{}, apperrors.BadRequest("entry not found or has been deleted")
+		}
+		return models.Entry{}, err
+	}
+
+	if entry.Type == document.EntryTypeComment {
+		return models.Entry{}, repository.ErrCommentOnComment
+	}
+
+	result, err := c.repos.CollectionRepository().
+		FindWithMembershipStatus(&repository.FindWithMembershipStatusArgs{
+			UserID:         userID,
+			WorkspaceID:    entry.Workspace.ID,
+			CollectionID:   entry.Collection.ID,
+			WorkspaceSlug:  "",
+			CollectionSlug: "",
+		})
+	if err != nil {
+		return models.Entry{}, err
+	}
+
+	if !result.MembershipStatus.Role.Can(permission) {
+		return models.Entry{}, rbac.ErrPermissionDenied
+	}
+
+	return entry, nil
+}
+
+// commentOwnership returns whether the current user wrote a comment and their role in the comment's collection
+func (c *commentHandler) commentOwnership(
+	userID int32,
+	commentPublicID string,
+) (pgtype.UUID, repository.EntryOwnership, error) {
+	commentID, err := lib.UUIDFromString(commentPublicID)
+	if err != nil {
+		return pgtype.UUID{}, repository.EntryOwnership{}, apperrors.BadRequest(
+			"invalid comment ID: " + commentPublicID,
+		)
+	}
+
+	ownerships, err := c.repos.EntryRepository().GetOwnerships(userID, []pgtype.UUID{commentID})
+	if err != nil {
+		return pgtype.UUID{}, repository.EntryOwnership{}, err
+	}
+
+	if len(ownerships) == 0 {
+		return pgtype.UUID{}, repository.EntryOwnership{}, repository.ErrCommentNotFound
+	}
+
+	return commentID, ownerships[0], nil
+}
+
+// queueCommentEmbedding sends the chunks of a created or updated comment to be embedded
+func (c *commentHandler) queueCommentEmbedding(comment *models.CreatedEntry) {
+	go func(id pgtype.UUID, internalID int32) {
+		err := c.queue.Add(&job.EntryChunkEmbeddingJob{Entries: []pgtype.UUID{id}})
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int32("entry_id", internalID).
+				Msg("failed to enqueue comment embedding")
+		}
+	}(comment.ID, comment.InternalID)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/robin"
+)
+
+type CommentHandler interface {
+	// List lists the comments made on an entry along with their replies
+	List(ctx *robin.Context, request ListCommentsRequest) (ListCommentsResponse, error)
+
+	// Create comments on an entry or replies to one of its comments
+	Create(ctx *robin.Context, request CreateCommentRequest) (SaveCommentResponse, error)
+
+	// Update replaces the content of a comment, only the author of a comment can edit it
+	Update(ctx *robin.Context, request UpdateCommentRequest) (SaveCommentResponse, error)
+
+	// Delete deletes a comment along with its replies
+	Delete(ctx *robin.Context, request DeleteCommentRequest) (DeleteCommentResponse, error)
+}
+
+type (
+	ListCommentsRequest struct {
+		WorkspaceSlug string `json:"workspace_slug" validate:"required,slug"`
+		EntryID       string `json:"entry_id"       validate:"required,uuid"`
+	}
+
+	ListCommentsResponse struct {
+		Comments []models.Comment `json:"comments"`
+	}
+
+	CreateCommentRequest struct {
+		WorkspaceSlug string `json:"workspace_slug" validate:"required,slug"`
+		EntryID       string `json:"entry_id"       validate:"required,uuid"`
+		// ReplyToID is the comment being replied to, it is left out to comment on the entry itself
+		ReplyToID string `json:"reply_to_id" validate:"omitempty,uuid" mirror:"optional:true"`
+		// Content is the markdown body of the comment
+		Content string `json:"content" validate:"required,max=10000"`
+	}
+
+	UpdateCommentRequest struct {
+		WorkspaceSlug string `json:"workspace_slug" validate:"required,slug"`
+		CommentID     string `json:"comment_id"     validate:"required,uuid"`
+		Content       string `json:"content"        validate:"required,max=10000"`
+	}
+
+	SaveCommentResponse struct {
+		CommentID pgtype.UUID `json:"comment_id" mirror:"type:string"`
+		Message   string      `json:"message"`
+	}
+
+	DeleteCommentRequest struct {
+		WorkspaceSlug string `json:"workspace_slug" validate:"required,slug"`
+		CommentID     string `json:"comment_id"     validate:"required,uuid"`
+	}
+
+	DeleteCommentResponse struct {
+		Message string `json:"message"`
+	}
+)
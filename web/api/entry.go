@@ -1,11 +1,13 @@
 package api
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,13 +16,16 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rs/zerolog/log"
 	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
 	"go.trulyao.dev/hubble/web/internal/job"
 	"go.trulyao.dev/hubble/web/internal/models"
 	"go.trulyao.dev/hubble/web/internal/repository"
+	"go.trulyao.dev/hubble/web/pkg/diff"
 	"go.trulyao.dev/hubble/web/pkg/document"
 	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
 	"go.trulyao.dev/hubble/web/pkg/lib"
 	authlib "go.trulyao.dev/hubble/web/pkg/lib/auth"
+	"go.trulyao.dev/hubble/web/pkg/llm"
 	"go.trulyao.dev/hubble/web/pkg/ograph"
 	"go.trulyao.dev/hubble/web/pkg/rbac"
 	"go.trulyao.dev/robin"
@@ -32,274 +37,1145 @@ type entryHandler struct {
 	*baseHandler
 }
 
+var ErrQuestionsDisabled = apperrors.BadRequest(
+	"question answering is not enabled, a chat model needs to be configured",
+)
+
 // Search implements EntryHandler.
 func (e *entryHandler) Search(ctx *robin.Context, request SearchRequest) (SearchResponse, error) {
 	var response SearchResponse
 
 	auth, err := authlib.ExtractAuthSession(ctx)
 	if err != nil {
-		return response, err
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+	request.Query = strings.TrimSpace(request.Query)
+
+	query, err := repository.ParseSearchQuery(request.Query)
+	if err != nil {
+		return response, err
+	}
+
+	filters, err := e.parseSearchFilters(&request.Filters)
+	if err != nil {
+		return response, err
+	}
+
+	workspaceID := lib.PgUUIDString(request.WorkspaceID)
+	status, err := e.repos.WorkspaceRepository().FindWithMembershipStatus(
+		repository.PublicIdOrSlug{
+			PublicID: workspaceID,
+			Slug:     request.WorkspaceSlug,
+		}, //nolint:exhaustruct
+		auth.UserID,
+	)
+	if err != nil {
+		return response, err
+	}
+
+	if !status.MembershipStatus.Role.Can(rbac.PermSearchEntry) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	// `collection:` operators are slugs, they can only be intersected with the requested collection IDs once resolved
+	if filters.HasCollections() && query.Filters.HasCollections() {
+		collections, err := e.repos.CollectionRepository().FindByWorkspaceAndUser(
+			status.ID,
+			auth.UserID,
+		)
+		if err != nil {
+			return response, err
+		}
+
+		filters.ResolveCollections(collections)
+		query.Filters.ResolveCollections(collections)
+	}
+	filters.Merge(&query.Filters)
+
+	pagination := repository.PaginationParams{
+		Page:    1,
+		PerPage: repository.SearchCandidateChunks,
+	}
+
+	// Paginated searches fetch a larger window of candidates and return a page of it
+	var after *repository.Cursor
+	if request.Pagination != nil {
+		pagination.PerPage = repository.PaginatedSearchCandidateChunks
+
+		after, err = e.decodeCursor(repository.CursorScopeSearch, request.Pagination.Cursor)
+		if err != nil {
+			return response, err
+		}
+	}
+
+	rankFusion, err := e.rankFusionParams(status.ID)
+	if err != nil {
+		return response, err
+	}
+
+	// We need a context to control the timeout
+	parentCtx := context.Background()
+	timedCtx, cancel := context.WithTimeout(parentCtx, time.Minute*1)
+	defer cancel()
+
+	// Generate vector if enabled, queries with only operators have no text to embed
+	var vector []float32
+	var embeddingModel models.EmbeddingModel
+	if e.config.LLM.EnabledEmbeddings() && !query.FilterOnly() {
+		embeddingModel, err = e.embeddingModel(status.ID)
+		if err != nil {
+			return response, err
+		}
+
+		vector, err = e.llm.GenerateEmbedding(timedCtx, embeddingModel, query.Text())
+		if err != nil {
+			return response, err
+		}
+	}
+
+	start := time.Now() // we are not interested in the time taken for the vector generation, we don't control this
+	searchArgs := &repository.HybridSearchArgs{
+		Context:         timedCtx,
+		Query:           query,
+		SemanticVector:  vector,
+		EmbeddingModel:  embeddingModel,
+		UserID:          auth.UserID,
+		Pagination:      pagination,
+		Filters:         filters,
+		RankFusion:      rankFusion,
+		FuzzyMinResults: e.config.Search.FuzzyMinResults,
+		Workspace: repository.PublicIdOrSlug{
+			PublicID: workspaceID,
+			Slug:     request.WorkspaceSlug,
+		},
+	}
+	results, err := e.repos.EntryRepository().QueryWithHybridSearch(searchArgs)
+	if err != nil {
+		return response, err
+	}
+
+	// Apply specificity if set
+	if e.config.Search.Mode == config.SearchModeThreshold ||
+		e.config.Search.Mode == config.SearchModeRrf {
+		results.CollapsedResults = repository.ApplyMinThreshold(
+			results.CollapsedResults,
+			e.config.Search.Threshold,
+		)
+	}
+
+	// Facets are counted once over everything the search matched, only the page is returned
+	results.Facets, err = e.repos.EntryRepository().CountSearchFacets(
+		searchArgs,
+		results.CollapsedResults,
+	)
+	if err != nil {
+		return response, err
+	}
+
+	var nextCursor *repository.Cursor
+	if request.Pagination != nil {
+		results.CollapsedResults, nextCursor = repository.PaginateSearchResults(
+			results.CollapsedResults,
+			after,
+			request.Pagination.PageSize(),
+		)
+	}
+
+	// Reorder the top results with the second-stage reranker (if enabled), this falls back to the current order on failure
+	// Only the first page holds the top results, the next pages follow the cursor which is based on the hybrid order
+	if !query.FilterOnly() && after == nil {
+		results.CollapsedResults = e.llm.Rerank(timedCtx, query.Text(), results.CollapsedResults)
+	}
+
+	// Semantic-only matches have no highlighted snippet, use the sentence closest to the query instead
+	if err := e.llm.SemanticSnippets(timedCtx, embeddingModel, vector, results.CollapsedResults); err != nil {
+		log.Warn().Err(err).Msg("failed to generate semantic snippets")
+	}
+
+	encodedCursor, err := e.encodeCursor(repository.CursorScopeSearch, nextCursor)
+	if err != nil {
+		return response, err
+	}
+
+	return SearchResponse{
+		Results:    *results,
+		Query:      request.Query,
+		TimeTaken:  time.Since(start).Milliseconds(),
+		NextCursor: encodedCursor,
+	}, nil
+}
+
+// rankFusionParams loads the workspace's fusion parameters if we are ranking with RRF, nil is returned otherwise
+func (e *entryHandler) rankFusionParams(workspaceID int32) (*repository.RRFParams, error) {
+	if e.config.Search.Mode != config.SearchModeRrf {
+		return nil, nil
+	}
+
+	settings, err := e.repos.WorkspaceRepository().FindSettings(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.RRFParams{
+		K:              float64(settings.RRFK),
+		SemanticWeight: settings.RRFSemanticWeight,
+		FullTextWeight: settings.RRFFullTextWeight,
+	}, nil
+}
+
+// embeddingModel returns the model the workspace's chunks are embedded with, queries have to be embedded with the same model to be comparable
+func (e *entryHandler) embeddingModel(workspaceID int32) (models.EmbeddingModel, error) {
+	settings, err := e.repos.WorkspaceRepository().FindSettings(workspaceID)
+	if err != nil {
+		return models.EmbeddingModel{}, err
+	}
+
+	// Workspaces are pinned to a model once their first chunk is embedded
+	if settings.EmbeddingModel.IsZero() {
+		return e.llm.EmbeddingModel(), nil
+	}
+
+	return settings.EmbeddingModel, nil
+}
+
+// decodeCursor verifies a cursor token from a request, a nil cursor is returned if the token is empty
+func (e *entryHandler) decodeCursor(scope string, token string) (*repository.Cursor, error) {
+	return repository.DecodeCursor(scope, e.config.Keys.CookieSecret, token)
+}
+
+// encodeCursor signs the cursor of the next page, nil is returned on the last page
+func (e *entryHandler) encodeCursor(scope string, cursor *repository.Cursor) (*string, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	token, err := repository.EncodeCursor(scope, e.config.Keys.CookieSecret, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// parseSearchFilters converts the optional search filters into the repository representation
+func (e *entryHandler) parseSearchFilters(
+	filters *SearchFilters,
+) (repository.SearchFilters, error) {
+	parsed := repository.SearchFilters{
+		Types:           make([]document.EntryType, 0, len(filters.Types)),
+		CollectionIDs:   make([]pgtype.UUID, 0, len(filters.CollectionIDs)),
+		CollectionSlugs: filters.CollectionSlugs,
+		AddedBy:         filters.AddedBy,
+		Tags:            filters.Tags,
+		CreatedAfter:    filters.CreatedAfter,
+		CreatedBefore:   filters.CreatedBefore,
+		UpdatedAfter:    filters.UpdatedAfter,
+		UpdatedBefore:   filters.UpdatedBefore,
+		Statuses:        make([]queries.EntryStatus, 0, len(filters.Statuses)),
+		Title:           nil,
+		Excluded:        nil,
+		IncludeArchived: filters.IncludeArchived,
+		ArchivedOnly:    filters.ArchivedOnly,
+	}
+
+	for _, t := range filters.Types {
+		entryType, err := document.ParseEntryType(t)
+		if err != nil {
+			return parsed, apperrors.NewValidationError(apperrors.ErrorMap{
+				"filters.types": {fmt.Sprintf("%q is not a valid entry type", t)},
+			})
+		}
+		parsed.Types = append(parsed.Types, entryType)
+	}
+
+	for _, id := range filters.CollectionIDs {
+		parsed.CollectionIDs = append(parsed.CollectionIDs, lib.PgUUIDString(id))
+	}
+
+	for _, status := range filters.Statuses {
+		parsed.Statuses = append(parsed.Statuses, queries.EntryStatus(status))
+	}
+
+	if !filters.CreatedAfter.IsZero() && !filters.CreatedBefore.IsZero() &&
+		filters.CreatedAfter.After(filters.CreatedBefore) {
+		return parsed, apperrors.NewValidationError(apperrors.ErrorMap{
+			"filters.created_after": {"created_after must be before created_before"},
+		})
+	}
+
+	if !filters.UpdatedAfter.IsZero() && !filters.UpdatedBefore.IsZero() &&
+		filters.UpdatedAfter.After(filters.UpdatedBefore) {
+		return parsed, apperrors.NewValidationError(apperrors.ErrorMap{
+			"filters.updated_after": {"updated_after must be before updated_before"},
+		})
+	}
+
+	return parsed, nil
+}
+
+// Find implements EntryHandler.
+func (e *entryHandler) Find(
+	ctx *robin.Context,
+	request FindEntryRequest,
+) (FindEntryResponse, error) {
+	var response FindEntryResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	entry, err := e.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+		InternalID: 0,
+		PublicID:   lib.PgUUIDString(request.EntryID),
+		Collection: repository.PublicIdOrSlug{Slug: request.CollectionSlug}, //nolint:exhaustruct
+		Workspace:  repository.PublicIdOrSlug{Slug: request.WorkspaceSlug},  //nolint:exhaustruct
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return response, apperrors.BadRequest("entry not found or has been deleted")
+		}
+		return response, err
+	}
+
+	// NOTE: We don't really care about the workspace here since entries are largely independent of their workspace (for now anyway)
+
+	// Check if the user has access to the entry in this collection's context
+	perm, err := e.repos.CollectionRepository().
+		FindWithMembershipStatus(&repository.FindWithMembershipStatusArgs{
+			UserID:         auth.UserID,
+			WorkspaceID:    entry.Workspace.ID,
+			CollectionID:   entry.Collection.ID,
+			WorkspaceSlug:  "",
+			CollectionSlug: "",
+		})
+	if err != nil {
+		return response, err
+	}
+
+	if !perm.MembershipStatus.Role.Can(rbac.PermReadEntry) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	return FindEntryResponse{Entry: entry}, nil
+}
+
+// FindRelated implements EntryHandler.
+func (e *entryHandler) FindRelated(
+	ctx *robin.Context,
+	request FindRelatedEntriesRequest,
+) (FindRelatedEntriesResponse, error) {
+	var response FindRelatedEntriesResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	entry, err := e.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+		InternalID: 0,
+		PublicID:   lib.PgUUIDString(request.EntryID),
+		Collection: repository.PublicIdOrSlug{Slug: request.CollectionSlug}, //nolint:exhaustruct
+		Workspace:  repository.PublicIdOrSlug{Slug: request.WorkspaceSlug},  //nolint:exhaustruct
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return response, apperrors.BadRequest("entry not found or has been deleted")
+		}
+		return response, err
+	}
+
+	perm, err := e.repos.CollectionRepository().
+		FindWithMembershipStatus(&repository.FindWithMembershipStatusArgs{
+			UserID:         auth.UserID,
+			WorkspaceID:    entry.Workspace.ID,
+			CollectionID:   entry.Collection.ID,
+			WorkspaceSlug:  "",
+			CollectionSlug: "",
+		})
+	if err != nil {
+		return response, err
+	}
+
+	if !perm.MembershipStatus.Role.Can(rbac.PermReadEntry) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	// The related entries are filtered by the user's memberships in the repository
+	related, err := e.repos.EntryRepository().FindCachedRelatedEntries(
+		&repository.FindRelatedEntriesArgs{
+			Context: context.Background(),
+			EntryID: entry.PublicID,
+			UserID:  auth.UserID,
+			Limit:   request.Limit,
+		},
+	)
+	if err != nil {
+		return response, err
+	}
+
+	return FindRelatedEntriesResponse{Entries: related}, nil
+}
+
+// FindLinks implements EntryHandler.
+func (e *entryHandler) FindLinks(
+	ctx *robin.Context,
+	request FindEntryLinksRequest,
+) (FindEntryLinksResponse, error) {
+	var response FindEntryLinksResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	entry, err := e.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+		InternalID: 0,
+		PublicID:   lib.PgUUIDString(request.EntryID),
+		Collection: repository.PublicIdOrSlug{Slug: request.CollectionSlug}, //nolint:exhaustruct
+		Workspace:  repository.PublicIdOrSlug{Slug: request.WorkspaceSlug},  //nolint:exhaustruct
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return response, apperrors.BadRequest("entry not found or has been deleted")
+		}
+		return response, err
+	}
+
+	perm, err := e.repos.CollectionRepository().
+		FindWithMembershipStatus(&repository.FindWithMembershipStatusArgs{
+			UserID:         auth.UserID,
+			WorkspaceID:    entry.Workspace.ID,
+			CollectionID:   entry.Collection.ID,
+			WorkspaceSlug:  "",
+			CollectionSlug: "",
+		})
+	if err != nil {
+		return response, err
+	}
+
+	if !perm.MembershipStatus.Role.Can(rbac.PermReadEntry) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	// The linked entries are filtered by the user's memberships in the repository
+	links, err := e.repos.LinkRepository().FindAll(entry.PublicID, auth.UserID)
+	if err != nil {
+		return response, err
+	}
+
+	return FindEntryLinksResponse{Links: links}, nil
+}
+
+// AskQuestion implements EntryHandler.
+func (e *entryHandler) AskQuestion(
+	ctx *robin.Context,
+	request AskQuestionRequest,
+) (AskQuestionResponse, error) {
+	var response AskQuestionResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+	request.Question = strings.TrimSpace(request.Question)
+
+	if !e.llm.ChatEnabled() {
+		return response, ErrQuestionsDisabled
+	}
+
+	filters, err := e.parseSearchFilters(&request.Filters)
+	if err != nil {
+		return response, err
+	}
+
+	timedCtx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
+	defer cancel()
+
+	start := time.Now()
+	chunks, err := e.retrieveContext(timedCtx, &retrieveContextArgs{
+		UserID:   auth.UserID,
+		Question: request.Question,
+		Filters:  filters,
+		Workspace: repository.PublicIdOrSlug{
+			PublicID: lib.PgUUIDString(request.WorkspaceID),
+			Slug:     request.WorkspaceSlug,
+		},
+	})
+	if err != nil {
+		return response, err
+	}
+
+	answer, err := e.llm.Answer(timedCtx, request.Question, chunks)
+	if err != nil {
+		return response, err
+	}
+
+	return AskQuestionResponse{
+		Answer:    *answer,
+		Question:  request.Question,
+		TimeTaken: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+type retrieveContextArgs struct {
+	UserID    int32
+	Workspace repository.PublicIdOrSlug
+	Question  string
+	Filters   repository.SearchFilters
+}
+
+// retrieveContext checks that the user can search the workspace and returns the most relevant chunks for the question, ordered by their hybrid score
+func (e *entryHandler) retrieveContext(
+	ctx context.Context,
+	args *retrieveContextArgs,
+) ([]llm.ContextChunk, error) {
+	status, err := e.repos.WorkspaceRepository().
+		FindWithMembershipStatus(args.Workspace, args.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !status.MembershipStatus.Role.Can(rbac.PermSearchEntry) {
+		return nil, rbac.ErrPermissionDenied
+	}
+
+	rankFusion, err := e.rankFusionParams(status.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var vector []float32
+	var embeddingModel models.EmbeddingModel
+	if e.config.LLM.EnabledEmbeddings() {
+		embeddingModel, err = e.embeddingModel(status.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		vector, err = e.llm.GenerateEmbedding(ctx, embeddingModel, args.Question)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := e.repos.EntryRepository().QueryWithHybridSearch(&repository.HybridSearchArgs{
+		Context:         ctx,
+		Query:           repository.NewTextSearchQuery(args.Question),
+		SemanticVector:  vector,
+		EmbeddingModel:  embeddingModel,
+		UserID:          args.UserID,
+		Workspace:       args.Workspace,
+		Filters:         args.Filters,
+		RankFusion:      rankFusion,
+		FuzzyMinResults: 0,
+		Pagination: repository.PaginationParams{
+			Page:    1,
+			PerPage: llm.MaxAnswerChunks,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]llm.ContextChunk, 0, llm.MaxAnswerChunks)
+	for i := range results.CollapsedResults {
+		result := &results.CollapsedResults[i]
+		for j := range result.Matches {
+			chunks = append(chunks, llm.ContextChunk{
+				EntryID:    result.ID,
+				EntryName:  result.Name,
+				ChunkIndex: result.Matches[j].Index,
+				Collection: result.Collection,
+				Content:    result.Matches[j].Text,
+				Score:      result.Matches[j].HybridScore,
+			})
+		}
+	}
+
+	slices.SortStableFunc(chunks, func(a, b llm.ContextChunk) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	return lib.WithMaxSize(chunks, llm.MaxAnswerChunks), nil
+}
+
+// Requeue implements EntryHandler.
+func (e *entryHandler) Requeue(
+	ctx *robin.Context,
+	request RequeueEntriesRequest,
+) (RequeueEntriesResponse, error) {
+	var response RequeueEntriesResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	workspaceId := lib.PgUUIDString(request.WorkspaceID)
+	result, err := e.repos.WorkspaceRepository().FindWithMembershipStatus(
+		repository.PublicIdOrSlug{PublicID: workspaceId}, //nolint:exhaustruct
+		auth.UserID,
+	)
+	if err != nil {
+		return response, err
+	}
+
+	if !result.MembershipStatus.Role.Can(rbac.PermRequeueEntry) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	// Filter duplicates and format the entries
+	uniqueEntries := lib.UniqueSlice(request.EntryIDs)
+	ids := make([]pgtype.UUID, 0, len(uniqueEntries))
+	for _, entry := range uniqueEntries {
+		id, err := lib.UUIDFromString(entry)
+		if err != nil {
+			return response, apperrors.BadRequest("invalid entry ID: " + entry)
+		}
+		ids = append(ids, id)
+	}
+
+	queued, err := e.repos.EntryRepository().RequeueEntries(&repository.RequeueEntriesArgs{
+		WorkspaceID:    workspaceId,
+		EntryPublicIDs: ids,
+	})
+	if err != nil {
+		return response, err
+	}
+
+	if len(queued) == 0 {
+		return response, apperrors.BadRequest("unable to requeue entries")
+	}
+
+	// Send the entries to the queue
+	go func(entries []int32) {
+		for _, id := range entries {
+			if err := e.queue.Add(&job.EntryJob{ID: id}); err != nil {
+				log.Error().Err(err).Msg("failed to enqueue entry")
+			}
+		}
+		log.Debug().Int("count", len(entries)).Msg("entries re-queued successfully")
+	}(queued)
+
+	return RequeueEntriesResponse{
+		WorkspaceSlug: result.Workspace.Slug,
+		Count:         len(queued),
+	}, nil
+}
+
+// Delete implements EntryHandler.
+func (e *entryHandler) Delete(
+	ctx *robin.Context,
+	request DeleteEntriesRequest,
+) (DeleteEntriesResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return DeleteEntriesResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return DeleteEntriesResponse{}, err
+	}
+
+	requested, allowed, err := e.permittedEntries(
+		auth.UserID,
+		request.Entries,
+		rbac.PermDeleteEntry,
+		"delete",
+	)
+	if err != nil {
+		return DeleteEntriesResponse{}, err
+	}
+
+	// Deleted entries are moved to the trash, they are purged once the retention period of the workspace has passed
+	deletedEntries, err := e.repos.EntryRepository().Delete(repository.DeleteEntriesArgs{
+		WorkspaceSlug: request.WorkspaceSlug,
+		Entries:       allowed,
+	})
+	if err != nil {
+		return DeleteEntriesResponse{}, err
+	}
+
+	message := "successfully moved " + countEntries(len(deletedEntries)) + " to the trash"
+	if len(allowed) != requested {
+		message = "some entries could not be deleted, this may be due to insufficient permissions"
+	}
+
+	return DeleteEntriesResponse{
+		DeletedEntries: deletedEntries,
+		Message:        lib.UppercaseFirst(message),
+	}, nil
+}
+
+// ListTrash implements EntryHandler.
+func (e *entryHandler) ListTrash(
+	ctx *robin.Context,
+	request ListTrashRequest,
+) (ListTrashResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return ListTrashResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return ListTrashResponse{}, err
+	}
+
+	workspace, err := e.repos.WorkspaceRepository().FindWithMembershipStatus(
+		repository.PublicIdOrSlug{Slug: request.WorkspaceSlug}, //nolint:exhaustruct
+		auth.UserID,
+	)
+	if err != nil {
+		return ListTrashResponse{}, err
+	}
+
+	if !workspace.MembershipStatus.Role.Can(rbac.PermListWorkspaceEntries) {
+		return ListTrashResponse{}, apperrors.Forbidden("permission denied")
+	}
+
+	data, err := e.repos.EntryRepository().ListTrash(
+		&repository.ListTrashArgs{WorkspaceSlug: workspace.Workspace.Slug, UserID: auth.UserID},
+		request.Pagination,
+	)
+	if err != nil {
+		return ListTrashResponse{}, err
+	}
+
+	return ListTrashResponse{
+		Entries:       data.Entries,
+		WorkspaceSlug: workspace.Workspace.Slug,
+		Pagination: request.Pagination.ToState(repository.PageStateArgs{
+			CurrentCount: len(data.Entries),
+			TotalCount:   data.TotalCount,
+		}),
+	}, nil
+}
+
+// Restore implements EntryHandler.
+func (e *entryHandler) Restore(
+	ctx *robin.Context,
+	request DeleteEntriesRequest,
+) (RestoreEntriesResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return RestoreEntriesResponse{}, err
 	}
 
 	if err := lib.ValidateStruct(&request); err != nil {
-		return response, err
+		return RestoreEntriesResponse{}, err
 	}
-	request.Query = strings.TrimSpace(request.Query)
 
-	workspaceID := lib.PgUUIDString(request.WorkspaceID)
-	status, err := e.repos.WorkspaceRepository().FindWithMembershipStatus(
-		repository.PublicIdOrSlug{
-			PublicID: workspaceID,
-			Slug:     request.WorkspaceSlug,
-		}, //nolint:exhaustruct
+	requested, allowed, err := e.permittedEntries(
 		auth.UserID,
+		request.Entries,
+		rbac.PermDeleteEntry,
+		"delete",
 	)
 	if err != nil {
-		return response, err
+		return RestoreEntriesResponse{}, err
 	}
 
-	if !status.MembershipStatus.Role.Can(rbac.PermSearchEntry) {
-		return response, rbac.ErrPermissionDenied
+	restored, err := e.repos.EntryRepository().Restore(repository.DeleteEntriesArgs{
+		WorkspaceSlug: request.WorkspaceSlug,
+		Entries:       allowed,
+	})
+	if err != nil {
+		return RestoreEntriesResponse{}, err
 	}
 
-	pagination := repository.PaginationParams{
-		Page:    1,
-		PerPage: 75, // For now, 75
+	// Entries that were deleted before they were processed are skipped by the queue, they are sent to it again
+	go func(entries []int32) {
+		for _, id := range entries {
+			if err := e.queue.Add(&job.EntryJob{ID: id}); err != nil {
+				log.Error().Err(err).Int32("entry_id", id).Msg("failed to enqueue restored entry")
+			}
+		}
+	}(restored.QueuedIDs)
+
+	message := "successfully restored " + countEntries(len(restored.Entries))
+	if len(allowed) != requested {
+		message = "some entries could not be restored, this may be due to insufficient permissions"
 	}
 
-	// We need a context to control the timeout
-	parentCtx := context.Background()
-	timedCtx, cancel := context.WithTimeout(parentCtx, time.Minute*1)
-	defer cancel()
+	return RestoreEntriesResponse{
+		RestoredEntries: restored.Entries,
+		Message:         lib.UppercaseFirst(message),
+	}, nil
+}
 
-	// Generate vector if enabled
-	var vector []float32
-	if e.config.LLM.EnabledEmbeddings() {
-		vector, err = e.llm.GenerateEmbedding(timedCtx, request.Query)
-		if err != nil {
-			return response, err
-		}
+// Purge implements EntryHandler.
+func (e *entryHandler) Purge(
+	ctx *robin.Context,
+	request DeleteEntriesRequest,
+) (PurgeEntriesResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return PurgeEntriesResponse{}, err
 	}
 
-	start := time.Now() // we are not interested in the time taken for the vector generation, we don't control this
-	results, err := e.repos.EntryRepository().QueryWithHybridSearch(&repository.HybridSearchArgs{
-		Context:        timedCtx,
-		TextQuery:      request.Query,
-		SemanticVector: vector,
-		UserID:         auth.UserID,
-		Pagination:     pagination,
-		Workspace: repository.PublicIdOrSlug{
-			PublicID: workspaceID,
-			Slug:     request.WorkspaceSlug,
-		},
+	if err := lib.ValidateStruct(&request); err != nil {
+		return PurgeEntriesResponse{}, err
+	}
+
+	requested, allowed, err := e.permittedEntries(
+		auth.UserID,
+		request.Entries,
+		rbac.PermPurgeEntry,
+		"purge",
+	)
+	if err != nil {
+		return PurgeEntriesResponse{}, err
+	}
+
+	purged, err := e.repos.EntryRepository().Purge(repository.DeleteEntriesArgs{
+		WorkspaceSlug: request.WorkspaceSlug,
+		Entries:       allowed,
 	})
 	if err != nil {
-		return response, err
+		return PurgeEntriesResponse{}, err
 	}
 
-	// Apply specificity if set
-	if e.config.Search.Mode == config.SearchModeThreshold {
-		results.CollapsedResults = repository.ApplyMinThreshold(
-			results.CollapsedResults,
-			e.config.Search.Threshold,
-		)
+	// The entries are gone at this point, files that could not be removed are retried by the trash cron
+	go func(fileIds []string) {
+		err := e.objectsStore.DeleteFiles(context.Background(), fileIds...)
+		if err == nil {
+			return
+		}
+
+		log.Error().Err(err).Strs("file_ids", fileIds).Msg("failed to delete purged files")
+		if err := e.repos.EntryRepository().RecordFailedFileDeletions(fileIds, err); err != nil {
+			log.Error().
+				Err(err).
+				Strs("file_ids", fileIds).
+				Msg("failed to record failed file deletions")
+		}
+	}(purged.FileIDs)
+
+	message := "successfully purged " + countEntries(len(purged.Entries))
+	if len(allowed) != requested {
+		message = "some entries could not be purged, this may be due to insufficient permissions"
 	}
 
-	return SearchResponse{
-		Results:   *results,
-		Query:     request.Query,
-		TimeTaken: time.Since(start).Milliseconds(),
+	return PurgeEntriesResponse{
+		PurgedEntries: purged.Entries,
+		Message:       lib.UppercaseFirst(message),
 	}, nil
 }
 
-// Find implements EntryHandler.
-func (e *entryHandler) Find(
+// Archive implements EntryHandler.
+func (e *entryHandler) Archive(
 	ctx *robin.Context,
-	request FindEntryRequest,
-) (FindEntryResponse, error) {
-	var response FindEntryResponse
-
+	request ArchiveEntriesRequest,
+) (ArchiveEntriesResponse, error) {
 	auth, err := authlib.ExtractAuthSession(ctx)
 	if err != nil {
-		return response, err
+		return ArchiveEntriesResponse{}, err
 	}
 
 	if err := lib.ValidateStruct(&request); err != nil {
-		return response, err
+		return ArchiveEntriesResponse{}, err
 	}
 
-	entry, err := e.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
-		InternalID: 0,
-		PublicID:   lib.PgUUIDString(request.EntryID),
-		Collection: repository.PublicIdOrSlug{Slug: request.CollectionSlug}, //nolint:exhaustruct
-		Workspace:  repository.PublicIdOrSlug{Slug: request.WorkspaceSlug},  //nolint:exhaustruct
-	})
+	requested, allowed, err := e.permittedEntries(
+		auth.UserID,
+		request.Entries,
+		rbac.PermArchiveEntry,
+		"archive",
+	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return response, apperrors.BadRequest("entry not found or has been deleted")
-		}
-		return response, err
+		return ArchiveEntriesResponse{}, err
 	}
 
-	// NOTE: We don't really care about the workspace here since entries are largely independent of their workspace (for now anyway)
-
-	// Check if the user has access to the entry in this collection's context
-	perm, err := e.repos.CollectionRepository().
-		FindWithMembershipStatus(&repository.FindWithMembershipStatusArgs{
-			UserID:         auth.UserID,
-			WorkspaceID:    entry.Workspace.ID,
-			CollectionID:   entry.Collection.ID,
-			WorkspaceSlug:  "",
-			CollectionSlug: "",
-		})
+	archivedEntries, err := e.repos.EntryRepository().Archive(repository.ArchiveEntriesArgs{
+		WorkspaceSlug: request.WorkspaceSlug,
+		Entries:       allowed,
+	})
 	if err != nil {
-		return response, err
+		return ArchiveEntriesResponse{}, err
 	}
 
-	if !perm.MembershipStatus.Role.Can(rbac.PermReadEntry) {
-		return response, rbac.ErrPermissionDenied
+	message := "successfully archived " + countEntries(len(archivedEntries))
+	if len(allowed) != requested {
+		message = "some entries could not be archived, this may be due to insufficient permissions"
 	}
 
-	return FindEntryResponse{Entry: entry}, nil
+	return ArchiveEntriesResponse{
+		ArchivedEntries: archivedEntries,
+		Message:         lib.UppercaseFirst(message),
+	}, nil
 }
 
-// Requeue implements EntryHandler.
-func (e *entryHandler) Requeue(
+// Unarchive implements EntryHandler.
+func (e *entryHandler) Unarchive(
 	ctx *robin.Context,
-	request RequeueEntriesRequest,
-) (RequeueEntriesResponse, error) {
-	var response RequeueEntriesResponse
-
+	request ArchiveEntriesRequest,
+) (UnarchiveEntriesResponse, error) {
 	auth, err := authlib.ExtractAuthSession(ctx)
 	if err != nil {
-		return response, err
+		return UnarchiveEntriesResponse{}, err
 	}
 
 	if err := lib.ValidateStruct(&request); err != nil {
-		return response, err
+		return UnarchiveEntriesResponse{}, err
 	}
 
-	workspaceId := lib.PgUUIDString(request.WorkspaceID)
-	result, err := e.repos.WorkspaceRepository().FindWithMembershipStatus(
-		repository.PublicIdOrSlug{PublicID: workspaceId}, //nolint:exhaustruct
+	requested, allowed, err := e.permittedEntries(
 		auth.UserID,
+		request.Entries,
+		rbac.PermArchiveEntry,
+		"archive",
 	)
 	if err != nil {
-		return response, err
+		return UnarchiveEntriesResponse{}, err
 	}
 
-	if !result.MembershipStatus.Role.Can(rbac.PermRequeueEntry) {
-		return response, rbac.ErrPermissionDenied
+	unarchived, err := e.repos.EntryRepository().Unarchive(repository.ArchiveEntriesArgs{
+		WorkspaceSlug: request.WorkspaceSlug,
+		Entries:       allowed,
+	})
+	if err != nil {
+		return UnarchiveEntriesResponse{}, err
 	}
 
-	// Filter duplicates and format the entries
-	uniqueEntries := lib.UniqueSlice(request.EntryIDs)
-	ids := make([]pgtype.UUID, 0, len(uniqueEntries))
-	for _, entry := range uniqueEntries {
-		id, err := lib.UUIDFromString(entry)
-		if err != nil {
-			return response, apperrors.BadRequest("invalid entry ID: " + entry)
+	// Entries that were archived before they were processed are skipped by the queue, they are sent to it again
+	go func(entries []int32) {
+		for _, id := range entries {
+			if err := e.queue.Add(&job.EntryJob{ID: id}); err != nil {
+				log.Error().Err(err).Int32("entry_id", id).Msg("failed to enqueue unarchived entry")
+			}
 		}
-		ids = append(ids, id)
+	}(unarchived.QueuedIDs)
+
+	message := "successfully unarchived " + countEntries(len(unarchived.Entries))
+	if len(allowed) != requested {
+		message = "some entries could not be unarchived, this may be due to insufficient permissions"
 	}
 
-	queued, err := e.repos.EntryRepository().RequeueEntries(&repository.RequeueEntriesArgs{
-		WorkspaceID:    workspaceId,
-		EntryPublicIDs: ids,
+	return UnarchiveEntriesResponse{
+		UnarchivedEntries: unarchived.Entries,
+		Message:           lib.UppercaseFirst(message),
+	}, nil
+}
+
+// Move implements EntryHandler.
+func (e *entryHandler) Move(
+	ctx *robin.Context,
+	request TransferEntriesRequest,
+) (MoveEntriesResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return MoveEntriesResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return MoveEntriesResponse{}, err
+	}
+
+	target, err := e.writableCollection(
+		auth.UserID,
+		request.TargetWorkspaceSlug,
+		request.TargetCollectionSlug,
+	)
+	if err != nil {
+		return MoveEntriesResponse{}, err
+	}
+
+	requested, allowed, err := e.permittedEntries(
+		auth.UserID,
+		request.Entries,
+		rbac.PermMoveEntry,
+		"move",
+	)
+	if err != nil {
+		return MoveEntriesResponse{}, err
+	}
+
+	movedEntries, err := e.repos.EntryRepository().Move(repository.MoveEntriesArgs{
+		WorkspaceSlug: request.WorkspaceSlug,
+		Entries:       allowed,
+		CollectionID:  target.CollectionID,
 	})
 	if err != nil {
-		return response, err
+		return MoveEntriesResponse{}, err
 	}
 
-	if len(queued) == 0 {
-		return response, apperrors.BadRequest("unable to requeue entries")
+	message := "successfully moved " + countEntries(len(movedEntries))
+	if len(allowed) != requested {
+		message = "some entries could not be moved, this may be due to insufficient permissions"
 	}
 
-	// Send the entries to the queue
+	return MoveEntriesResponse{
+		MovedEntries: movedEntries,
+		Message:      lib.UppercaseFirst(message),
+	}, nil
+}
+
+// Copy implements EntryHandler.
+func (e *entryHandler) Copy(
+	ctx *robin.Context,
+	request TransferEntriesRequest,
+) (CopyEntriesResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return CopyEntriesResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return CopyEntriesResponse{}, err
+	}
+
+	target, err := e.writableCollection(
+		auth.UserID,
+		request.TargetWorkspaceSlug,
+		request.TargetCollectionSlug,
+	)
+	if err != nil {
+		return CopyEntriesResponse{}, err
+	}
+
+	requested, allowed, err := e.permittedEntries(
+		auth.UserID,
+		request.Entries,
+		rbac.PermCopyEntry,
+		"copy",
+	)
+	if err != nil {
+		return CopyEntriesResponse{}, err
+	}
+
+	// Copying the files can take a while for large entries
+	timedCtx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+
+	copied, err := e.repos.EntryRepository().Copy(&repository.CopyEntriesArgs{
+		Context:        timedCtx,
+		WorkspaceSlug:  request.WorkspaceSlug,
+		Entries:        allowed,
+		UserID:         auth.UserID,
+		CollectionID:   target.CollectionID,
+		CrossWorkspace: request.WorkspaceSlug != request.TargetWorkspaceSlug,
+		Files:          e.objectsStore,
+	})
+	if err != nil {
+		return CopyEntriesResponse{}, err
+	}
+
+	// Copies of entries that were not processed yet are processed on their own
 	go func(entries []int32) {
 		for _, id := range entries {
 			if err := e.queue.Add(&job.EntryJob{ID: id}); err != nil {
-				log.Error().Err(err).Msg("failed to enqueue entry")
+				log.Error().Err(err).Int32("entry_id", id).Msg("failed to enqueue copied entry")
 			}
 		}
-		log.Debug().Int("count", len(entries)).Msg("entries re-queued successfully")
-	}(queued)
+	}(copied.QueuedIDs)
 
-	return RequeueEntriesResponse{
-		WorkspaceSlug: result.Workspace.Slug,
-		Count:         len(queued),
+	message := "successfully copied " + countEntries(len(copied.Entries))
+	if len(allowed) != requested {
+		message = "some entries could not be copied, this may be due to insufficient permissions"
+	}
+
+	return CopyEntriesResponse{
+		CopiedEntries: copied.Entries,
+		Message:       lib.UppercaseFirst(message),
 	}, nil
 }
 
-// Delete implements EntryHandler.
-func (e *entryHandler) Delete(
-	ctx *robin.Context,
-	request DeleteEntriesRequest,
-) (DeleteEntriesResponse, error) {
-	auth, err := authlib.ExtractAuthSession(ctx)
+// writableCollection returns the user's membership of a collection entries are added to, such as the target of a move or a copy, they need to be able to add entries to it
+func (e *entryHandler) writableCollection(
+	userID int32,
+	workspaceSlug string,
+	collectionSlug string,
+) (models.CollectionMember, error) {
+	//nolint:exhaustruct
+	member, err := e.repos.CollectionRepository().FindMember(
+		repository.PublicIdOrSlug{Slug: workspaceSlug},
+		repository.PublicIdOrSlug{Slug: collectionSlug},
+		userID,
+	)
 	if err != nil {
-		return DeleteEntriesResponse{}, err
+		return models.CollectionMember{}, err
 	}
 
-	if err := lib.ValidateStruct(&request); err != nil {
-		return DeleteEntriesResponse{}, err
+	if !member.Role.Can(rbac.PermCreateEntry) {
+		return models.CollectionMember{}, rbac.ErrPermissionDenied
 	}
 
-	// Filter duplicates
-	uniqueEntries := lib.UniqueSlice(request.Entries)
-	entries := make([]pgtype.UUID, 0)
+	return member, nil
+}
 
+// permittedEntries returns the number of unique requested entries and the ones the user either owns or has the permission for
+func (e *entryHandler) permittedEntries(
+	userID int32,
+	entryIDs []string,
+	permission rbac.Permission,
+	action string,
+) (int, []pgtype.UUID, error) {
+	uniqueEntries := lib.UniqueSlice(entryIDs)
+	entries := make([]pgtype.UUID, 0, len(uniqueEntries))
 	for _, entry := range uniqueEntries {
 		id, err := lib.UUIDFromString(entry)
 		if err != nil {
-			return DeleteEntriesResponse{}, apperrors.BadRequest("invalid entry ID: " + entry)
+			return 0, nil, apperrors.BadRequest("invalid entry ID: " + entry)
 		}
 
 		entries = append(entries, id)
 	}
 
-	ownerships, err := e.repos.EntryRepository().GetOwnerships(auth.UserID, entries)
+	ownerships, err := e.repos.EntryRepository().GetOwnerships(userID, entries)
 	if err != nil {
-		return DeleteEntriesResponse{}, seer.Wrap(
+		return 0, nil, seer.Wrap(
 			"get_entries_ownerships",
 			err,
 			"oops, we ran into an issue while trying to process your request",
 		)
 	}
 
-	// Filter out entries that the user doesn't have access to delete
-	entriesToDelete := make([]pgtype.UUID, 0)
+	allowed := make([]pgtype.UUID, 0, len(ownerships))
 	for _, ownership := range ownerships {
-		if ownership.IsOwner || ownership.UserRole.Can(rbac.PermDeleteEntry) {
-			entriesToDelete = append(entriesToDelete, ownership.EntryID)
+		if ownership.IsOwner || ownership.UserRole.Can(permission) {
+			allowed = append(allowed, ownership.EntryID)
 		}
 	}
 
-	if len(entriesToDelete) == 0 {
-		return DeleteEntriesResponse{}, apperrors.Forbidden("no entries with delete permission")
+	if len(allowed) == 0 {
+		return 0, nil, apperrors.Forbidden("no entries with " + action + " permission")
 	}
 
-	// Delete entries
-	deletedEntries, err := e.repos.EntryRepository().Delete(repository.DeleteEntriesArgs{
-		Entries: entries,
-	})
-	if err != nil {
-		return DeleteEntriesResponse{}, err
-	}
+	return len(entries), allowed, nil
+}
 
-	suffix := "ies"
-	if len(deletedEntries) == 1 {
-		suffix = "y"
-	}
-	message := fmt.Sprintf("successfully deleted %d entr%s", len(deletedEntries), suffix)
-	if len(entriesToDelete) != len(entries) {
-		message = "some entries could not be deleted, this may be due to insufficient permissions"
+// countEntries formats a number of entries, e.g. "1 entry" or "3 entries"
+func countEntries(count int) string {
+	if count == 1 {
+		return "1 entry"
 	}
 
-	return DeleteEntriesResponse{
-		DeletedEntries: deletedEntries,
-		Message:        lib.UppercaseFirst(message),
-	}, nil
+	return fmt.Sprintf("%d entries", count)
 }
 
 // FindCollectionEntries implements EntryHandler.
@@ -331,6 +1207,11 @@ func (e *entryHandler) FindCollectionEntries(
 		)
 	}
 
+	after, err := e.decodeCursor(repository.CursorScopeEntries, request.Cursor)
+	if err != nil {
+		return FindEntriesResponse{}, err
+	}
+
 	data, err := e.repos.EntryRepository().FindAllWithPagination(
 		//nolint:exhaustruct
 		&repository.FindEntriesArgs{
@@ -340,7 +1221,11 @@ func (e *entryHandler) FindCollectionEntries(
 			Collection: repository.PublicIdOrSlug{
 				Slug: request.CollectionSlug,
 			},
-			UserID: auth.UserID,
+			UserID:          auth.UserID,
+			Tags:            request.Tags,
+			IncludeArchived: request.IncludeArchived,
+			ArchivedOnly:    request.ArchivedOnly,
+			After:           after,
 		},
 		request.Pagination,
 	)
@@ -348,14 +1233,7 @@ func (e *entryHandler) FindCollectionEntries(
 		return FindEntriesResponse{}, err
 	}
 
-	return FindEntriesResponse{
-		Entries:       data.Entries,
-		WorkspaceSlug: request.WorkspaceSlug,
-		Pagination: request.Pagination.ToState(repository.PageStateArgs{
-			CurrentCount: len(data.Entries),
-			TotalCount:   data.TotalCount,
-		}),
-	}, nil
+	return e.makeFindEntriesResponse(request.WorkspaceSlug, request.Pagination, after, &data)
 }
 
 // FindWorkspaceEntries implements EntryHandler.
@@ -383,24 +1261,54 @@ func (e *entryHandler) FindWorkspaceEntries(
 	if !canListEntries {
 		return FindEntriesResponse{}, apperrors.Forbidden("permission denied")
 	}
+	after, err := e.decodeCursor(repository.CursorScopeEntries, request.Cursor)
+	if err != nil {
+		return FindEntriesResponse{}, err
+	}
+
 	data, err := e.repos.EntryRepository().FindAllWithPagination(
 		//nolint:exhaustruct
 		&repository.FindEntriesArgs{
-			Workspace: repository.PublicIdOrSlug{PublicID: workspace.PublicID},
-			UserID:    auth.UserID,
+			Workspace:       repository.PublicIdOrSlug{PublicID: workspace.PublicID},
+			UserID:          auth.UserID,
+			Tags:            request.Tags,
+			IncludeArchived: request.IncludeArchived,
+			ArchivedOnly:    request.ArchivedOnly,
+			After:           after,
 		}, request.Pagination,
 	)
 	if err != nil {
 		return FindEntriesResponse{}, err
 	}
 
-	return FindEntriesResponse{
-		Entries:       data.Entries,
-		WorkspaceSlug: workspace.Workspace.Slug,
-		Pagination: request.Pagination.ToState(repository.PageStateArgs{
+	return e.makeFindEntriesResponse(workspace.Workspace.Slug, request.Pagination, after, &data)
+}
+
+// makeFindEntriesResponse builds the response of entry listings, page numbers and the total count are unknown when paginating with a cursor
+func (e *entryHandler) makeFindEntriesResponse(
+	workspaceSlug string,
+	pagination repository.PaginationParams,
+	after *repository.Cursor,
+	data *repository.FindEntriesByWorkspaceResult,
+) (FindEntriesResponse, error) {
+	nextCursor, err := e.encodeCursor(repository.CursorScopeEntries, data.NextCursor)
+	if err != nil {
+		return FindEntriesResponse{}, err
+	}
+
+	state := repository.PaginationState{} //nolint:exhaustruct
+	if after == nil {
+		state = pagination.ToState(repository.PageStateArgs{
 			CurrentCount: len(data.Entries),
 			TotalCount:   data.TotalCount,
-		}),
+		})
+	}
+
+	return FindEntriesResponse{
+		Entries:       data.Entries,
+		WorkspaceSlug: workspaceSlug,
+		Pagination:    state,
+		NextCursor:    nextCursor,
 	}, nil
 }
 
@@ -530,6 +1438,16 @@ func (e *entryHandler) Import(ctx *robin.Context, body io.ReadCloser) (ImportEnt
 		return ImportEntryResponse{}, seer.Wrap("enqueue_entries_in_handler", err)
 	}
 
+	// The links written before these entries existed can point at them now
+	createdIDs := make([]pgtype.UUID, 0, len(createdEntries))
+	for _, entry := range createdEntries {
+		createdIDs = append(createdIDs, entry.ID)
+	}
+
+	if err := e.repos.LinkRepository().Resolve(createdIDs); err != nil {
+		log.Error().Err(err).Msg("failed to resolve links to imported entries")
+	}
+
 	// Send the entries to the queue
 	go func(entries []repository.EnqueueEntryParams) {
 		for _, entry := range entries {
@@ -630,6 +1548,443 @@ func (e *entryHandler) ImportLinks(
 	return entries
 }
 
+// CreateVersion implements EntryHandler.
+func (e *entryHandler) CreateVersion(
+	ctx *robin.Context,
+	body io.ReadCloser,
+) (CreateEntryVersionResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return CreateEntryVersionResponse{}, err
+	}
+
+	payload, err := extractEntryVersionPayload(ctx)
+	if err != nil {
+		return CreateEntryVersionResponse{}, err
+	}
+
+	latest, err := e.repos.EntryVersionRepository().FindLatest(payload.EntryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CreateEntryVersionResponse{}, apperrors.BadRequest(
+				"entry not found or has been deleted",
+			)
+		}
+		return CreateEntryVersionResponse{}, err
+	}
+
+	if latest.Workspace.ID != payload.WorkspaceID {
+		return CreateEntryVersionResponse{}, apperrors.BadRequest(
+			"entry does not exist in this workspace",
+		)
+	}
+
+	if err := e.ensureEntryPermission(auth.UserID, &latest, rbac.PermUpdateEntry); err != nil {
+		return CreateEntryVersionResponse{}, err
+	}
+
+	args := repository.CreateEntryVersionArgs{
+		EntryID: latest.PublicID,
+		UserID:  auth.UserID,
+		File:    nil,
+		Link:    nil,
+	}
+
+	switch meta := latest.Metadata.(type) {
+	case ograph.Metadata:
+		if len(payload.Files) > 0 {
+			return CreateEntryVersionResponse{}, apperrors.BadRequest(
+				"links can only be refreshed, files cannot be uploaded as a new version of a link",
+			)
+		}
+
+		// The cached metadata is skipped, the point of a new version is to fetch the page again
+		refreshed, err := ograph.Parse(meta.Link)
+		if err != nil {
+			return CreateEntryVersionResponse{}, err
+		}
+
+		go func() {
+			if err := e.repos.EntryRepository().SaveLinkMetadata(meta.Link, refreshed); err != nil {
+				log.Error().Err(err).Msg("failed to cache metadata")
+			}
+		}()
+
+		args.Link = refreshed
+
+	default:
+		if len(payload.Files) != 1 {
+			return CreateEntryVersionResponse{}, apperrors.BadRequest(
+				"exactly one file is required to create a new version of this entry",
+			)
+		}
+
+		file := payload.Files[0]
+		args.File = &models.FileEntry{
+			FileID:       file.StorageKey,
+			OriginalName: file.OriginalName,
+			SavedName:    file.UploadedFileName,
+			Filesize:     file.Size,
+			MimeType:     file.MimeType,
+			Type: document.InferType(
+				document.InferExtension(file.OriginalName),
+				file.MimeType,
+			),
+			CollectionID: 0,
+			UserID:       auth.UserID,
+		}
+	}
+
+	created, err := e.repos.EntryVersionRepository().Create(&args)
+	if err != nil {
+		return CreateEntryVersionResponse{}, err
+	}
+
+	e.queueEntryVersion(&created)
+
+	return CreateEntryVersionResponse{Entry: created.Entry, Version: created.Version}, nil
+}
+
+// ListVersions implements EntryHandler.
+func (e *entryHandler) ListVersions(
+	ctx *robin.Context,
+	request ListEntryVersionsRequest,
+) (ListEntryVersionsResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return ListEntryVersionsResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return ListEntryVersionsResponse{}, err
+	}
+
+	entry, err := e.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+		InternalID: 0,
+		PublicID:   lib.PgUUIDString(request.EntryID),
+		Collection: repository.PublicIdOrSlug{Slug: request.CollectionSlug}, //nolint:exhaustruct
+		Workspace:  repository.PublicIdOrSlug{Slug: request.WorkspaceSlug},  //nolint:exhaustruct
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ListEntryVersionsResponse{}, apperrors.BadRequest(
+				"entry not found or has been deleted",
+			)
+		}
+		return ListEntryVersionsResponse{}, err
+	}
+
+	if err := e.ensureEntryPermission(auth.UserID, &entry, rbac.PermReadEntry); err != nil {
+		return ListEntryVersionsResponse{}, err
+	}
+
+	versions, err := e.repos.EntryVersionRepository().FindAll(entry.PublicID)
+	if err != nil {
+		return ListEntryVersionsResponse{}, err
+	}
+
+	return ListEntryVersionsResponse{Versions: versions}, nil
+}
+
+// RestoreVersion implements EntryHandler.
+func (e *entryHandler) RestoreVersion(
+	ctx *robin.Context,
+	request RestoreEntryVersionRequest,
+) (CreateEntryVersionResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return CreateEntryVersionResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return CreateEntryVersionResponse{}, err
+	}
+
+	//nolint:exhaustruct
+	entry, err := e.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+		PublicID:  lib.PgUUIDString(request.EntryID),
+		Workspace: repository.PublicIdOrSlug{PublicID: lib.PgUUIDString(request.WorkspaceID)},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CreateEntryVersionResponse{}, apperrors.BadRequest(
+				"entry not found or has been deleted",
+			)
+		}
+		return CreateEntryVersionResponse{}, err
+	}
+
+	if err := e.ensureEntryPermission(auth.UserID, &entry, rbac.PermUpdateEntry); err != nil {
+		return CreateEntryVersionResponse{}, err
+	}
+
+	restored, err := e.repos.EntryVersionRepository().Restore(&repository.RestoreEntryVersionArgs{
+		EntryID: entry.PublicID,
+		UserID:  auth.UserID,
+	})
+	if err != nil {
+		return CreateEntryVersionResponse{}, err
+	}
+
+	e.queueEntryVersion(&restored)
+
+	return CreateEntryVersionResponse{Entry: restored.Entry, Version: restored.Version}, nil
+}
+
+// DiffVersions implements EntryHandler.
+func (e *entryHandler) DiffVersions(
+	ctx *robin.Context,
+	request DiffEntryVersionsRequest,
+) (DiffEntryVersionsResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return DiffEntryVersionsResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return DiffEntryVersionsResponse{}, err
+	}
+
+	findVersion := func(entryID string) (models.Entry, error) {
+		entry, err := e.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+			InternalID: 0,
+			PublicID:   lib.PgUUIDString(entryID),
+			Collection: repository.PublicIdOrSlug{Slug: request.CollectionSlug}, //nolint:exhaustruct
+			Workspace:  repository.PublicIdOrSlug{Slug: request.WorkspaceSlug},  //nolint:exhaustruct
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.Entry{}, apperrors.BadRequest("version not found or has been deleted")
+			}
+			return models.Entry{}, err
+		}
+
+		return entry, nil
+	}
+
+	from, err := findVersion(request.FromID)
+	if err != nil {
+		return DiffEntryVersionsResponse{}, err
+	}
+
+	to, err := findVersion(request.ToID)
+	if err != nil {
+		return DiffEntryVersionsResponse{}, err
+	}
+
+	if from.OriginID != to.OriginID {
+		return DiffEntryVersionsResponse{}, apperrors.BadRequest(
+			"only versions of the same entry can be compared",
+		)
+	}
+
+	if err := e.ensureEntryPermission(auth.UserID, &to, rbac.PermReadEntry); err != nil {
+		return DiffEntryVersionsResponse{}, err
+	}
+
+	response := DiffEntryVersionsResponse{
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Text:        nil,
+		Chunks:      nil,
+	}
+
+	if request.Mode == "chunk" {
+		chunks, err := e.repos.EntryVersionRepository().DiffChunks(from.ID, to.ID)
+		if err != nil {
+			return DiffEntryVersionsResponse{}, err
+		}
+
+		response.Chunks = &chunks
+		return response, nil
+	}
+
+	oldText, newText := from.TextContent.String, to.TextContent.String
+	if request.Field == "content" {
+		oldText, newText = from.Content.String, to.Content.String
+	}
+
+	contextLines := diff.DefaultContext
+	if request.ContextLines != nil {
+		contextLines = *request.ContextLines
+	}
+
+	result, err := diff.Text(oldText, newText, diff.Options{
+		Context:  contextLines,
+		MaxBytes: diff.DefaultMaxBytes,
+		Words:    request.Mode == "word",
+		OldLabel: fmt.Sprintf("%s (v%d)", from.Name, from.Version),
+		NewLabel: fmt.Sprintf("%s (v%d)", to.Name, to.Version),
+	})
+	if err != nil {
+		if errors.Is(err, diff.ErrInputTooLarge) {
+			return DiffEntryVersionsResponse{}, apperrors.BadRequest(
+				"the versions are too large to be compared, try comparing their chunks instead",
+			)
+		}
+		return DiffEntryVersionsResponse{}, err
+	}
+
+	response.Text = &result
+	return response, nil
+}
+
+// CreateNote implements EntryHandler.
+func (e *entryHandler) CreateNote(
+	ctx *robin.Context,
+	request CreateNoteRequest,
+) (SaveNoteResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return SaveNoteResponse{}, err
+	}
+
+	request.Title = strings.TrimSpace(request.Title)
+	if err := lib.ValidateStruct(&request); err != nil {
+		return SaveNoteResponse{}, err
+	}
+
+	collection, err := e.writableCollection(
+		auth.UserID,
+		request.WorkspaceSlug,
+		request.CollectionSlug,
+	)
+	if err != nil {
+		return SaveNoteResponse{}, err
+	}
+
+	note, err := e.repos.NoteRepository().Create(&repository.CreateNoteArgs{
+		CollectionID: collection.CollectionID,
+		UserID:       auth.UserID,
+		Title:        request.Title,
+		Content:      request.Content,
+	})
+	if err != nil {
+		return SaveNoteResponse{}, err
+	}
+
+	e.queueNote(&note)
+
+	return SaveNoteResponse{
+		Entry:   note.Entry,
+		Version: note.Version,
+		Message: "Note created",
+	}, nil
+}
+
+// UpdateNote implements EntryHandler.
+func (e *entryHandler) UpdateNote(
+	ctx *robin.Context,
+	request UpdateNoteRequest,
+) (SaveNoteResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return SaveNoteResponse{}, err
+	}
+
+	request.Title = strings.TrimSpace(request.Title)
+	if err := lib.ValidateStruct(&request); err != nil {
+		return SaveNoteResponse{}, err
+	}
+
+	//nolint:exhaustruct
+	entry, err := e.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+		PublicID:  lib.PgUUIDString(request.EntryID),
+		Workspace: repository.PublicIdOrSlug{Slug: request.WorkspaceSlug},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SaveNoteResponse{}, repository.ErrNoteNotFound
+		}
+		return SaveNoteResponse{}, err
+	}
+
+	if err := e.ensureEntryPermission(auth.UserID, &entry, rbac.PermUpdateEntry); err != nil {
+		return SaveNoteResponse{}, err
+	}
+
+	note, err := e.repos.NoteRepository().Update(&repository.UpdateNoteArgs{
+		EntryID: entry.PublicID,
+		UserID:  auth.UserID,
+		Version: request.Version,
+		Title:   request.Title,
+		Content: request.Content,
+	})
+	if err != nil {
+		return SaveNoteResponse{}, err
+	}
+
+	e.queueNote(&note)
+
+	return SaveNoteResponse{
+		Entry:   note.Entry,
+		Version: note.Version,
+		Message: fmt.Sprintf("Note saved as version %d", note.Version),
+	}, nil
+}
+
+// ensureEntryPermission checks the user's role in the entry's collection
+func (e *entryHandler) ensureEntryPermission(
+	userID int32,
+	entry *models.Entry,
+	permission rbac.Permission,
+) error {
+	result, err := e.repos.CollectionRepository().
+		FindWithMembershipStatus(&repository.FindWithMembershipStatusArgs{
+			UserID:         userID,
+			WorkspaceID:    entry.Workspace.ID,
+			CollectionID:   entry.Collection.ID,
+			WorkspaceSlug:  "",
+			CollectionSlug: "",
+		})
+	if err != nil {
+		return err
+	}
+
+	if !result.MembershipStatus.Role.Can(permission) {
+		return rbac.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// queueEntryVersion sends a new version to the queue, restored versions that reuse the chunks of an older version are skipped
+func (e *entryHandler) queueEntryVersion(version *repository.CreatedEntryVersion) {
+	if !version.Queued {
+		return
+	}
+
+	go func(id int32) {
+		if err := e.queue.Add(&job.EntryJob{ID: id}); err != nil {
+			log.Error().Err(err).Int32("entry_id", id).Msg("failed to enqueue entry version")
+		}
+	}(version.Entry.InternalID)
+}
+
+// queueNote sends the chunks of a saved note that do not have an embedding yet to be embedded, notes skip the plugins so they are summarised from here too
+func (e *entryHandler) queueNote(note *repository.SavedNote) {
+	go func(id pgtype.UUID, internalID int32, pending bool) {
+		if pending {
+			err := e.queue.Add(&job.EntryChunkEmbeddingJob{Entries: []pgtype.UUID{id}})
+			if err != nil {
+				log.Error().
+					Err(err).
+					Int32("entry_id", internalID).
+					Msg("failed to enqueue note embedding")
+			}
+		}
+
+		if e.llm.ChatEnabled() {
+			if err := e.queue.Add(&job.EntrySummaryJob{ID: internalID}); err != nil {
+				log.Error().
+					Err(err).
+					Int32("entry_id", internalID).
+					Msg("failed to enqueue note summary")
+			}
+		}
+	}(note.Entry.ID, note.Entry.InternalID, note.Chunks > note.Reused)
+}
+
 func extractImportPayload(ctx *robin.Context) (*ImportEntryPayload, error) {
 	payload := new(ImportEntryPayload)
 
@@ -682,4 +2037,48 @@ func extractImportPayload(ctx *robin.Context) (*ImportEntryPayload, error) {
 	return payload, nil
 }
 
+func extractEntryVersionPayload(ctx *robin.Context) (*CreateEntryVersionPayload, error) {
+	err := ctx.Request().ParseMultipartForm(25 << 20)
+	if err != nil {
+		return &CreateEntryVersionPayload{}, apperrors.BadRequest("failed to parse form")
+	}
+
+	ctxFiles, ok := ctx.Get("files").(gulter.Files)
+	if !ok {
+		// Links are refreshed without a file
+		ctxFiles = gulter.Files{}
+	}
+
+	files := []gulter.File{}
+	if f, ok := ctxFiles["files"]; ok {
+		files = f
+	}
+
+	workspaceID := ctx.Request().MultipartForm.Value["workspace_id"]
+	if len(workspaceID) == 0 {
+		return &CreateEntryVersionPayload{}, apperrors.BadRequest("workspace ID is required")
+	}
+
+	entryID := ctx.Request().MultipartForm.Value["entry_id"]
+	if len(entryID) == 0 {
+		return &CreateEntryVersionPayload{}, apperrors.BadRequest("entry ID is required")
+	}
+
+	workspaceUUID, err := lib.UUIDFromString(workspaceID[0])
+	if err != nil {
+		return &CreateEntryVersionPayload{}, apperrors.BadRequest("invalid workspace ID")
+	}
+
+	entryUUID, err := lib.UUIDFromString(entryID[0])
+	if err != nil {
+		return &CreateEntryVersionPayload{}, apperrors.BadRequest("invalid entry ID")
+	}
+
+	return &CreateEntryVersionPayload{
+		WorkspaceID: workspaceUUID,
+		EntryID:     entryUUID,
+		Files:       files,
+	}, nil
+}
+
 var _ EntryHandler = (*entryHandler)(nil)
@@ -2,11 +2,13 @@ package api
 
 import (
 	"io"
+	"time"
 
 	"github.com/adelowo/gulter"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.trulyao.dev/hubble/web/internal/models"
 	"go.trulyao.dev/hubble/web/internal/repository"
+	"go.trulyao.dev/hubble/web/pkg/diff"
 	"go.trulyao.dev/hubble/web/pkg/ograph"
 	"go.trulyao.dev/robin"
 )
@@ -31,9 +33,30 @@ type (
 			request FindCollectionEntriesRequest,
 		) (FindEntriesResponse, error)
 
-		// Delete multiple entries
+		// Delete moves multiple entries to the trash
 		Delete(ctx *robin.Context, request DeleteEntriesRequest) (DeleteEntriesResponse, error)
 
+		// ListTrash returns the deleted entries of a workspace along with the time they will be purged at
+		ListTrash(ctx *robin.Context, request ListTrashRequest) (ListTrashResponse, error)
+
+		// Restore brings deleted entries back from the trash
+		Restore(ctx *robin.Context, request DeleteEntriesRequest) (RestoreEntriesResponse, error)
+
+		// Purge permanently deletes entries in the trash along with their files
+		Purge(ctx *robin.Context, request DeleteEntriesRequest) (PurgeEntriesResponse, error)
+
+		// Archive hides entries from listings and search without deleting them
+		Archive(ctx *robin.Context, request ArchiveEntriesRequest) (ArchiveEntriesResponse, error)
+
+		// Unarchive makes archived entries visible in listings and search again
+		Unarchive(ctx *robin.Context, request ArchiveEntriesRequest) (UnarchiveEntriesResponse, error)
+
+		// Move moves entries to another collection, possibly in another workspace
+		Move(ctx *robin.Context, request TransferEntriesRequest) (MoveEntriesResponse, error)
+
+		// Copy duplicates entries into another collection, possibly in another workspace
+		Copy(ctx *robin.Context, request TransferEntriesRequest) (CopyEntriesResponse, error)
+
 		// Requeue multiple entries
 		Requeue(ctx *robin.Context, request RequeueEntriesRequest) (RequeueEntriesResponse, error)
 
@@ -42,6 +65,45 @@ type (
 
 		// Search using full-text and/or vectors
 		Search(ctx *robin.Context, request SearchRequest) (SearchResponse, error)
+
+		// FindRelated returns the entries that are semantically similar to an entry
+		FindRelated(
+			ctx *robin.Context,
+			request FindRelatedEntriesRequest,
+		) (FindRelatedEntriesResponse, error)
+
+		// FindLinks returns the entries an entry links to, the entries that link to it and its unresolved links
+		FindLinks(ctx *robin.Context, request FindEntryLinksRequest) (FindEntryLinksResponse, error)
+
+		// AskQuestion answers a question using the most relevant chunks in the workspace, with citations
+		AskQuestion(ctx *robin.Context, request AskQuestionRequest) (AskQuestionResponse, error)
+
+		// CreateVersion replaces the file of a file entry or refreshes a link entry as a new version of the entry
+		CreateVersion(ctx *robin.Context, body io.ReadCloser) (CreateEntryVersionResponse, error)
+
+		// ListVersions returns all the versions of an entry
+		ListVersions(
+			ctx *robin.Context,
+			request ListEntryVersionsRequest,
+		) (ListEntryVersionsResponse, error)
+
+		// RestoreVersion makes a copy of an older version the latest version of its entry
+		RestoreVersion(
+			ctx *robin.Context,
+			request RestoreEntryVersionRequest,
+		) (CreateEntryVersionResponse, error)
+
+		// DiffVersions compares the content or the chunks of two versions of an entry
+		DiffVersions(
+			ctx *robin.Context,
+			request DiffEntryVersionsRequest,
+		) (DiffEntryVersionsResponse, error)
+
+		// CreateNote adds a markdown note written in Hubble to a collection
+		CreateNote(ctx *robin.Context, request CreateNoteRequest) (SaveNoteResponse, error)
+
+		// UpdateNote saves the changes made to a note as a new version, unless it was changed by someone else in the meantime
+		UpdateNote(ctx *robin.Context, request UpdateNoteRequest) (SaveNoteResponse, error)
 	}
 
 	DeleteEntriesRequest struct {
@@ -54,6 +116,59 @@ type (
 		Message        string        `json:"message"`
 	}
 
+	ListTrashRequest struct {
+		Pagination    repository.PaginationParams `json:"pagination"`
+		WorkspaceSlug string                      `json:"workspace_slug" validate:"required,slug"`
+	}
+
+	ListTrashResponse struct {
+		Entries       []models.TrashedEntry      `json:"entries"`
+		WorkspaceSlug string                     `json:"workspace_slug"`
+		Pagination    repository.PaginationState `json:"pagination"`
+	}
+
+	RestoreEntriesResponse struct {
+		RestoredEntries []pgtype.UUID `json:"restored_entries" mirror:"type:Array<string>"`
+		Message         string        `json:"message"`
+	}
+
+	PurgeEntriesResponse struct {
+		PurgedEntries []pgtype.UUID `json:"purged_entries" mirror:"type:Array<string>"`
+		Message       string        `json:"message"`
+	}
+
+	ArchiveEntriesRequest struct {
+		WorkspaceSlug string   `json:"workspace_slug" validate:"required,slug"`
+		Entries       []string `json:"entry_ids"      validate:"required,min=1,dive,uuid"`
+	}
+
+	ArchiveEntriesResponse struct {
+		ArchivedEntries []pgtype.UUID `json:"archived_entries" mirror:"type:Array<string>"`
+		Message         string        `json:"message"`
+	}
+
+	UnarchiveEntriesResponse struct {
+		UnarchivedEntries []pgtype.UUID `json:"unarchived_entries" mirror:"type:Array<string>"`
+		Message           string        `json:"message"`
+	}
+
+	TransferEntriesRequest struct {
+		WorkspaceSlug        string   `json:"workspace_slug"         validate:"required,slug"`
+		Entries              []string `json:"entry_ids"              validate:"required,min=1,dive,uuid"`
+		TargetWorkspaceSlug  string   `json:"target_workspace_slug"  validate:"required,slug"`
+		TargetCollectionSlug string   `json:"target_collection_slug" validate:"required,slug"`
+	}
+
+	MoveEntriesResponse struct {
+		MovedEntries []pgtype.UUID `json:"moved_entries" mirror:"type:Array<string>"`
+		Message      string        `json:"message"`
+	}
+
+	CopyEntriesResponse struct {
+		CopiedEntries []models.CreatedEntry `json:"copied_entries"`
+		Message       string                `json:"message"`
+	}
+
 	ImportEntryPayload struct {
 		CollectionID pgtype.UUID   `json:"collection_id" mirror:"type:string"`
 		WorkspaceID  pgtype.UUID   `json:"workspace_id"  mirror:"type:string"`
@@ -82,18 +197,36 @@ type (
 	FindWorkspaceEntriesRequest struct {
 		Pagination    repository.PaginationParams `json:"pagination"`
 		WorkspaceSlug string                      `json:"workspace_slug" validate:"required,slug"`
+		// Tags only returns the entries that have all the given tags
+		Tags []string `json:"tags" validate:"omitempty,dive,required,max=72" mirror:"optional:true"`
+		// IncludeArchived lists the archived entries along with the other entries
+		IncludeArchived bool `json:"include_archived" mirror:"optional:true"`
+		// ArchivedOnly only lists the archived entries
+		ArchivedOnly bool `json:"archived_only" mirror:"optional:true"`
+		// Cursor is the `next_cursor` of the previous page, the page number is ignored when it is set
+		Cursor string `json:"cursor" mirror:"optional:true"`
 	}
 
 	FindCollectionEntriesRequest struct {
 		Pagination     repository.PaginationParams `json:"pagination"`
 		CollectionSlug string                      `json:"collection_slug" validate:"required,slug"`
 		WorkspaceSlug  string                      `json:"workspace_slug"  validate:"required,slug"`
+		// Tags only returns the entries that have all the given tags
+		Tags []string `json:"tags" validate:"omitempty,dive,required,max=72" mirror:"optional:true"`
+		// IncludeArchived lists the archived entries along with the other entries
+		IncludeArchived bool `json:"include_archived" mirror:"optional:true"`
+		// ArchivedOnly only lists the archived entries
+		ArchivedOnly bool `json:"archived_only" mirror:"optional:true"`
+		// Cursor is the `next_cursor` of the previous page, the page number is ignored when it is set
+		Cursor string `json:"cursor" mirror:"optional:true"`
 	}
 
 	FindEntriesResponse struct {
-		Entries       []models.Entry             `json:"entries"`
-		WorkspaceSlug string                     `json:"workspace_slug"`
-		Pagination    repository.PaginationState `json:"pagination"`
+		Entries       []models.Entry `json:"entries"`
+		WorkspaceSlug string         `json:"workspace_slug"`
+		// Pagination only contains the page numbers and total count for page-number requests
+		Pagination repository.PaginationState `json:"pagination"`
+		NextCursor *string                    `json:"next_cursor"`
 	}
 
 	RequeueEntriesRequest struct {
@@ -116,15 +249,145 @@ type (
 		Entry models.Entry `json:"entry"`
 	}
 
+	CreateEntryVersionPayload struct {
+		WorkspaceID pgtype.UUID `json:"workspace_id" mirror:"type:string"`
+		// EntryID is the ID of any version of the entry
+		EntryID pgtype.UUID `json:"entry_id" mirror:"type:string"`
+		// Files must contain exactly one file for file entries and must be empty for links
+		Files []gulter.File `json:"files" mirror:"type:Array<File>,optional:true"`
+	}
+
+	CreateEntryVersionResponse struct {
+		Entry   models.CreatedEntry `json:"entry"`
+		Version int32               `json:"version"`
+	}
+
+	ListEntryVersionsRequest struct {
+		EntryID        string `json:"entry_id"        validate:"required,uuid"`
+		WorkspaceSlug  string `json:"workspace_slug"  validate:"required,slug"`
+		CollectionSlug string `json:"collection_slug" validate:"required,slug"`
+	}
+
+	ListEntryVersionsResponse struct {
+		Versions []models.EntryVersion `json:"versions"`
+	}
+
+	RestoreEntryVersionRequest struct {
+		WorkspaceID string `json:"workspace_id" validate:"required,uuid"`
+		// EntryID is the ID of the version to restore
+		EntryID string `json:"entry_id" validate:"required,uuid"`
+	}
+
+	DiffEntryVersionsRequest struct {
+		WorkspaceSlug  string `json:"workspace_slug"  validate:"required,slug"`
+		CollectionSlug string `json:"collection_slug" validate:"required,slug"`
+		// FromID is the ID of the version to compare from, usually the older one
+		FromID string `json:"from_id" validate:"required,uuid"`
+		// ToID is the ID of the version to compare to, usually the newer one
+		ToID string `json:"to_id" validate:"required,uuid"`
+		// Field is the content that is compared, the extracted text by default or the markdown content
+		Field string `json:"field" validate:"omitempty,oneof=text content" mirror:"optional:true,type:'text' | 'content'"`
+		// Mode is `line` by default, `word` adds the word-level changes of modified lines and `chunk` compares the chunks of the versions instead
+		Mode string `json:"mode" validate:"omitempty,oneof=line word chunk" mirror:"optional:true,type:'line' | 'word' | 'chunk'"`
+		// ContextLines is the number of unchanged lines around the changes, diff.DefaultContext is used if it is not set and 0 only shows the changes
+		ContextLines *int `json:"context_lines" validate:"omitempty,min=0,max=20" mirror:"optional:true"`
+	}
+
+	DiffEntryVersionsResponse struct {
+		FromVersion int32 `json:"from_version"`
+		ToVersion   int32 `json:"to_version"`
+		// Text is only set in the line and word modes
+		Text *diff.Result `json:"text" mirror:"optional:true"`
+		// Chunks is only set in the chunk mode
+		Chunks *models.EntryChunkDiff `json:"chunks" mirror:"optional:true"`
+	}
+
+	CreateNoteRequest struct {
+		WorkspaceSlug  string `json:"workspace_slug"  validate:"required,slug"`
+		CollectionSlug string `json:"collection_slug" validate:"required,slug"`
+		Title          string `json:"title"           validate:"required,max=255"`
+		// Content is the markdown body of the note
+		Content string `json:"content" validate:"max=500000"`
+	}
+
+	UpdateNoteRequest struct {
+		WorkspaceSlug string `json:"workspace_slug" validate:"required,slug"`
+		// EntryID is the ID of any version of the note
+		EntryID string `json:"entry_id" validate:"required,uuid"`
+		// Version is the version of the note the changes were made on, the changes are rejected if a newer version has been saved since
+		Version int32  `json:"version" validate:"required,min=1"`
+		Title   string `json:"title"   validate:"required,max=255"`
+		Content string `json:"content" validate:"max=500000"`
+	}
+
+	SaveNoteResponse struct {
+		Entry   models.CreatedEntry `json:"entry"`
+		Version int32               `json:"version"`
+		Message string              `json:"message"`
+	}
+
+	FindRelatedEntriesRequest struct {
+		EntryID        string `json:"entry_id"        validate:"required,uuid"`
+		WorkspaceSlug  string `json:"workspace_slug"  validate:"required,slug"`
+		CollectionSlug string `json:"collection_slug" validate:"required,slug"`
+		Limit          int32  `json:"limit"           validate:"omitempty,min=1,max=25" mirror:"optional:true"`
+	}
+
+	FindRelatedEntriesResponse struct {
+		Entries []models.RelatedEntry `json:"entries"`
+	}
+
+	FindEntryLinksRequest struct {
+		EntryID        string `json:"entry_id"        validate:"required,uuid"`
+		WorkspaceSlug  string `json:"workspace_slug"  validate:"required,slug"`
+		CollectionSlug string `json:"collection_slug" validate:"required,slug"`
+	}
+
+	FindEntryLinksResponse struct {
+		Links models.EntryLinks `json:"links"`
+	}
+
+	SearchFilters struct {
+		Types           []string  `json:"types"            validate:"omitempty,dive,required"                                                 mirror:"optional:true"`
+		CollectionIDs   []string  `json:"collection_ids"   validate:"omitempty,dive,uuid"                                                     mirror:"optional:true"`
+		CollectionSlugs []string  `json:"collection_slugs" validate:"omitempty,dive,slug"                                                     mirror:"optional:true"`
+		AddedBy         []string  `json:"added_by"         validate:"omitempty,dive,username"                                                 mirror:"optional:true"`
+		Tags            []string  `json:"tags"             validate:"omitempty,dive,required,max=72"                                          mirror:"optional:true"`
+		CreatedAfter    time.Time `json:"created_after"                                                                                       mirror:"type:string,optional:true"`
+		CreatedBefore   time.Time `json:"created_before"                                                                                      mirror:"type:string,optional:true"`
+		UpdatedAfter    time.Time `json:"updated_after"                                                                                       mirror:"type:string,optional:true"`
+		UpdatedBefore   time.Time `json:"updated_before"                                                                                      mirror:"type:string,optional:true"`
+		Statuses        []string  `json:"statuses"         validate:"omitempty,dive,oneof=queued processing completed failed canceled paused" mirror:"optional:true"`
+		IncludeArchived bool      `json:"include_archived"                                                                                    mirror:"optional:true"`
+		ArchivedOnly    bool      `json:"archived_only"                                                                                       mirror:"optional:true"`
+	}
+
 	SearchRequest struct {
-		WorkspaceID   string `json:"workspace_id"   validate:"required_without=WorkspaceSlug" mirror:"optional:true"`
-		WorkspaceSlug string `json:"workspace_slug" validate:"optional_slug"                  mirror:"optional:true"`
-		Query         string `json:"query"          validate:"required,ascii,min=2"`
+		WorkspaceID   string        `json:"workspace_id"   validate:"required_without=WorkspaceSlug" mirror:"optional:true"`
+		WorkspaceSlug string        `json:"workspace_slug" validate:"optional_slug"                  mirror:"optional:true"`
+		Query         string        `json:"query"          validate:"required,ascii,min=2"`
+		Filters       SearchFilters `json:"filters"                                                  mirror:"optional:true"`
+		// Pagination is optional, all the results are returned in a single response when it is not set
+		Pagination *repository.CursorParams `json:"pagination" mirror:"optional:true"`
+	}
+
+	AskQuestionRequest struct {
+		WorkspaceID   string        `json:"workspace_id"   validate:"required_without=WorkspaceSlug" mirror:"optional:true"`
+		WorkspaceSlug string        `json:"workspace_slug" validate:"optional_slug"                  mirror:"optional:true"`
+		Question      string        `json:"question"       validate:"required,min=3,max=1000"`
+		Filters       SearchFilters `json:"filters"                                                  mirror:"optional:true"`
+	}
+
+	AskQuestionResponse struct {
+		Answer    models.Answer `json:"answer"`
+		Question  string        `json:"question"`
+		TimeTaken int64         `json:"time_taken_ms"`
 	}
 
 	SearchResponse struct {
-		Results   models.HybridSearchResults `json:"results"`
-		Query     string                     `json:"query"`
-		TimeTaken int64                      `json:"time_taken_ms"`
+		Results    models.HybridSearchResults `json:"results"`
+		Query      string                     `json:"query"`
+		TimeTaken  int64                      `json:"time_taken_ms"`
+		NextCursor *string                    `json:"next_cursor"`
 	}
 )
@@ -18,7 +18,10 @@ type Handler interface {
 	Workspace() WorkspaceHandler
 	Collection() CollectionHandler
 	Entry() EntryHandler
+	Tag() TagHandler
+	Comment() CommentHandler
 	Plugin() PluginHandler
+	Stream() StreamHandler
 }
 
 type api struct {
@@ -39,7 +42,10 @@ type api struct {
 	workspaceHandler  WorkspaceHandler
 	collectionHandler CollectionHandler
 	entryHandler      EntryHandler
+	tagHandler        TagHandler
+	commentHandler    CommentHandler
 	pluginHandler     PluginHandler
+	streamHandler     StreamHandler
 }
 
 type Deps struct {
@@ -81,7 +87,10 @@ func New(deps *Deps) Handler {
 		workspaceHandler:  nil,
 		collectionHandler: nil,
 		entryHandler:      nil,
+		tagHandler:        nil,
+		commentHandler:    nil,
 		pluginHandler:     nil,
+		streamHandler:     nil,
 	}
 }
 
@@ -146,6 +155,22 @@ func (a *api) Entry() EntryHandler {
 	return a.entryHandler
 }
 
+func (a *api) Tag() TagHandler {
+	if a.tagHandler == nil {
+		a.tagHandler = &tagHandler{a.makeBaseHandler()}
+	}
+
+	return a.tagHandler
+}
+
+func (a *api) Comment() CommentHandler {
+	if a.commentHandler == nil {
+		a.commentHandler = &commentHandler{a.makeBaseHandler()}
+	}
+
+	return a.commentHandler
+}
+
 func (a *api) Plugin() PluginHandler {
 	if a.pluginHandler == nil {
 		a.pluginHandler = &pluginHandler{a.makeBaseHandler()}
@@ -153,3 +178,12 @@ func (a *api) Plugin() PluginHandler {
 
 	return a.pluginHandler
 }
+
+func (a *api) Stream() StreamHandler {
+	if a.streamHandler == nil {
+		base := a.makeBaseHandler()
+		a.streamHandler = &streamHandler{baseHandler: base, entry: &entryHandler{base}}
+	}
+
+	return a.streamHandler
+}
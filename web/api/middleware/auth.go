@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"go.trulyao.dev/hubble/web/internal/models"
 	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
 	authlib "go.trulyao.dev/hubble/web/pkg/lib/auth"
 	"go.trulyao.dev/robin"
@@ -12,14 +13,44 @@ import (
 
 // WithAuth is a middleware that requires the user to be authenticated
 func (m *middleware) WithAuth(ctx *robin.Context) error {
-	authCookie, err := ctx.Request().Cookie(authlib.CookieAuthSession)
+	session, err := m.authenticate(ctx.Request(), func(cookie *http.Cookie) { ctx.SetCookie(cookie) })
 	if err != nil {
-		return apperrors.ErrUnauthorized
+		return err
+	}
+
+	// Set the user ID in the context
+	ctx.Set(authlib.StateKeyUserID, session.UserID)
+	ctx.Set(authlib.StateKeySession, session)
+
+	return nil
+}
+
+// WithHttpAuth is the plain HTTP version of WithAuth for handlers that are not procedures (e.g. streams), the session is stored in the request context
+func (m *middleware) WithHttpAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := m.authenticate(r, func(cookie *http.Cookie) { http.SetCookie(w, cookie) })
+		if err != nil {
+			apperrors.WriteHttpError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(authlib.WithAuthSession(r.Context(), session)))
+	})
+}
+
+// authenticate looks up the session from the signed session cookie, the cookie is removed with `setCookie` if the session is invalid
+func (m *middleware) authenticate(
+	r *http.Request,
+	setCookie func(*http.Cookie),
+) (models.AuthSession, error) {
+	authCookie, err := r.Cookie(authlib.CookieAuthSession)
+	if err != nil {
+		return models.AuthSession{}, apperrors.ErrUnauthorized
 	}
 
 	logout := func() {
 		// Remove the cookie
-		ctx.SetCookie(&http.Cookie{
+		setCookie(&http.Cookie{
 			Name:     authlib.CookieAuthSession,
 			Value:    "",
 			HttpOnly: true,
@@ -34,7 +65,7 @@ func (m *middleware) WithAuth(ctx *robin.Context) error {
 		log.Debug().Err(err).Msg("failed to decode auth cookie")
 		logout()
 
-		return apperrors.ErrSessionExpired
+		return models.AuthSession{}, apperrors.ErrSessionExpired
 	}
 
 	// Look through the user's session data
@@ -43,7 +74,7 @@ func (m *middleware) WithAuth(ctx *robin.Context) error {
 		log.Debug().Err(err).Msg("failed to lookup session, logging out")
 		logout()
 
-		return err
+		return models.AuthSession{}, err
 	}
 
 	// Check if the session is still valid
@@ -53,13 +84,9 @@ func (m *middleware) WithAuth(ctx *robin.Context) error {
 
 		// Delete the session if hasn't been deleted already
 		if err := m.repository.AuthRepository().RevokeAuthSession(authToken.Value()); err != nil {
-			return err
+			return models.AuthSession{}, err
 		}
 	}
 
-	// Set the user ID in the context
-	ctx.Set(authlib.StateKeyUserID, session.UserID)
-	ctx.Set(authlib.StateKeySession, session)
-
-	return nil
+	return session, nil
 }
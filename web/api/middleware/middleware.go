@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"net/http"
+
 	"github.com/adelowo/gulter"
 	"go.trulyao.dev/hubble/web/internal/config"
 	"go.trulyao.dev/hubble/web/internal/objectstore"
@@ -11,8 +13,10 @@ import (
 
 type Middleware interface {
 	WithAuth(ctx *robin.Context) error
+	WithHttpAuth(next http.Handler) http.Handler
 	WithGulter(*gulter.Gulter, []string) func(ctx *robin.Context) error
 	WithRateLimit(ctx *robin.Context) error
+	WithHttpRateLimit(name string, next http.Handler) http.Handler
 }
 
 type middleware struct {
@@ -18,12 +18,31 @@ func (m *middleware) WithRateLimit(ctx *robin.Context) error {
 		return nil
 	}
 
-	ipAddress, err := lib.GetRequestIP(ctx.Request())
+	return m.checkRateLimit(ctx.ProcedureName(), ctx.Request())
+}
+
+// WithHttpRateLimit applies the rate limit of the procedure named `name` to a plain HTTP handler
+func (m *middleware) WithHttpRateLimit(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.config.InDevelopment() {
+			if err := m.checkRateLimit(name, r); err != nil {
+				apperrors.WriteHttpError(w, err)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkRateLimit counts the request against the limit of `name` for the client's IP address
+func (m *middleware) checkRateLimit(name string, r *http.Request) error {
+	ipAddress, err := lib.GetRequestIP(r)
 	if err != nil {
 		return err
 	}
 
-	key := ratelimit.WithIdentifier(ctx.ProcedureName(), ipAddress)
+	key := ratelimit.WithIdentifier(name, ipAddress)
 
 	reachedLimit, err := m.rateLimiter.HasReachedLimit(key)
 	if err != nil {
@@ -53,7 +72,7 @@ func (m *middleware) WithRateLimit(ctx *robin.Context) error {
 
 	if m.config.Debug() {
 		log.Debug().
-			Str("procedure", ctx.ProcedureName()).
+			Str("procedure", name).
 			Str("ip_address", ipAddress).
 			Int("counter", counter).
 			Msg("rate limit check")
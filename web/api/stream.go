@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.trulyao.dev/hubble/web/internal/repository"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	authlib "go.trulyao.dev/hubble/web/pkg/lib/auth"
+	"go.trulyao.dev/hubble/web/pkg/sse"
+)
+
+type streamHandler struct {
+	*baseHandler
+
+	entry *entryHandler
+}
+
+// AskQuestion implements StreamHandler.
+func (s *streamHandler) AskQuestion(w http.ResponseWriter, r *http.Request) {
+	auth, err := authlib.ExtractAuthSessionFromContext(r.Context())
+	if err != nil {
+		apperrors.WriteHttpError(w, err)
+		return
+	}
+
+	request, err := decodeAskQuestionRequest(r)
+	if err != nil {
+		apperrors.WriteHttpError(w, err)
+		return
+	}
+
+	if !s.llm.ChatEnabled() {
+		apperrors.WriteHttpError(w, ErrQuestionsDisabled)
+		return
+	}
+
+	filters, err := s.entry.parseSearchFilters(&request.Filters)
+	if err != nil {
+		apperrors.WriteHttpError(w, err)
+		return
+	}
+
+	// The request context is cancelled when the client disconnects, which also cancels the upstream requests
+	ctx := r.Context()
+
+	start := time.Now()
+	chunks, err := s.entry.retrieveContext(ctx, &retrieveContextArgs{
+		UserID:   auth.UserID,
+		Question: request.Question,
+		Filters:  filters,
+		Workspace: repository.PublicIdOrSlug{
+			PublicID: lib.PgUUIDString(request.WorkspaceID),
+			Slug:     request.WorkspaceSlug,
+		},
+	})
+	if err != nil {
+		apperrors.WriteHttpError(w, err)
+		return
+	}
+	retrievalTime := time.Since(start)
+
+	stream, err := sse.NewWriter(w)
+	if err != nil {
+		apperrors.WriteHttpError(w, err)
+		return
+	}
+
+	answer, err := s.llm.StreamAnswer(ctx, request.Question, chunks, func(content string) error {
+		return stream.Send(EventDelta, StreamDeltaEvent{Content: content})
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Debug().Err(err).Msg("client disconnected while streaming an answer")
+			return
+		}
+
+		message, _ := apperrors.ErrorHandler(err)
+		_ = stream.Send(EventError, StreamErrorEvent{Error: message})
+		return
+	}
+
+	_ = stream.Send(EventDone, AskQuestionDoneEvent{
+		Question:  request.Question,
+		Answer:    answer.Answer,
+		Citations: answer.Citations,
+		Timings: StreamTimings{
+			RetrievalMs:  retrievalTime.Milliseconds(),
+			GenerationMs: (time.Since(start) - retrievalTime).Milliseconds(),
+			TotalMs:      time.Since(start).Milliseconds(),
+		},
+	})
+}
+
+// decodeAskQuestionRequest reads the request from the query parameters (for `EventSource` clients that can only send GET requests) or from the JSON body
+func decodeAskQuestionRequest(r *http.Request) (AskQuestionRequest, error) {
+	var request AskQuestionRequest
+
+	if r.Method == http.MethodGet {
+		params := r.URL.Query()
+		request.WorkspaceID = params.Get("workspace_id")
+		request.WorkspaceSlug = params.Get("workspace_slug")
+		request.Question = params.Get("question")
+
+		// Filters are sent as a JSON-encoded `SearchFilters` object since they do not map cleanly to flat parameters
+		if filters := params.Get("filters"); filters != "" {
+			if err := json.Unmarshal([]byte(filters), &request.Filters); err != nil {
+				return request, apperrors.BadRequest("invalid filters")
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return request, apperrors.BadRequest("invalid request body")
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return request, err
+	}
+	request.Question = strings.TrimSpace(request.Question)
+
+	return request, nil
+}
+
+var _ StreamHandler = (*streamHandler)(nil)
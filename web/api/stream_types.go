@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"go.trulyao.dev/hubble/web/internal/models"
+)
+
+// Stream event names
+const (
+	EventDelta = "delta"
+	EventDone  = "done"
+	EventError = "error"
+)
+
+type (
+	/*
+		StreamHandler serves the long-running LLM operations as Server-Sent Events, these are plain HTTP handlers since robin procedures are request/response only.
+
+		A stream sends a `delta` event for every generated piece of content, followed by either a `done` event with the final metadata or an `error` event. Errors that happen before the stream starts are returned as regular JSON error responses.
+	*/
+	StreamHandler interface {
+		// AskQuestion streams the answer to a question (GET with query parameters, with `filters` as JSON, or POST with a JSON `AskQuestionRequest` body)
+		AskQuestion(w http.ResponseWriter, r *http.Request)
+	}
+
+	StreamDeltaEvent struct {
+		Content string `json:"content"`
+	}
+
+	StreamErrorEvent struct {
+		Error any `json:"error"`
+	}
+
+	StreamTimings struct {
+		RetrievalMs  int64 `json:"retrieval_ms"`
+		GenerationMs int64 `json:"generation_ms"`
+		TotalMs      int64 `json:"total_ms"`
+	}
+
+	AskQuestionDoneEvent struct {
+		Question  string            `json:"question"`
+		Answer    string            `json:"answer"`
+		Citations []models.Citation `json:"citations"`
+		Timings   StreamTimings     `json:"timings"`
+	}
+)
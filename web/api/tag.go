@@ -0,0 +1,407 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/internal/repository"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	authlib "go.trulyao.dev/hubble/web/pkg/lib/auth"
+	"go.trulyao.dev/hubble/web/pkg/rbac"
+	"go.trulyao.dev/robin"
+)
+
+type tagHandler struct {
+	*baseHandler
+}
+
+// List implements TagHandler.
+func (t *tagHandler) List(ctx *robin.Context, request ListTagsRequest) (ListTagsResponse, error) {
+	if err := lib.ValidateStruct(&request); err != nil {
+		return ListTagsResponse{}, err
+	}
+
+	collection, err := t.findCollection(
+		ctx,
+		request.WorkspaceID,
+		request.CollectionID,
+		rbac.PermListTags,
+	)
+	if err != nil {
+		return ListTagsResponse{}, err
+	}
+
+	tags, err := t.repos.TagRepository().FindAll(collection.InternalID)
+	if err != nil {
+		return ListTagsResponse{}, err
+	}
+
+	return ListTagsResponse{Tags: tags}, nil
+}
+
+// Create implements TagHandler.
+func (t *tagHandler) Create(ctx *robin.Context, request CreateTagRequest) (models.Tag, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if err := lib.ValidateStruct(&request); err != nil {
+		return models.Tag{}, err
+	}
+
+	collection, err := t.findCollection(
+		ctx,
+		request.WorkspaceID,
+		request.CollectionID,
+		rbac.PermCreateTag,
+	)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	return t.repos.TagRepository().Create(&repository.CreateTagArgs{
+		CollectionID: collection.InternalID,
+		UserID:       auth.UserID,
+		Name:         request.Name,
+		Description:  strings.TrimSpace(request.Description),
+		Color:        strings.ToLower(request.Color),
+	})
+}
+
+// Update implements TagHandler.
+func (t *tagHandler) Update(ctx *robin.Context, request UpdateTagRequest) (models.Tag, error) {
+	request.Name = strings.TrimSpace(request.Name)
+	if err := lib.ValidateStruct(&request); err != nil {
+		return models.Tag{}, err
+	}
+
+	collection, err := t.findCollection(
+		ctx,
+		request.WorkspaceID,
+		request.CollectionID,
+		rbac.PermUpdateTag,
+	)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	tag, err := t.findTag(collection.InternalID, request.TagID)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	return t.repos.TagRepository().Update(&repository.UpdateTagArgs{
+		Tag:         &tag,
+		Name:        request.Name,
+		Description: strings.TrimSpace(request.Description),
+		Color:       strings.ToLower(request.Color),
+	})
+}
+
+// Delete implements TagHandler.
+func (t *tagHandler) Delete(ctx *robin.Context, request DeleteTagRequest) (models.Tag, error) {
+	if err := lib.ValidateStruct(&request); err != nil {
+		return models.Tag{}, err
+	}
+
+	collection, err := t.findCollection(
+		ctx,
+		request.WorkspaceID,
+		request.CollectionID,
+		rbac.PermDeleteTag,
+	)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	tag, err := t.findTag(collection.InternalID, request.TagID)
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	if err := t.repos.TagRepository().Delete(tag.InternalID); err != nil {
+		return models.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// TagEntries implements TagHandler.
+func (t *tagHandler) TagEntries(
+	ctx *robin.Context,
+	request TagEntriesRequest,
+) (TagEntriesResponse, error) {
+	args, err := t.makeTagEntriesArgs(ctx, &request)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	count, err := t.repos.TagRepository().TagEntries(args)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	return TagEntriesResponse{Count: count}, nil
+}
+
+// UntagEntries implements TagHandler.
+func (t *tagHandler) UntagEntries(
+	ctx *robin.Context,
+	request TagEntriesRequest,
+) (TagEntriesResponse, error) {
+	args, err := t.makeTagEntriesArgs(ctx, &request)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	count, err := t.repos.TagRepository().UntagEntries(args)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	return TagEntriesResponse{Count: count}, nil
+}
+
+// ListSuggestions implements TagHandler.
+func (t *tagHandler) ListSuggestions(
+	ctx *robin.Context,
+	request ListTagSuggestionsRequest,
+) (ListTagSuggestionsResponse, error) {
+	if err := lib.ValidateStruct(&request); err != nil {
+		return ListTagSuggestionsResponse{}, err
+	}
+
+	collection, err := t.findCollection(
+		ctx,
+		request.WorkspaceID,
+		request.CollectionID,
+		rbac.PermListTags,
+	)
+	if err != nil {
+		return ListTagSuggestionsResponse{}, err
+	}
+
+	var entryID pgtype.UUID
+	if request.EntryID != "" {
+		if entryID, err = lib.UUIDFromString(request.EntryID); err != nil {
+			return ListTagSuggestionsResponse{}, apperrors.BadRequest(
+				"invalid entry ID: " + request.EntryID,
+			)
+		}
+	}
+
+	suggestions, err := t.repos.TagSuggestionRepository().
+		FindAll(&repository.FindTagSuggestionsArgs{
+			CollectionID: collection.InternalID,
+			EntryID:      entryID,
+			Status:       queries.TagSuggestionStatus(request.Status),
+		})
+	if err != nil {
+		return ListTagSuggestionsResponse{}, err
+	}
+
+	return ListTagSuggestionsResponse{Suggestions: suggestions}, nil
+}
+
+// AcceptSuggestions implements TagHandler.
+func (t *tagHandler) AcceptSuggestions(
+	ctx *robin.Context,
+	request ReviewTagSuggestionsRequest,
+) (TagEntriesResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	// Accepting a suggestion creates its tag if it does not exist yet
+	collection, err := t.findCollection(
+		ctx,
+		request.WorkspaceID,
+		request.CollectionID,
+		rbac.PermTagEntry,
+		rbac.PermCreateTag,
+	)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	suggestionIDs, err := parseUUIDs(
+		lib.UniqueSlice(request.SuggestionIDs),
+		"invalid suggestion ID: ",
+	)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	pending, err := t.repos.TagSuggestionRepository().
+		FindPending(collection.InternalID, suggestionIDs)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	count, err := t.repos.TagSuggestionRepository().Accept(&repository.AcceptTagSuggestionsArgs{
+		CollectionID: collection.InternalID,
+		UserID:       auth.UserID,
+		AutoApplied:  false,
+		Suggestions:  pending,
+	})
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	return TagEntriesResponse{Count: count}, nil
+}
+
+// RejectSuggestions implements TagHandler.
+func (t *tagHandler) RejectSuggestions(
+	ctx *robin.Context,
+	request ReviewTagSuggestionsRequest,
+) (TagEntriesResponse, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	collection, err := t.findCollection(
+		ctx,
+		request.WorkspaceID,
+		request.CollectionID,
+		rbac.PermTagEntry,
+	)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	suggestionIDs, err := parseUUIDs(
+		lib.UniqueSlice(request.SuggestionIDs),
+		"invalid suggestion ID: ",
+	)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	count, err := t.repos.TagSuggestionRepository().
+		Reject(collection.InternalID, auth.UserID, suggestionIDs)
+	if err != nil {
+		return TagEntriesResponse{}, err
+	}
+
+	return TagEntriesResponse{Count: count}, nil
+}
+
+// makeTagEntriesArgs checks that the user can tag entries in the collection and parses the (deduplicated) tag and entry IDs
+func (t *tagHandler) makeTagEntriesArgs(
+	ctx *robin.Context,
+	request *TagEntriesRequest,
+) (*repository.TagEntriesArgs, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lib.ValidateStruct(request); err != nil {
+		return nil, err
+	}
+
+	collection, err := t.findCollection(
+		ctx,
+		request.WorkspaceID,
+		request.CollectionID,
+		rbac.PermTagEntry,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs, err := parseUUIDs(lib.UniqueSlice(request.TagIDs), "invalid tag ID: ")
+	if err != nil {
+		return nil, err
+	}
+
+	entryIDs, err := parseUUIDs(lib.UniqueSlice(request.EntryIDs), "invalid entry ID: ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.TagEntriesArgs{
+		CollectionID: collection.InternalID,
+		UserID:       auth.UserID,
+		TagIDs:       tagIDs,
+		EntryIDs:     entryIDs,
+	}, nil
+}
+
+// findCollection loads the collection and checks that the current user has all the given permissions in it
+func (t *tagHandler) findCollection(
+	ctx *robin.Context,
+	workspacePublicID, collectionPublicID string,
+	permissions ...rbac.Permission,
+) (*models.Collection, error) {
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var workspaceID, collectionID pgtype.UUID
+	if workspaceID, err = lib.UUIDFromString(workspacePublicID); err != nil {
+		return nil, err
+	}
+	if collectionID, err = lib.UUIDFromString(collectionPublicID); err != nil {
+		return nil, err
+	}
+
+	//nolint:exhaustruct
+	result, err := t.repos.CollectionRepository().
+		FindWithMembershipStatus(&repository.FindWithMembershipStatusArgs{
+			UserID:       auth.UserID,
+			WorkspaceID:  workspaceID,
+			CollectionID: collectionID,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, permission := range permissions {
+		if !result.MembershipStatus.Role.Can(permission) {
+			return nil, rbac.ErrPermissionDenied
+		}
+	}
+
+	return result.Collection, nil
+}
+
+func (t *tagHandler) findTag(collectionID int32, tagPublicID string) (models.Tag, error) {
+	tagID, err := lib.UUIDFromString(tagPublicID)
+	if err != nil {
+		return models.Tag{}, apperrors.BadRequest("invalid tag ID: " + tagPublicID)
+	}
+
+	return t.repos.TagRepository().FindByID(collectionID, tagID)
+}
+
+// parseUUIDs parses a list of public IDs, the prefix is used in the error message of the first invalid ID
+func parseUUIDs(ids []string, prefix string) ([]pgtype.UUID, error) {
+	parsed := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		uuid, err := lib.UUIDFromString(id)
+		if err != nil {
+			return nil, apperrors.BadRequest(prefix + id)
+		}
+		parsed = append(parsed, uuid)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/robin"
+)
+
+type TagHandler interface {
+	// List lists the tags of a collection
+	List(ctx *robin.Context, request ListTagsRequest) (ListTagsResponse, error)
+
+	// Create creates a new tag in a collection
+	Create(ctx *robin.Context, request CreateTagRequest) (models.Tag, error)
+
+	// Update updates a tag's details
+	Update(ctx *robin.Context, request UpdateTagRequest) (models.Tag, error)
+
+	// Delete deletes a tag and removes it from all the entries it was assigned to
+	Delete(ctx *robin.Context, request DeleteTagRequest) (models.Tag, error)
+
+	// TagEntries assigns tags to multiple entries of a collection
+	TagEntries(ctx *robin.Context, request TagEntriesRequest) (TagEntriesResponse, error)
+
+	// UntagEntries removes tags from multiple entries of a collection
+	UntagEntries(ctx *robin.Context, request TagEntriesRequest) (TagEntriesResponse, error)
+
+	// ListSuggestions lists the tags suggested for the entries of a collection by the chat model
+	ListSuggestions(
+		ctx *robin.Context,
+		request ListTagSuggestionsRequest,
+	) (ListTagSuggestionsResponse, error)
+
+	// AcceptSuggestions assigns the suggested tags to their entries, creating the tags that do not exist yet
+	AcceptSuggestions(
+		ctx *robin.Context,
+		request ReviewTagSuggestionsRequest,
+	) (TagEntriesResponse, error)
+
+	// RejectSuggestions rejects suggestions, rejected tags are never suggested again for the entry and are used as negative examples for the collection
+	RejectSuggestions(
+		ctx *robin.Context,
+		request ReviewTagSuggestionsRequest,
+	) (TagEntriesResponse, error)
+}
+
+type (
+	ListTagsRequest struct {
+		WorkspaceID  string `json:"workspace_id"  validate:"required,uuid"`
+		CollectionID string `json:"collection_id" validate:"required,uuid"`
+	}
+
+	ListTagsResponse struct {
+		Tags []models.Tag `json:"tags"`
+	}
+
+	CreateTagRequest struct {
+		WorkspaceID  string `json:"workspace_id"  validate:"required,uuid"`
+		CollectionID string `json:"collection_id" validate:"required,uuid"`
+		Name         string `json:"name"          validate:"required,mixed_name,min=1,max=72"`
+		Description  string `json:"description"   validate:"ascii,max=512"                     mirror:"optional:true"`
+		// Color is a hex colour code (e.g. #ff5733)
+		Color string `json:"color" validate:"omitempty,hexcolor" mirror:"optional:true"`
+	}
+
+	// UpdateTagRequest updates the details of a tag, empty fields are left unchanged
+	UpdateTagRequest struct {
+		WorkspaceID  string `json:"workspace_id"  validate:"required,uuid"`
+		CollectionID string `json:"collection_id" validate:"required,uuid"`
+		TagID        string `json:"tag_id"        validate:"required,uuid"`
+		Name         string `json:"name"          validate:"optional_mixed_name,max=72" mirror:"optional:true"`
+		Description  string `json:"description"   validate:"ascii,max=512"              mirror:"optional:true"`
+		Color        string `json:"color"         validate:"omitempty,hexcolor"         mirror:"optional:true"`
+	}
+
+	DeleteTagRequest struct {
+		WorkspaceID  string `json:"workspace_id"  validate:"required,uuid"`
+		CollectionID string `json:"collection_id" validate:"required,uuid"`
+		TagID        string `json:"tag_id"        validate:"required,uuid"`
+	}
+
+	TagEntriesRequest struct {
+		WorkspaceID  string   `json:"workspace_id"  validate:"required,uuid"`
+		CollectionID string   `json:"collection_id" validate:"required,uuid"`
+		TagIDs       []string `json:"tag_ids"       validate:"required,min=1,dive,uuid"`
+		EntryIDs     []string `json:"entry_ids"     validate:"required,min=1,dive,uuid"`
+	}
+
+	ListTagSuggestionsRequest struct {
+		WorkspaceID  string `json:"workspace_id"  validate:"required,uuid"`
+		CollectionID string `json:"collection_id" validate:"required,uuid"`
+		EntryID      string `json:"entry_id"      validate:"omitempty,uuid"                            mirror:"optional:true"`
+		Status       string `json:"status"        validate:"omitempty,oneof=pending accepted rejected" mirror:"optional:true,type:'pending' | 'accepted' | 'rejected'"`
+	}
+
+	ListTagSuggestionsResponse struct {
+		Suggestions []models.TagSuggestion `json:"suggestions"`
+	}
+
+	ReviewTagSuggestionsRequest struct {
+		WorkspaceID   string   `json:"workspace_id"   validate:"required,uuid"`
+		CollectionID  string   `json:"collection_id"  validate:"required,uuid"`
+		SuggestionIDs []string `json:"suggestion_ids" validate:"required,min=1,dive,uuid"`
+	}
+
+	TagEntriesResponse struct {
+		// Count is the number of entry and tag pairs that were added or removed, or the number of reviewed suggestions
+		Count int64 `json:"count"`
+	}
+)
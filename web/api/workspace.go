@@ -9,6 +9,8 @@ import (
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/rs/zerolog/log"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/job"
 	"go.trulyao.dev/hubble/web/internal/mail"
 	"go.trulyao.dev/hubble/web/internal/mail/templates"
 	"go.trulyao.dev/hubble/web/internal/models"
@@ -25,6 +27,24 @@ type workspaceHandler struct {
 	*baseHandler
 }
 
+var (
+	ErrEmbeddingsDisabled = apperrors.BadRequest(
+		"embeddings are not enabled, an embedding model needs to be configured",
+	)
+	ErrEmbeddingModelUnchanged = apperrors.BadRequest(
+		"the workspace is already embedded with the configured model",
+	)
+	ErrSummariesDisabled = apperrors.BadRequest(
+		"summaries are not enabled, a chat model needs to be configured",
+	)
+	ErrTagSuggestionsDisabled = apperrors.BadRequest(
+		"tag suggestions are not enabled, a chat model needs to be configured",
+	)
+	ErrReembedInProgress = apperrors.BadRequest(
+		"a re-embedding is already in progress for this workspace",
+	)
+)
+
 // Delete implements WorkspaceHandler.
 func (w *workspaceHandler) Delete(
 	ctx *robin.Context,
@@ -818,3 +838,340 @@ func (w *workspaceHandler) inviteUser(
 }
 
 var _ WorkspaceHandler = (*workspaceHandler)(nil)
+
+// FindSettings implements WorkspaceHandler.
+func (w *workspaceHandler) FindSettings(
+	ctx *robin.Context,
+	request FindWorkspaceSettingsRequest,
+) (WorkspaceSettingsResponse, error) {
+	var response WorkspaceSettingsResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	workspaceID := lib.PgUUIDString(request.WorkspaceID)
+	result, err := w.repos.WorkspaceRepository().FindWithMembershipStatus(
+		repository.PublicIdOrSlug{PublicID: workspaceID}, //nolint:exhaustruct
+		auth.UserID,
+	)
+	if err != nil {
+		return response, err
+	}
+
+	if !result.MembershipStatus.Role.Can(rbac.PermViewWorkspaceSettings) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	settings, err := w.repos.WorkspaceRepository().FindSettings(result.ID)
+	if err != nil {
+		return response, err
+	}
+
+	return WorkspaceSettingsResponse{Settings: settings}, nil
+}
+
+// UpdateSettings implements WorkspaceHandler.
+func (w *workspaceHandler) UpdateSettings(
+	ctx *robin.Context,
+	request UpdateWorkspaceSettingsRequest,
+) (WorkspaceSettingsResponse, error) {
+	var response WorkspaceSettingsResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	workspaceID := lib.PgUUIDString(request.WorkspaceID)
+	result, err := w.repos.WorkspaceRepository().FindWithMembershipStatus(
+		repository.PublicIdOrSlug{PublicID: workspaceID}, //nolint:exhaustruct
+		auth.UserID,
+	)
+	if err != nil {
+		return response, err
+	}
+
+	if !result.MembershipStatus.Role.Can(rbac.PermUpdateWorkspaceSettings) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	settings, err := w.repos.WorkspaceRepository().FindSettings(result.ID)
+	if err != nil {
+		return response, err
+	}
+
+	if request.RRFK != nil {
+		settings.RRFK = *request.RRFK
+	}
+	if request.RRFSemanticWeight != nil {
+		settings.RRFSemanticWeight = *request.RRFSemanticWeight
+	}
+	if request.RRFFullTextWeight != nil {
+		settings.RRFFullTextWeight = *request.RRFFullTextWeight
+	}
+
+	summariesEnabled := settings.SummariesEnabled
+	if request.SummariesEnabled != nil {
+		if *request.SummariesEnabled && !w.llm.ChatEnabled() {
+			return response, ErrSummariesDisabled
+		}
+
+		settings.SummariesEnabled = *request.SummariesEnabled
+	}
+
+	tagSuggestionMode := settings.TagSuggestionMode
+	if request.TagSuggestionMode != nil {
+		mode := queries.TagSuggestionMode(*request.TagSuggestionMode)
+		if mode != queries.TagSuggestionModeOff && !w.llm.ChatEnabled() {
+			return response, ErrTagSuggestionsDisabled
+		}
+
+		settings.TagSuggestionMode = mode
+	}
+	if request.TagAutoApplyThreshold != nil {
+		settings.TagAutoApplyThreshold = *request.TagAutoApplyThreshold
+	}
+	if request.TrashRetentionDays != nil {
+		settings.TrashRetentionDays = *request.TrashRetentionDays
+	}
+
+	if settings.RRFSemanticWeight == 0 && settings.RRFFullTextWeight == 0 {
+		return response, apperrors.NewValidationError(apperrors.ErrorMap{
+			"rrf_semantic_weight": {"At least one of the semantic or full-text weights must be set"},
+		})
+	}
+
+	updated, err := w.repos.WorkspaceRepository().UpdateSettings(&settings)
+	if err != nil {
+		return response, err
+	}
+
+	// Summarise the existing entries once the workspace opts in
+	if updated.SummariesEnabled && !summariesEnabled {
+		w.queueSummaries(result.ID)
+	}
+
+	// Classify the existing entries once the workspace opts into tag suggestions
+	if updated.TagSuggestionMode != queries.TagSuggestionModeOff &&
+		tagSuggestionMode == queries.TagSuggestionModeOff {
+		w.queueTagSuggestions(result.ID)
+	}
+
+	return WorkspaceSettingsResponse{Settings: updated}, nil
+}
+
+// queueSummaries queues the entries of a workspace that have not been summarised yet
+func (w *workspaceHandler) queueSummaries(workspaceID int32) {
+	var afterID int32
+	for {
+		ids, err := w.repos.SummaryRepository().FindUnsummarisedIDs(workspaceID, afterID)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int32("workspace_id", workspaceID).
+				Msg("failed to find unsummarised entries")
+			return
+		}
+
+		for _, id := range ids {
+			if err := w.queue.Add(&job.EntrySummaryJob{ID: id}); err != nil {
+				log.Error().Err(err).Int32("entry_id", id).Msg("failed to queue entry summary job")
+			}
+		}
+
+		if len(ids) < repository.UnsummarisedEntriesPageSize {
+			return
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// queueTagSuggestions queues the entries of a workspace that have not been classified yet
+func (w *workspaceHandler) queueTagSuggestions(workspaceID int32) {
+	var afterID int32
+	for {
+		ids, err := w.repos.TagSuggestionRepository().FindUnclassifiedIDs(workspaceID, afterID)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int32("workspace_id", workspaceID).
+				Msg("failed to find unclassified entries")
+			return
+		}
+
+		for _, id := range ids {
+			if err := w.queue.Add(&job.EntryTagSuggestionJob{ID: id}); err != nil {
+				log.Error().
+					Err(err).
+					Int32("entry_id", id).
+					Msg("failed to queue entry tag suggestion job")
+			}
+		}
+
+		if len(ids) < repository.UnclassifiedEntriesPageSize {
+			return
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// FindEmbeddingMigration implements WorkspaceHandler.
+func (w *workspaceHandler) FindEmbeddingMigration(
+	ctx *robin.Context,
+	request FindEmbeddingMigrationRequest,
+) (EmbeddingMigrationResponse, error) {
+	var response EmbeddingMigrationResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	result, err := w.repos.WorkspaceRepository().FindWithMembershipStatus(
+		repository.PublicIdOrSlug{Slug: request.WorkspaceSlug}, //nolint:exhaustruct
+		auth.UserID,
+	)
+	if err != nil {
+		return response, err
+	}
+
+	if !result.MembershipStatus.Role.Can(rbac.PermViewWorkspaceSettings) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	return w.embeddingMigrationResponse(result.ID)
+}
+
+// Reembed implements WorkspaceHandler.
+func (w *workspaceHandler) Reembed(
+	ctx *robin.Context,
+	request ReembedWorkspaceRequest,
+) (EmbeddingMigrationResponse, error) {
+	var response EmbeddingMigrationResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	if !w.config.LLM.EnabledEmbeddings() {
+		return response, ErrEmbeddingsDisabled
+	}
+
+	workspaceID := lib.PgUUIDString(request.WorkspaceID)
+	result, err := w.repos.WorkspaceRepository().FindWithMembershipStatus(
+		repository.PublicIdOrSlug{PublicID: workspaceID}, //nolint:exhaustruct
+		auth.UserID,
+	)
+	if err != nil {
+		return response, err
+	}
+
+	if !result.MembershipStatus.Role.Can(rbac.PermUpdateWorkspaceSettings) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	latest, err := w.repos.EmbeddingRepository().FindLatestMigration(result.ID)
+	if err != nil {
+		return response, err
+	}
+
+	if latest != nil && latest.Status == queries.EmbeddingMigrationStatusRunning {
+		return response, ErrReembedInProgress
+	}
+
+	settings, err := w.repos.WorkspaceRepository().FindSettings(result.ID)
+	if err != nil {
+		return response, err
+	}
+
+	target := w.llm.EmbeddingModel()
+	if settings.EmbeddingModel.Equal(target) {
+		return response, ErrEmbeddingModelUnchanged
+	}
+
+	if _, err := w.repos.EmbeddingRepository().StartMigration(&repository.StartEmbeddingMigrationArgs{
+		WorkspaceID: result.ID,
+		StartedBy:   auth.UserID,
+		From:        settings.EmbeddingModel,
+		To:          target,
+	}); err != nil {
+		return response, err
+	}
+
+	return w.embeddingMigrationResponse(result.ID)
+}
+
+// CancelReembed implements WorkspaceHandler.
+func (w *workspaceHandler) CancelReembed(
+	ctx *robin.Context,
+	request ReembedWorkspaceRequest,
+) (EmbeddingMigrationResponse, error) {
+	var response EmbeddingMigrationResponse
+
+	auth, err := authlib.ExtractAuthSession(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	if err := lib.ValidateStruct(&request); err != nil {
+		return response, err
+	}
+
+	workspaceID := lib.PgUUIDString(request.WorkspaceID)
+	result, err := w.repos.WorkspaceRepository().FindWithMembershipStatus(
+		repository.PublicIdOrSlug{PublicID: workspaceID}, //nolint:exhaustruct
+		auth.UserID,
+	)
+	if err != nil {
+		return response, err
+	}
+
+	if !result.MembershipStatus.Role.Can(rbac.PermUpdateWorkspaceSettings) {
+		return response, rbac.ErrPermissionDenied
+	}
+
+	if err := w.repos.EmbeddingRepository().CancelMigration(result.ID); err != nil {
+		return response, err
+	}
+
+	return w.embeddingMigrationResponse(result.ID)
+}
+
+func (w *workspaceHandler) embeddingMigrationResponse(
+	workspaceID int32,
+) (EmbeddingMigrationResponse, error) {
+	settings, err := w.repos.WorkspaceRepository().FindSettings(workspaceID)
+	if err != nil {
+		return EmbeddingMigrationResponse{}, err
+	}
+
+	migration, err := w.repos.EmbeddingRepository().FindLatestMigration(workspaceID)
+	if err != nil {
+		return EmbeddingMigrationResponse{}, err
+	}
+
+	return EmbeddingMigrationResponse{
+		ActiveModel:     settings.EmbeddingModel,
+		ConfiguredModel: w.llm.EmbeddingModel(),
+		Migration:       migration,
+	}, nil
+}
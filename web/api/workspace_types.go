@@ -48,6 +48,33 @@ type WorkspaceHandler interface {
 		ctx *robin.Context,
 		request ChangeMemberRoleRequest,
 	) (ChangeMemberRoleResponse, error)
+
+	// FindSettings returns the settings of a workspace
+	FindSettings(
+		ctx *robin.Context,
+		request FindWorkspaceSettingsRequest,
+	) (WorkspaceSettingsResponse, error)
+
+	// UpdateSettings updates the provided settings of a workspace, omitted settings are left untouched
+	UpdateSettings(
+		ctx *robin.Context,
+		request UpdateWorkspaceSettingsRequest,
+	) (WorkspaceSettingsResponse, error)
+
+	// FindEmbeddingMigration returns the embedding model of a workspace and the progress of its latest re-embedding
+	FindEmbeddingMigration(
+		ctx *robin.Context,
+		request FindEmbeddingMigrationRequest,
+	) (EmbeddingMigrationResponse, error)
+
+	// Reembed starts re-embedding all the chunks of a workspace with the configured model, searches keep using the current vectors until it completes
+	Reembed(ctx *robin.Context, request ReembedWorkspaceRequest) (EmbeddingMigrationResponse, error)
+
+	// CancelReembed stops the running re-embedding of a workspace, the workspace stays on its current model
+	CancelReembed(
+		ctx *robin.Context,
+		request ReembedWorkspaceRequest,
+	) (EmbeddingMigrationResponse, error)
 }
 
 type (
@@ -155,4 +182,39 @@ type (
 	DeleteWorkspaceResponse struct {
 		WorkspaceID string `json:"workspace_id" validate:"required,uuid"`
 	}
+
+	FindWorkspaceSettingsRequest struct {
+		WorkspaceID string `json:"workspace_id" validate:"required,uuid"`
+	}
+
+	UpdateWorkspaceSettingsRequest struct {
+		WorkspaceID           string   `json:"workspace_id"             validate:"required,uuid"`
+		RRFK                  *int32   `json:"rrf_k"                    validate:"omitempty,min=1,max=1000"         mirror:"optional:true"`
+		RRFSemanticWeight     *float64 `json:"rrf_semantic_weight"      validate:"omitempty,min=0,max=10"           mirror:"optional:true"`
+		RRFFullTextWeight     *float64 `json:"rrf_full_text_weight"     validate:"omitempty,min=0,max=10"           mirror:"optional:true"`
+		SummariesEnabled      *bool    `json:"summaries_enabled"                                                    mirror:"optional:true"`
+		TagSuggestionMode     *string  `json:"tag_suggestion_mode"      validate:"omitempty,oneof=off suggest auto" mirror:"optional:true,type:'off' | 'suggest' | 'auto'"`
+		TagAutoApplyThreshold *float64 `json:"tag_auto_apply_threshold" validate:"omitempty,min=0,max=1"            mirror:"optional:true"`
+		TrashRetentionDays    *int32   `json:"trash_retention_days"     validate:"omitempty,min=1,max=3650"         mirror:"optional:true"`
+	}
+
+	WorkspaceSettingsResponse struct {
+		Settings models.WorkspaceSettings `json:"settings"`
+	}
+
+	FindEmbeddingMigrationRequest struct {
+		WorkspaceSlug string `json:"workspace_slug" validate:"required,slug"`
+	}
+
+	ReembedWorkspaceRequest struct {
+		WorkspaceID string `json:"workspace_id" validate:"required,uuid"`
+	}
+
+	EmbeddingMigrationResponse struct {
+		// ActiveModel is the model the workspace is searched with
+		ActiveModel models.EmbeddingModel `json:"active_model"`
+		// ConfiguredModel is the model new workspaces are embedded with and the target of re-embeddings
+		ConfiguredModel models.EmbeddingModel      `json:"configured_model"`
+		Migration       *models.EmbeddingMigration `json:"migration"        mirror:"optional:true"`
+	}
 )
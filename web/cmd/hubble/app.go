@@ -24,6 +24,7 @@ import (
 	"go.trulyao.dev/hubble/web/internal/queue"
 	"go.trulyao.dev/hubble/web/internal/ratelimit"
 	"go.trulyao.dev/hubble/web/internal/repository"
+	"go.trulyao.dev/hubble/web/internal/trash"
 	"go.trulyao.dev/hubble/web/pkg/llm"
 	llmcron "go.trulyao.dev/hubble/web/pkg/llm/cron"
 	"go.trulyao.dev/hubble/web/pkg/secrets"
@@ -46,6 +47,7 @@ type App struct {
 	wasmRuntime    *host.Runtime
 	llm            *llm.LLM
 	llmCron        *llmcron.Cron
+	trashCron      *trash.Cron
 
 	robin      *robin.Robin
 	handler    api.Handler
@@ -128,7 +130,7 @@ func (a *App) initDeps() error {
 	}
 	a.wasmRuntime = wasmRuntime
 
-	llm, err := llm.NewService(a.config, a.repository)
+	llm, err := llm.NewService(a.config, a.repository, a.store)
 	if err != nil {
 		return seer.Wrap("create_llm_service", err)
 	}
@@ -138,12 +140,18 @@ func (a *App) initDeps() error {
 	a.wasmRuntime.SetQueueFn(a.queue.Add) // set queue function to wasm runtime
 
 	// Only enable CRON if LLM is enabled
-	llmCron, err := llmcron.NewCron(a.config, a.repository, a.queue)
+	llmCron, err := llmcron.NewCron(a.config, a.repository, a.queue, a.llm)
 	if err != nil {
 		return seer.Wrap("create_llm_cron", err)
 	}
 	a.llmCron = llmCron
 
+	trashCron, err := trash.NewCron(a.repository, a.objectsStore)
+	if err != nil {
+		return seer.Wrap("create_trash_cron", err)
+	}
+	a.trashCron = trashCron
+
 	a.handler = api.New(&api.Deps{
 		Repo:          a.repository,
 		Config:        a.config,
@@ -235,6 +243,12 @@ func (a *App) close() {
 		log.Error().Err(err).Msg("failed to shutdown embeddings cron manager")
 	}
 
+	// trash cron manager
+	log.Info().Msg("shutting down trash cron manager")
+	if err := a.trashCron.Stop(); err != nil {
+		log.Error().Err(err).Msg("failed to shutdown trash cron manager")
+	}
+
 	log.Info().Msg("closing database connection pool")
 	a.pool.Close()
 }
@@ -18,6 +18,8 @@ func (a *App) attachProcedures() {
 	workspace := a.handler.Workspace()
 	collection := a.handler.Collection()
 	entry := a.handler.Entry()
+	tag := a.handler.Tag()
+	comment := a.handler.Comment()
 	plugin := a.handler.Plugin()
 
 	//nolint:all
@@ -65,11 +67,25 @@ func (a *App) attachProcedures() {
 		// Entry
 		query(r, procedure.GetLinkMetadata, entry.GetLinkMetadata, "/entry/url/lookup"),
 		query(r, procedure.FindEntry, entry.Find, "/entry"),
+		query(r, procedure.FindRelatedEntries, entry.FindRelated, "/entry/related"),
+		query(r, procedure.FindEntryLinks, entry.FindLinks, "/entry/links"),
 		query(r, procedure.SearchEntries, entry.Search, "/entry/search"),
+		query(r, procedure.AskQuestion, entry.AskQuestion, "/entry/ask"),
+		query(r, procedure.ListEntryVersions, entry.ListVersions, "/entry/versions"),
+		query(r, procedure.DiffEntryVersions, entry.DiffVersions, "/entry/versions/diff"),
+		query(r, procedure.ListComments, comment.List, "/entry/comments"),
 
 		// WORKSPACE
 		query(r, procedure.FindWorkspace, workspace.Find, "/workspace"),
+		query(r, procedure.FindWorkspaceSettings, workspace.FindSettings, "/workspace/settings"),
+		query(
+			r,
+			procedure.FindEmbeddingMigration,
+			workspace.FindEmbeddingMigration,
+			"/workspace/embeddings",
+		),
 		query(r, procedure.ListWorkspaceEntries, entry.FindWorkspaceEntries, "/workspace/entries"),
+		query(r, procedure.ListTrash, entry.ListTrash, "/workspace/trash"),
 		query(r, procedure.ListWorkspaceMembers, workspace.ListMembers, "/workspace/members"),
 		query(r, procedure.FindInvite, workspace.FindInvite, "/workspace/invite"),
 		query(
@@ -93,6 +109,8 @@ func (a *App) attachProcedures() {
 			"/collection/member/status",
 		),
 		query(r, procedure.ListCollectionMembers, collection.ListMembers, "/collection/members"),
+		query(r, procedure.ListTags, tag.List, "/collection/tags"),
+		query(r, procedure.ListTagSuggestions, tag.ListSuggestions, "/collection/tags/suggestions"),
 
 		// PLUGINS
 		query(r, procedure.ListPluginSources, plugin.ListSources, "/plugin/sources"),
@@ -157,6 +175,19 @@ func (a *App) attachProcedures() {
 		// Workspace
 		mutation(r, procedure.CreateWorkspace, workspace.Create, "/workspace/create"),
 		mutation(r, procedure.UpdateWorkspaceDetails, workspace.Update, "/workspace/update"),
+		mutation(
+			r,
+			procedure.UpdateWorkspaceSettings,
+			workspace.UpdateSettings,
+			"/workspace/settings/update",
+		),
+		mutation(r, procedure.ReembedWorkspace, workspace.Reembed, "/workspace/embeddings/reembed"),
+		mutation(
+			r,
+			procedure.CancelWorkspaceReembed,
+			workspace.CancelReembed,
+			"/workspace/embeddings/cancel",
+		),
 		mutation(r, procedure.DeleteWorkspace, workspace.Delete, "/workspace/delete"),
 		mutation(r, procedure.InviteUsersToWorkspace, workspace.InviteUsers, "/workspace/invite"),
 		mutation(
@@ -196,6 +227,25 @@ func (a *App) attachProcedures() {
 		mutation(r, procedure.LeaveCollection, collection.Leave, "/collection/leave"),
 		mutation(r, procedure.DeleteCollection, collection.Delete, "/collection/delete"),
 
+		// Tags
+		mutation(r, procedure.CreateTag, tag.Create, "/collection/tags/create"),
+		mutation(r, procedure.UpdateTag, tag.Update, "/collection/tags/update"),
+		mutation(r, procedure.DeleteTag, tag.Delete, "/collection/tags/delete"),
+		mutation(r, procedure.TagEntries, tag.TagEntries, "/entry/tags/add"),
+		mutation(r, procedure.UntagEntries, tag.UntagEntries, "/entry/tags/remove"),
+		mutation(
+			r,
+			procedure.AcceptTagSuggestions,
+			tag.AcceptSuggestions,
+			"/entry/tags/suggestions/accept",
+		),
+		mutation(
+			r,
+			procedure.RejectTagSuggestions,
+			tag.RejectSuggestions,
+			"/entry/tags/suggestions/reject",
+		),
+
 		// Entries
 		mutation(
 			r,
@@ -207,7 +257,31 @@ func (a *App) attachProcedures() {
 			WithRawPayload(api.ImportEntryPayload{}), // nolint:exhaustruct
 
 		mutation(r, procedure.DeleteEntries, entry.Delete, "/entry/delete"),
+		mutation(r, procedure.ArchiveEntries, entry.Archive, "/entry/archive"),
+		mutation(r, procedure.UnarchiveEntries, entry.Unarchive, "/entry/unarchive"),
+		mutation(r, procedure.RestoreEntries, entry.Restore, "/entry/restore"),
+		mutation(r, procedure.PurgeEntries, entry.Purge, "/entry/purge"),
+		mutation(r, procedure.MoveEntries, entry.Move, "/entry/move"),
+		mutation(r, procedure.CopyEntries, entry.Copy, "/entry/copy"),
 		mutation(r, procedure.RequeueEntries, entry.Requeue, "/entry/requeue"),
+		mutation(
+			r,
+			procedure.CreateEntryVersion,
+			entry.CreateVersion,
+			"/entry/versions/create",
+		).
+			WithMiddleware(a.middleware.WithGulter(gulterInstance, []string{"files"})).
+			WithRawPayload(api.CreateEntryVersionPayload{}), // nolint:exhaustruct
+		mutation(r, procedure.RestoreEntryVersion, entry.RestoreVersion, "/entry/versions/restore"),
+
+		// Comments
+		mutation(r, procedure.CreateComment, comment.Create, "/entry/comments/create"),
+		mutation(r, procedure.UpdateComment, comment.Update, "/entry/comments/update"),
+		mutation(r, procedure.DeleteComment, comment.Delete, "/entry/comments/delete"),
+
+		// Notes
+		mutation(r, procedure.CreateNote, entry.CreateNote, "/entry/notes/create"),
+		mutation(r, procedure.UpdateNote, entry.UpdateNote, "/entry/notes/update"),
 
 		// Plugins
 		mutation(r, procedure.FindPluginSource, plugin.FindSourceByURL, "/plugin/source/lookup"),
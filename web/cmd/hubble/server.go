@@ -12,6 +12,8 @@ import (
 	"go.trulyao.dev/hubble/web/internal/models"
 	"go.trulyao.dev/hubble/web/internal/plugin"
 	"go.trulyao.dev/hubble/web/internal/plugin/spec"
+	"go.trulyao.dev/hubble/web/internal/procedure"
+	"go.trulyao.dev/hubble/web/pkg/diff"
 	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
 	"go.trulyao.dev/hubble/web/pkg/ograph"
 	"go.trulyao.dev/hubble/web/pkg/secrets"
@@ -73,10 +75,25 @@ func (a *App) generateAdditionalTypes() error {
 		models.User{},
 		models.Collection{},
 		models.Workspace{},
+		models.EmbeddingModel{},
+		models.WorkspaceSettings{},
+		models.EmbeddingMigration{},
 		models.MemberWithUserID{},
 		models.EntryAddedBy{},
 		models.EntryRelation{},
+		models.EntrySummary{},
+		models.EntryTag{},
+		models.Tag{},
+		models.TagSuggestion{},
 		models.Entry{},
+		models.EntryVersion{},
+		models.TrashedEntry{},
+		models.EntryChunkChange{},
+		models.EntryChunkDiff{},
+		diff.Word{},
+		diff.Line{},
+		diff.Hunk{},
+		diff.Result{},
 		models.PluginSource{},
 		ograph.Metadata{},
 		models.FileMetadata{},
@@ -84,11 +101,18 @@ func (a *App) generateAdditionalTypes() error {
 		models.MembershipStatus{},
 		models.MemberUser{},
 		models.InstalledPlugin{},
+		models.Highlight{},
 		models.SearchResultChunkMetadata{},
 		models.SearchResult{},
 		models.MatchedChunk{},
 		models.CollapsedSearchResult{},
+		models.RelatedEntry{},
+		models.TypeFacet{},
+		models.CollectionFacet{},
+		models.SearchFacets{},
 		models.HybridSearchResults{},
+		models.Citation{},
+		models.Answer{},
 		api.PluginListItemSource{},
 		api.PluginListItem{},
 		spec.Privilege{},
@@ -154,6 +178,18 @@ func (a *App) Run() error {
 		ui.ServeSPA(w, r)
 	})
 
+	// Streaming (SSE) endpoints, these are authenticated with the session cookie like the protected procedures
+	a.attachStreams(mux, func(handler http.Handler) http.Handler {
+		if !isDev {
+			return handler
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			robin.CorsHandler(w, corsOpts)
+			handler.ServeHTTP(w, r)
+		})
+	})
+
 	// API endpoints
 	instance.AttachRestEndpoints(mux, &robin.RestApiOptions{
 		Enable:                 true,
@@ -204,6 +240,16 @@ func (a *App) Run() error {
 		return nil
 	})
 
+	// Start the trash cron manager
+	g.Go(func() error {
+		if err := a.trashCron.Start(); err != nil {
+			log.Error().Err(err).Msg("failed to start trash cron manager")
+			return err
+		}
+
+		return nil
+	})
+
 	// Start the HTTP server
 	g.Go(func() error {
 		defer func() {
@@ -224,6 +270,26 @@ func (a *App) Run() error {
 	return g.Wait()
 }
 
+// attachStreams mounts the SSE endpoints on the mux, every stream requires an authenticated session and shares the rate limit of its procedure
+func (a *App) attachStreams(mux *http.ServeMux, wrap func(http.Handler) http.Handler) {
+	stream := a.handler.Stream()
+
+	type route struct {
+		procedure string
+		handler   http.HandlerFunc
+	}
+
+	routes := map[string]route{
+		"GET /api/stream/entry/ask":  {procedure.AskQuestion, stream.AskQuestion},
+		"POST /api/stream/entry/ask": {procedure.AskQuestion, stream.AskQuestion},
+	}
+
+	for pattern, r := range routes {
+		handler := a.middleware.WithHttpAuth(r.handler)
+		mux.Handle(pattern, wrap(a.middleware.WithHttpRateLimit(r.procedure, handler)))
+	}
+}
+
 // protect is a helper function that wraps a procedure with the WithAuth middleware
 func (a *App) protect(procedure robin.Procedure) robin.Procedure {
 	procedure.WithMiddleware(a.middleware.WithAuth)
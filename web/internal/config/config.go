@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
@@ -15,9 +16,21 @@ import (
 
 //go:generate go tool github.com/abice/go-enum --marshal
 
-// ENUM(score,threshold)
+// ENUM(score,threshold,rrf)
 type SearchMode string
 
+// ENUM(none,api,chat)
+type RerankDriver string
+
+// ENUM(openai,hashed)
+type EmbeddingDriver string
+
+// ENUM(none,postgres,kv)
+type EmbeddingCacheDriver string
+
+// DefaultHashedEmbeddingModel is the model name recorded for vectors generated by the hashed embedder
+const DefaultHashedEmbeddingModel = "hashed-ngrams"
+
 const (
 	EnvironmentDevelopment = "development"
 	EnvironmentProduction  = "production"
@@ -68,6 +81,33 @@ type (
 		// EmbeddingsModel is the model used for embeddings (e.g. text-embedding-ada-002)
 		EmbeddingsModel string `mapstructure:"embedding_model"`
 
+		// EmbeddingDimensions is the number of dimensions requested from the embedding model (default: 768)
+		EmbeddingDimensions int `mapstructure:"embedding_dimensions"`
+
+		// EmbeddingBatchSize is the maximum number of chunks embedded in a single request (default: 64)
+		EmbeddingBatchSize int `mapstructure:"embedding_batch_size"`
+
+		// EmbeddingBatchTokens is the maximum (estimated) number of tokens embedded in a single request (default: 8000)
+		EmbeddingBatchTokens int `mapstructure:"embedding_batch_tokens"`
+
+		// EmbeddingFlushInterval is how long chunks wait for more chunks to fill a batch before it is sent anyway (default: 250ms)
+		EmbeddingFlushInterval time.Duration `mapstructure:"embedding_flush_interval"`
+
+		// EmbeddingCacheMaxEntries is the number of embeddings kept in the cache, the least recently used ones are evicted first (default: 100000)
+		EmbeddingCacheMaxEntries int `mapstructure:"embedding_cache_max_entries"`
+
+		// ReembedBatchSize is the number of chunks embedded at once when a workspace is re-embedded with a new model (default: 32)
+		ReembedBatchSize int `mapstructure:"reembed_batch_size"`
+
+		// ReembedInterval is the delay between two re-embedding batches, this throttles the requests sent to the embedding server (default: 10s)
+		ReembedInterval time.Duration `mapstructure:"reembed_interval"`
+
+		// ChatModel is the chat-completion model used to answer questions (e.g. gpt-4o-mini, llama3.1)
+		ChatModel string `mapstructure:"chat_model"`
+
+		// MaxContextTokens is the (estimated) number of tokens of retrieved content that can be added to a prompt (default: 4000)
+		MaxContextTokens int `mapstructure:"max_context_tokens"`
+
 		enabledEmbeddings bool `mapstructure:"-"`
 	}
 
@@ -79,17 +119,55 @@ type (
 
 		   The other options are:
 		   - "score": The search engine will only use a score-based approach to determine the relevance of the search results.
+		   - "rrf": The semantic and full-text results are fused with Reciprocal Rank Fusion (using the workspace's `k` and weights), the threshold is still applied to the fused results.
 		*/
 		Mode SearchMode `mapstructure:"mode"`
 
 		// Threshold is the threshold for the search engine (default: 30)
 		Threshold float64 `mapstructure:"threshold"`
+
+		// Reranker is the optional second-stage reranker applied to the top results
+		Reranker Reranker `mapstructure:"rerank"`
+
+		// FuzzyMinResults is the number of full-text matches below which the trigram fuzzy search kicks in to catch misspellings, 0 disables it (default: 5)
+		FuzzyMinResults int `mapstructure:"fuzzy_min_results"`
+	}
+
+	Reranker struct {
+		/*
+		   Driver is the reranker to use after the hybrid search has collapsed the results (default: none)
+
+		   - "api": sends the query and the documents to a `/rerank` endpoint (Cohere/Jina-compatible)
+		   - "chat": asks a chat-completion model to score the documents through the OpenAI-compatible server
+		*/
+		Driver RerankDriver `mapstructure:"driver"`
+
+		// BaseURL is the base URL of the rerank API, defaults to the LLM base URL
+		BaseURL string `mapstructure:"base_url"`
+
+		// ApiKey is the API key for the rerank API, defaults to the LLM API key
+		ApiKey string `mapstructure:"api_key"`
+
+		// Model is the reranking (or chat) model to use
+		Model string `mapstructure:"model"`
+
+		// TopN is the number of top results sent to the reranker (default: 20)
+		TopN int `mapstructure:"top_n"`
+
+		// Timeout is the time budget for a single rerank request, the original order is kept if it is exceeded (default: 3s)
+		Timeout time.Duration `mapstructure:"timeout"`
 	}
 
 	// Drivers is the configuration for the modular pieces of the application (e.g. key-value store)
 	Drivers struct {
 		// The preferred driver for the key-value store
 		KV string `mapstructure:"kv"`
+
+		// Embedder is the driver used to generate semantic vectors, "hashed" runs in-process and does not need an LLM server (e.g. for development, CI or air-gapped deployments)
+		Embedder EmbeddingDriver `mapstructure:"embedder"`
+
+		// EmbeddingCache is where embeddings are cached by model and content, "kv" uses the key-value store and is meant for small deployments
+		EmbeddingCache EmbeddingCacheDriver `mapstructure:"embedding_cache"`
 	}
 
 	// Keys is the configuration for the encryption keys used by the application for various purposes
@@ -235,9 +313,23 @@ func Load() (Config, error) {
 	viper.SetDefault("app_url", "http://localhost:3288")
 	viper.SetDefault("plugins.directory", ".plugins")
 	viper.SetDefault("driver.kv", kv.DriverBadgerDb)
+	viper.SetDefault("driver.embedder", EmbeddingDriverOpenai.String())
+	viper.SetDefault("driver.embedding_cache", EmbeddingCacheDriverPostgres.String())
 	viper.SetDefault("environment", EnvironmentDevelopment)
+	viper.SetDefault("llm.embedding_dimensions", 768)
+	viper.SetDefault("llm.embedding_batch_size", 64)
+	viper.SetDefault("llm.embedding_batch_tokens", 8000)
+	viper.SetDefault("llm.embedding_flush_interval", 250*time.Millisecond)
+	viper.SetDefault("llm.embedding_cache_max_entries", 100000)
+	viper.SetDefault("llm.reembed_batch_size", 32)
+	viper.SetDefault("llm.reembed_interval", 10*time.Second)
+	viper.SetDefault("llm.max_context_tokens", 4000)
 	viper.SetDefault("search.mode", SearchModeThreshold.String())
 	viper.SetDefault("search.threshold", 30.0)
+	viper.SetDefault("search.fuzzy_min_results", 5)
+	viper.SetDefault("search.rerank.driver", RerankDriverNone.String())
+	viper.SetDefault("search.rerank.top_n", 20)
+	viper.SetDefault("search.rerank.timeout", 3*time.Second)
 
 	bindAllEnv()
 
@@ -258,9 +350,19 @@ func Load() (Config, error) {
 	return *c, nil
 }
 
-// EnabledEmbeddings if the base URL and the embeddings model are set, then the embeddings are enabled
+// EnabledEmbeddings returns true if an embedding driver is usable (an LLM server and an embeddings model, or the hashed driver)
 func (l *LLM) EnabledEmbeddings() bool {
-	return l.enabledEmbeddings && l.BaseURL != "" && l.EmbeddingsModel != ""
+	return l.enabledEmbeddings && l.EmbeddingsModel != ""
+}
+
+// EnabledChat returns true if the base URL and the chat model are set
+func (l *LLM) EnabledChat() bool {
+	return l.BaseURL != "" && l.ChatModel != ""
+}
+
+// Enabled returns true if a reranker driver and model are set
+func (r *Reranker) Enabled() bool {
+	return r.Driver != "" && r.Driver != RerankDriverNone && r.Model != ""
 }
 
 // Debug returns true if the application is running in the development environment or if the debug flag is set
@@ -319,11 +421,33 @@ func (c *Config) Validate() error {
 		}
 
 		// Check if the embeddings model is set
-		if c.LLM.EmbeddingsModel != "" {
+		if c.LLM.EmbeddingsModel != "" && c.Drivers.Embedder == EmbeddingDriverOpenai {
 			c.LLM.enabledEmbeddings = true
 		}
 	}
 
+	if !c.Drivers.Embedder.IsValid() {
+		return seer.New(
+			"embedder_driver",
+			fmt.Sprintf("invalid embedder driver %q, set `HUBBLE_DRIVER_EMBEDDER` to one of: openai, hashed", c.Drivers.Embedder),
+		)
+	}
+
+	if !c.Drivers.EmbeddingCache.IsValid() {
+		return seer.New(
+			"embedding_cache_driver",
+			fmt.Sprintf("invalid embedding cache driver %q, set `HUBBLE_DRIVER_EMBEDDING_CACHE` to one of: none, postgres, kv", c.Drivers.EmbeddingCache),
+		)
+	}
+
+	// The hashed embedder runs in-process, it only needs a name to version the vectors it generates
+	if c.Drivers.Embedder == EmbeddingDriverHashed {
+		if c.LLM.EmbeddingsModel == "" {
+			c.LLM.EmbeddingsModel = DefaultHashedEmbeddingModel
+		}
+		c.LLM.enabledEmbeddings = true
+	}
+
 	return nil
 }
 
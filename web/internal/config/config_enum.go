@@ -11,11 +11,166 @@ import (
 	"fmt"
 )
 
+const (
+	// EmbeddingCacheDriverNone is a EmbeddingCacheDriver of type none.
+	EmbeddingCacheDriverNone EmbeddingCacheDriver = "none"
+	// EmbeddingCacheDriverPostgres is a EmbeddingCacheDriver of type postgres.
+	EmbeddingCacheDriverPostgres EmbeddingCacheDriver = "postgres"
+	// EmbeddingCacheDriverKv is a EmbeddingCacheDriver of type kv.
+	EmbeddingCacheDriverKv EmbeddingCacheDriver = "kv"
+)
+
+var ErrInvalidEmbeddingCacheDriver = errors.New("not a valid EmbeddingCacheDriver")
+
+// String implements the Stringer interface.
+func (x EmbeddingCacheDriver) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x EmbeddingCacheDriver) IsValid() bool {
+	_, err := ParseEmbeddingCacheDriver(string(x))
+	return err == nil
+}
+
+var _EmbeddingCacheDriverValue = map[string]EmbeddingCacheDriver{
+	"none":     EmbeddingCacheDriverNone,
+	"postgres": EmbeddingCacheDriverPostgres,
+	"kv":       EmbeddingCacheDriverKv,
+}
+
+// ParseEmbeddingCacheDriver attempts to convert a string to a EmbeddingCacheDriver.
+func ParseEmbeddingCacheDriver(name string) (EmbeddingCacheDriver, error) {
+	if x, ok := _EmbeddingCacheDriverValue[name]; ok {
+		return x, nil
+	}
+	return EmbeddingCacheDriver(""), fmt.Errorf("%s is %w", name, ErrInvalidEmbeddingCacheDriver)
+}
+
+// MarshalText implements the text marshaller method.
+func (x EmbeddingCacheDriver) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *EmbeddingCacheDriver) UnmarshalText(text []byte) error {
+	tmp, err := ParseEmbeddingCacheDriver(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// EmbeddingDriverOpenai is a EmbeddingDriver of type openai.
+	EmbeddingDriverOpenai EmbeddingDriver = "openai"
+	// EmbeddingDriverHashed is a EmbeddingDriver of type hashed.
+	EmbeddingDriverHashed EmbeddingDriver = "hashed"
+)
+
+var ErrInvalidEmbeddingDriver = errors.New("not a valid EmbeddingDriver")
+
+// String implements the Stringer interface.
+func (x EmbeddingDriver) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x EmbeddingDriver) IsValid() bool {
+	_, err := ParseEmbeddingDriver(string(x))
+	return err == nil
+}
+
+var _EmbeddingDriverValue = map[string]EmbeddingDriver{
+	"openai": EmbeddingDriverOpenai,
+	"hashed": EmbeddingDriverHashed,
+}
+
+// ParseEmbeddingDriver attempts to convert a string to a EmbeddingDriver.
+func ParseEmbeddingDriver(name string) (EmbeddingDriver, error) {
+	if x, ok := _EmbeddingDriverValue[name]; ok {
+		return x, nil
+	}
+	return EmbeddingDriver(""), fmt.Errorf("%s is %w", name, ErrInvalidEmbeddingDriver)
+}
+
+// MarshalText implements the text marshaller method.
+func (x EmbeddingDriver) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *EmbeddingDriver) UnmarshalText(text []byte) error {
+	tmp, err := ParseEmbeddingDriver(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// RerankDriverNone is a RerankDriver of type none.
+	RerankDriverNone RerankDriver = "none"
+	// RerankDriverApi is a RerankDriver of type api.
+	RerankDriverApi RerankDriver = "api"
+	// RerankDriverChat is a RerankDriver of type chat.
+	RerankDriverChat RerankDriver = "chat"
+)
+
+var ErrInvalidRerankDriver = errors.New("not a valid RerankDriver")
+
+// String implements the Stringer interface.
+func (x RerankDriver) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x RerankDriver) IsValid() bool {
+	_, err := ParseRerankDriver(string(x))
+	return err == nil
+}
+
+var _RerankDriverValue = map[string]RerankDriver{
+	"none": RerankDriverNone,
+	"api":  RerankDriverApi,
+	"chat": RerankDriverChat,
+}
+
+// ParseRerankDriver attempts to convert a string to a RerankDriver.
+func ParseRerankDriver(name string) (RerankDriver, error) {
+	if x, ok := _RerankDriverValue[name]; ok {
+		return x, nil
+	}
+	return RerankDriver(""), fmt.Errorf("%s is %w", name, ErrInvalidRerankDriver)
+}
+
+// MarshalText implements the text marshaller method.
+func (x RerankDriver) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *RerankDriver) UnmarshalText(text []byte) error {
+	tmp, err := ParseRerankDriver(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
 const (
 	// SearchModeScore is a SearchMode of type score.
 	SearchModeScore SearchMode = "score"
 	// SearchModeThreshold is a SearchMode of type threshold.
 	SearchModeThreshold SearchMode = "threshold"
+	// SearchModeRrf is a SearchMode of type rrf.
+	SearchModeRrf SearchMode = "rrf"
 )
 
 var ErrInvalidSearchMode = errors.New("not a valid SearchMode")
@@ -35,6 +190,7 @@ func (x SearchMode) IsValid() bool {
 var _SearchModeValue = map[string]SearchMode{
 	"score":     SearchModeScore,
 	"threshold": SearchModeThreshold,
+	"rrf":       SearchModeRrf,
 }
 
 // ParseSearchMode attempts to convert a string to a SearchMode.
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS workspace_settings (
+	workspace_id INTEGER PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+
+	-- Reciprocal Rank Fusion parameters, only used when the search mode is set to `rrf`
+	rrf_k INTEGER NOT NULL DEFAULT 60 CHECK (rrf_k > 0),
+	rrf_semantic_weight DOUBLE PRECISION NOT NULL DEFAULT 1.0 CHECK (rrf_semantic_weight >= 0),
+	rrf_full_text_weight DOUBLE PRECISION NOT NULL DEFAULT 1.0 CHECK (rrf_full_text_weight >= 0),
+
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON workspace_settings
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Used by the fuzzy search leg (word similarity on entry names and chunk content)
+CREATE INDEX IF NOT EXISTS idx_entries_name_trgm ON entries USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_content_trgm ON entry_chunks USING GIN (content gin_trgm_ops);
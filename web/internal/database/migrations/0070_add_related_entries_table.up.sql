@@ -0,0 +1,12 @@
+-- Cached "more like this" recommendations, refreshed whenever all the chunks of an entry have been embedded
+CREATE TABLE IF NOT EXISTS related_entries (
+	entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+	related_entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+	similarity DOUBLE PRECISION NOT NULL,
+
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+	PRIMARY KEY (entry_id, related_entry_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_related_entries_related_entry_id ON related_entries (related_entry_id);
@@ -0,0 +1,69 @@
+-- HNSW indexes can only be built on vectors with a fixed number of dimensions, the semantic vector can now come from models with different dimensions.
+-- Semantic searches are always scoped to a workspace and a model, so the distances are computed over the workspace's chunks instead.
+DROP INDEX IF EXISTS entry_chunks_semantic_vector_idx;
+
+ALTER TABLE entry_chunks
+ALTER COLUMN semantic_vector TYPE vector;
+
+ALTER TABLE entry_chunks
+ADD COLUMN IF NOT EXISTS embedding_model TEXT DEFAULT NULL,
+ADD COLUMN IF NOT EXISTS embedding_dimensions INTEGER DEFAULT NULL;
+
+COMMENT ON COLUMN entry_chunks.embedding_model IS 'The embedding model that produced the semantic vector';
+COMMENT ON COLUMN entry_chunks.embedding_dimensions IS 'The number of dimensions of the semantic vector';
+
+-- Existing vectors were all generated with the configured model, the model is recorded when the application starts
+UPDATE entry_chunks
+SET embedding_dimensions = vector_dims(semantic_vector)
+WHERE semantic_vector IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_embedding_model ON entry_chunks (embedding_model, embedding_dimensions);
+
+-- The model the workspace's vectors are generated with, this only changes once a re-embedding migration has completed
+ALTER TABLE workspace_settings
+ADD COLUMN IF NOT EXISTS embedding_model TEXT DEFAULT NULL,
+ADD COLUMN IF NOT EXISTS embedding_dimensions INTEGER DEFAULT NULL;
+
+CREATE TYPE embedding_migration_status AS ENUM ('running', 'completed', 'failed', 'cancelled');
+
+CREATE TABLE IF NOT EXISTS embedding_migrations (
+	id SERIAL PRIMARY KEY,
+	workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	started_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+
+	from_model TEXT NOT NULL,
+	from_dimensions INTEGER NOT NULL,
+	to_model TEXT NOT NULL,
+	to_dimensions INTEGER NOT NULL CHECK (to_dimensions > 0),
+
+	status embedding_migration_status NOT NULL DEFAULT 'running',
+	total_chunks INTEGER NOT NULL DEFAULT 0,
+	processed_chunks INTEGER NOT NULL DEFAULT 0,
+	-- chunks are processed in order of their IDs, this is where the migration resumes from after a restart
+	last_chunk_id INTEGER NOT NULL DEFAULT 0,
+	-- consecutive failed batches, the migration fails once this reaches the limit
+	error_count INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT DEFAULT NULL,
+
+	completed_at TIMESTAMPTZ DEFAULT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Only one migration can be running for a workspace at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_embedding_migrations_running ON embedding_migrations (workspace_id) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_embedding_migrations_workspace_id ON embedding_migrations (workspace_id);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON embedding_migrations
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- The new vectors are kept aside until every chunk has been re-embedded, searches keep using the old vectors in the meantime
+CREATE TABLE IF NOT EXISTS embedding_migration_vectors (
+	migration_id INTEGER NOT NULL REFERENCES embedding_migrations(id) ON DELETE CASCADE,
+	chunk_id INTEGER NOT NULL REFERENCES entry_chunks(id) ON DELETE CASCADE,
+	semantic_vector vector NOT NULL,
+
+	PRIMARY KEY (migration_id, chunk_id)
+);
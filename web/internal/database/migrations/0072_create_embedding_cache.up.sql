@@ -0,0 +1,15 @@
+-- Embeddings are cached by model and content so that requeued entries and identical chunks are not embedded again
+CREATE TABLE IF NOT EXISTS embedding_cache (
+	-- SHA-256 of the model, its dimensions and the normalised content
+	cache_key TEXT PRIMARY KEY,
+	embedding_model TEXT NOT NULL,
+	embedding_dimensions INTEGER NOT NULL,
+	semantic_vector vector NOT NULL,
+
+	hit_count INTEGER NOT NULL DEFAULT 0,
+	last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The least recently used entries are evicted once the cache is over its size limit
+CREATE INDEX IF NOT EXISTS idx_embedding_cache_last_used_at ON embedding_cache (last_used_at DESC);
@@ -0,0 +1,88 @@
+-- Summaries are generated by the chat model once an entry has been processed, they are only generated for workspaces that opted in
+ALTER TABLE workspace_settings
+ADD COLUMN IF NOT EXISTS summaries_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS entry_summaries (
+	entry_id INTEGER PRIMARY KEY REFERENCES entries(id) ON DELETE CASCADE,
+	-- the version of the entry the summaries were generated from, they are regenerated when it changes
+	entry_version INTEGER NOT NULL,
+	summary_short TEXT NOT NULL,
+	summary_long TEXT NOT NULL,
+	chat_model TEXT NOT NULL,
+
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN entry_summaries.entry_version IS 'The version of the entry the summaries were generated from';
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON entry_summaries
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- Drop the old trigger
+drop trigger if exists entry_chunks_text_vector_update on entry_chunks;
+
+-- Drop the old function
+drop function if exists update_text_vector
+;
+
+-- The summaries are indexed with the first chunk of the entry, so that searches can match an entry on its summary exactly once
+create function update_text_vector()
+returns trigger
+as $$
+DECLARE
+summary TEXT;
+BEGIN
+NEW.text_vector := to_tsvector(ts_regconfig(NEW.language), NEW.content);
+
+IF NEW.deleted_at IS NULL AND NOT EXISTS (
+    SELECT 1
+    FROM entry_chunks ec
+    WHERE ec.entry_id = NEW.entry_id AND ec.deleted_at IS NULL AND ec.chunk_index < NEW.chunk_index
+) THEN
+    SELECT es.summary_short || ' ' || es.summary_long INTO summary
+    FROM entry_summaries es
+    WHERE es.entry_id = NEW.entry_id;
+
+    IF summary IS NOT NULL THEN
+        NEW.text_vector := NEW.text_vector || to_tsvector(ts_regconfig(NEW.language), summary);
+    END IF;
+END IF;
+
+RETURN NEW;
+END;
+$$
+language plpgsql
+;
+
+-- Recreate the trigger to use the new function
+CREATE TRIGGER entry_chunks_text_vector_update
+BEFORE INSERT OR UPDATE OF content, language ON entry_chunks
+FOR EACH ROW EXECUTE FUNCTION update_text_vector();
+
+-- Re-index the first chunk of the entry whenever its summaries change
+create function update_entry_summary_text_vector()
+returns trigger
+as $$
+BEGIN
+UPDATE entry_chunks
+SET language = language
+WHERE id = (
+    SELECT ec.id
+    FROM entry_chunks ec
+    WHERE ec.entry_id = NEW.entry_id AND ec.deleted_at IS NULL
+    ORDER BY ec.chunk_index ASC
+    LIMIT 1
+);
+
+RETURN NEW;
+END;
+$$
+language plpgsql
+;
+
+CREATE TRIGGER entry_summaries_text_vector_update
+AFTER INSERT OR UPDATE OF summary_short, summary_long ON entry_summaries
+FOR EACH ROW EXECUTE FUNCTION update_entry_summary_text_vector();
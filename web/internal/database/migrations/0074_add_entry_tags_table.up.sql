@@ -0,0 +1,19 @@
+-- Tag names were globally unique, they are now only unique (case-insensitively) within their collection
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_name_key;
+
+ALTER TABLE tags
+ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_collection_id_name ON tags (collection_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS entry_tags (
+	entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+	tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	added_by INTEGER NOT NULL REFERENCES users(id) ON DELETE NO ACTION,
+
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+	PRIMARY KEY (entry_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entry_tags_tag_id ON entry_tags (tag_id);
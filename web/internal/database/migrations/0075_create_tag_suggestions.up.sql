@@ -0,0 +1,54 @@
+-- Tags are suggested by the chat model once an entry has been processed, workspaces can either review every suggestion or let the confident ones be applied automatically
+CREATE TYPE tag_suggestion_mode AS ENUM ('off', 'suggest', 'auto');
+
+CREATE TYPE tag_suggestion_status AS ENUM ('pending', 'accepted', 'rejected');
+
+ALTER TABLE workspace_settings
+ADD COLUMN IF NOT EXISTS tag_suggestion_mode tag_suggestion_mode NOT NULL DEFAULT 'off',
+ADD COLUMN IF NOT EXISTS tag_auto_apply_threshold DOUBLE PRECISION NOT NULL DEFAULT 0.8;
+
+COMMENT ON COLUMN workspace_settings.tag_auto_apply_threshold IS 'The minimum confidence of the suggestions that are applied automatically in the auto mode';
+
+CREATE TABLE IF NOT EXISTS tag_suggestions (
+	id SERIAL PRIMARY KEY,
+	public_id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+	entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+	-- the suggested name, it matches an existing tag of the collection or becomes a new tag once accepted
+	name VARCHAR(72) NOT NULL,
+	-- the tag the suggestion was applied as, only set once it has been accepted
+	tag_id INTEGER DEFAULT NULL REFERENCES tags(id) ON DELETE SET NULL,
+	confidence DOUBLE PRECISION NOT NULL,
+	status tag_suggestion_status NOT NULL DEFAULT 'pending',
+	-- the version of the entry the suggestion was generated from
+	entry_version INTEGER NOT NULL,
+	-- the user who accepted or rejected the suggestion, null for pending and automatically applied suggestions
+	reviewed_by INTEGER DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL,
+
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Rejected suggestions are kept so that the same tag is never suggested again for the entry
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tag_suggestions_entry_id_name ON tag_suggestions (entry_id, lower(name));
+
+CREATE INDEX IF NOT EXISTS idx_tag_suggestions_status ON tag_suggestions (status);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON tag_suggestions
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- Entries can end up without any suggestion, the runs record the version of the entry that was last classified so that it is not classified again
+CREATE TABLE IF NOT EXISTS tag_suggestion_runs (
+	entry_id INTEGER PRIMARY KEY REFERENCES entries(id) ON DELETE CASCADE,
+	entry_version INTEGER NOT NULL,
+	chat_model TEXT NOT NULL,
+
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON tag_suggestion_runs
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
@@ -0,0 +1,12 @@
+-- Deleted entries are kept in the trash of their workspace until they are restored or purged, they are purged automatically once the retention period has passed
+ALTER TABLE workspace_settings
+ADD COLUMN IF NOT EXISTS trash_retention_days INTEGER NOT NULL DEFAULT 30 CHECK (trash_retention_days > 0);
+
+COMMENT ON COLUMN workspace_settings.trash_retention_days IS 'The number of days deleted entries are kept in the trash before they are purged';
+
+-- The queue records are purged along with their entries
+ALTER TABLE entries_queue
+DROP CONSTRAINT IF EXISTS entries_queue_entry_id_fkey,
+ADD CONSTRAINT entries_queue_entry_id_fkey FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_entry_id_deleted_at ON entry_chunks (entry_id, deleted_at);
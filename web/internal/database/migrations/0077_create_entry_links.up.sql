@@ -0,0 +1,25 @@
+-- Links written in the content of entries, either as [[Entry Name]] or as hubble://entry/<uuid>
+-- Both ends of a link are the first version of their entry so that links are kept across versions and renames
+CREATE TABLE IF NOT EXISTS entry_links (
+	id SERIAL PRIMARY KEY,
+	workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+	source_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+	-- null until a matching entry exists, links to purged entries become unresolved again
+	target_id INTEGER DEFAULT NULL REFERENCES entries(id) ON DELETE SET NULL,
+	-- the name is set for wiki links and the public ID for entry URIs, the public ID can be the one of any version
+	target_name TEXT DEFAULT NULL,
+	target_public_id UUID DEFAULT NULL,
+
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+	CONSTRAINT entry_links_target_check CHECK ((target_name IS NULL) != (target_public_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entry_links_source_id_target_name ON entry_links (source_id, lower(target_name));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entry_links_source_id_target_public_id ON entry_links (source_id, target_public_id);
+
+CREATE INDEX IF NOT EXISTS idx_entry_links_target_id ON entry_links (target_id);
+
+-- Unresolved links are looked up by name whenever an entry is created or renamed
+CREATE INDEX IF NOT EXISTS idx_entry_links_unresolved ON entry_links (workspace_id, lower(target_name)) WHERE target_id IS NULL;
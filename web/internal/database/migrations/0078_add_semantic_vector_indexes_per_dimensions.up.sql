@@ -0,0 +1,22 @@
+-- HNSW indexes need vectors with a fixed number of dimensions, every supported number of dimensions gets its own partial index.
+-- Queries cast the semantic vector to the dimensions of the model (e.g. `semantic_vector::vector(768)`) so that the index expression matches.
+-- Vectors with other dimensions are still searched, without an index.
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_semantic_vector_384 ON entry_chunks
+USING hnsw ((semantic_vector::vector(384)) vector_cosine_ops) WITH (ef_construction=256)
+WHERE embedding_dimensions = 384;
+
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_semantic_vector_512 ON entry_chunks
+USING hnsw ((semantic_vector::vector(512)) vector_cosine_ops) WITH (ef_construction=256)
+WHERE embedding_dimensions = 512;
+
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_semantic_vector_768 ON entry_chunks
+USING hnsw ((semantic_vector::vector(768)) vector_cosine_ops) WITH (ef_construction=256)
+WHERE embedding_dimensions = 768;
+
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_semantic_vector_1024 ON entry_chunks
+USING hnsw ((semantic_vector::vector(1024)) vector_cosine_ops) WITH (ef_construction=256)
+WHERE embedding_dimensions = 1024;
+
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_semantic_vector_1536 ON entry_chunks
+USING hnsw ((semantic_vector::vector(1536)) vector_cosine_ops) WITH (ef_construction=256)
+WHERE embedding_dimensions = 1536;
@@ -0,0 +1,15 @@
+-- The files of purged entries that could not be removed from the object store, the trash cron retries them until they are removed
+CREATE TABLE IF NOT EXISTS failed_file_deletions (
+	file_id TEXT PRIMARY KEY,
+	attempts INTEGER NOT NULL DEFAULT 1,
+	last_error TEXT DEFAULT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_failed_file_deletions_updated_at ON failed_file_deletions (updated_at);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON failed_file_deletions
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
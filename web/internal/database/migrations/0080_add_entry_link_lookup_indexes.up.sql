@@ -0,0 +1,5 @@
+-- The versions of an entry are matched through their first version by the link and backlink queries
+CREATE INDEX IF NOT EXISTS idx_entries_coalesce_parent_id_id ON entries ((coalesce(parent_id, id)));
+
+-- Links written by name are matched against the names of the entries regardless of case
+CREATE INDEX IF NOT EXISTS idx_entries_lower_name ON entries (lower(name));
@@ -0,0 +1,47 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// vectorPlaceholder is the cast used by the queries that compare semantic vectors, the HNSW indexes are built per number of dimensions so the cast has to name the dimensions of the vectors being compared
+const vectorPlaceholder = "::vector(1)"
+
+// dimensionsDB replaces the vector casts of every statement with the given dimensions
+type dimensionsDB struct {
+	DBTX
+
+	cast string
+}
+
+// WithDimensions returns queries whose vector casts are set to `dimensions`, it must be used for the queries that order by vector distance
+func (q *Queries) WithDimensions(dimensions int32) *Queries {
+	return &Queries{
+		db: &dimensionsDB{DBTX: q.db, cast: fmt.Sprintf("::vector(%d)", dimensions)},
+	}
+}
+
+func (d *dimensionsDB) Exec(
+	ctx context.Context,
+	sql string,
+	args ...interface{},
+) (pgconn.CommandTag, error) {
+	return d.DBTX.Exec(ctx, d.rewrite(sql), args...)
+}
+
+func (d *dimensionsDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return d.DBTX.Query(ctx, d.rewrite(sql), args...)
+}
+
+func (d *dimensionsDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return d.DBTX.QueryRow(ctx, d.rewrite(sql), args...)
+}
+
+func (d *dimensionsDB) rewrite(sql string) string {
+	return strings.ReplaceAll(sql, vectorPlaceholder, d.cast)
+}
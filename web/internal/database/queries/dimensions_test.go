@@ -0,0 +1,66 @@
+package queries_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+)
+
+var errRecorded = errors.New("recorded")
+
+// recordingDB records the statements it receives instead of running them
+type recordingDB struct {
+	statements []string
+}
+
+func (r *recordingDB) Exec(_ context.Context, sql string, _ ...interface{}) (pgconn.CommandTag, error) {
+	r.statements = append(r.statements, sql)
+	return pgconn.CommandTag{}, errRecorded
+}
+
+func (r *recordingDB) Query(_ context.Context, sql string, _ ...interface{}) (pgx.Rows, error) {
+	r.statements = append(r.statements, sql)
+	return nil, errRecorded
+}
+
+func (r *recordingDB) QueryRow(_ context.Context, sql string, _ ...interface{}) pgx.Row {
+	r.statements = append(r.statements, sql)
+	return nil
+}
+
+func (r *recordingDB) CopyFrom(
+	context.Context,
+	pgx.Identifier,
+	[]string,
+	pgx.CopyFromSource,
+) (int64, error) {
+	return 0, errRecorded
+}
+
+func Test_WithDimensions(t *testing.T) {
+	db := &recordingDB{}
+
+	_, err := queries.New(db).
+		WithDimensions(768).
+		FindRelatedEntries(context.Background(), queries.FindRelatedEntriesParams{})
+	if !errors.Is(err, errRecorded) {
+		t.Fatalf("expected the statement to be recorded, got %v", err)
+	}
+
+	if len(db.statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(db.statements))
+	}
+
+	statement := db.statements[0]
+	if strings.Contains(statement, "::vector(1)") {
+		t.Errorf("expected the placeholder casts to be replaced, got %s", statement)
+	}
+	if !strings.Contains(statement, "ck.semantic_vector::vector(768) <=>") {
+		t.Errorf("expected the vectors to be cast to 768 dimensions, got %s", statement)
+	}
+}
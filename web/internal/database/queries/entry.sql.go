@@ -14,11 +14,63 @@ import (
 	"go.trulyao.dev/hubble/web/pkg/rbac"
 )
 
+const archiveEntries = `-- name: ArchiveEntries :many
+with
+    targets as (
+        select e.public_id, e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any($1::uuid[])
+            and w.slug = $2
+            and e.deleted_at is null
+            and e.archived_at is null
+    ),
+    archived as (
+        update entries e
+        set archived_at = now()
+        from targets t
+        where e.origin = t.origin and e.archived_at is null
+    )
+select t.public_id
+from targets t
+`
+
+type ArchiveEntriesParams struct {
+	EntryPublicIds []pgtype.UUID `json:"entry_public_ids"`
+	WorkspaceSlug  pgtype.Text   `json:"workspace_slug"`
+}
+
+// Archive entries along with all their versions, only the public IDs of the given versions that were not archived yet are returned
+func (q *Queries) ArchiveEntries(ctx context.Context, arg ArchiveEntriesParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, archiveEntries, arg.EntryPublicIds, arg.WorkspaceSlug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var public_id pgtype.UUID
+		if err := rows.Scan(&public_id); err != nil {
+			return nil, err
+		}
+		items = append(items, public_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const chunkCanBeProcessed = `-- name: ChunkCanBeProcessed :one
 select count(*) > 0 as can_process
 from entry_chunks ec
+join entries e on e.id = ec.entry_id
 where
     ec.id = $1
+    -- chunks of entries archived after they were queued are skipped
+    and e.archived_at is null
     and (
         ec.embedding_status = 'pending'
         or (ec.embedding_status = 'failed' and ec.embedding_error_count < 5)
@@ -41,6 +93,304 @@ func (q *Queries) ChunkCanBeProcessed(ctx context.Context, chunkID int32) (bool,
 	return can_process, err
 }
 
+const copyEntry = `-- name: CopyEntry :one
+insert into entries (
+    name,
+    meta,
+    content,
+    text_content,
+    file_id,
+    entry_type,
+    checksum,
+    filesize_bytes,
+    collection_id,
+    added_by,
+    last_updated_by
+)
+select
+    s.name,
+    $1,
+    s.content,
+    s.text_content,
+    $2,
+    s.entry_type,
+    s.checksum,
+    s.filesize_bytes,
+    $3,
+    $4,
+    $4
+from entries s
+where s.id = $5
+returning id, origin, name, content, file_id, version, entry_type, checksum, parent_id, collection_id, added_by, last_updated_by, meta, created_at, updated_at, deleted_at, archived_at, filesize_bytes, public_id, text_content
+`
+
+type CopyEntryParams struct {
+	Meta         []byte      `json:"meta"`
+	FileID       pgtype.Text `json:"file_id"`
+	CollectionID int32       `json:"collection_id"`
+	UserID       int32       `json:"user_id"`
+	SourceID     int32       `json:"source_id"`
+}
+
+// Copy a version of an entry into a collection as the first version of a new entry
+func (q *Queries) CopyEntry(ctx context.Context, arg CopyEntryParams) (Entry, error) {
+	row := q.db.QueryRow(ctx, copyEntry,
+		arg.Meta,
+		arg.FileID,
+		arg.CollectionID,
+		arg.UserID,
+		arg.SourceID,
+	)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.Origin,
+		&i.Name,
+		&i.Content,
+		&i.FileID,
+		&i.Version,
+		&i.EntryType,
+		&i.Checksum,
+		&i.ParentID,
+		&i.CollectionID,
+		&i.AddedBy,
+		&i.LastUpdatedBy,
+		&i.Meta,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.FilesizeBytes,
+		&i.PublicID,
+		&i.TextContent,
+	)
+	return i, err
+}
+
+const countSearchFacets = `-- name: CountSearchFacets :many
+with recursive
+    matched as (
+        select e.id, e.origin, e.parent_id, e.entry_type, false as via_comment
+        from entries e
+        where
+            e.deleted_at is null
+            and (
+                e.public_id = any($19::uuid[])
+                or e.id in (
+                    select ck.entry_id
+                    from entry_chunks ck
+                    join entries ce on ce.id = ck.entry_id
+                    join collections c on c.id = ce.collection_id
+                    join workspaces w on w.id = c.workspace_id
+                    where
+                        ck.text_vector @@ websearch_to_tsquery(ts_regconfig(ck.language), $1)
+                        and (
+                            $3::uuid is null
+                            or w.public_id = $3
+                        )
+                        and ($4::text is null or w.slug = $4)
+                        and (
+                            $16::text[] is null
+                            or not coalesce(ck.content, '')
+                            ilike any($16::text[])
+                        )
+                )
+            )
+        union
+        -- comments are followed up to the entry of their thread
+        select p.id, p.origin, p.parent_id, p.entry_type, true as via_comment
+        from matched m
+        join entries p on p.id = m.parent_id
+        where m.entry_type = 'comment'
+    )
+select
+    e.entry_type as type,
+    c.public_id as collection_id,
+    c.name as collection_name,
+    c.slug as collection_slug,
+    count(distinct e.origin) as entries
+from entries e
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries_queue q on q.entry_id = e.id
+join workspace_members wm on wm.workspace_id = w.id
+join collection_members cm on cm.collection_id = c.id
+where
+    e.entry_type != 'comment'
+    and (
+        -- queries with only operators match every entry that passes the filters
+        $1::text = ''
+        or e.id in (
+            select m.id from matched m where not m.via_comment and m.entry_type != 'comment'
+        )
+        -- comments count towards the latest version of the entry they were made on
+        or e.origin in (
+            select m.origin from matched m where m.via_comment and m.entry_type != 'comment'
+        )
+    )
+    and q.status = any(
+        coalesce($2::entry_status[], '{completed}'::entry_status[])
+    )
+    and e.version = (
+        select max(v.version)
+        from entries v
+        join entries_queue vq on vq.entry_id = v.id
+        where
+            v.origin = e.origin
+            and v.deleted_at is null
+            and vq.status = any(
+                coalesce($2::entry_status[], '{completed}'::entry_status[])
+            )
+    )
+    and ($3::uuid is null or w.public_id = $3)
+    and ($4::text is null or w.slug = $4)
+    and wm.user_id = $5
+    and cm.user_id = $5
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+    and (
+        $6::text[] is null
+        or e.entry_type = any($6::text[])
+    )
+    and (
+        (
+            $7::uuid[] is null
+            and $8::text[] is null
+        )
+        or c.public_id = any($7::uuid[])
+        or c.slug = any($8::text[])
+    )
+    and (
+        $9::text[] is null
+        or e.added_by in (
+            select u.id from users u where u.username = any($9::text[])
+        )
+    )
+    and (
+        $10::text[] is null
+        or e.id in (
+            select et.entry_id
+            from entry_tags et
+            join tags t on t.id = et.tag_id
+            where lower(t.name) = any($10::text[])
+            group by et.entry_id
+            having count(distinct lower(t.name))
+            = cardinality($10::text[])
+        )
+    )
+    and (
+        $11::timestamptz is null
+        or e.created_at >= $11::timestamptz
+    )
+    and (
+        $12::timestamptz is null
+        or e.created_at < $12::timestamptz
+    )
+    and (
+        $13::timestamptz is null
+        or e.updated_at >= $13::timestamptz
+    )
+    and (
+        $14::timestamptz is null
+        or e.updated_at < $14::timestamptz
+    )
+    and (
+        $15::text[] is null
+        or e.name ilike all($15::text[])
+    )
+    and (
+        $16::text[] is null
+        or not e.name ilike any($16::text[])
+    )
+    -- archived entries are only counted if they were asked for
+    and (
+        case
+            when $17::boolean then e.archived_at is not null
+            when $18::boolean then true
+            else e.archived_at is null
+        end
+    )
+group by e.entry_type, c.id
+`
+
+type CountSearchFacetsParams struct {
+	Query             string             `json:"query"`
+	Statuses          []EntryStatus      `json:"statuses"`
+	WorkspacePublicID pgtype.UUID        `json:"workspace_public_id"`
+	WorkspaceSlug     pgtype.Text        `json:"workspace_slug"`
+	UserID            int32              `json:"user_id"`
+	EntryTypes        []string           `json:"entry_types"`
+	CollectionIds     []pgtype.UUID      `json:"collection_ids"`
+	CollectionSlugs   []string           `json:"collection_slugs"`
+	AddedBy           []string           `json:"added_by"`
+	Tags              []string           `json:"tags"`
+	CreatedAfter      pgtype.Timestamptz `json:"created_after"`
+	CreatedBefore     pgtype.Timestamptz `json:"created_before"`
+	UpdatedAfter      pgtype.Timestamptz `json:"updated_after"`
+	UpdatedBefore     pgtype.Timestamptz `json:"updated_before"`
+	TitlePatterns     []string           `json:"title_patterns"`
+	ExcludedPatterns  []string           `json:"excluded_patterns"`
+	ArchivedOnly      bool               `json:"archived_only"`
+	IncludeArchived   bool               `json:"include_archived"`
+	CandidateIds      []pgtype.UUID      `json:"candidate_ids"`
+}
+
+type CountSearchFacetsRow struct {
+	Type           document.EntryType `json:"type"`
+	CollectionID   pgtype.UUID        `json:"collection_id"`
+	CollectionName string             `json:"collection_name"`
+	CollectionSlug pgtype.Text        `json:"collection_slug"`
+	Entries        int64              `json:"entries"`
+}
+
+// Count the entries matched by a search per type and collection, an entry matched through its comments is counted as itself
+func (q *Queries) CountSearchFacets(ctx context.Context, arg CountSearchFacetsParams) ([]CountSearchFacetsRow, error) {
+	rows, err := q.db.Query(ctx, countSearchFacets,
+		arg.Query,
+		arg.Statuses,
+		arg.WorkspacePublicID,
+		arg.WorkspaceSlug,
+		arg.UserID,
+		arg.EntryTypes,
+		arg.CollectionIds,
+		arg.CollectionSlugs,
+		arg.AddedBy,
+		arg.Tags,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.TitlePatterns,
+		arg.ExcludedPatterns,
+		arg.ArchivedOnly,
+		arg.IncludeArchived,
+		arg.CandidateIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountSearchFacetsRow{}
+	for rows.Next() {
+		var i CountSearchFacetsRow
+		if err := rows.Scan(
+			&i.Type,
+			&i.CollectionID,
+			&i.CollectionName,
+			&i.CollectionSlug,
+			&i.Entries,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFileEntry = `-- name: CreateFileEntry :one
 insert into entries (name, meta, content, file_id, entry_type, checksum, collection_id, added_by, last_updated_by, filesize_bytes) values ($1, $2, $3, $4, $5, $6, $7, $8, $8, $9) returning id, origin, name, content, file_id, version, entry_type, checksum, parent_id, collection_id, added_by, last_updated_by, meta, created_at, updated_at, deleted_at, archived_at, filesize_bytes, public_id, text_content
 `
@@ -140,13 +490,43 @@ func (q *Queries) CreateLinkEntry(ctx context.Context, arg CreateLinkEntryParams
 }
 
 const deleteEntries = `-- name: DeleteEntries :many
-delete from entries
-where public_id = any($1::uuid[]) and deleted_at is null
-returning public_id
+with
+    targets as (
+        select e.public_id, e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any($1::uuid[])
+            and w.slug = $2
+            and e.deleted_at is null
+    ),
+    deleted as (
+        update entries e
+        set deleted_at = now()
+        from targets t
+        where e.origin = t.origin and e.deleted_at is null
+        returning e.id
+    ),
+    deleted_chunks as (
+        -- the chunks are marked with the same timestamp as their entries so that only those are brought back on restore
+        update entry_chunks ec
+        set deleted_at = now()
+        from deleted d
+        where ec.entry_id = d.id and ec.deleted_at is null
+    )
+select t.public_id
+from targets t
 `
 
-func (q *Queries) DeleteEntries(ctx context.Context, entryPublicIds []pgtype.UUID) ([]pgtype.UUID, error) {
-	rows, err := q.db.Query(ctx, deleteEntries, entryPublicIds)
+type DeleteEntriesParams struct {
+	EntryPublicIds []pgtype.UUID `json:"entry_public_ids"`
+	WorkspaceSlug  pgtype.Text   `json:"workspace_slug"`
+}
+
+// Move entries along with all their versions and chunks to the trash, only the public IDs of the given versions are returned
+func (q *Queries) DeleteEntries(ctx context.Context, arg DeleteEntriesParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, deleteEntries, arg.EntryPublicIds, arg.WorkspaceSlug)
 	if err != nil {
 		return nil, err
 	}
@@ -208,6 +588,24 @@ func (q *Queries) DeleteEntryChunksByPublicId(ctx context.Context, arg DeleteEnt
 	return items, nil
 }
 
+const deleteFailedFileDeletions = `-- name: DeleteFailedFileDeletions :exec
+delete from failed_file_deletions where file_id = any($1::text[])
+`
+
+func (q *Queries) DeleteFailedFileDeletions(ctx context.Context, fileIds []string) error {
+	_, err := q.db.Exec(ctx, deleteFailedFileDeletions, fileIds)
+	return err
+}
+
+const deleteRelatedEntries = `-- name: DeleteRelatedEntries :exec
+delete from related_entries where entry_id = $1
+`
+
+func (q *Queries) DeleteRelatedEntries(ctx context.Context, entryID int32) error {
+	_, err := q.db.Exec(ctx, deleteRelatedEntries, entryID)
+	return err
+}
+
 const dequeueEntries = `-- name: DequeueEntries :many
 delete from entries_queue eq
 using entries e
@@ -246,6 +644,8 @@ from entries_queue q
 join entries e on e.id = q.entry_id
 where
     q.attempts <= q.max_attempts
+    -- archived entries are processed once they are unarchived
+    and e.archived_at is null
     and (
         q.status in ('queued', 'failed')  -- either it hasn't been processed or it failed
         or (q.status = 'processing' and q.updated_at < now() - interval '12 hours')  -- or it has been marked as processing but hasn't been updated in the last 12 hours
@@ -274,95 +674,227 @@ func (q *Queries) FindAllQueuedEntries(ctx context.Context) ([]int32, error) {
 	return items, nil
 }
 
-const findEntries = `-- name: FindEntries :many
-with
-    latest_entries as (
-        select
-            e.id, e.origin, e.name, e.content, e.file_id, e.version, e.entry_type, e.checksum, e.parent_id, e.collection_id, e.added_by, e.last_updated_by, e.meta, e.created_at, e.updated_at, e.deleted_at, e.archived_at, e.filesize_bytes, e.public_id, e.text_content,
-            row_number() over (
-                partition by coalesce(e.parent_id, e.id) order by e.version desc
-            ) as rn
-        from entries e
-        join collections c on c.id = e.collection_id
-        join workspaces w on w.id = c.workspace_id
-        join collection_members cm on cm.collection_id = c.id
-        join workspace_members wm on wm.workspace_id = w.id
-        where
-            cm.user_id = $3
-            and wm.user_id = $3
-            and e.deleted_at is null
-            and e.archived_at is null
-            and (
-                $4::text is null
-                or w.slug = $4::text
-            )
-            and (
-                $5::uuid is null
-                or w.public_id = $5::uuid
-            )
-            and (
-                $6::text is null
-                or c.slug = $6::text
-            )
-            and (
-                $7::uuid is null
-                or c.public_id = $7::uuid
-            )
-            and c.deleted_at is null
-            and w.deleted_at is null
-            and e.deleted_at is null
-            and e.archived_at is null
-        order by e.collection_id, e.version desc
-    )
+const findCachedRelatedEntries = `-- name: FindCachedRelatedEntries :many
 select
-    e.id,
     e.public_id,
-    e.parent_id,
-    e.origin,
-    e.content,
-    e.text_content,
     e.name,
-    e.meta,
-    e.version,
     e.entry_type as type,
     e.file_id,
     e.filesize_bytes,
-    e.added_by,
-    e.last_updated_by,
     e.created_at,
     e.updated_at,
-    e.archived_at,
     c.name as collection_name,
     c.public_id as collection_id,
     c.slug as collection_slug,
-    u.first_name as added_by_first_name,
-    u.last_name as added_by_last_name,
-    u.username as added_by_username,
-    u.avatar_id as added_by_avatar_id,
-    w.public_id as workspace_id,
     w.display_name as workspace_name,
+    w.public_id as workspace_id,
     w.slug as workspace_slug,
-    q.status,
-    q.created_at as queued_at,
-    count(*) over () as total_entries
-from latest_entries e
+    r.similarity
+from related_entries r
+join entries source on source.id = r.entry_id
+join entries related on related.id = r.related_entry_id
+join entries e on e.origin = related.origin
 join collections c on c.id = e.collection_id
 join workspaces w on w.id = c.workspace_id
 join entries_queue q on q.entry_id = e.id
-join users u on u.id = e.added_by
-order by coalesce(e.updated_at, e.created_at) desc, q.updated_at desc
-limit $1
-offset $2
+join workspace_members wm on wm.workspace_id = w.id
+join collection_members cm on cm.collection_id = c.id
+where
+    source.public_id = $1
+    and q.status = 'completed'
+    and e.version = (
+        select max(v.version)
+        from entries v
+        join entries_queue vq on vq.entry_id = v.id
+        where
+            v.origin = e.origin
+            and v.deleted_at is null
+            and vq.status = 'completed'
+    )
+    and e.archived_at is null
+    and wm.user_id = $2
+    and cm.user_id = $2
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+order by r.similarity desc
+limit $3::int
 `
 
-type FindEntriesParams struct {
-	Limit              int32       `json:"limit"`
-	Offset             int32       `json:"offset"`
-	UserID             int32       `json:"user_id"`
-	WorkspaceSlug      pgtype.Text `json:"workspace_slug"`
-	WorkspacePublicID  pgtype.UUID `json:"workspace_public_id"`
-	CollectionSlug     pgtype.Text `json:"collection_slug"`
-	CollectionPublicID pgtype.UUID `json:"collection_public_id"`
+type FindCachedRelatedEntriesParams struct {
+	EntryPublicID pgtype.UUID `json:"entry_public_id"`
+	UserID        int32       `json:"user_id"`
+	ResultLimit   int32       `json:"result_limit"`
+}
+
+type FindCachedRelatedEntriesRow struct {
+	PublicID       pgtype.UUID        `json:"public_id"`
+	Name           string             `json:"name"`
+	Type           document.EntryType `json:"type"`
+	FileID         pgtype.Text        `json:"file_id"`
+	FilesizeBytes  int64              `json:"filesize_bytes"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	CollectionName string             `json:"collection_name"`
+	CollectionID   pgtype.UUID        `json:"collection_id"`
+	CollectionSlug pgtype.Text        `json:"collection_slug"`
+	WorkspaceName  string             `json:"workspace_name"`
+	WorkspaceID    pgtype.UUID        `json:"workspace_id"`
+	WorkspaceSlug  pgtype.Text        `json:"workspace_slug"`
+	Similarity     float64            `json:"similarity"`
+}
+
+// Find the cached related entries of an entry, the cache stores the version that was related so the latest processed version of its entry is returned instead
+func (q *Queries) FindCachedRelatedEntries(ctx context.Context, arg FindCachedRelatedEntriesParams) ([]FindCachedRelatedEntriesRow, error) {
+	rows, err := q.db.Query(ctx, findCachedRelatedEntries, arg.EntryPublicID, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindCachedRelatedEntriesRow{}
+	for rows.Next() {
+		var i FindCachedRelatedEntriesRow
+		if err := rows.Scan(
+			&i.PublicID,
+			&i.Name,
+			&i.Type,
+			&i.FileID,
+			&i.FilesizeBytes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CollectionName,
+			&i.CollectionID,
+			&i.CollectionSlug,
+			&i.WorkspaceName,
+			&i.WorkspaceID,
+			&i.WorkspaceSlug,
+			&i.Similarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findEntries = `-- name: FindEntries :many
+with
+    latest_entries as (
+        select
+            e.id, e.origin, e.name, e.content, e.file_id, e.version, e.entry_type, e.checksum, e.parent_id, e.collection_id, e.added_by, e.last_updated_by, e.meta, e.created_at, e.updated_at, e.deleted_at, e.archived_at, e.filesize_bytes, e.public_id, e.text_content,
+            row_number() over (
+                partition by coalesce(e.parent_id, e.id) order by e.version desc
+            ) as rn
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join collection_members cm on cm.collection_id = c.id
+        join workspace_members wm on wm.workspace_id = w.id
+        where
+            cm.user_id = $4
+            and wm.user_id = $4
+            and e.deleted_at is null
+            -- comments share the parent of the versions of the entry they were made on
+            and e.entry_type != 'comment'
+            and (
+                $5::text is null
+                or w.slug = $5::text
+            )
+            and (
+                $6::uuid is null
+                or w.public_id = $6::uuid
+            )
+            and (
+                $7::text is null
+                or c.slug = $7::text
+            )
+            and (
+                $8::uuid is null
+                or c.public_id = $8::uuid
+            )
+            and c.deleted_at is null
+            and w.deleted_at is null
+            and e.deleted_at is null
+            -- archived entries are only returned if they were asked for
+            and (
+                case
+                    when $9::boolean then e.archived_at is not null
+                    when $10::boolean then true
+                    else e.archived_at is null
+                end
+            )
+        order by e.collection_id, e.version desc
+    )
+select
+    e.id,
+    e.public_id,
+    e.parent_id,
+    e.origin,
+    e.content,
+    e.text_content,
+    e.name,
+    e.meta,
+    e.version,
+    e.entry_type as type,
+    e.file_id,
+    e.filesize_bytes,
+    e.added_by,
+    e.last_updated_by,
+    e.created_at,
+    e.updated_at,
+    e.archived_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    u.first_name as added_by_first_name,
+    u.last_name as added_by_last_name,
+    u.username as added_by_username,
+    u.avatar_id as added_by_avatar_id,
+    w.public_id as workspace_id,
+    w.display_name as workspace_name,
+    w.slug as workspace_slug,
+    q.status,
+    q.created_at as queued_at,
+    count(*) over () as total_entries
+from latest_entries e
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries_queue q on q.entry_id = e.id
+join users u on u.id = e.added_by
+where
+    e.rn = 1
+    -- the tags are matched on the latest version, an older version may still have a tag that was removed since
+    and (
+        $3::text[] is null
+        or e.id in (
+            select et.entry_id
+            from entry_tags et
+            join tags t on t.id = et.tag_id
+            where lower(t.name) = any($3::text[])
+            group by et.entry_id
+            having count(distinct lower(t.name))
+            = cardinality($3::text[])
+        )
+    )
+order by coalesce(e.updated_at, e.created_at) desc, e.public_id desc
+limit $1
+offset $2
+`
+
+type FindEntriesParams struct {
+	Limit              int32       `json:"limit"`
+	Offset             int32       `json:"offset"`
+	Tags               []string    `json:"tags"`
+	UserID             int32       `json:"user_id"`
+	WorkspaceSlug      pgtype.Text `json:"workspace_slug"`
+	WorkspacePublicID  pgtype.UUID `json:"workspace_public_id"`
+	CollectionSlug     pgtype.Text `json:"collection_slug"`
+	CollectionPublicID pgtype.UUID `json:"collection_public_id"`
+	ArchivedOnly       bool        `json:"archived_only"`
+	IncludeArchived    bool        `json:"include_archived"`
 }
 
 type FindEntriesRow struct {
@@ -402,11 +934,14 @@ func (q *Queries) FindEntries(ctx context.Context, arg FindEntriesParams) ([]Fin
 	rows, err := q.db.Query(ctx, findEntries,
 		arg.Limit,
 		arg.Offset,
+		arg.Tags,
 		arg.UserID,
 		arg.WorkspaceSlug,
 		arg.WorkspacePublicID,
 		arg.CollectionSlug,
 		arg.CollectionPublicID,
+		arg.ArchivedOnly,
+		arg.IncludeArchived,
 	)
 	if err != nil {
 		return nil, err
@@ -457,7 +992,54 @@ func (q *Queries) FindEntries(ctx context.Context, arg FindEntriesParams) ([]Fin
 	return items, nil
 }
 
-const findEntryById = `-- name: FindEntryById :one
+const findEntriesAfterCursor = `-- name: FindEntriesAfterCursor :many
+with
+    latest_entries as (
+        select
+            e.id, e.origin, e.name, e.content, e.file_id, e.version, e.entry_type, e.checksum, e.parent_id, e.collection_id, e.added_by, e.last_updated_by, e.meta, e.created_at, e.updated_at, e.deleted_at, e.archived_at, e.filesize_bytes, e.public_id, e.text_content,
+            row_number() over (
+                partition by coalesce(e.parent_id, e.id) order by e.version desc
+            ) as rn
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join collection_members cm on cm.collection_id = c.id
+        join workspace_members wm on wm.workspace_id = w.id
+        where
+            cm.user_id = $5
+            and wm.user_id = $5
+            and e.deleted_at is null
+            -- comments share the parent of the versions of the entry they were made on
+            and e.entry_type != 'comment'
+            and (
+                $6::text is null
+                or w.slug = $6::text
+            )
+            and (
+                $7::uuid is null
+                or w.public_id = $7::uuid
+            )
+            and (
+                $8::text is null
+                or c.slug = $8::text
+            )
+            and (
+                $9::uuid is null
+                or c.public_id = $9::uuid
+            )
+            and c.deleted_at is null
+            and w.deleted_at is null
+            and e.deleted_at is null
+            -- archived entries are only returned if they were asked for
+            and (
+                case
+                    when $10::boolean then e.archived_at is not null
+                    when $11::boolean then true
+                    else e.archived_at is null
+                end
+            )
+        order by e.collection_id, e.version desc
+    )
 select
     e.id,
     e.public_id,
@@ -487,43 +1069,53 @@ select
     w.display_name as workspace_name,
     w.slug as workspace_slug,
     q.status,
-    q.created_at as queued_at
-from entries e
+    q.created_at as queued_at,
+    -- keyset pages are not counted, the column only keeps the row in the same shape as FindEntries
+    0::bigint as total_entries
+from latest_entries e
 join collections c on c.id = e.collection_id
 join workspaces w on w.id = c.workspace_id
 join entries_queue q on q.entry_id = e.id
 join users u on u.id = e.added_by
 where
-    (
-        ($1::uuid is null and e.id = $2)
-        or ($2::integer is null and e.public_id = $1)
-    )
-    and ($3::text is null or w.slug = $3)
+    e.rn = 1
+    -- the tags are matched on the latest version, an older version may still have a tag that was removed since
     and (
-        $4::uuid is null
-        or w.public_id = $4
+        $2::text[] is null
+        or e.id in (
+            select et.entry_id
+            from entry_tags et
+            join tags t on t.id = et.tag_id
+            where lower(t.name) = any($2::text[])
+            group by et.entry_id
+            having count(distinct lower(t.name))
+            = cardinality($2::text[])
+        )
     )
-    and ($5::text is null or c.slug = $5)
     and (
-        $6::uuid is null
-        or c.public_id = $6
+        $3::timestamptz is null
+        or (coalesce(e.updated_at, e.created_at), e.public_id)
+        < ($3::timestamptz, $4::uuid)
     )
-    and e.deleted_at is null
-    and c.deleted_at is null
-    and w.deleted_at is null
-limit 1
+order by coalesce(e.updated_at, e.created_at) desc, e.public_id desc
+limit $1
 `
 
-type FindEntryByIdParams struct {
-	EntryPublicID      pgtype.UUID `json:"entry_public_id"`
-	EntryID            pgtype.Int4 `json:"entry_id"`
-	WorkspaceSlug      pgtype.Text `json:"workspace_slug"`
-	WorkspacePublicID  pgtype.UUID `json:"workspace_public_id"`
-	CollectionSlug     pgtype.Text `json:"collection_slug"`
-	CollectionPublicID pgtype.UUID `json:"collection_public_id"`
+type FindEntriesAfterCursorParams struct {
+	Limit              int32              `json:"limit"`
+	Tags               []string           `json:"tags"`
+	CursorSortKey      pgtype.Timestamptz `json:"cursor_sort_key"`
+	CursorID           pgtype.UUID        `json:"cursor_id"`
+	UserID             int32              `json:"user_id"`
+	WorkspaceSlug      pgtype.Text        `json:"workspace_slug"`
+	WorkspacePublicID  pgtype.UUID        `json:"workspace_public_id"`
+	CollectionSlug     pgtype.Text        `json:"collection_slug"`
+	CollectionPublicID pgtype.UUID        `json:"collection_public_id"`
+	ArchivedOnly       bool               `json:"archived_only"`
+	IncludeArchived    bool               `json:"include_archived"`
 }
 
-type FindEntryByIdRow struct {
+type FindEntriesAfterCursorRow struct {
 	ID               int32              `json:"id"`
 	PublicID         pgtype.UUID        `json:"public_id"`
 	ParentID         pgtype.Int4        `json:"parent_id"`
@@ -533,7 +1125,7 @@ type FindEntryByIdRow struct {
 	Name             string             `json:"name"`
 	Meta             []byte             `json:"meta"`
 	Version          int32              `json:"version"`
-	Type             document.EntryType `json:"type"`
+	Type             string             `json:"type"`
 	FileID           pgtype.Text        `json:"file_id"`
 	FilesizeBytes    int64              `json:"filesize_bytes"`
 	AddedBy          int32              `json:"added_by"`
@@ -553,77 +1145,251 @@ type FindEntryByIdRow struct {
 	WorkspaceSlug    pgtype.Text        `json:"workspace_slug"`
 	Status           EntryStatus        `json:"status"`
 	QueuedAt         pgtype.Timestamp   `json:"queued_at"`
+	TotalEntries     int64              `json:"total_entries"`
 }
 
-func (q *Queries) FindEntryById(ctx context.Context, arg FindEntryByIdParams) (FindEntryByIdRow, error) {
-	row := q.db.QueryRow(ctx, findEntryById,
-		arg.EntryPublicID,
-		arg.EntryID,
+// Keyset variant of FindEntries without the total count, the cursor is the sort key and
+// public ID of the last entry on the previous page.
+func (q *Queries) FindEntriesAfterCursor(ctx context.Context, arg FindEntriesAfterCursorParams) ([]FindEntriesAfterCursorRow, error) {
+	rows, err := q.db.Query(ctx, findEntriesAfterCursor,
+		arg.Limit,
+		arg.Tags,
+		arg.CursorSortKey,
+		arg.CursorID,
+		arg.UserID,
 		arg.WorkspaceSlug,
 		arg.WorkspacePublicID,
 		arg.CollectionSlug,
 		arg.CollectionPublicID,
+		arg.ArchivedOnly,
+		arg.IncludeArchived,
 	)
-	var i FindEntryByIdRow
-	err := row.Scan(
-		&i.ID,
-		&i.PublicID,
-		&i.ParentID,
-		&i.Origin,
-		&i.Content,
-		&i.TextContent,
-		&i.Name,
-		&i.Meta,
-		&i.Version,
-		&i.Type,
-		&i.FileID,
-		&i.FilesizeBytes,
-		&i.AddedBy,
-		&i.LastUpdatedBy,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.ArchivedAt,
-		&i.CollectionName,
-		&i.CollectionID,
-		&i.CollectionSlug,
-		&i.AddedByFirstName,
-		&i.AddedByLastName,
-		&i.AddedByUsername,
-		&i.AddedByAvatarID,
-		&i.WorkspaceID,
-		&i.WorkspaceName,
-		&i.WorkspaceSlug,
-		&i.Status,
-		&i.QueuedAt,
-	)
-	return i, err
-}
-
-const findEntryChunks = `-- name: FindEntryChunks :many
-select ec.id, ec.content, ec.embedding_status
-from entry_chunks ec
-join entries e on e.id = ec.entry_id
-where
-    e.public_id = $1
-    and (
-        ec.embedding_status = 'pending'
-        -- we will only retry failed chunks that have failed less than 5 times
-        or (ec.embedding_status = 'failed' and ec.embedding_error_count < 5)
-        or (
-            -- this is a special case where the chunk was marked as processing but
-            -- hasn't
-            -- been updated in the last 2 hours
-            ec.embedding_status = 'processing'
-            and ec.embedding_status_updated_at < now() - interval '2 hours'
-        )
-    )
-    and ec.semantic_vector is null
-    and ec.content is not null
-    and ec.content != ''
-    and e.deleted_at is null
-`
-
-type FindEntryChunksRow struct {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindEntriesAfterCursorRow{}
+	for rows.Next() {
+		var i FindEntriesAfterCursorRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PublicID,
+			&i.ParentID,
+			&i.Origin,
+			&i.Content,
+			&i.TextContent,
+			&i.Name,
+			&i.Meta,
+			&i.Version,
+			&i.Type,
+			&i.FileID,
+			&i.FilesizeBytes,
+			&i.AddedBy,
+			&i.LastUpdatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.CollectionName,
+			&i.CollectionID,
+			&i.CollectionSlug,
+			&i.AddedByFirstName,
+			&i.AddedByLastName,
+			&i.AddedByUsername,
+			&i.AddedByAvatarID,
+			&i.WorkspaceID,
+			&i.WorkspaceName,
+			&i.WorkspaceSlug,
+			&i.Status,
+			&i.QueuedAt,
+			&i.TotalEntries,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findEntryById = `-- name: FindEntryById :one
+select
+    e.id,
+    e.public_id,
+    e.parent_id,
+    e.origin,
+    e.content,
+    e.text_content,
+    e.name,
+    e.meta,
+    e.version,
+    e.entry_type as type,
+    e.file_id,
+    e.filesize_bytes,
+    e.added_by,
+    e.last_updated_by,
+    e.created_at,
+    e.updated_at,
+    e.archived_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    u.first_name as added_by_first_name,
+    u.last_name as added_by_last_name,
+    u.username as added_by_username,
+    u.avatar_id as added_by_avatar_id,
+    w.public_id as workspace_id,
+    w.display_name as workspace_name,
+    w.slug as workspace_slug,
+    q.status,
+    q.created_at as queued_at,
+    es.summary_short,
+    es.summary_long,
+    es.entry_version as summary_version
+from entries e
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries_queue q on q.entry_id = e.id
+join users u on u.id = e.added_by
+left join entry_summaries es on es.entry_id = e.id
+where
+    (
+        ($1::uuid is null and e.id = $2)
+        or ($2::integer is null and e.public_id = $1)
+    )
+    and ($3::text is null or w.slug = $3)
+    and (
+        $4::uuid is null
+        or w.public_id = $4
+    )
+    and ($5::text is null or c.slug = $5)
+    and (
+        $6::uuid is null
+        or c.public_id = $6
+    )
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+limit 1
+`
+
+type FindEntryByIdParams struct {
+	EntryPublicID      pgtype.UUID `json:"entry_public_id"`
+	EntryID            pgtype.Int4 `json:"entry_id"`
+	WorkspaceSlug      pgtype.Text `json:"workspace_slug"`
+	WorkspacePublicID  pgtype.UUID `json:"workspace_public_id"`
+	CollectionSlug     pgtype.Text `json:"collection_slug"`
+	CollectionPublicID pgtype.UUID `json:"collection_public_id"`
+}
+
+type FindEntryByIdRow struct {
+	ID               int32              `json:"id"`
+	PublicID         pgtype.UUID        `json:"public_id"`
+	ParentID         pgtype.Int4        `json:"parent_id"`
+	Origin           pgtype.UUID        `json:"origin"`
+	Content          pgtype.Text        `json:"content"`
+	TextContent      pgtype.Text        `json:"text_content"`
+	Name             string             `json:"name"`
+	Meta             []byte             `json:"meta"`
+	Version          int32              `json:"version"`
+	Type             document.EntryType `json:"type"`
+	FileID           pgtype.Text        `json:"file_id"`
+	FilesizeBytes    int64              `json:"filesize_bytes"`
+	AddedBy          int32              `json:"added_by"`
+	LastUpdatedBy    int32              `json:"last_updated_by"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	ArchivedAt       pgtype.Timestamptz `json:"archived_at"`
+	CollectionName   string             `json:"collection_name"`
+	CollectionID     pgtype.UUID        `json:"collection_id"`
+	CollectionSlug   pgtype.Text        `json:"collection_slug"`
+	AddedByFirstName string             `json:"added_by_first_name"`
+	AddedByLastName  string             `json:"added_by_last_name"`
+	AddedByUsername  string             `json:"added_by_username"`
+	AddedByAvatarID  pgtype.Text        `json:"added_by_avatar_id"`
+	WorkspaceID      pgtype.UUID        `json:"workspace_id"`
+	WorkspaceName    string             `json:"workspace_name"`
+	WorkspaceSlug    pgtype.Text        `json:"workspace_slug"`
+	Status           EntryStatus        `json:"status"`
+	QueuedAt         pgtype.Timestamp   `json:"queued_at"`
+	SummaryShort     pgtype.Text        `json:"summary_short"`
+	SummaryLong      pgtype.Text        `json:"summary_long"`
+	SummaryVersion   pgtype.Int4        `json:"summary_version"`
+}
+
+func (q *Queries) FindEntryById(ctx context.Context, arg FindEntryByIdParams) (FindEntryByIdRow, error) {
+	row := q.db.QueryRow(ctx, findEntryById,
+		arg.EntryPublicID,
+		arg.EntryID,
+		arg.WorkspaceSlug,
+		arg.WorkspacePublicID,
+		arg.CollectionSlug,
+		arg.CollectionPublicID,
+	)
+	var i FindEntryByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.PublicID,
+		&i.ParentID,
+		&i.Origin,
+		&i.Content,
+		&i.TextContent,
+		&i.Name,
+		&i.Meta,
+		&i.Version,
+		&i.Type,
+		&i.FileID,
+		&i.FilesizeBytes,
+		&i.AddedBy,
+		&i.LastUpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.CollectionName,
+		&i.CollectionID,
+		&i.CollectionSlug,
+		&i.AddedByFirstName,
+		&i.AddedByLastName,
+		&i.AddedByUsername,
+		&i.AddedByAvatarID,
+		&i.WorkspaceID,
+		&i.WorkspaceName,
+		&i.WorkspaceSlug,
+		&i.Status,
+		&i.QueuedAt,
+		&i.SummaryShort,
+		&i.SummaryLong,
+		&i.SummaryVersion,
+	)
+	return i, err
+}
+
+const findEntryChunks = `-- name: FindEntryChunks :many
+select ec.id, ec.content, ec.embedding_status
+from entry_chunks ec
+join entries e on e.id = ec.entry_id
+where
+    e.public_id = $1
+    and (
+        ec.embedding_status = 'pending'
+        -- we will only retry failed chunks that have failed less than 5 times
+        or (ec.embedding_status = 'failed' and ec.embedding_error_count < 5)
+        or (
+            -- this is a special case where the chunk was marked as processing but
+            -- hasn't
+            -- been updated in the last 2 hours
+            ec.embedding_status = 'processing'
+            and ec.embedding_status_updated_at < now() - interval '2 hours'
+        )
+    )
+    and ec.semantic_vector is null
+    and ec.content is not null
+    and ec.content != ''
+    and e.deleted_at is null
+`
+
+type FindEntryChunksRow struct {
 	ID              int32                     `json:"id"`
 	Content         pgtype.Text               `json:"content"`
 	EmbeddingStatus EntryChunkEmbeddingStatus `json:"embedding_status"`
@@ -649,6 +1415,55 @@ func (q *Queries) FindEntryChunks(ctx context.Context, entryPublicID pgtype.UUID
 	return items, nil
 }
 
+const findEntryEmbeddingDimensions = `-- name: FindEntryEmbeddingDimensions :one
+select ck.embedding_dimensions::int
+from entries e
+join entry_chunks ck on ck.entry_id = e.id
+where
+    (e.id = $1::int or e.public_id = $2::uuid)
+    and ck.semantic_vector is not null
+group by ck.embedding_model, ck.embedding_dimensions
+order by count(*) desc
+limit 1
+`
+
+type FindEntryEmbeddingDimensionsParams struct {
+	EntryID       pgtype.Int4 `json:"entry_id"`
+	EntryPublicID pgtype.UUID `json:"entry_public_id"`
+}
+
+// the dimensions of most of the entry's vectors, they can only differ while it is being re-embedded
+func (q *Queries) FindEntryEmbeddingDimensions(ctx context.Context, arg FindEntryEmbeddingDimensionsParams) (int32, error) {
+	row := q.db.QueryRow(ctx, findEntryEmbeddingDimensions, arg.EntryID, arg.EntryPublicID)
+	var ck_embedding_dimensions int32
+	err := row.Scan(&ck_embedding_dimensions)
+	return ck_embedding_dimensions, err
+}
+
+const findEntryEmbeddingProgress = `-- name: FindEntryEmbeddingProgress :one
+select ck.entry_id, (count(*) filter (where other.semantic_vector is null))::int as pending_chunks
+from entry_chunks ck
+join entry_chunks other on other.entry_id = ck.entry_id
+where
+    ck.id = $1
+    and other.deleted_at is null
+    and other.content is not null
+    and other.content != ''
+group by ck.entry_id
+`
+
+type FindEntryEmbeddingProgressRow struct {
+	EntryID       pgtype.Int4 `json:"entry_id"`
+	PendingChunks int32       `json:"pending_chunks"`
+}
+
+func (q *Queries) FindEntryEmbeddingProgress(ctx context.Context, chunkID int32) (FindEntryEmbeddingProgressRow, error) {
+	row := q.db.QueryRow(ctx, findEntryEmbeddingProgress, chunkID)
+	var i FindEntryEmbeddingProgressRow
+	err := row.Scan(&i.EntryID, &i.PendingChunks)
+	return i, err
+}
+
 const findEntryInCollectionAndWorkspace = `-- name: FindEntryInCollectionAndWorkspace :one
 select
     e.id, e.origin, e.name, e.content, e.file_id, e.version, e.entry_type, e.checksum, e.parent_id, e.collection_id, e.added_by, e.last_updated_by, e.meta, e.created_at, e.updated_at, e.deleted_at, e.archived_at, e.filesize_bytes, e.public_id, e.text_content,
@@ -732,42 +1547,27 @@ func (q *Queries) FindEntryInCollectionAndWorkspace(ctx context.Context, arg Fin
 	return i, err
 }
 
-const findUnindexedChunks = `-- name: FindUnindexedChunks :many
-select id, content
-from entry_chunks
-where
-    embedding_status = 'pending'
-    -- we will only retry failed chunks that have failed less than 5 times
-    or (embedding_status = 'failed' and embedding_error_count < 5)
-    or (
-        -- this is a special case where the chunk was marked as processing but hasn't
-        -- been updated in the last 2 hours
-        embedding_status = 'processing'
-        and embedding_status_updated_at < now() - interval '2 hours'
-    )
-    and semantic_vector is null
-    and content is not null
-    and content != ''
+const findFailedFileDeletions = `-- name: FindFailedFileDeletions :many
+select file_id
+from failed_file_deletions
+order by updated_at asc
+limit $1::int
 `
 
-type FindUnindexedChunksRow struct {
-	ID      int32       `json:"id"`
-	Content pgtype.Text `json:"content"`
-}
-
-func (q *Queries) FindUnindexedChunks(ctx context.Context) ([]FindUnindexedChunksRow, error) {
-	rows, err := q.db.Query(ctx, findUnindexedChunks)
+// the files that were attempted the longest time ago come first, so that a file that keeps failing does not hold the others back
+func (q *Queries) FindFailedFileDeletions(ctx context.Context, maxFiles int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, findFailedFileDeletions, maxFiles)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []FindUnindexedChunksRow{}
+	items := []string{}
 	for rows.Next() {
-		var i FindUnindexedChunksRow
-		if err := rows.Scan(&i.ID, &i.Content); err != nil {
+		var file_id string
+		if err := rows.Scan(&file_id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, file_id)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -775,17 +1575,288 @@ func (q *Queries) FindUnindexedChunks(ctx context.Context) ([]FindUnindexedChunk
 	return items, nil
 }
 
-const getEntriesOwnership = `-- name: GetEntriesOwnership :many
-select
-    e.public_id,
-    e.added_by,
-    (e.added_by = cm.user_id) as is_owner,
-    cm.bitmask_role as user_role
+const findLatestEntryVersions = `-- name: FindLatestEntryVersions :many
+select distinct on (l.origin) l.id, l.origin, l.name, l.content, l.file_id, l.version, l.entry_type, l.checksum, l.parent_id, l.collection_id, l.added_by, l.last_updated_by, l.meta, l.created_at, l.updated_at, l.deleted_at, l.archived_at, l.filesize_bytes, l.public_id, l.text_content, q.status
 from entries e
-join collection_members cm on cm.collection_id = e.collection_id
-where cm.user_id = $1 and e.public_id = any($2::uuid[])
-`
-
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries l on l.origin = e.origin and l.deleted_at is null
+join entries_queue q on q.entry_id = l.id
+where
+    e.public_id = any($1::uuid[])
+    and w.slug = $2
+    and e.deleted_at is null
+    and e.entry_type != 'comment'
+order by l.origin, l.version desc
+`
+
+type FindLatestEntryVersionsParams struct {
+	EntryPublicIds []pgtype.UUID `json:"entry_public_ids"`
+	WorkspaceSlug  pgtype.Text   `json:"workspace_slug"`
+}
+
+type FindLatestEntryVersionsRow struct {
+	Entry  Entry       `json:"entry"`
+	Status EntryStatus `json:"status"`
+}
+
+// Find the latest version of each of the given entries in a workspace along with its processing status
+func (q *Queries) FindLatestEntryVersions(ctx context.Context, arg FindLatestEntryVersionsParams) ([]FindLatestEntryVersionsRow, error) {
+	rows, err := q.db.Query(ctx, findLatestEntryVersions, arg.EntryPublicIds, arg.WorkspaceSlug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindLatestEntryVersionsRow{}
+	for rows.Next() {
+		var i FindLatestEntryVersionsRow
+		if err := rows.Scan(
+			&i.Entry.ID,
+			&i.Entry.Origin,
+			&i.Entry.Name,
+			&i.Entry.Content,
+			&i.Entry.FileID,
+			&i.Entry.Version,
+			&i.Entry.EntryType,
+			&i.Entry.Checksum,
+			&i.Entry.ParentID,
+			&i.Entry.CollectionID,
+			&i.Entry.AddedBy,
+			&i.Entry.LastUpdatedBy,
+			&i.Entry.Meta,
+			&i.Entry.CreatedAt,
+			&i.Entry.UpdatedAt,
+			&i.Entry.DeletedAt,
+			&i.Entry.ArchivedAt,
+			&i.Entry.FilesizeBytes,
+			&i.Entry.PublicID,
+			&i.Entry.TextContent,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findRelatedEntries = `-- name: FindRelatedEntries :many
+with
+    source_entry as (
+        select
+            e.id,
+            e.origin,
+            c.workspace_id,
+            ck.embedding_model,
+            ck.embedding_dimensions,
+            avg(ck.semantic_vector) as centroid
+        from entries e
+        join collections c on c.id = e.collection_id
+        join entry_chunks ck on ck.entry_id = e.id
+        where e.public_id = $2 and ck.semantic_vector is not null
+        group by e.id, e.origin, c.workspace_id, ck.embedding_model, ck.embedding_dimensions
+        -- the chunks of an entry can only be from different models while it is being re-embedded
+        order by count(*) desc
+        limit 1
+    ),
+    -- the closest chunks to the entry's centroid, every entry is then scored by its closest chunk
+    -- the ` + "`" + `vector(1)` + "`" + ` casts are replaced with the entry's dimensions (see ` + "`" + `queries.WithDimensions` + "`" + `) to match their HNSW index
+    -- all the filters are applied here, the candidates would otherwise be taken by chunks of entries that are dropped afterwards
+    nearest_chunks as (
+        select
+            ck.entry_id,
+            ck.semantic_vector::vector(1) <=> (select centroid from source_entry) as distance
+        from entry_chunks ck
+        join entries e on e.id = ck.entry_id
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join entries_queue q on q.entry_id = e.id
+        join workspace_members wm on wm.workspace_id = w.id
+        join collection_members cm on cm.collection_id = c.id
+        where
+            ck.semantic_vector is not null
+            and ck.deleted_at is null
+            -- the other versions of the entry are not related to it
+            and e.origin != (select origin from source_entry)
+            and e.entry_type != 'comment'
+            -- only the latest processed version of an entry can be related to it
+            and e.version = (
+                select max(v.version)
+                from entries v
+                join entries_queue vq on vq.entry_id = v.id
+                where
+                    v.origin = e.origin
+                    and v.deleted_at is null
+                    and vq.status = 'completed'
+            )
+            and c.workspace_id = (select workspace_id from source_entry)
+            and ck.embedding_model = (select embedding_model from source_entry)
+            and ck.embedding_dimensions = (select embedding_dimensions from source_entry)
+            and q.status = 'completed'
+            and e.archived_at is null
+            and wm.user_id = $3
+            and cm.user_id = $3
+            and e.deleted_at is null
+            and c.deleted_at is null
+            and w.deleted_at is null
+        order by ck.semantic_vector::vector(1) <=> (select centroid from source_entry)
+        limit $4::int
+    ),
+    related as (
+        select nc.entry_id, (1 - min(nc.distance))::float8 as similarity
+        from nearest_chunks nc
+        group by nc.entry_id
+    )
+select
+    e.public_id,
+    e.name,
+    e.entry_type as type,
+    e.file_id,
+    e.filesize_bytes,
+    e.created_at,
+    e.updated_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    w.display_name as workspace_name,
+    w.public_id as workspace_id,
+    w.slug as workspace_slug,
+    r.similarity
+from related r
+join entries e on e.id = r.entry_id
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+order by r.similarity desc
+limit $1::int
+`
+
+type FindRelatedEntriesParams struct {
+	ResultLimit   int32       `json:"result_limit"`
+	EntryPublicID pgtype.UUID `json:"entry_public_id"`
+	UserID        int32       `json:"user_id"`
+	Candidates    int32       `json:"candidates"`
+}
+
+type FindRelatedEntriesRow struct {
+	PublicID       pgtype.UUID        `json:"public_id"`
+	Name           string             `json:"name"`
+	Type           document.EntryType `json:"type"`
+	FileID         pgtype.Text        `json:"file_id"`
+	FilesizeBytes  int64              `json:"filesize_bytes"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	CollectionName string             `json:"collection_name"`
+	CollectionID   pgtype.UUID        `json:"collection_id"`
+	CollectionSlug pgtype.Text        `json:"collection_slug"`
+	WorkspaceName  string             `json:"workspace_name"`
+	WorkspaceID    pgtype.UUID        `json:"workspace_id"`
+	WorkspaceSlug  pgtype.Text        `json:"workspace_slug"`
+	Similarity     float64            `json:"similarity"`
+}
+
+func (q *Queries) FindRelatedEntries(ctx context.Context, arg FindRelatedEntriesParams) ([]FindRelatedEntriesRow, error) {
+	rows, err := q.db.Query(ctx, findRelatedEntries,
+		arg.ResultLimit,
+		arg.EntryPublicID,
+		arg.UserID,
+		arg.Candidates,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindRelatedEntriesRow{}
+	for rows.Next() {
+		var i FindRelatedEntriesRow
+		if err := rows.Scan(
+			&i.PublicID,
+			&i.Name,
+			&i.Type,
+			&i.FileID,
+			&i.FilesizeBytes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CollectionName,
+			&i.CollectionID,
+			&i.CollectionSlug,
+			&i.WorkspaceName,
+			&i.WorkspaceID,
+			&i.WorkspaceSlug,
+			&i.Similarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findUnindexedChunks = `-- name: FindUnindexedChunks :many
+select ck.id, ck.content
+from entry_chunks ck
+join entries e on e.id = ck.entry_id
+where
+    (
+        ck.embedding_status = 'pending'
+        -- we will only retry failed chunks that have failed less than 5 times
+        or (ck.embedding_status = 'failed' and ck.embedding_error_count < 5)
+        or (
+            -- this is a special case where the chunk was marked as processing but hasn't
+            -- been updated in the last 2 hours
+            ck.embedding_status = 'processing'
+            and ck.embedding_status_updated_at < now() - interval '2 hours'
+        )
+    )
+    and ck.semantic_vector is null
+    and ck.content is not null
+    and ck.content != ''
+    -- the chunks of archived entries are embedded once they are unarchived
+    and e.archived_at is null
+    and e.deleted_at is null
+`
+
+type FindUnindexedChunksRow struct {
+	ID      int32       `json:"id"`
+	Content pgtype.Text `json:"content"`
+}
+
+func (q *Queries) FindUnindexedChunks(ctx context.Context) ([]FindUnindexedChunksRow, error) {
+	rows, err := q.db.Query(ctx, findUnindexedChunks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindUnindexedChunksRow{}
+	for rows.Next() {
+		var i FindUnindexedChunksRow
+		if err := rows.Scan(&i.ID, &i.Content); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEntriesOwnership = `-- name: GetEntriesOwnership :many
+select
+    e.public_id,
+    e.added_by,
+    (e.added_by = cm.user_id) as is_owner,
+    cm.bitmask_role as user_role
+from entries e
+join collection_members cm on cm.collection_id = e.collection_id
+where cm.user_id = $1 and e.public_id = any($2::uuid[])
+`
+
 type GetEntriesOwnershipParams struct {
 	UserID         int32         `json:"user_id"`
 	EntryPublicIds []pgtype.UUID `json:"entry_public_ids"`
@@ -798,20 +1869,726 @@ type GetEntriesOwnershipRow struct {
 	UserRole rbac.Role   `json:"user_role"`
 }
 
-func (q *Queries) GetEntriesOwnership(ctx context.Context, arg GetEntriesOwnershipParams) ([]GetEntriesOwnershipRow, error) {
-	rows, err := q.db.Query(ctx, getEntriesOwnership, arg.UserID, arg.EntryPublicIds)
+func (q *Queries) GetEntriesOwnership(ctx context.Context, arg GetEntriesOwnershipParams) ([]GetEntriesOwnershipRow, error) {
+	rows, err := q.db.Query(ctx, getEntriesOwnership, arg.UserID, arg.EntryPublicIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetEntriesOwnershipRow{}
+	for rows.Next() {
+		var i GetEntriesOwnershipRow
+		if err := rows.Scan(
+			&i.PublicID,
+			&i.AddedBy,
+			&i.IsOwner,
+			&i.UserRole,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertChunk = `-- name: InsertChunk :one
+with
+    entry_id as (
+        select id from entries where public_id = $5 and deleted_at is null
+    )
+    insert into entry_chunks(entry_id, chunk_index, min_version, content, language)
+select id, $1, $2, $3, $4
+from entry_id
+returning id, entry_id, chunk_index, min_version, content, created_at, updated_at, deleted_at, text_vector, semantic_vector, language, embedding_status, embedding_status_updated_at, last_embedding_error, last_embedding_error_at, embedding_error_count, embedding_model, embedding_dimensions
+`
+
+type InsertChunkParams struct {
+	Index         int32       `json:"index"`
+	MinVersion    int32       `json:"min_version"`
+	Content       pgtype.Text `json:"content"`
+	Language      pgtype.Text `json:"language"`
+	EntryPublicID pgtype.UUID `json:"entry_public_id"`
+}
+
+func (q *Queries) InsertChunk(ctx context.Context, arg InsertChunkParams) (EntryChunk, error) {
+	row := q.db.QueryRow(ctx, insertChunk,
+		arg.Index,
+		arg.MinVersion,
+		arg.Content,
+		arg.Language,
+		arg.EntryPublicID,
+	)
+	var i EntryChunk
+	err := row.Scan(
+		&i.ID,
+		&i.EntryID,
+		&i.ChunkIndex,
+		&i.MinVersion,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TextVector,
+		&i.SemanticVector,
+		&i.Language,
+		&i.EmbeddingStatus,
+		&i.EmbeddingStatusUpdatedAt,
+		&i.LastEmbeddingError,
+		&i.LastEmbeddingErrorAt,
+		&i.EmbeddingErrorCount,
+		&i.EmbeddingModel,
+		&i.EmbeddingDimensions,
+	)
+	return i, err
+}
+
+type InsertChunksParams struct {
+	EntryID    pgtype.Int4 `json:"entry_id"`
+	Index      int32       `json:"index"`
+	MinVersion int32       `json:"min_version"`
+	Content    pgtype.Text `json:"content"`
+	Language   pgtype.Text `json:"language"`
+}
+
+const insertRelatedEntries = `-- name: InsertRelatedEntries :execrows
+with
+    source_entry as (
+        select
+            e.id,
+            e.origin,
+            c.workspace_id,
+            ck.embedding_model,
+            ck.embedding_dimensions,
+            avg(ck.semantic_vector) as centroid
+        from entries e
+        join collections c on c.id = e.collection_id
+        join entry_chunks ck on ck.entry_id = e.id
+        where e.id = $2 and ck.semantic_vector is not null
+        group by e.id, e.origin, c.workspace_id, ck.embedding_model, ck.embedding_dimensions
+        -- the chunks of an entry can only be from different models while it is being re-embedded
+        order by count(*) desc
+        limit 1
+    ),
+    -- the cache is shared by all the members, the user-specific filters are applied when it is read
+    -- the ` + "`" + `vector(1)` + "`" + ` casts are replaced with the entry's dimensions, as in FindRelatedEntries
+    nearest_chunks as (
+        select
+            ck.entry_id,
+            ck.semantic_vector::vector(1) <=> (select centroid from source_entry) as distance
+        from entry_chunks ck
+        join entries e on e.id = ck.entry_id
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            ck.semantic_vector is not null
+            and ck.deleted_at is null
+            and e.origin != (select origin from source_entry)
+            and e.entry_type != 'comment'
+            and e.version = (
+                select max(v.version)
+                from entries v
+                join entries_queue vq on vq.entry_id = v.id
+                where
+                    v.origin = e.origin
+                    and v.deleted_at is null
+                    and vq.status = 'completed'
+            )
+            and c.workspace_id = (select workspace_id from source_entry)
+            and ck.embedding_model = (select embedding_model from source_entry)
+            and ck.embedding_dimensions = (select embedding_dimensions from source_entry)
+            and e.deleted_at is null
+            and c.deleted_at is null
+            and w.deleted_at is null
+        order by ck.semantic_vector::vector(1) <=> (select centroid from source_entry)
+        limit $3::int
+    )
+insert into related_entries (entry_id, related_entry_id, similarity)
+select (select id from source_entry), nc.entry_id, (1 - min(nc.distance))::float8
+from nearest_chunks nc
+group by nc.entry_id
+order by min(nc.distance)
+limit $1::int
+`
+
+type InsertRelatedEntriesParams struct {
+	MaxRelated int32 `json:"max_related"`
+	EntryID    int32 `json:"entry_id"`
+	Candidates int32 `json:"candidates"`
+}
+
+func (q *Queries) InsertRelatedEntries(ctx context.Context, arg InsertRelatedEntriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertRelatedEntries, arg.MaxRelated, arg.EntryID, arg.Candidates)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listTrash = `-- name: ListTrash :many
+with
+    trashed as (
+        select distinct on (e.origin)
+            e.id,
+            e.public_id,
+            e.origin,
+            e.name,
+            e.version,
+            e.entry_type,
+            e.filesize_bytes,
+            e.collection_id,
+            e.added_by,
+            e.created_at,
+            e.updated_at,
+            e.deleted_at
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join collection_members cm on cm.collection_id = c.id
+        join workspace_members wm on wm.workspace_id = w.id
+        where
+            cm.user_id = $3
+            and wm.user_id = $3
+            and w.slug = $4
+            and e.deleted_at is not null
+            and c.deleted_at is null
+            and w.deleted_at is null
+        order by e.origin, e.version desc
+    )
+select
+    t.public_id,
+    t.origin,
+    t.name,
+    t.version,
+    t.entry_type as type,
+    t.filesize_bytes,
+    t.created_at,
+    t.updated_at,
+    t.deleted_at,
+    (
+        t.deleted_at + make_interval(days => coalesce(ws.trash_retention_days, 30))
+    )::timestamptz as purge_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    u.first_name as added_by_first_name,
+    u.last_name as added_by_last_name,
+    u.username as added_by_username,
+    count(*) over () as total_entries
+from trashed t
+join collections c on c.id = t.collection_id
+join users u on u.id = t.added_by
+left join workspace_settings ws on ws.workspace_id = c.workspace_id
+order by t.deleted_at desc, t.public_id desc
+limit $1
+offset $2
+`
+
+type ListTrashParams struct {
+	Limit         int32       `json:"limit"`
+	Offset        int32       `json:"offset"`
+	UserID        int32       `json:"user_id"`
+	WorkspaceSlug pgtype.Text `json:"workspace_slug"`
+}
+
+type ListTrashRow struct {
+	PublicID         pgtype.UUID        `json:"public_id"`
+	Origin           pgtype.UUID        `json:"origin"`
+	Name             string             `json:"name"`
+	Version          int32              `json:"version"`
+	Type             string             `json:"type"`
+	FilesizeBytes    int64              `json:"filesize_bytes"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
+	PurgeAt          pgtype.Timestamptz `json:"purge_at"`
+	CollectionName   string             `json:"collection_name"`
+	CollectionID     pgtype.UUID        `json:"collection_id"`
+	CollectionSlug   pgtype.Text        `json:"collection_slug"`
+	AddedByFirstName string             `json:"added_by_first_name"`
+	AddedByLastName  string             `json:"added_by_last_name"`
+	AddedByUsername  string             `json:"added_by_username"`
+	TotalEntries     int64              `json:"total_entries"`
+}
+
+// List the deleted entries of a workspace, only the latest version of each entry is returned along with the time it will be purged at
+func (q *Queries) ListTrash(ctx context.Context, arg ListTrashParams) ([]ListTrashRow, error) {
+	rows, err := q.db.Query(ctx, listTrash,
+		arg.Limit,
+		arg.Offset,
+		arg.UserID,
+		arg.WorkspaceSlug,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTrashRow{}
+	for rows.Next() {
+		var i ListTrashRow
+		if err := rows.Scan(
+			&i.PublicID,
+			&i.Origin,
+			&i.Name,
+			&i.Version,
+			&i.Type,
+			&i.FilesizeBytes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.PurgeAt,
+			&i.CollectionName,
+			&i.CollectionID,
+			&i.CollectionSlug,
+			&i.AddedByFirstName,
+			&i.AddedByLastName,
+			&i.AddedByUsername,
+			&i.TotalEntries,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveEntries = `-- name: MoveEntries :many
+with recursive
+    targets as (
+        select e.public_id, e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any($1::uuid[])
+            and w.slug = $2
+            and e.deleted_at is null
+            and e.entry_type != 'comment'
+            and e.collection_id != $3
+    ),
+    threads as (
+        select e.id
+        from entries e
+        join targets t on t.origin = e.origin
+        union all
+        -- comments follow the entry they were made on
+        select cm.id
+        from entries cm
+        join threads th on th.id = cm.parent_id
+        where cm.entry_type = 'comment'
+    ),
+    moved as (
+        update entries e
+        set collection_id = $3
+        from threads th
+        where e.id = th.id
+        returning e.id
+    ),
+    untagged as (
+        -- tags belong to a collection, they are not carried over to the new one
+        delete from entry_tags et
+        using moved m
+        where et.entry_id = m.id
+    ),
+    unrelated as (
+        -- the related entries are computed again for the new collection once they are requested
+        delete from related_entries r
+        using moved m
+        where r.entry_id = m.id or r.related_entry_id = m.id
+    )
+select t.public_id, t.origin
+from targets t
+`
+
+type MoveEntriesParams struct {
+	EntryPublicIds []pgtype.UUID `json:"entry_public_ids"`
+	WorkspaceSlug  pgtype.Text   `json:"workspace_slug"`
+	CollectionID   int32         `json:"collection_id"`
+}
+
+type MoveEntriesRow struct {
+	PublicID pgtype.UUID `json:"public_id"`
+	Origin   pgtype.UUID `json:"origin"`
+}
+
+// Move entries along with all their versions and comments to another collection, only the public IDs of the given versions that were not already in the collection are returned
+func (q *Queries) MoveEntries(ctx context.Context, arg MoveEntriesParams) ([]MoveEntriesRow, error) {
+	rows, err := q.db.Query(ctx, moveEntries, arg.EntryPublicIds, arg.WorkspaceSlug, arg.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MoveEntriesRow{}
+	for rows.Next() {
+		var i MoveEntriesRow
+		if err := rows.Scan(&i.PublicID, &i.Origin); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeEntries = `-- name: PurgeEntries :many
+with
+    targets as (
+        select e.public_id, v.id, v.file_id
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join entries v on v.origin = e.origin
+        where
+            e.public_id = any($1::uuid[])
+            and w.slug = $2
+            and e.deleted_at is not null
+    ),
+    purged as (delete from entries e using targets t where e.id = t.id),
+    orphaned_files as (
+        select t.id, t.file_id
+        from targets t
+        where
+            t.file_id is not null
+            and not exists (
+                select 1
+                from entries o
+                where o.file_id = t.file_id and o.id not in (select id from targets)
+            )
+    )
+select t.public_id, f.file_id
+from targets t
+left join orphaned_files f on f.id = t.id
+`
+
+type PurgeEntriesParams struct {
+	EntryPublicIds []pgtype.UUID `json:"entry_public_ids"`
+	WorkspaceSlug  pgtype.Text   `json:"workspace_slug"`
+}
+
+type PurgeEntriesRow struct {
+	PublicID pgtype.UUID `json:"public_id"`
+	FileID   pgtype.Text `json:"file_id"`
+}
+
+// Permanently delete entries in the trash along with all their versions, the files that are not referenced by any other entry are returned so that they can be removed from the object store
+func (q *Queries) PurgeEntries(ctx context.Context, arg PurgeEntriesParams) ([]PurgeEntriesRow, error) {
+	rows, err := q.db.Query(ctx, purgeEntries, arg.EntryPublicIds, arg.WorkspaceSlug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PurgeEntriesRow{}
+	for rows.Next() {
+		var i PurgeEntriesRow
+		if err := rows.Scan(&i.PublicID, &i.FileID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeExpiredEntries = `-- name: PurgeExpiredEntries :many
+with
+    expired as (
+        select e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        left join workspace_settings ws on ws.workspace_id = c.workspace_id
+        where
+            -- an entry expires from the earliest of its own deletion and the deletion of its collection or workspace (nulls are ignored)
+            least(e.deleted_at, c.deleted_at, w.deleted_at)
+            < now() - make_interval(days => coalesce(ws.trash_retention_days, 30))
+        group by e.origin
+        order by min(least(e.deleted_at, c.deleted_at, w.deleted_at))
+        limit $1
+    ),
+    targets as (
+        select v.id, v.public_id, v.file_id
+        from entries v
+        join expired x on x.origin = v.origin
+    ),
+    purged as (delete from entries e using targets t where e.id = t.id),
+    orphaned_files as (
+        select t.id, t.file_id
+        from targets t
+        where
+            t.file_id is not null
+            and not exists (
+                select 1
+                from entries o
+                where o.file_id = t.file_id and o.id not in (select id from targets)
+            )
+    )
+select t.public_id, f.file_id
+from targets t
+left join orphaned_files f on f.id = t.id
+`
+
+type PurgeExpiredEntriesRow struct {
+	PublicID pgtype.UUID `json:"public_id"`
+	FileID   pgtype.Text `json:"file_id"`
+}
+
+// Permanently delete the entries that have been in the trash for longer than the retention period of their workspace, the entries of deleted collections and workspaces are purged as well
+func (q *Queries) PurgeExpiredEntries(ctx context.Context, maxEntries int32) ([]PurgeExpiredEntriesRow, error) {
+	rows, err := q.db.Query(ctx, purgeExpiredEntries, maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PurgeExpiredEntriesRow{}
+	for rows.Next() {
+		var i PurgeExpiredEntriesRow
+		if err := rows.Scan(&i.PublicID, &i.FileID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const queryWithFuzzySearch = `-- name: QueryWithFuzzySearch :many
+select
+    e.public_id,
+    e.name as title,
+    e.meta,
+    e.entry_type as type,
+    e.file_id,
+    e.filesize_bytes,
+    e.created_at,
+    e.updated_at,
+    e.archived_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    w.display_name as workspace_name,
+    w.public_id as workspace_id,
+    w.slug as workspace_slug,
+    ck.id as chunk_id,
+    ck.content as chunk_content,
+    ck.chunk_index,
+    q.status,
+    greatest(
+        word_similarity($3::text, coalesce(ck.content, '')),
+        case when ck.chunk_index = 0 then word_similarity($3::text, e.name) else 0 end
+    )::float8 as fuzzy_score
+from entry_chunks ck
+join entries e on e.id = ck.entry_id
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries_queue q on q.entry_id = e.id
+join workspace_members wm on wm.workspace_id = w.id
+join collection_members cm on cm.collection_id = c.id
+where
+    -- entry names are only matched against the first chunk so that an entry is not returned once per chunk
+    ($3::text <% ck.content or (ck.chunk_index = 0 and $3::text <% e.name))
+    and q.status = any(
+        coalesce($4::entry_status[], '{completed}'::entry_status[])
+    )
+    -- only the latest processed version of an entry is searched, the previous one is kept while a new one is processed
+    and e.version = (
+        select max(v.version)
+        from entries v
+        join entries_queue vq on vq.entry_id = v.id
+        where
+            v.origin = e.origin
+            and v.deleted_at is null
+            and vq.status = any(
+                coalesce($4::entry_status[], '{completed}'::entry_status[])
+            )
+    )
+    and ($5::uuid is null or w.public_id = $5)
+    and ($6::text is null or w.slug = $6)
+    and wm.user_id = $7
+    and cm.user_id = $7
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+    and (
+        $8::text[] is null
+        or e.entry_type = any($8::text[])
+        -- comments are filtered by the entry they were made on once they are attributed to it
+        or e.entry_type = 'comment'
+    )
+    and (
+        (
+            $9::uuid[] is null
+            and $10::text[] is null
+        )
+        or c.public_id = any($9::uuid[])
+        or c.slug = any($10::text[])
+    )
+    and (
+        $11::text[] is null
+        or e.added_by in (
+            select u.id from users u where u.username = any($11::text[])
+        )
+    )
+    and (
+        $12::text[] is null
+        or e.entry_type = 'comment'
+        or e.id in (
+            select et.entry_id
+            from entry_tags et
+            join tags t on t.id = et.tag_id
+            where lower(t.name) = any($12::text[])
+            group by et.entry_id
+            having count(distinct lower(t.name))
+            = cardinality($12::text[])
+        )
+    )
+    and (
+        $13::timestamptz is null
+        or e.created_at >= $13::timestamptz
+    )
+    and (
+        $14::timestamptz is null
+        or e.created_at < $14::timestamptz
+    )
+    and (
+        $15::timestamptz is null
+        or e.updated_at >= $15::timestamptz
+    )
+    and (
+        $16::timestamptz is null
+        or e.updated_at < $16::timestamptz
+    )
+    and (
+        $17::text[] is null
+        or e.name ilike all($17::text[])
+    )
+    and (
+        $18::text[] is null
+        or not (
+            e.name ilike any($18::text[])
+            or coalesce(ck.content, '') ilike any($18::text[])
+        )
+    )
+    -- archived entries are only returned if they were asked for
+    and (
+        case
+            when $19::boolean then e.archived_at is not null
+            when $20::boolean then true
+            else e.archived_at is null
+        end
+    )
+order by fuzzy_score desc
+limit $1
+offset $2
+`
+
+type QueryWithFuzzySearchParams struct {
+	Limit             int32              `json:"limit"`
+	Offset            int32              `json:"offset"`
+	Query             string             `json:"query"`
+	Statuses          []EntryStatus      `json:"statuses"`
+	WorkspacePublicID pgtype.UUID        `json:"workspace_public_id"`
+	WorkspaceSlug     pgtype.Text        `json:"workspace_slug"`
+	UserID            int32              `json:"user_id"`
+	EntryTypes        []string           `json:"entry_types"`
+	CollectionIds     []pgtype.UUID      `json:"collection_ids"`
+	CollectionSlugs   []string           `json:"collection_slugs"`
+	AddedBy           []string           `json:"added_by"`
+	Tags              []string           `json:"tags"`
+	CreatedAfter      pgtype.Timestamptz `json:"created_after"`
+	CreatedBefore     pgtype.Timestamptz `json:"created_before"`
+	UpdatedAfter      pgtype.Timestamptz `json:"updated_after"`
+	UpdatedBefore     pgtype.Timestamptz `json:"updated_before"`
+	TitlePatterns     []string           `json:"title_patterns"`
+	ExcludedPatterns  []string           `json:"excluded_patterns"`
+	ArchivedOnly      bool               `json:"archived_only"`
+	IncludeArchived   bool               `json:"include_archived"`
+}
+
+type QueryWithFuzzySearchRow struct {
+	PublicID       pgtype.UUID        `json:"public_id"`
+	Title          string             `json:"title"`
+	Meta           []byte             `json:"meta"`
+	Type           document.EntryType `json:"type"`
+	FileID         pgtype.Text        `json:"file_id"`
+	FilesizeBytes  int64              `json:"filesize_bytes"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	ArchivedAt     pgtype.Timestamptz `json:"archived_at"`
+	CollectionName string             `json:"collection_name"`
+	CollectionID   pgtype.UUID        `json:"collection_id"`
+	CollectionSlug pgtype.Text        `json:"collection_slug"`
+	WorkspaceName  string             `json:"workspace_name"`
+	WorkspaceID    pgtype.UUID        `json:"workspace_id"`
+	WorkspaceSlug  pgtype.Text        `json:"workspace_slug"`
+	ChunkID        int32              `json:"chunk_id"`
+	ChunkContent   pgtype.Text        `json:"chunk_content"`
+	ChunkIndex     int32              `json:"chunk_index"`
+	Status         EntryStatus        `json:"status"`
+	FuzzyScore     float64            `json:"fuzzy_score"`
+}
+
+func (q *Queries) QueryWithFuzzySearch(ctx context.Context, arg QueryWithFuzzySearchParams) ([]QueryWithFuzzySearchRow, error) {
+	rows, err := q.db.Query(ctx, queryWithFuzzySearch,
+		arg.Limit,
+		arg.Offset,
+		arg.Query,
+		arg.Statuses,
+		arg.WorkspacePublicID,
+		arg.WorkspaceSlug,
+		arg.UserID,
+		arg.EntryTypes,
+		arg.CollectionIds,
+		arg.CollectionSlugs,
+		arg.AddedBy,
+		arg.Tags,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.TitlePatterns,
+		arg.ExcludedPatterns,
+		arg.ArchivedOnly,
+		arg.IncludeArchived,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetEntriesOwnershipRow{}
+	items := []QueryWithFuzzySearchRow{}
 	for rows.Next() {
-		var i GetEntriesOwnershipRow
+		var i QueryWithFuzzySearchRow
 		if err := rows.Scan(
 			&i.PublicID,
-			&i.AddedBy,
-			&i.IsOwner,
-			&i.UserRole,
+			&i.Title,
+			&i.Meta,
+			&i.Type,
+			&i.FileID,
+			&i.FilesizeBytes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.CollectionName,
+			&i.CollectionID,
+			&i.CollectionSlug,
+			&i.WorkspaceName,
+			&i.WorkspaceID,
+			&i.WorkspaceSlug,
+			&i.ChunkID,
+			&i.ChunkContent,
+			&i.ChunkIndex,
+			&i.Status,
+			&i.FuzzyScore,
 		); err != nil {
 			return nil, err
 		}
@@ -823,63 +2600,6 @@ func (q *Queries) GetEntriesOwnership(ctx context.Context, arg GetEntriesOwnersh
 	return items, nil
 }
 
-const insertChunk = `-- name: InsertChunk :one
-with
-    entry_id as (
-        select id from entries where public_id = $5 and deleted_at is null
-    )
-    insert into entry_chunks(entry_id, chunk_index, min_version, content, language)
-select id, $1, $2, $3, $4
-from entry_id
-returning id, entry_id, chunk_index, min_version, content, created_at, updated_at, deleted_at, text_vector, semantic_vector, language, embedding_status, embedding_status_updated_at, last_embedding_error, last_embedding_error_at, embedding_error_count
-`
-
-type InsertChunkParams struct {
-	Index         int32       `json:"index"`
-	MinVersion    int32       `json:"min_version"`
-	Content       pgtype.Text `json:"content"`
-	Language      pgtype.Text `json:"language"`
-	EntryPublicID pgtype.UUID `json:"entry_public_id"`
-}
-
-func (q *Queries) InsertChunk(ctx context.Context, arg InsertChunkParams) (EntryChunk, error) {
-	row := q.db.QueryRow(ctx, insertChunk,
-		arg.Index,
-		arg.MinVersion,
-		arg.Content,
-		arg.Language,
-		arg.EntryPublicID,
-	)
-	var i EntryChunk
-	err := row.Scan(
-		&i.ID,
-		&i.EntryID,
-		&i.ChunkIndex,
-		&i.MinVersion,
-		&i.Content,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-		&i.TextVector,
-		&i.SemanticVector,
-		&i.Language,
-		&i.EmbeddingStatus,
-		&i.EmbeddingStatusUpdatedAt,
-		&i.LastEmbeddingError,
-		&i.LastEmbeddingErrorAt,
-		&i.EmbeddingErrorCount,
-	)
-	return i, err
-}
-
-type InsertChunksParams struct {
-	EntryID    pgtype.Int4 `json:"entry_id"`
-	Index      int32       `json:"index"`
-	MinVersion int32       `json:"min_version"`
-	Content    pgtype.Text `json:"content"`
-	Language   pgtype.Text `json:"language"`
-}
-
 const queryWithHybridSearch = `-- name: QueryWithHybridSearch :many
 with
     semantic_search as (
@@ -904,8 +2624,14 @@ with
             ck.chunk_index,
             q.status,
             null::float8 as text_score,
-            ($3 <=> ck.semantic_vector)::float8 as semantic_score,
-            rank() over (order by $3 <=> ck.semantic_vector) as rank
+            -- the ` + "`" + `vector(1)` + "`" + ` casts are replaced with the model's dimensions (see ` + "`" + `queries.WithDimensions` + "`" + `) to match its HNSW index
+            (
+                ck.semantic_vector::vector(1) <=> $3::real[]::vector
+            )::float8 as semantic_score,
+            rank() over (
+                order by ck.semantic_vector::vector(1) <=> $3::real[]::vector
+            ) as rank,
+            null::text as headline
         from entry_chunks ck
         join entries e on e.id = ck.entry_id
         join collections c on c.id = e.collection_id
@@ -914,20 +2640,106 @@ with
         join workspace_members wm on wm.workspace_id = w.id
         join collection_members cm on cm.collection_id = c.id
         where
-            q.status = 'completed'
-            and e.archived_at is null
+            q.status = any(
+                coalesce($4::entry_status[], '{completed}'::entry_status[])
+            )
+            -- only the latest processed version of an entry is searched, the previous one is kept while a new one is processed
+            and e.version = (
+                select max(v.version)
+                from entries v
+                join entries_queue vq on vq.entry_id = v.id
+                where
+                    v.origin = e.origin
+                    and v.deleted_at is null
+                    and vq.status = any(
+                        coalesce($4::entry_status[], '{completed}'::entry_status[])
+                    )
+            )
+            -- there is no vector when embeddings are disabled or the query only has operators
+            and $3::real[] is not null
             and ck.semantic_vector is not null
+            -- vectors from other models cannot be compared with the query's vector
+            and ck.embedding_model = $5
+            and ck.embedding_dimensions = $6
             and (
-                $4::uuid is null
-                or w.public_id = $4
+                $7::uuid is null
+                or w.public_id = $7
             )
-            and ($5::text is null or w.slug = $5)
-            and wm.user_id = $6
-            and cm.user_id = $6
+            and ($8::text is null or w.slug = $8)
+            and wm.user_id = $9
+            and cm.user_id = $9
             and e.deleted_at is null
             and c.deleted_at is null
             and w.deleted_at is null
-        order by $3 <=> ck.semantic_vector
+            and (
+                $10::text[] is null
+                or e.entry_type = any($10::text[])
+                -- comments are filtered by the entry they were made on once they are attributed to it
+                or e.entry_type = 'comment'
+            )
+            and (
+                (
+                    $11::uuid[] is null
+                    and $12::text[] is null
+                )
+                or c.public_id = any($11::uuid[])
+                or c.slug = any($12::text[])
+            )
+            and (
+                $13::text[] is null
+                or e.added_by in (
+                    select u.id from users u where u.username = any($13::text[])
+                )
+            )
+            and (
+                $14::text[] is null
+                or e.entry_type = 'comment'
+                or e.id in (
+                    select et.entry_id
+                    from entry_tags et
+                    join tags t on t.id = et.tag_id
+                    where lower(t.name) = any($14::text[])
+                    group by et.entry_id
+                    having count(distinct lower(t.name))
+                    = cardinality($14::text[])
+                )
+            )
+            and (
+                $15::timestamptz is null
+                or e.created_at >= $15::timestamptz
+            )
+            and (
+                $16::timestamptz is null
+                or e.created_at < $16::timestamptz
+            )
+            and (
+                $17::timestamptz is null
+                or e.updated_at >= $17::timestamptz
+            )
+            and (
+                $18::timestamptz is null
+                or e.updated_at < $18::timestamptz
+            )
+            and (
+                $19::text[] is null
+                or e.name ilike all($19::text[])
+            )
+            and (
+                $20::text[] is null
+                or not (
+                    e.name ilike any($20::text[])
+                    or coalesce(ck.content, '') ilike any($20::text[])
+                )
+            )
+            -- archived entries are only returned if they were asked for
+            and (
+                case
+                    when $21::boolean then e.archived_at is not null
+                    when $22::boolean then true
+                    else e.archived_at is null
+                end
+            )
+        order by ck.semantic_vector::vector(1) <=> $3::real[]::vector
         limit $1
         offset $2
     ),
@@ -953,16 +2765,26 @@ with
             ck.chunk_index,
             q.status,
             ts_rank(
-                ck.text_vector, websearch_to_tsquery(ts_regconfig(ck.language), $7)
+                ck.text_vector, websearch_to_tsquery(ts_regconfig(ck.language), $23)
             ) as text_score,
             0.0::float8 as semantic_score,
             rank() over (
                 order by
                     ts_rank(
                         ck.text_vector,
-                        websearch_to_tsquery(ts_regconfig(ck.language), $7)
-                    )
-            ) as rank
+                        websearch_to_tsquery(ts_regconfig(ck.language), $23)
+                    ) desc,
+                    -- queries with only operators have nothing to rank by, recently updated entries come first
+                    case when $23::text = '' then e.updated_at end desc nulls last
+            ) as rank,
+            -- the matched words are wrapped in control characters (STX/ETX) that cannot appear in the content, they are replaced with offsets in the repository
+            ts_headline(
+                ts_regconfig(ck.language),
+                ck.content,
+                websearch_to_tsquery(ts_regconfig(ck.language), $23),
+                'StartSel=' || chr(2) || ', StopSel=' || chr(3)
+                || ', MinWords=15, MaxWords=35, MaxFragments=2, FragmentDelimiter=" ... "'
+            ) as headline
         from entry_chunks ck
         join entries e on e.id = ck.entry_id
         join collections c on c.id = e.collection_id
@@ -971,31 +2793,116 @@ with
         join workspace_members wm on wm.workspace_id = w.id
         join collection_members cm on cm.collection_id = c.id
         where
-            (ck.text_vector @@ websearch_to_tsquery(ts_regconfig(ck.language), $7))
-            and q.status = 'completed'
-            and e.archived_at is null
+            (
+                ck.text_vector @@ websearch_to_tsquery(ts_regconfig(ck.language), $23)
+                -- queries with only operators list the entries that match them, once each
+                or ($23::text = '' and ck.chunk_index = 0)
+            )
+            and q.status = any(
+                coalesce($4::entry_status[], '{completed}'::entry_status[])
+            )
+            -- only the latest processed version of an entry is searched, the previous one is kept while a new one is processed
+            and e.version = (
+                select max(v.version)
+                from entries v
+                join entries_queue vq on vq.entry_id = v.id
+                where
+                    v.origin = e.origin
+                    and v.deleted_at is null
+                    and vq.status = any(
+                        coalesce($4::entry_status[], '{completed}'::entry_status[])
+                    )
+            )
             and ck.text_vector is not null
             and (
-                $4::uuid is null
-                or w.public_id = $4
+                $7::uuid is null
+                or w.public_id = $7
             )
-            and ($5::text is null or w.slug = $5)
-            and wm.user_id = $6
-            and cm.user_id = $6
+            and ($8::text is null or w.slug = $8)
+            and wm.user_id = $9
+            and cm.user_id = $9
             and e.deleted_at is null
             and c.deleted_at is null
             and w.deleted_at is null
+            and (
+                $10::text[] is null
+                or e.entry_type = any($10::text[])
+                -- comments are filtered by the entry they were made on once they are attributed to it
+                or e.entry_type = 'comment'
+            )
+            and (
+                (
+                    $11::uuid[] is null
+                    and $12::text[] is null
+                )
+                or c.public_id = any($11::uuid[])
+                or c.slug = any($12::text[])
+            )
+            and (
+                $13::text[] is null
+                or e.added_by in (
+                    select u.id from users u where u.username = any($13::text[])
+                )
+            )
+            and (
+                $14::text[] is null
+                or e.entry_type = 'comment'
+                or e.id in (
+                    select et.entry_id
+                    from entry_tags et
+                    join tags t on t.id = et.tag_id
+                    where lower(t.name) = any($14::text[])
+                    group by et.entry_id
+                    having count(distinct lower(t.name))
+                    = cardinality($14::text[])
+                )
+            )
+            and (
+                $15::timestamptz is null
+                or e.created_at >= $15::timestamptz
+            )
+            and (
+                $16::timestamptz is null
+                or e.created_at < $16::timestamptz
+            )
+            and (
+                $17::timestamptz is null
+                or e.updated_at >= $17::timestamptz
+            )
+            and (
+                $18::timestamptz is null
+                or e.updated_at < $18::timestamptz
+            )
+            and (
+                $19::text[] is null
+                or e.name ilike all($19::text[])
+            )
+            and (
+                $20::text[] is null
+                or not (
+                    e.name ilike any($20::text[])
+                    or coalesce(ck.content, '') ilike any($20::text[])
+                )
+            )
+            -- archived entries are only returned if they were asked for
+            and (
+                case
+                    when $21::boolean then e.archived_at is not null
+                    when $22::boolean then true
+                    else e.archived_at is null
+                end
+            )
         order by rank
         limit $1
         offset $2
     )
-select public_id, title, meta, type, file_id, filesize_bytes, created_at, updated_at, archived_at, collection_name, collection_id, collection_slug, workspace_name, workspace_id, workspace_slug, chunk_id, chunk_content, chunk_index, status, text_score, semantic_score, rank, sum(coalesce(1.0 / (results.rank + 50), 0.0))::float8 as score
+select public_id, title, meta, type, file_id, filesize_bytes, created_at, updated_at, archived_at, collection_name, collection_id, collection_slug, workspace_name, workspace_id, workspace_slug, chunk_id, chunk_content, chunk_index, status, text_score, semantic_score, rank, headline, sum(coalesce(1.0 / (results.rank + 50), 0.0))::float8 as score
 from
     (
-        select public_id, title, meta, type, file_id, filesize_bytes, created_at, updated_at, archived_at, collection_name, collection_id, collection_slug, workspace_name, workspace_id, workspace_slug, chunk_id, chunk_content, chunk_index, status, text_score, semantic_score, rank
+        select public_id, title, meta, type, file_id, filesize_bytes, created_at, updated_at, archived_at, collection_name, collection_id, collection_slug, workspace_name, workspace_id, workspace_slug, chunk_id, chunk_content, chunk_index, status, text_score, semantic_score, rank, headline
         from semantic_search
         union all
-        select public_id, title, meta, type, file_id, filesize_bytes, created_at, updated_at, archived_at, collection_name, collection_id, collection_slug, workspace_name, workspace_id, workspace_slug, chunk_id, chunk_content, chunk_index, status, text_score, semantic_score, rank
+        select public_id, title, meta, type, file_id, filesize_bytes, created_at, updated_at, archived_at, collection_name, collection_id, collection_slug, workspace_name, workspace_id, workspace_slug, chunk_id, chunk_content, chunk_index, status, text_score, semantic_score, rank, headline
         from text_search
     ) as results
 group by
@@ -1020,20 +2927,35 @@ group by
     results.text_score,
     results.semantic_score,
     results.rank,
+    results.headline,
     results.status
 order by score desc
-limit $1
-offset $2
 `
 
 type QueryWithHybridSearchParams struct {
-	Limit             int32           `json:"limit"`
-	Offset            int32           `json:"offset"`
-	Embedding         pgvector.Vector `json:"embedding"`
-	WorkspacePublicID pgtype.UUID     `json:"workspace_public_id"`
-	WorkspaceSlug     pgtype.Text     `json:"workspace_slug"`
-	UserID            int32           `json:"user_id"`
-	Query             string          `json:"query"`
+	Limit               int32              `json:"limit"`
+	Offset              int32              `json:"offset"`
+	Embedding           []float32          `json:"embedding"`
+	Statuses            []EntryStatus      `json:"statuses"`
+	EmbeddingModel      pgtype.Text        `json:"embedding_model"`
+	EmbeddingDimensions pgtype.Int4        `json:"embedding_dimensions"`
+	WorkspacePublicID   pgtype.UUID        `json:"workspace_public_id"`
+	WorkspaceSlug       pgtype.Text        `json:"workspace_slug"`
+	UserID              int32              `json:"user_id"`
+	EntryTypes          []string           `json:"entry_types"`
+	CollectionIds       []pgtype.UUID      `json:"collection_ids"`
+	CollectionSlugs     []string           `json:"collection_slugs"`
+	AddedBy             []string           `json:"added_by"`
+	Tags                []string           `json:"tags"`
+	CreatedAfter        pgtype.Timestamptz `json:"created_after"`
+	CreatedBefore       pgtype.Timestamptz `json:"created_before"`
+	UpdatedAfter        pgtype.Timestamptz `json:"updated_after"`
+	UpdatedBefore       pgtype.Timestamptz `json:"updated_before"`
+	TitlePatterns       []string           `json:"title_patterns"`
+	ExcludedPatterns    []string           `json:"excluded_patterns"`
+	ArchivedOnly        bool               `json:"archived_only"`
+	IncludeArchived     bool               `json:"include_archived"`
+	Query               string             `json:"query"`
 }
 
 type QueryWithHybridSearchRow struct {
@@ -1059,6 +2981,7 @@ type QueryWithHybridSearchRow struct {
 	TextScore      pgtype.Float8      `json:"text_score"`
 	SemanticScore  float64            `json:"semantic_score"`
 	Rank           int64              `json:"rank"`
+	Headline       pgtype.Text        `json:"headline"`
 	Score          float64            `json:"score"`
 }
 
@@ -1067,9 +2990,25 @@ func (q *Queries) QueryWithHybridSearch(ctx context.Context, arg QueryWithHybrid
 		arg.Limit,
 		arg.Offset,
 		arg.Embedding,
+		arg.Statuses,
+		arg.EmbeddingModel,
+		arg.EmbeddingDimensions,
 		arg.WorkspacePublicID,
 		arg.WorkspaceSlug,
 		arg.UserID,
+		arg.EntryTypes,
+		arg.CollectionIds,
+		arg.CollectionSlugs,
+		arg.AddedBy,
+		arg.Tags,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.TitlePatterns,
+		arg.ExcludedPatterns,
+		arg.ArchivedOnly,
+		arg.IncludeArchived,
 		arg.Query,
 	)
 	if err != nil {
@@ -1102,6 +3041,7 @@ func (q *Queries) QueryWithHybridSearch(ctx context.Context, arg QueryWithHybrid
 			&i.TextScore,
 			&i.SemanticScore,
 			&i.Rank,
+			&i.Headline,
 			&i.Score,
 		); err != nil {
 			return nil, err
@@ -1114,6 +3054,23 @@ func (q *Queries) QueryWithHybridSearch(ctx context.Context, arg QueryWithHybrid
 	return items, nil
 }
 
+const recordFailedFileDeletions = `-- name: RecordFailedFileDeletions :exec
+insert into failed_file_deletions (file_id, last_error)
+select unnest($1::text[]), $2::text
+on conflict (file_id) do update
+set attempts = failed_file_deletions.attempts + 1, last_error = excluded.last_error
+`
+
+type RecordFailedFileDeletionsParams struct {
+	FileIds   []string `json:"file_ids"`
+	LastError string   `json:"last_error"`
+}
+
+func (q *Queries) RecordFailedFileDeletions(ctx context.Context, arg RecordFailedFileDeletionsParams) error {
+	_, err := q.db.Exec(ctx, recordFailedFileDeletions, arg.FileIds, arg.LastError)
+	return err
+}
+
 const requeueEntries = `-- name: RequeueEntries :many
 update entries_queue
 set status = 'queued', attempts = 0, available_at = now()
@@ -1141,8 +3098,52 @@ func (q *Queries) RequeueEntries(ctx context.Context, entryIds []int32) ([]int32
 	return items, nil
 }
 
+const resetForeignChunkEmbeddings = `-- name: ResetForeignChunkEmbeddings :execrows
+with recursive
+    threads as (
+        select e.id
+        from entries e
+        where e.origin = any($1::uuid[])
+        union all
+        -- the comments of an entry are in the same workspace as the entry
+        select cm.id
+        from entries cm
+        join threads th on th.id = cm.parent_id
+        where cm.entry_type = 'comment'
+    )
+update entry_chunks ck
+set
+    semantic_vector = null,
+    embedding_model = null,
+    embedding_dimensions = null,
+    embedding_status = 'pending',
+    embedding_status_updated_at = now(),
+    embedding_error_count = 0
+from entries e
+join collections c on c.id = e.collection_id
+join workspace_settings ws on ws.workspace_id = c.workspace_id
+where
+    ck.entry_id = e.id
+    and e.id in (select th.id from threads th)
+    and ck.semantic_vector is not null
+    and ws.embedding_model is not null
+    and (
+        ck.embedding_model is distinct from ws.embedding_model
+        or ck.embedding_dimensions is distinct from ws.embedding_dimensions
+    )
+`
+
+// Reset the embeddings of the chunks that were not generated with the model of their workspace, they are embedded again with the right model
+func (q *Queries) ResetForeignChunkEmbeddings(ctx context.Context, origins []pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, resetForeignChunkEmbeddings, origins)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const resolveEntryIds = `-- name: ResolveEntryIds :many
-select e.id, e.public_id
+select e.id, e.public_id, e.version
 from entries e
 where e.public_id = any($1::uuid[]) and e.deleted_at is null
 `
@@ -1150,6 +3151,7 @@ where e.public_id = any($1::uuid[]) and e.deleted_at is null
 type ResolveEntryIdsRow struct {
 	ID       int32       `json:"id"`
 	PublicID pgtype.UUID `json:"public_id"`
+	Version  int32       `json:"version"`
 }
 
 func (q *Queries) ResolveEntryIds(ctx context.Context, entryPublicIds []pgtype.UUID) ([]ResolveEntryIdsRow, error) {
@@ -1161,7 +3163,7 @@ func (q *Queries) ResolveEntryIds(ctx context.Context, entryPublicIds []pgtype.U
 	items := []ResolveEntryIdsRow{}
 	for rows.Next() {
 		var i ResolveEntryIdsRow
-		if err := rows.Scan(&i.ID, &i.PublicID); err != nil {
+		if err := rows.Scan(&i.ID, &i.PublicID, &i.Version); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1208,13 +3210,146 @@ func (q *Queries) ResolveEntryIdsWithoutChunks(ctx context.Context, entryPublicI
 	return items, nil
 }
 
+const restoreEntries = `-- name: RestoreEntries :many
+with
+    targets as (
+        select e.public_id, e.origin, e.deleted_at
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any($1::uuid[])
+            and w.slug = $2
+            and e.deleted_at is not null
+            and c.deleted_at is null
+    ),
+    restored as (
+        update entries e
+        set deleted_at = null
+        from targets t
+        where e.origin = t.origin and e.deleted_at = t.deleted_at
+        returning e.id, t.deleted_at
+    ),
+    restored_chunks as (
+        update entry_chunks ec
+        set deleted_at = null
+        from restored r
+        where ec.entry_id = r.id and ec.deleted_at = r.deleted_at
+    )
+select t.public_id, q.entry_id as queued_entry_id
+from targets t
+left join entries_queue q
+    on q.status = 'queued'
+    and q.entry_id = (
+        select l.id
+        from entries l
+        where l.origin = t.origin
+        order by l.version desc
+        limit 1
+    )
+`
+
+type RestoreEntriesParams struct {
+	EntryPublicIds []pgtype.UUID `json:"entry_public_ids"`
+	WorkspaceSlug  pgtype.Text   `json:"workspace_slug"`
+}
+
+type RestoreEntriesRow struct {
+	PublicID      pgtype.UUID `json:"public_id"`
+	QueuedEntryID pgtype.Int4 `json:"queued_entry_id"`
+}
+
+// Restore entries along with all their versions and chunks from the trash, the chunks keep their vectors so the entries are searchable again without being re-embedded. The ID of the latest version is also returned if it is still waiting to be processed
+func (q *Queries) RestoreEntries(ctx context.Context, arg RestoreEntriesParams) ([]RestoreEntriesRow, error) {
+	rows, err := q.db.Query(ctx, restoreEntries, arg.EntryPublicIds, arg.WorkspaceSlug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RestoreEntriesRow{}
+	for rows.Next() {
+		var i RestoreEntriesRow
+		if err := rows.Scan(&i.PublicID, &i.QueuedEntryID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unarchiveEntries = `-- name: UnarchiveEntries :many
+with
+    targets as (
+        select e.public_id, e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any($1::uuid[])
+            and w.slug = $2
+            and e.deleted_at is null
+            and e.archived_at is not null
+    ),
+    unarchived as (
+        update entries e
+        set archived_at = null
+        from targets t
+        where e.origin = t.origin
+    )
+select t.public_id, q.entry_id as queued_entry_id
+from targets t
+left join entries_queue q
+    on q.status = 'queued'
+    and q.entry_id = (
+        select l.id
+        from entries l
+        where l.origin = t.origin and l.deleted_at is null
+        order by l.version desc
+        limit 1
+    )
+`
+
+type UnarchiveEntriesParams struct {
+	EntryPublicIds []pgtype.UUID `json:"entry_public_ids"`
+	WorkspaceSlug  pgtype.Text   `json:"workspace_slug"`
+}
+
+type UnarchiveEntriesRow struct {
+	PublicID      pgtype.UUID `json:"public_id"`
+	QueuedEntryID pgtype.Int4 `json:"queued_entry_id"`
+}
+
+// Unarchive entries along with all their versions, the ID of the latest version is also returned if it is still waiting to be processed
+func (q *Queries) UnarchiveEntries(ctx context.Context, arg UnarchiveEntriesParams) ([]UnarchiveEntriesRow, error) {
+	rows, err := q.db.Query(ctx, unarchiveEntries, arg.EntryPublicIds, arg.WorkspaceSlug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UnarchiveEntriesRow{}
+	for rows.Next() {
+		var i UnarchiveEntriesRow
+		if err := rows.Scan(&i.PublicID, &i.QueuedEntryID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateChunk = `-- name: UpdateChunk :one
 update entry_chunks
 set
     content = case when $1::text is null then content else $1 end,
     language = case when $2::text is null then language else $2 end
 where entry_id = $3 and chunk_index = $4 and min_version = $5
-returning id, entry_id, chunk_index, min_version, content, created_at, updated_at, deleted_at, text_vector, semantic_vector, language, embedding_status, embedding_status_updated_at, last_embedding_error, last_embedding_error_at, embedding_error_count
+returning id, entry_id, chunk_index, min_version, content, created_at, updated_at, deleted_at, text_vector, semantic_vector, language, embedding_status, embedding_status_updated_at, last_embedding_error, last_embedding_error_at, embedding_error_count, embedding_model, embedding_dimensions
 `
 
 type UpdateChunkParams struct {
@@ -1251,6 +3386,8 @@ func (q *Queries) UpdateChunk(ctx context.Context, arg UpdateChunkParams) (Entry
 		&i.LastEmbeddingError,
 		&i.LastEmbeddingErrorAt,
 		&i.EmbeddingErrorCount,
+		&i.EmbeddingModel,
+		&i.EmbeddingDimensions,
 	)
 	return i, err
 }
@@ -1279,19 +3416,28 @@ const updateChunkSemanticVector = `-- name: UpdateChunkSemanticVector :exec
 update entry_chunks
 set
     semantic_vector = $1,
+    embedding_model = $2,
+    embedding_dimensions = $3,
     embedding_status = 'done',
     embedding_status_updated_at = now(),
     embedding_error_count = 0
-where id = $2
+where id = $4
 `
 
 type UpdateChunkSemanticVectorParams struct {
-	SemanticVector pgvector.Vector `json:"semantic_vector"`
-	ChunkID        int32           `json:"chunk_id"`
+	SemanticVector      pgvector.Vector `json:"semantic_vector"`
+	EmbeddingModel      pgtype.Text     `json:"embedding_model"`
+	EmbeddingDimensions pgtype.Int4     `json:"embedding_dimensions"`
+	ChunkID             int32           `json:"chunk_id"`
 }
 
 func (q *Queries) UpdateChunkSemanticVector(ctx context.Context, arg UpdateChunkSemanticVectorParams) error {
-	_, err := q.db.Exec(ctx, updateChunkSemanticVector, arg.SemanticVector, arg.ChunkID)
+	_, err := q.db.Exec(ctx, updateChunkSemanticVector,
+		arg.SemanticVector,
+		arg.EmbeddingModel,
+		arg.EmbeddingDimensions,
+		arg.ChunkID,
+	)
 	return err
 }
 
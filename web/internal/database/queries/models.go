@@ -14,6 +14,70 @@ import (
 	"go.trulyao.dev/hubble/web/pkg/rbac"
 )
 
+type EmbeddingMigrationStatus string
+
+const (
+	EmbeddingMigrationStatusRunning   EmbeddingMigrationStatus = "running"
+	EmbeddingMigrationStatusCompleted EmbeddingMigrationStatus = "completed"
+	EmbeddingMigrationStatusFailed    EmbeddingMigrationStatus = "failed"
+	EmbeddingMigrationStatusCancelled EmbeddingMigrationStatus = "cancelled"
+)
+
+func (e *EmbeddingMigrationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmbeddingMigrationStatus(s)
+	case string:
+		*e = EmbeddingMigrationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmbeddingMigrationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullEmbeddingMigrationStatus struct {
+	EmbeddingMigrationStatus EmbeddingMigrationStatus `json:"embedding_migration_status"`
+	Valid                    bool                     `json:"valid"` // Valid is true if EmbeddingMigrationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmbeddingMigrationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmbeddingMigrationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmbeddingMigrationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmbeddingMigrationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmbeddingMigrationStatus), nil
+}
+
+func (e EmbeddingMigrationStatus) Valid() bool {
+	switch e {
+	case EmbeddingMigrationStatusRunning,
+		EmbeddingMigrationStatusCompleted,
+		EmbeddingMigrationStatusFailed,
+		EmbeddingMigrationStatusCancelled:
+		return true
+	}
+	return false
+}
+
+func AllEmbeddingMigrationStatusValues() []EmbeddingMigrationStatus {
+	return []EmbeddingMigrationStatus{
+		EmbeddingMigrationStatusRunning,
+		EmbeddingMigrationStatusCompleted,
+		EmbeddingMigrationStatusFailed,
+		EmbeddingMigrationStatusCancelled,
+	}
+}
+
 type EntryChunkEmbeddingStatus string
 
 const (
@@ -502,6 +566,128 @@ func AllPluginSyncStatusValues() []PluginSyncStatus {
 	}
 }
 
+type TagSuggestionMode string
+
+const (
+	TagSuggestionModeOff     TagSuggestionMode = "off"
+	TagSuggestionModeSuggest TagSuggestionMode = "suggest"
+	TagSuggestionModeAuto    TagSuggestionMode = "auto"
+)
+
+func (e *TagSuggestionMode) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TagSuggestionMode(s)
+	case string:
+		*e = TagSuggestionMode(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TagSuggestionMode: %T", src)
+	}
+	return nil
+}
+
+type NullTagSuggestionMode struct {
+	TagSuggestionMode TagSuggestionMode `json:"tag_suggestion_mode"`
+	Valid             bool              `json:"valid"` // Valid is true if TagSuggestionMode is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTagSuggestionMode) Scan(value interface{}) error {
+	if value == nil {
+		ns.TagSuggestionMode, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TagSuggestionMode.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTagSuggestionMode) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TagSuggestionMode), nil
+}
+
+func (e TagSuggestionMode) Valid() bool {
+	switch e {
+	case TagSuggestionModeOff,
+		TagSuggestionModeSuggest,
+		TagSuggestionModeAuto:
+		return true
+	}
+	return false
+}
+
+func AllTagSuggestionModeValues() []TagSuggestionMode {
+	return []TagSuggestionMode{
+		TagSuggestionModeOff,
+		TagSuggestionModeSuggest,
+		TagSuggestionModeAuto,
+	}
+}
+
+type TagSuggestionStatus string
+
+const (
+	TagSuggestionStatusPending  TagSuggestionStatus = "pending"
+	TagSuggestionStatusAccepted TagSuggestionStatus = "accepted"
+	TagSuggestionStatusRejected TagSuggestionStatus = "rejected"
+)
+
+func (e *TagSuggestionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TagSuggestionStatus(s)
+	case string:
+		*e = TagSuggestionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TagSuggestionStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTagSuggestionStatus struct {
+	TagSuggestionStatus TagSuggestionStatus `json:"tag_suggestion_status"`
+	Valid               bool                `json:"valid"` // Valid is true if TagSuggestionStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTagSuggestionStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TagSuggestionStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TagSuggestionStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTagSuggestionStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TagSuggestionStatus), nil
+}
+
+func (e TagSuggestionStatus) Valid() bool {
+	switch e {
+	case TagSuggestionStatusPending,
+		TagSuggestionStatusAccepted,
+		TagSuggestionStatusRejected:
+		return true
+	}
+	return false
+}
+
+func AllTagSuggestionStatusValues() []TagSuggestionStatus {
+	return []TagSuggestionStatus{
+		TagSuggestionStatusPending,
+		TagSuggestionStatusAccepted,
+		TagSuggestionStatusRejected,
+	}
+}
+
 type VersioningStrategy string
 
 const (
@@ -594,6 +780,41 @@ type CollectionMember struct {
 	BitmaskRole      rbac.Role          `json:"bitmask_role"`
 }
 
+type EmbeddingCache struct {
+	CacheKey            string             `json:"cache_key"`
+	EmbeddingModel      string             `json:"embedding_model"`
+	EmbeddingDimensions int32              `json:"embedding_dimensions"`
+	SemanticVector      pgvector.Vector    `json:"semantic_vector"`
+	HitCount            int32              `json:"hit_count"`
+	LastUsedAt          pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+}
+
+type EmbeddingMigration struct {
+	ID              int32                    `json:"id"`
+	WorkspaceID     int32                    `json:"workspace_id"`
+	StartedBy       pgtype.Int4              `json:"started_by"`
+	FromModel       string                   `json:"from_model"`
+	FromDimensions  int32                    `json:"from_dimensions"`
+	ToModel         string                   `json:"to_model"`
+	ToDimensions    int32                    `json:"to_dimensions"`
+	Status          EmbeddingMigrationStatus `json:"status"`
+	TotalChunks     int32                    `json:"total_chunks"`
+	ProcessedChunks int32                    `json:"processed_chunks"`
+	LastChunkID     int32                    `json:"last_chunk_id"`
+	ErrorCount      int32                    `json:"error_count"`
+	LastError       pgtype.Text              `json:"last_error"`
+	CompletedAt     pgtype.Timestamptz       `json:"completed_at"`
+	CreatedAt       pgtype.Timestamptz       `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz       `json:"updated_at"`
+}
+
+type EmbeddingMigrationVector struct {
+	MigrationID    int32           `json:"migration_id"`
+	ChunkID        int32           `json:"chunk_id"`
+	SemanticVector pgvector.Vector `json:"semantic_vector"`
+}
+
 type EntriesQueue struct {
 	ID          pgtype.UUID           `json:"id"`
 	EntryID     int32                 `json:"entry_id"`
@@ -650,6 +871,46 @@ type EntryChunk struct {
 	LastEmbeddingError       pgtype.Text               `json:"last_embedding_error"`
 	LastEmbeddingErrorAt     pgtype.Timestamptz        `json:"last_embedding_error_at"`
 	EmbeddingErrorCount      pgtype.Int4               `json:"embedding_error_count"`
+	// The embedding model that produced the semantic vector
+	EmbeddingModel pgtype.Text `json:"embedding_model"`
+	// The number of dimensions of the semantic vector
+	EmbeddingDimensions pgtype.Int4 `json:"embedding_dimensions"`
+}
+
+type EntryLink struct {
+	ID             int32              `json:"id"`
+	WorkspaceID    int32              `json:"workspace_id"`
+	SourceID       int32              `json:"source_id"`
+	TargetID       pgtype.Int4        `json:"target_id"`
+	TargetName     pgtype.Text        `json:"target_name"`
+	TargetPublicID pgtype.UUID        `json:"target_public_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type EntrySummary struct {
+	EntryID int32 `json:"entry_id"`
+	// The version of the entry the summaries were generated from
+	EntryVersion int32              `json:"entry_version"`
+	SummaryShort string             `json:"summary_short"`
+	SummaryLong  string             `json:"summary_long"`
+	ChatModel    string             `json:"chat_model"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type EntryTag struct {
+	EntryID   int32              `json:"entry_id"`
+	TagID     int32              `json:"tag_id"`
+	AddedBy   int32              `json:"added_by"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type FailedFileDeletion struct {
+	FileID    string             `json:"file_id"`
+	Attempts  int32              `json:"attempts"`
+	LastError pgtype.Text        `json:"last_error"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type InstalledPlugin struct {
@@ -723,6 +984,13 @@ type PluginsKv struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type RelatedEntry struct {
+	EntryID        int32              `json:"entry_id"`
+	RelatedEntryID int32              `json:"related_entry_id"`
+	Similarity     float64            `json:"similarity"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
 type Tag struct {
 	ID           int32              `json:"id"`
 	Name         string             `json:"name"`
@@ -732,6 +1000,29 @@ type Tag struct {
 	CreatedBy    int32              `json:"created_by"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	PublicID     pgtype.UUID        `json:"public_id"`
+}
+
+type TagSuggestion struct {
+	ID           int32               `json:"id"`
+	PublicID     pgtype.UUID         `json:"public_id"`
+	EntryID      int32               `json:"entry_id"`
+	Name         string              `json:"name"`
+	TagID        pgtype.Int4         `json:"tag_id"`
+	Confidence   float64             `json:"confidence"`
+	Status       TagSuggestionStatus `json:"status"`
+	EntryVersion int32               `json:"entry_version"`
+	ReviewedBy   pgtype.Int4         `json:"reviewed_by"`
+	CreatedAt    pgtype.Timestamptz  `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz  `json:"updated_at"`
+}
+
+type TagSuggestionRun struct {
+	EntryID      int32              `json:"entry_id"`
+	EntryVersion int32              `json:"entry_version"`
+	ChatModel    string             `json:"chat_model"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
 type TotpSecret struct {
@@ -797,3 +1088,20 @@ type WorkspaceMember struct {
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
 }
+
+type WorkspaceSetting struct {
+	WorkspaceID         int32              `json:"workspace_id"`
+	RrfK                int32              `json:"rrf_k"`
+	RrfSemanticWeight   float64            `json:"rrf_semantic_weight"`
+	RrfFullTextWeight   float64            `json:"rrf_full_text_weight"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	EmbeddingModel      pgtype.Text        `json:"embedding_model"`
+	EmbeddingDimensions pgtype.Int4        `json:"embedding_dimensions"`
+	SummariesEnabled    bool               `json:"summaries_enabled"`
+	TagSuggestionMode   TagSuggestionMode  `json:"tag_suggestion_mode"`
+	// The minimum confidence of the suggestions that are applied automatically in the auto mode
+	TagAutoApplyThreshold float64 `json:"tag_auto_apply_threshold"`
+	// The number of days deleted entries are kept in the trash before they are purged
+	TrashRetentionDays int32 `json:"trash_retention_days"`
+}
@@ -0,0 +1,133 @@
+-- name: CreateComment :one
+-- Comments are entries whose parent is either the first version of the entry they were made on or the comment they reply to, they belong to the collection of their parent
+insert into entries (
+    name,
+    content,
+    text_content,
+    entry_type,
+    parent_id,
+    collection_id,
+    added_by,
+    last_updated_by
+)
+select
+    @name,
+    @content,
+    @text_content,
+    'comment',
+    p.id,
+    p.collection_id,
+    @user_id,
+    @user_id
+from entries p
+where p.id = @parent_id
+returning *
+;
+
+-- name: FindComment :one
+select e.*
+from entries e
+where
+    e.public_id = @comment_public_id
+    and e.entry_type = 'comment'
+    and e.deleted_at is null
+    and e.collection_id in (
+        select c.id
+        from collections c
+        join workspaces w on w.id = c.workspace_id
+        where w.slug = @workspace_slug and c.deleted_at is null
+    )
+;
+
+-- name: FindComments :many
+-- Find the comments on an entry along with all their replies in the order they were made
+with recursive
+    thread as (
+        select cm.id
+        from entries cm
+        where
+            cm.parent_id = (
+                select coalesce(e.parent_id, e.id) from entries e where e.public_id = @entry_public_id
+            )
+            and cm.entry_type = 'comment'
+        union all
+        select r.id
+        from entries r
+        join thread t on r.parent_id = t.id
+        where r.entry_type = 'comment'
+    )
+select
+    cm.public_id,
+    rt.public_id as reply_to_id,
+    cm.content,
+    cm.created_at,
+    cm.updated_at,
+    u.first_name as added_by_first_name,
+    u.last_name as added_by_last_name,
+    u.username as added_by_username
+from thread t
+join entries cm on cm.id = t.id
+join users u on u.id = cm.added_by
+-- top-level comments have the entry as their parent, they do not reply to anything
+left join entries rt on rt.id = cm.parent_id and rt.entry_type = 'comment'
+where cm.deleted_at is null
+order by cm.created_at asc, cm.id asc
+;
+
+-- name: FindCommentedEntries :many
+-- Find the latest version of the entries the given comments were made on, replies are followed up to the entry of their thread
+with recursive
+    thread as (
+        select cm.public_id as comment_id, cm.parent_id
+        from entries cm
+        where cm.public_id = any(@comment_public_ids::uuid[]) and cm.entry_type = 'comment'
+        union all
+        select t.comment_id, p.parent_id
+        from thread t
+        join entries p on p.id = t.parent_id
+        where p.entry_type = 'comment'
+    )
+select distinct on (t.comment_id)
+    t.comment_id,
+    r.id as root_id,
+    l.public_id,
+    l.name,
+    l.meta,
+    l.entry_type as type,
+    l.file_id,
+    l.filesize_bytes,
+    l.created_at,
+    l.updated_at,
+    l.archived_at,
+    q.status,
+    array(
+        select lower(tg.name)
+        from entry_tags et
+        join tags tg on tg.id = et.tag_id
+        where et.entry_id = l.id
+    )::text[] as tags
+from thread t
+join entries r on r.id = t.parent_id and r.entry_type != 'comment'
+-- deleted entries have no latest version, their comments are left out
+join entries l on l.origin = r.origin and l.deleted_at is null
+join entries_queue q on q.entry_id = l.id
+order by t.comment_id, l.version desc
+;
+
+-- name: UpdateComment :one
+update entries
+set
+    name = @name,
+    content = @content,
+    text_content = @text_content,
+    last_updated_by = @user_id
+where id = @comment_id and entry_type = 'comment'
+returning *
+;
+
+-- name: DeleteCommentChunks :exec
+delete from entry_chunks where entry_id = @entry_id;
+
+-- name: DeleteComment :execrows
+-- Delete a comment, its replies, chunks and queue records are removed along with it
+delete from entries where id = @comment_id and entry_type = 'comment';
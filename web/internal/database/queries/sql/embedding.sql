@@ -0,0 +1,233 @@
+-- name: AdoptUnversionedEmbeddings :execrows
+-- vectors generated before the models were recorded can only have been generated with the configured model
+update entry_chunks
+set embedding_model = @embedding_model::text
+where
+    semantic_vector is not null
+    and embedding_model is null
+    and embedding_dimensions = @embedding_dimensions::int
+;
+
+-- name: ResetUnversionedEmbeddings :execrows
+-- vectors without a model and with other dimensions were generated with an unknown model, they are embedded again with the workspace's model by the embedding cron
+update entry_chunks
+set
+    semantic_vector = null,
+    embedding_dimensions = null,
+    embedding_status = 'pending'
+where
+    semantic_vector is not null
+    and embedding_model is null
+    and embedding_dimensions is distinct from @embedding_dimensions::int
+;
+
+-- name: PinWorkspaceEmbeddingModel :execrows
+-- workspaces keep the model their vectors were generated with even if the configured model changes, all workspaces are pinned if no ID is provided
+insert into workspace_settings (workspace_id, embedding_model, embedding_dimensions)
+select w.id, @embedding_model::text, @embedding_dimensions::int
+from workspaces w
+where sqlc.narg('workspace_id')::int is null or w.id = sqlc.narg('workspace_id')::int
+on conflict (workspace_id) do update
+set
+    embedding_model = excluded.embedding_model,
+    embedding_dimensions = excluded.embedding_dimensions
+where workspace_settings.embedding_model is null
+;
+
+-- name: SetWorkspaceEmbeddingModel :exec
+insert into workspace_settings (workspace_id, embedding_model, embedding_dimensions)
+values (@workspace_id, @embedding_model::text, @embedding_dimensions::int)
+on conflict (workspace_id) do update
+set
+    embedding_model = excluded.embedding_model,
+    embedding_dimensions = excluded.embedding_dimensions
+;
+
+-- name: FindChunkEmbeddingModel :one
+select c.workspace_id, ws.embedding_model, ws.embedding_dimensions
+from entry_chunks ck
+join entries e on e.id = ck.entry_id
+join collections c on c.id = e.collection_id
+left join workspace_settings ws on ws.workspace_id = c.workspace_id
+where ck.id = @chunk_id
+;
+
+-- name: CreateEmbeddingMigration :one
+insert into embedding_migrations (
+    workspace_id,
+    started_by,
+    from_model,
+    from_dimensions,
+    to_model,
+    to_dimensions,
+    total_chunks
+)
+values (
+    @workspace_id,
+    @started_by,
+    @from_model,
+    @from_dimensions,
+    @to_model,
+    @to_dimensions,
+    (
+        select count(*)::int
+        from entry_chunks ck
+        join entries e on e.id = ck.entry_id
+        join collections c on c.id = e.collection_id
+        where
+            c.workspace_id = @workspace_id
+            and ck.content is not null
+            and ck.content != ''
+            and ck.deleted_at is null
+            and e.deleted_at is null
+    )
+)
+returning *
+;
+
+-- name: FindLatestEmbeddingMigration :one
+select *
+from embedding_migrations
+where workspace_id = @workspace_id
+order by id desc
+limit 1
+;
+
+-- name: FindRunningEmbeddingMigrations :many
+select *
+from embedding_migrations
+where status = 'running'
+order by id
+;
+
+-- name: FindEmbeddingMigrationChunks :many
+-- chunks are processed in order of their IDs, so chunks added during the migration are picked up by the last batches
+select ck.id, ck.content
+from entry_chunks ck
+join entries e on e.id = ck.entry_id
+join collections c on c.id = e.collection_id
+where
+    c.workspace_id = @workspace_id
+    and ck.id > @after_chunk_id
+    and ck.content is not null
+    and ck.content != ''
+    and ck.deleted_at is null
+    and e.deleted_at is null
+order by ck.id
+limit @batch_size::int
+;
+
+-- name: InsertEmbeddingMigrationVector :exec
+insert into embedding_migration_vectors (migration_id, chunk_id, semantic_vector)
+values (@migration_id, @chunk_id, @semantic_vector)
+on conflict (migration_id, chunk_id) do update
+set semantic_vector = excluded.semantic_vector
+;
+
+-- name: UpdateEmbeddingMigrationProgress :execrows
+update embedding_migrations
+set
+    processed_chunks = processed_chunks + @processed_chunks::int,
+    last_chunk_id = @last_chunk_id,
+    error_count = 0
+where id = @migration_id and status = 'running'
+;
+
+-- name: RecordEmbeddingMigrationError :one
+-- the migration is marked as failed once too many batches have failed in a row
+update embedding_migrations
+set
+    error_count = error_count + 1,
+    last_error = @last_error,
+    status = case
+        when error_count + 1 >= @max_errors::int then 'failed'::embedding_migration_status
+        else status
+    end,
+    completed_at = case when error_count + 1 >= @max_errors::int then now() else completed_at end
+where id = @migration_id
+returning status
+;
+
+-- name: ApplyEmbeddingMigrationVectors :execrows
+update entry_chunks ck
+set
+    semantic_vector = v.semantic_vector,
+    embedding_model = m.to_model,
+    embedding_dimensions = m.to_dimensions,
+    embedding_status = 'done',
+    embedding_status_updated_at = now(),
+    embedding_error_count = 0
+from embedding_migration_vectors v
+join embedding_migrations m on m.id = v.migration_id
+where v.migration_id = @migration_id and ck.id = v.chunk_id and m.status = 'running'
+;
+
+-- name: ResetOutdatedChunkEmbeddings :execrows
+-- chunks that were not re-embedded by the migration (e.g. edited while it was running) are embedded again with the workspace's model by the embedding cron
+update entry_chunks ck
+set
+    semantic_vector = null,
+    embedding_model = null,
+    embedding_dimensions = null,
+    embedding_status = 'pending'
+from entries e
+join collections c on c.id = e.collection_id
+where
+    ck.entry_id = e.id
+    and c.workspace_id = @workspace_id
+    and ck.semantic_vector is not null
+    and (
+        ck.embedding_model is distinct from @embedding_model::text
+        or ck.embedding_dimensions is distinct from @embedding_dimensions::int
+    )
+;
+
+-- name: CompleteEmbeddingMigration :execrows
+-- nothing is updated if the migration was cancelled (or has failed) in the meantime
+update embedding_migrations
+set status = 'completed', completed_at = now()
+where id = @migration_id and status = 'running'
+;
+
+-- name: CancelEmbeddingMigration :one
+update embedding_migrations
+set status = 'cancelled', completed_at = now()
+where workspace_id = @workspace_id and status = 'running'
+returning id
+;
+
+-- name: DeleteEmbeddingMigrationVectors :exec
+delete from embedding_migration_vectors
+where migration_id = @migration_id
+;
+
+-- name: FindCachedEmbedding :one
+select semantic_vector from embedding_cache where cache_key = @cache_key;
+
+-- name: RecordCachedEmbeddingHits :exec
+-- hits are recorded in batches, so that a lookup is not a write
+update embedding_cache ec
+set hit_count = ec.hit_count + h.hits, last_used_at = now()
+from (
+    select unnest(@cache_keys::text[]) as cache_key, unnest(@hits::int[]) as hits
+) h
+where ec.cache_key = h.cache_key
+;
+
+-- name: CacheEmbedding :exec
+insert into embedding_cache (cache_key, embedding_model, embedding_dimensions, semantic_vector)
+values (@cache_key, @embedding_model, @embedding_dimensions, @semantic_vector)
+on conflict (cache_key) do update
+set last_used_at = now()
+;
+
+-- name: EvictCachedEmbeddings :execrows
+-- keeps the most recently used entries
+delete from embedding_cache
+where cache_key in (
+    select cache_key
+    from embedding_cache
+    order by last_used_at desc
+    offset @max_entries::int
+)
+;
@@ -66,12 +66,16 @@ select
     w.display_name as workspace_name,
     w.slug as workspace_slug,
     q.status,
-    q.created_at as queued_at
+    q.created_at as queued_at,
+    es.summary_short,
+    es.summary_long,
+    es.entry_version as summary_version
 from entries e
 join collections c on c.id = e.collection_id
 join workspaces w on w.id = c.workspace_id
 join entries_queue q on q.entry_id = e.id
 join users u on u.id = e.added_by
+left join entry_summaries es on es.entry_id = e.id
 where
     (
         (sqlc.narg('entry_public_id')::uuid is null and e.id = @entry_id)
@@ -110,7 +114,8 @@ with
             cm.user_id = @user_id
             and wm.user_id = @user_id
             and e.deleted_at is null
-            and e.archived_at is null
+            -- comments share the parent of the versions of the entry they were made on
+            and e.entry_type != 'comment'
             and (
                 sqlc.narg('workspace_slug')::text is null
                 or w.slug = sqlc.narg('workspace_slug')::text
@@ -130,7 +135,14 @@ with
             and c.deleted_at is null
             and w.deleted_at is null
             and e.deleted_at is null
-            and e.archived_at is null
+            -- archived entries are only returned if they were asked for
+            and (
+                case
+                    when @archived_only::boolean then e.archived_at is not null
+                    when @include_archived::boolean then true
+                    else e.archived_at is null
+                end
+            )
         order by e.collection_id, e.version desc
     )
 select
@@ -169,11 +181,137 @@ join collections c on c.id = e.collection_id
 join workspaces w on w.id = c.workspace_id
 join entries_queue q on q.entry_id = e.id
 join users u on u.id = e.added_by
-order by coalesce(e.updated_at, e.created_at) desc, q.updated_at desc
+where
+    e.rn = 1
+    -- the tags are matched on the latest version, an older version may still have a tag that was removed since
+    and (
+        sqlc.narg('tags')::text[] is null
+        or e.id in (
+            select et.entry_id
+            from entry_tags et
+            join tags t on t.id = et.tag_id
+            where lower(t.name) = any(sqlc.narg('tags')::text[])
+            group by et.entry_id
+            having count(distinct lower(t.name))
+            = cardinality(sqlc.narg('tags')::text[])
+        )
+    )
+order by coalesce(e.updated_at, e.created_at) desc, e.public_id desc
 limit $1
 offset $2
 ;
 
+-- name: FindEntriesAfterCursor :many
+-- Keyset variant of FindEntries without the total count, the cursor is the sort key and
+-- public ID of the last entry on the previous page.
+with
+    latest_entries as (
+        select
+            e.*,
+            row_number() over (
+                partition by coalesce(e.parent_id, e.id) order by e.version desc
+            ) as rn
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join collection_members cm on cm.collection_id = c.id
+        join workspace_members wm on wm.workspace_id = w.id
+        where
+            cm.user_id = @user_id
+            and wm.user_id = @user_id
+            and e.deleted_at is null
+            -- comments share the parent of the versions of the entry they were made on
+            and e.entry_type != 'comment'
+            and (
+                sqlc.narg('workspace_slug')::text is null
+                or w.slug = sqlc.narg('workspace_slug')::text
+            )
+            and (
+                sqlc.narg('workspace_public_id')::uuid is null
+                or w.public_id = sqlc.narg('workspace_public_id')::uuid
+            )
+            and (
+                sqlc.narg('collection_slug')::text is null
+                or c.slug = sqlc.narg('collection_slug')::text
+            )
+            and (
+                sqlc.narg('collection_public_id')::uuid is null
+                or c.public_id = sqlc.narg('collection_public_id')::uuid
+            )
+            and c.deleted_at is null
+            and w.deleted_at is null
+            and e.deleted_at is null
+            -- archived entries are only returned if they were asked for
+            and (
+                case
+                    when @archived_only::boolean then e.archived_at is not null
+                    when @include_archived::boolean then true
+                    else e.archived_at is null
+                end
+            )
+        order by e.collection_id, e.version desc
+    )
+select
+    e.id,
+    e.public_id,
+    e.parent_id,
+    e.origin,
+    e.content,
+    e.text_content,
+    e.name,
+    e.meta,
+    e.version,
+    e.entry_type as type,
+    e.file_id,
+    e.filesize_bytes,
+    e.added_by,
+    e.last_updated_by,
+    e.created_at,
+    e.updated_at,
+    e.archived_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    u.first_name as added_by_first_name,
+    u.last_name as added_by_last_name,
+    u.username as added_by_username,
+    u.avatar_id as added_by_avatar_id,
+    w.public_id as workspace_id,
+    w.display_name as workspace_name,
+    w.slug as workspace_slug,
+    q.status,
+    q.created_at as queued_at,
+    -- keyset pages are not counted, the column only keeps the row in the same shape as FindEntries
+    0::bigint as total_entries
+from latest_entries e
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries_queue q on q.entry_id = e.id
+join users u on u.id = e.added_by
+where
+    e.rn = 1
+    -- the tags are matched on the latest version, an older version may still have a tag that was removed since
+    and (
+        sqlc.narg('tags')::text[] is null
+        or e.id in (
+            select et.entry_id
+            from entry_tags et
+            join tags t on t.id = et.tag_id
+            where lower(t.name) = any(sqlc.narg('tags')::text[])
+            group by et.entry_id
+            having count(distinct lower(t.name))
+            = cardinality(sqlc.narg('tags')::text[])
+        )
+    )
+    and (
+        sqlc.narg('cursor_sort_key')::timestamptz is null
+        or (coalesce(e.updated_at, e.created_at), e.public_id)
+        < (sqlc.narg('cursor_sort_key')::timestamptz, sqlc.narg('cursor_id')::uuid)
+    )
+order by coalesce(e.updated_at, e.created_at) desc, e.public_id desc
+limit $1
+;
+
 -- name: GetEntriesOwnership :many
 select
     e.public_id,
@@ -186,9 +324,412 @@ where cm.user_id = @user_id and e.public_id = any(@entry_public_ids::uuid[])
 ;
 
 -- name: DeleteEntries :many
-delete from entries
-where public_id = any(@entry_public_ids::uuid[]) and deleted_at is null
-returning public_id
+-- Move entries along with all their versions and chunks to the trash, only the public IDs of the given versions are returned
+with
+    targets as (
+        select e.public_id, e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any(@entry_public_ids::uuid[])
+            and w.slug = @workspace_slug
+            and e.deleted_at is null
+    ),
+    deleted as (
+        update entries e
+        set deleted_at = now()
+        from targets t
+        where e.origin = t.origin and e.deleted_at is null
+        returning e.id
+    ),
+    deleted_chunks as (
+        -- the chunks are marked with the same timestamp as their entries so that only those are brought back on restore
+        update entry_chunks ec
+        set deleted_at = now()
+        from deleted d
+        where ec.entry_id = d.id and ec.deleted_at is null
+    )
+select t.public_id
+from targets t
+;
+
+-- name: ListTrash :many
+-- List the deleted entries of a workspace, only the latest version of each entry is returned along with the time it will be purged at
+with
+    trashed as (
+        select distinct on (e.origin)
+            e.id,
+            e.public_id,
+            e.origin,
+            e.name,
+            e.version,
+            e.entry_type,
+            e.filesize_bytes,
+            e.collection_id,
+            e.added_by,
+            e.created_at,
+            e.updated_at,
+            e.deleted_at
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join collection_members cm on cm.collection_id = c.id
+        join workspace_members wm on wm.workspace_id = w.id
+        where
+            cm.user_id = @user_id
+            and wm.user_id = @user_id
+            and w.slug = @workspace_slug
+            and e.deleted_at is not null
+            and c.deleted_at is null
+            and w.deleted_at is null
+        order by e.origin, e.version desc
+    )
+select
+    t.public_id,
+    t.origin,
+    t.name,
+    t.version,
+    t.entry_type as type,
+    t.filesize_bytes,
+    t.created_at,
+    t.updated_at,
+    t.deleted_at,
+    (
+        t.deleted_at + make_interval(days => coalesce(ws.trash_retention_days, 30))
+    )::timestamptz as purge_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    u.first_name as added_by_first_name,
+    u.last_name as added_by_last_name,
+    u.username as added_by_username,
+    count(*) over () as total_entries
+from trashed t
+join collections c on c.id = t.collection_id
+join users u on u.id = t.added_by
+left join workspace_settings ws on ws.workspace_id = c.workspace_id
+order by t.deleted_at desc, t.public_id desc
+limit $1
+offset $2
+;
+
+-- name: RestoreEntries :many
+-- Restore entries along with all their versions and chunks from the trash, the chunks keep their vectors so the entries are searchable again without being re-embedded. The ID of the latest version is also returned if it is still waiting to be processed
+with
+    targets as (
+        select e.public_id, e.origin, e.deleted_at
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any(@entry_public_ids::uuid[])
+            and w.slug = @workspace_slug
+            and e.deleted_at is not null
+            and c.deleted_at is null
+    ),
+    restored as (
+        update entries e
+        set deleted_at = null
+        from targets t
+        where e.origin = t.origin and e.deleted_at = t.deleted_at
+        returning e.id, t.deleted_at
+    ),
+    restored_chunks as (
+        update entry_chunks ec
+        set deleted_at = null
+        from restored r
+        where ec.entry_id = r.id and ec.deleted_at = r.deleted_at
+    )
+select t.public_id, q.entry_id as queued_entry_id
+from targets t
+left join entries_queue q
+    on q.status = 'queued'
+    and q.entry_id = (
+        select l.id
+        from entries l
+        where l.origin = t.origin
+        order by l.version desc
+        limit 1
+    )
+;
+
+-- name: PurgeEntries :many
+-- Permanently delete entries in the trash along with all their versions, the files that are not referenced by any other entry are returned so that they can be removed from the object store
+with
+    targets as (
+        select e.public_id, v.id, v.file_id
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join entries v on v.origin = e.origin
+        where
+            e.public_id = any(@entry_public_ids::uuid[])
+            and w.slug = @workspace_slug
+            and e.deleted_at is not null
+    ),
+    purged as (delete from entries e using targets t where e.id = t.id),
+    orphaned_files as (
+        select t.id, t.file_id
+        from targets t
+        where
+            t.file_id is not null
+            and not exists (
+                select 1
+                from entries o
+                where o.file_id = t.file_id and o.id not in (select id from targets)
+            )
+    )
+select t.public_id, f.file_id
+from targets t
+left join orphaned_files f on f.id = t.id
+;
+
+-- name: PurgeExpiredEntries :many
+-- Permanently delete the entries that have been in the trash for longer than the retention period of their workspace, the entries of deleted collections and workspaces are purged as well
+with
+    expired as (
+        select e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        left join workspace_settings ws on ws.workspace_id = c.workspace_id
+        where
+            -- an entry expires from the earliest of its own deletion and the deletion of its collection or workspace (nulls are ignored)
+            least(e.deleted_at, c.deleted_at, w.deleted_at)
+            < now() - make_interval(days => coalesce(ws.trash_retention_days, 30))
+        group by e.origin
+        order by min(least(e.deleted_at, c.deleted_at, w.deleted_at))
+        limit @max_entries
+    ),
+    targets as (
+        select v.id, v.public_id, v.file_id
+        from entries v
+        join expired x on x.origin = v.origin
+    ),
+    purged as (delete from entries e using targets t where e.id = t.id),
+    orphaned_files as (
+        select t.id, t.file_id
+        from targets t
+        where
+            t.file_id is not null
+            and not exists (
+                select 1
+                from entries o
+                where o.file_id = t.file_id and o.id not in (select id from targets)
+            )
+    )
+select t.public_id, f.file_id
+from targets t
+left join orphaned_files f on f.id = t.id
+;
+
+-- name: RecordFailedFileDeletions :exec
+insert into failed_file_deletions (file_id, last_error)
+select unnest(@file_ids::text[]), @last_error::text
+on conflict (file_id) do update
+set attempts = failed_file_deletions.attempts + 1, last_error = excluded.last_error
+;
+
+-- name: FindFailedFileDeletions :many
+-- the files that were attempted the longest time ago come first, so that a file that keeps failing does not hold the others back
+select file_id
+from failed_file_deletions
+order by updated_at asc
+limit @max_files::int
+;
+
+-- name: DeleteFailedFileDeletions :exec
+delete from failed_file_deletions where file_id = any(@file_ids::text[]);
+
+-- name: ArchiveEntries :many
+-- Archive entries along with all their versions, only the public IDs of the given versions that were not archived yet are returned
+with
+    targets as (
+        select e.public_id, e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any(@entry_public_ids::uuid[])
+            and w.slug = @workspace_slug
+            and e.deleted_at is null
+            and e.archived_at is null
+    ),
+    archived as (
+        update entries e
+        set archived_at = now()
+        from targets t
+        where e.origin = t.origin and e.archived_at is null
+    )
+select t.public_id
+from targets t
+;
+
+-- name: UnarchiveEntries :many
+-- Unarchive entries along with all their versions, the ID of the latest version is also returned if it is still waiting to be processed
+with
+    targets as (
+        select e.public_id, e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any(@entry_public_ids::uuid[])
+            and w.slug = @workspace_slug
+            and e.deleted_at is null
+            and e.archived_at is not null
+    ),
+    unarchived as (
+        update entries e
+        set archived_at = null
+        from targets t
+        where e.origin = t.origin
+    )
+select t.public_id, q.entry_id as queued_entry_id
+from targets t
+left join entries_queue q
+    on q.status = 'queued'
+    and q.entry_id = (
+        select l.id
+        from entries l
+        where l.origin = t.origin and l.deleted_at is null
+        order by l.version desc
+        limit 1
+    )
+;
+
+-- name: MoveEntries :many
+-- Move entries along with all their versions and comments to another collection, only the public IDs of the given versions that were not already in the collection are returned
+with recursive
+    targets as (
+        select e.public_id, e.origin
+        from entries e
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            e.public_id = any(@entry_public_ids::uuid[])
+            and w.slug = @workspace_slug
+            and e.deleted_at is null
+            and e.entry_type != 'comment'
+            and e.collection_id != @collection_id
+    ),
+    threads as (
+        select e.id
+        from entries e
+        join targets t on t.origin = e.origin
+        union all
+        -- comments follow the entry they were made on
+        select cm.id
+        from entries cm
+        join threads th on th.id = cm.parent_id
+        where cm.entry_type = 'comment'
+    ),
+    moved as (
+        update entries e
+        set collection_id = @collection_id
+        from threads th
+        where e.id = th.id
+        returning e.id
+    ),
+    untagged as (
+        -- tags belong to a collection, they are not carried over to the new one
+        delete from entry_tags et
+        using moved m
+        where et.entry_id = m.id
+    ),
+    unrelated as (
+        -- the related entries are computed again for the new collection once they are requested
+        delete from related_entries r
+        using moved m
+        where r.entry_id = m.id or r.related_entry_id = m.id
+    )
+select t.public_id, t.origin
+from targets t
+;
+
+-- name: FindLatestEntryVersions :many
+-- Find the latest version of each of the given entries in a workspace along with its processing status
+select distinct on (l.origin) sqlc.embed(l), q.status
+from entries e
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries l on l.origin = e.origin and l.deleted_at is null
+join entries_queue q on q.entry_id = l.id
+where
+    e.public_id = any(@entry_public_ids::uuid[])
+    and w.slug = @workspace_slug
+    and e.deleted_at is null
+    and e.entry_type != 'comment'
+order by l.origin, l.version desc
+;
+
+-- name: CopyEntry :one
+-- Copy a version of an entry into a collection as the first version of a new entry
+insert into entries (
+    name,
+    meta,
+    content,
+    text_content,
+    file_id,
+    entry_type,
+    checksum,
+    filesize_bytes,
+    collection_id,
+    added_by,
+    last_updated_by
+)
+select
+    s.name,
+    @meta,
+    s.content,
+    s.text_content,
+    sqlc.narg('file_id'),
+    s.entry_type,
+    s.checksum,
+    s.filesize_bytes,
+    @collection_id,
+    @user_id,
+    @user_id
+from entries s
+where s.id = @source_id
+returning *
+;
+
+-- name: ResetForeignChunkEmbeddings :execrows
+-- Reset the embeddings of the chunks that were not generated with the model of their workspace, they are embedded again with the right model
+with recursive
+    threads as (
+        select e.id
+        from entries e
+        where e.origin = any(@origins::uuid[])
+        union all
+        -- the comments of an entry are in the same workspace as the entry
+        select cm.id
+        from entries cm
+        join threads th on th.id = cm.parent_id
+        where cm.entry_type = 'comment'
+    )
+update entry_chunks ck
+set
+    semantic_vector = null,
+    embedding_model = null,
+    embedding_dimensions = null,
+    embedding_status = 'pending',
+    embedding_status_updated_at = now(),
+    embedding_error_count = 0
+from entries e
+join collections c on c.id = e.collection_id
+join workspace_settings ws on ws.workspace_id = c.workspace_id
+where
+    ck.entry_id = e.id
+    and e.id in (select th.id from threads th)
+    and ck.semantic_vector is not null
+    and ws.embedding_model is not null
+    and (
+        ck.embedding_model is distinct from ws.embedding_model
+        or ck.embedding_dimensions is distinct from ws.embedding_dimensions
+    )
 ;
 
 -- name: EnqueueEntries :copyfrom
@@ -204,7 +745,7 @@ returning eq.entry_id
 ;
 
 -- name: ResolveEntryIds :many
-select e.id, e.public_id
+select e.id, e.public_id, e.version
 from entries e
 where e.public_id = any(@entry_public_ids::uuid[]) and e.deleted_at is null
 ;
@@ -253,6 +794,8 @@ from entries_queue q
 join entries e on e.id = q.entry_id
 where
     q.attempts <= q.max_attempts
+    -- archived entries are processed once they are unarchived
+    and e.archived_at is null
     and (
         q.status in ('queued', 'failed')  -- either it hasn't been processed or it failed
         or (q.status = 'processing' and q.updated_at < now() - interval '12 hours')  -- or it has been marked as processing but hasn't been updated in the last 12 hours
@@ -325,21 +868,27 @@ where
 ;
 
 -- name: FindUnindexedChunks :many
-select id, content
-from entry_chunks
+select ck.id, ck.content
+from entry_chunks ck
+join entries e on e.id = ck.entry_id
 where
-    embedding_status = 'pending'
-    -- we will only retry failed chunks that have failed less than 5 times
-    or (embedding_status = 'failed' and embedding_error_count < 5)
-    or (
-        -- this is a special case where the chunk was marked as processing but hasn't
-        -- been updated in the last 2 hours
-        embedding_status = 'processing'
-        and embedding_status_updated_at < now() - interval '2 hours'
+    (
+        ck.embedding_status = 'pending'
+        -- we will only retry failed chunks that have failed less than 5 times
+        or (ck.embedding_status = 'failed' and ck.embedding_error_count < 5)
+        or (
+            -- this is a special case where the chunk was marked as processing but hasn't
+            -- been updated in the last 2 hours
+            ck.embedding_status = 'processing'
+            and ck.embedding_status_updated_at < now() - interval '2 hours'
+        )
     )
-    and semantic_vector is null
-    and content is not null
-    and content != ''
+    and ck.semantic_vector is null
+    and ck.content is not null
+    and ck.content != ''
+    -- the chunks of archived entries are embedded once they are unarchived
+    and e.archived_at is null
+    and e.deleted_at is null
 ;
 
 -- name: ChunkCanBeProcessed :one
@@ -347,8 +896,11 @@ where
 -- jobs, handlers need to ensure they can actually process what they have just gotten
 select count(*) > 0 as can_process
 from entry_chunks ec
+join entries e on e.id = ec.entry_id
 where
     ec.id = @chunk_id
+    -- chunks of entries archived after they were queued are skipped
+    and e.archived_at is null
     and (
         ec.embedding_status = 'pending'
         or (ec.embedding_status = 'failed' and ec.embedding_error_count < 5)
@@ -366,6 +918,8 @@ where
 update entry_chunks
 set
     semantic_vector = @semantic_vector,
+    embedding_model = @embedding_model,
+    embedding_dimensions = @embedding_dimensions,
     embedding_status = 'done',
     embedding_status_updated_at = now(),
     embedding_error_count = 0
@@ -403,8 +957,14 @@ with
             ck.chunk_index,
             q.status,
             null::float8 as text_score,
-            (@embedding <=> ck.semantic_vector)::float8 as semantic_score,
-            rank() over (order by @embedding <=> ck.semantic_vector) as rank
+            -- the `vector(1)` casts are replaced with the model's dimensions (see `queries.WithDimensions`) to match its HNSW index
+            (
+                ck.semantic_vector::vector(1) <=> sqlc.narg('embedding')::real[]::vector
+            )::float8 as semantic_score,
+            rank() over (
+                order by ck.semantic_vector::vector(1) <=> sqlc.narg('embedding')::real[]::vector
+            ) as rank,
+            null::text as headline
         from entry_chunks ck
         join entries e on e.id = ck.entry_id
         join collections c on c.id = e.collection_id
@@ -413,9 +973,27 @@ with
         join workspace_members wm on wm.workspace_id = w.id
         join collection_members cm on cm.collection_id = c.id
         where
-            q.status = 'completed'
-            and e.archived_at is null
+            q.status = any(
+                coalesce(sqlc.narg('statuses')::entry_status[], '{completed}'::entry_status[])
+            )
+            -- only the latest processed version of an entry is searched, the previous one is kept while a new one is processed
+            and e.version = (
+                select max(v.version)
+                from entries v
+                join entries_queue vq on vq.entry_id = v.id
+                where
+                    v.origin = e.origin
+                    and v.deleted_at is null
+                    and vq.status = any(
+                        coalesce(sqlc.narg('statuses')::entry_status[], '{completed}'::entry_status[])
+                    )
+            )
+            -- there is no vector when embeddings are disabled or the query only has operators
+            and sqlc.narg('embedding')::real[] is not null
             and ck.semantic_vector is not null
+            -- vectors from other models cannot be compared with the query's vector
+            and ck.embedding_model = @embedding_model
+            and ck.embedding_dimensions = @embedding_dimensions
             and (
                 sqlc.narg('workspace_public_id')::uuid is null
                 or w.public_id = @workspace_public_id
@@ -426,7 +1004,75 @@ with
             and e.deleted_at is null
             and c.deleted_at is null
             and w.deleted_at is null
-        order by @embedding <=> ck.semantic_vector
+            and (
+                sqlc.narg('entry_types')::text[] is null
+                or e.entry_type = any(sqlc.narg('entry_types')::text[])
+                -- comments are filtered by the entry they were made on once they are attributed to it
+                or e.entry_type = 'comment'
+            )
+            and (
+                (
+                    sqlc.narg('collection_ids')::uuid[] is null
+                    and sqlc.narg('collection_slugs')::text[] is null
+                )
+                or c.public_id = any(sqlc.narg('collection_ids')::uuid[])
+                or c.slug = any(sqlc.narg('collection_slugs')::text[])
+            )
+            and (
+                sqlc.narg('added_by')::text[] is null
+                or e.added_by in (
+                    select u.id from users u where u.username = any(sqlc.narg('added_by')::text[])
+                )
+            )
+            and (
+                sqlc.narg('tags')::text[] is null
+                or e.entry_type = 'comment'
+                or e.id in (
+                    select et.entry_id
+                    from entry_tags et
+                    join tags t on t.id = et.tag_id
+                    where lower(t.name) = any(sqlc.narg('tags')::text[])
+                    group by et.entry_id
+                    having count(distinct lower(t.name))
+                    = cardinality(sqlc.narg('tags')::text[])
+                )
+            )
+            and (
+                sqlc.narg('created_after')::timestamptz is null
+                or e.created_at >= sqlc.narg('created_after')::timestamptz
+            )
+            and (
+                sqlc.narg('created_before')::timestamptz is null
+                or e.created_at < sqlc.narg('created_before')::timestamptz
+            )
+            and (
+                sqlc.narg('updated_after')::timestamptz is null
+                or e.updated_at >= sqlc.narg('updated_after')::timestamptz
+            )
+            and (
+                sqlc.narg('updated_before')::timestamptz is null
+                or e.updated_at < sqlc.narg('updated_before')::timestamptz
+            )
+            and (
+                sqlc.narg('title_patterns')::text[] is null
+                or e.name ilike all(sqlc.narg('title_patterns')::text[])
+            )
+            and (
+                sqlc.narg('excluded_patterns')::text[] is null
+                or not (
+                    e.name ilike any(sqlc.narg('excluded_patterns')::text[])
+                    or coalesce(ck.content, '') ilike any(sqlc.narg('excluded_patterns')::text[])
+                )
+            )
+            -- archived entries are only returned if they were asked for
+            and (
+                case
+                    when @archived_only::boolean then e.archived_at is not null
+                    when @include_archived::boolean then true
+                    else e.archived_at is null
+                end
+            )
+        order by ck.semantic_vector::vector(1) <=> sqlc.narg('embedding')::real[]::vector
         limit $1
         offset $2
     ),
@@ -460,8 +1106,18 @@ with
                     ts_rank(
                         ck.text_vector,
                         websearch_to_tsquery(ts_regconfig(ck.language), @query)
-                    )
-            ) as rank
+                    ) desc,
+                    -- queries with only operators have nothing to rank by, recently updated entries come first
+                    case when @query::text = '' then e.updated_at end desc nulls last
+            ) as rank,
+            -- the matched words are wrapped in control characters (STX/ETX) that cannot appear in the content, they are replaced with offsets in the repository
+            ts_headline(
+                ts_regconfig(ck.language),
+                ck.content,
+                websearch_to_tsquery(ts_regconfig(ck.language), @query),
+                'StartSel=' || chr(2) || ', StopSel=' || chr(3)
+                || ', MinWords=15, MaxWords=35, MaxFragments=2, FragmentDelimiter=" ... "'
+            ) as headline
         from entry_chunks ck
         join entries e on e.id = ck.entry_id
         join collections c on c.id = e.collection_id
@@ -470,9 +1126,26 @@ with
         join workspace_members wm on wm.workspace_id = w.id
         join collection_members cm on cm.collection_id = c.id
         where
-            (ck.text_vector @@ websearch_to_tsquery(ts_regconfig(ck.language), @query))
-            and q.status = 'completed'
-            and e.archived_at is null
+            (
+                ck.text_vector @@ websearch_to_tsquery(ts_regconfig(ck.language), @query)
+                -- queries with only operators list the entries that match them, once each
+                or (@query::text = '' and ck.chunk_index = 0)
+            )
+            and q.status = any(
+                coalesce(sqlc.narg('statuses')::entry_status[], '{completed}'::entry_status[])
+            )
+            -- only the latest processed version of an entry is searched, the previous one is kept while a new one is processed
+            and e.version = (
+                select max(v.version)
+                from entries v
+                join entries_queue vq on vq.entry_id = v.id
+                where
+                    v.origin = e.origin
+                    and v.deleted_at is null
+                    and vq.status = any(
+                        coalesce(sqlc.narg('statuses')::entry_status[], '{completed}'::entry_status[])
+                    )
+            )
             and ck.text_vector is not null
             and (
                 sqlc.narg('workspace_public_id')::uuid is null
@@ -484,6 +1157,74 @@ with
             and e.deleted_at is null
             and c.deleted_at is null
             and w.deleted_at is null
+            and (
+                sqlc.narg('entry_types')::text[] is null
+                or e.entry_type = any(sqlc.narg('entry_types')::text[])
+                -- comments are filtered by the entry they were made on once they are attributed to it
+                or e.entry_type = 'comment'
+            )
+            and (
+                (
+                    sqlc.narg('collection_ids')::uuid[] is null
+                    and sqlc.narg('collection_slugs')::text[] is null
+                )
+                or c.public_id = any(sqlc.narg('collection_ids')::uuid[])
+                or c.slug = any(sqlc.narg('collection_slugs')::text[])
+            )
+            and (
+                sqlc.narg('added_by')::text[] is null
+                or e.added_by in (
+                    select u.id from users u where u.username = any(sqlc.narg('added_by')::text[])
+                )
+            )
+            and (
+                sqlc.narg('tags')::text[] is null
+                or e.entry_type = 'comment'
+                or e.id in (
+                    select et.entry_id
+                    from entry_tags et
+                    join tags t on t.id = et.tag_id
+                    where lower(t.name) = any(sqlc.narg('tags')::text[])
+                    group by et.entry_id
+                    having count(distinct lower(t.name))
+                    = cardinality(sqlc.narg('tags')::text[])
+                )
+            )
+            and (
+                sqlc.narg('created_after')::timestamptz is null
+                or e.created_at >= sqlc.narg('created_after')::timestamptz
+            )
+            and (
+                sqlc.narg('created_before')::timestamptz is null
+                or e.created_at < sqlc.narg('created_before')::timestamptz
+            )
+            and (
+                sqlc.narg('updated_after')::timestamptz is null
+                or e.updated_at >= sqlc.narg('updated_after')::timestamptz
+            )
+            and (
+                sqlc.narg('updated_before')::timestamptz is null
+                or e.updated_at < sqlc.narg('updated_before')::timestamptz
+            )
+            and (
+                sqlc.narg('title_patterns')::text[] is null
+                or e.name ilike all(sqlc.narg('title_patterns')::text[])
+            )
+            and (
+                sqlc.narg('excluded_patterns')::text[] is null
+                or not (
+                    e.name ilike any(sqlc.narg('excluded_patterns')::text[])
+                    or coalesce(ck.content, '') ilike any(sqlc.narg('excluded_patterns')::text[])
+                )
+            )
+            -- archived entries are only returned if they were asked for
+            and (
+                case
+                    when @archived_only::boolean then e.archived_at is not null
+                    when @include_archived::boolean then true
+                    else e.archived_at is null
+                end
+            )
         order by rank
         limit $1
         offset $2
@@ -519,9 +1260,512 @@ group by
     results.text_score,
     results.semantic_score,
     results.rank,
+    results.headline,
     results.status
 order by score desc
+;
+
+
+-- name: QueryWithFuzzySearch :many
+select
+    e.public_id,
+    e.name as title,
+    e.meta,
+    e.entry_type as type,
+    e.file_id,
+    e.filesize_bytes,
+    e.created_at,
+    e.updated_at,
+    e.archived_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    w.display_name as workspace_name,
+    w.public_id as workspace_id,
+    w.slug as workspace_slug,
+    ck.id as chunk_id,
+    ck.content as chunk_content,
+    ck.chunk_index,
+    q.status,
+    greatest(
+        word_similarity(@query::text, coalesce(ck.content, '')),
+        case when ck.chunk_index = 0 then word_similarity(@query::text, e.name) else 0 end
+    )::float8 as fuzzy_score
+from entry_chunks ck
+join entries e on e.id = ck.entry_id
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries_queue q on q.entry_id = e.id
+join workspace_members wm on wm.workspace_id = w.id
+join collection_members cm on cm.collection_id = c.id
+where
+    -- entry names are only matched against the first chunk so that an entry is not returned once per chunk
+    (@query::text <% ck.content or (ck.chunk_index = 0 and @query::text <% e.name))
+    and q.status = any(
+        coalesce(sqlc.narg('statuses')::entry_status[], '{completed}'::entry_status[])
+    )
+    -- only the latest processed version of an entry is searched, the previous one is kept while a new one is processed
+    and e.version = (
+        select max(v.version)
+        from entries v
+        join entries_queue vq on vq.entry_id = v.id
+        where
+            v.origin = e.origin
+            and v.deleted_at is null
+            and vq.status = any(
+                coalesce(sqlc.narg('statuses')::entry_status[], '{completed}'::entry_status[])
+            )
+    )
+    and (sqlc.narg('workspace_public_id')::uuid is null or w.public_id = @workspace_public_id)
+    and (sqlc.narg('workspace_slug')::text is null or w.slug = @workspace_slug)
+    and wm.user_id = @user_id
+    and cm.user_id = @user_id
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+    and (
+        sqlc.narg('entry_types')::text[] is null
+        or e.entry_type = any(sqlc.narg('entry_types')::text[])
+        -- comments are filtered by the entry they were made on once they are attributed to it
+        or e.entry_type = 'comment'
+    )
+    and (
+        (
+            sqlc.narg('collection_ids')::uuid[] is null
+            and sqlc.narg('collection_slugs')::text[] is null
+        )
+        or c.public_id = any(sqlc.narg('collection_ids')::uuid[])
+        or c.slug = any(sqlc.narg('collection_slugs')::text[])
+    )
+    and (
+        sqlc.narg('added_by')::text[] is null
+        or e.added_by in (
+            select u.id from users u where u.username = any(sqlc.narg('added_by')::text[])
+        )
+    )
+    and (
+        sqlc.narg('tags')::text[] is null
+        or e.entry_type = 'comment'
+        or e.id in (
+            select et.entry_id
+            from entry_tags et
+            join tags t on t.id = et.tag_id
+            where lower(t.name) = any(sqlc.narg('tags')::text[])
+            group by et.entry_id
+            having count(distinct lower(t.name))
+            = cardinality(sqlc.narg('tags')::text[])
+        )
+    )
+    and (
+        sqlc.narg('created_after')::timestamptz is null
+        or e.created_at >= sqlc.narg('created_after')::timestamptz
+    )
+    and (
+        sqlc.narg('created_before')::timestamptz is null
+        or e.created_at < sqlc.narg('created_before')::timestamptz
+    )
+    and (
+        sqlc.narg('updated_after')::timestamptz is null
+        or e.updated_at >= sqlc.narg('updated_after')::timestamptz
+    )
+    and (
+        sqlc.narg('updated_before')::timestamptz is null
+        or e.updated_at < sqlc.narg('updated_before')::timestamptz
+    )
+    and (
+        sqlc.narg('title_patterns')::text[] is null
+        or e.name ilike all(sqlc.narg('title_patterns')::text[])
+    )
+    and (
+        sqlc.narg('excluded_patterns')::text[] is null
+        or not (
+            e.name ilike any(sqlc.narg('excluded_patterns')::text[])
+            or coalesce(ck.content, '') ilike any(sqlc.narg('excluded_patterns')::text[])
+        )
+    )
+    -- archived entries are only returned if they were asked for
+    and (
+        case
+            when @archived_only::boolean then e.archived_at is not null
+            when @include_archived::boolean then true
+            else e.archived_at is null
+        end
+    )
+order by fuzzy_score desc
 limit $1
 offset $2
 ;
 
+-- name: CountSearchFacets :many
+-- Count the entries matched by a search per type and collection, an entry matched through its comments is counted as itself
+with recursive
+    matched as (
+        select e.id, e.origin, e.parent_id, e.entry_type, false as via_comment
+        from entries e
+        where
+            e.deleted_at is null
+            and (
+                e.public_id = any(@candidate_ids::uuid[])
+                or e.id in (
+                    select ck.entry_id
+                    from entry_chunks ck
+                    join entries ce on ce.id = ck.entry_id
+                    join collections c on c.id = ce.collection_id
+                    join workspaces w on w.id = c.workspace_id
+                    where
+                        ck.text_vector @@ websearch_to_tsquery(ts_regconfig(ck.language), @query)
+                        and (
+                            sqlc.narg('workspace_public_id')::uuid is null
+                            or w.public_id = @workspace_public_id
+                        )
+                        and (sqlc.narg('workspace_slug')::text is null or w.slug = @workspace_slug)
+                        and (
+                            sqlc.narg('excluded_patterns')::text[] is null
+                            or not coalesce(ck.content, '')
+                            ilike any(sqlc.narg('excluded_patterns')::text[])
+                        )
+                )
+            )
+        union
+        -- comments are followed up to the entry of their thread
+        select p.id, p.origin, p.parent_id, p.entry_type, true as via_comment
+        from matched m
+        join entries p on p.id = m.parent_id
+        where m.entry_type = 'comment'
+    )
+select
+    e.entry_type as type,
+    c.public_id as collection_id,
+    c.name as collection_name,
+    c.slug as collection_slug,
+    count(distinct e.origin) as entries
+from entries e
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries_queue q on q.entry_id = e.id
+join workspace_members wm on wm.workspace_id = w.id
+join collection_members cm on cm.collection_id = c.id
+where
+    e.entry_type != 'comment'
+    and (
+        -- queries with only operators match every entry that passes the filters
+        @query::text = ''
+        or e.id in (
+            select m.id from matched m where not m.via_comment and m.entry_type != 'comment'
+        )
+        -- comments count towards the latest version of the entry they were made on
+        or e.origin in (
+            select m.origin from matched m where m.via_comment and m.entry_type != 'comment'
+        )
+    )
+    and q.status = any(
+        coalesce(sqlc.narg('statuses')::entry_status[], '{completed}'::entry_status[])
+    )
+    and e.version = (
+        select max(v.version)
+        from entries v
+        join entries_queue vq on vq.entry_id = v.id
+        where
+            v.origin = e.origin
+            and v.deleted_at is null
+            and vq.status = any(
+                coalesce(sqlc.narg('statuses')::entry_status[], '{completed}'::entry_status[])
+            )
+    )
+    and (sqlc.narg('workspace_public_id')::uuid is null or w.public_id = @workspace_public_id)
+    and (sqlc.narg('workspace_slug')::text is null or w.slug = @workspace_slug)
+    and wm.user_id = @user_id
+    and cm.user_id = @user_id
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+    and (
+        sqlc.narg('entry_types')::text[] is null
+        or e.entry_type = any(sqlc.narg('entry_types')::text[])
+    )
+    and (
+        (
+            sqlc.narg('collection_ids')::uuid[] is null
+            and sqlc.narg('collection_slugs')::text[] is null
+        )
+        or c.public_id = any(sqlc.narg('collection_ids')::uuid[])
+        or c.slug = any(sqlc.narg('collection_slugs')::text[])
+    )
+    and (
+        sqlc.narg('added_by')::text[] is null
+        or e.added_by in (
+            select u.id from users u where u.username = any(sqlc.narg('added_by')::text[])
+        )
+    )
+    and (
+        sqlc.narg('tags')::text[] is null
+        or e.id in (
+            select et.entry_id
+            from entry_tags et
+            join tags t on t.id = et.tag_id
+            where lower(t.name) = any(sqlc.narg('tags')::text[])
+            group by et.entry_id
+            having count(distinct lower(t.name))
+            = cardinality(sqlc.narg('tags')::text[])
+        )
+    )
+    and (
+        sqlc.narg('created_after')::timestamptz is null
+        or e.created_at >= sqlc.narg('created_after')::timestamptz
+    )
+    and (
+        sqlc.narg('created_before')::timestamptz is null
+        or e.created_at < sqlc.narg('created_before')::timestamptz
+    )
+    and (
+        sqlc.narg('updated_after')::timestamptz is null
+        or e.updated_at >= sqlc.narg('updated_after')::timestamptz
+    )
+    and (
+        sqlc.narg('updated_before')::timestamptz is null
+        or e.updated_at < sqlc.narg('updated_before')::timestamptz
+    )
+    and (
+        sqlc.narg('title_patterns')::text[] is null
+        or e.name ilike all(sqlc.narg('title_patterns')::text[])
+    )
+    and (
+        sqlc.narg('excluded_patterns')::text[] is null
+        or not e.name ilike any(sqlc.narg('excluded_patterns')::text[])
+    )
+    -- archived entries are only counted if they were asked for
+    and (
+        case
+            when @archived_only::boolean then e.archived_at is not null
+            when @include_archived::boolean then true
+            else e.archived_at is null
+        end
+    )
+group by e.entry_type, c.id
+;
+
+-- name: FindEntryEmbeddingDimensions :one
+-- the dimensions of most of the entry's vectors, they can only differ while it is being re-embedded
+select ck.embedding_dimensions::int
+from entries e
+join entry_chunks ck on ck.entry_id = e.id
+where
+    (e.id = sqlc.narg('entry_id')::int or e.public_id = sqlc.narg('entry_public_id')::uuid)
+    and ck.semantic_vector is not null
+group by ck.embedding_model, ck.embedding_dimensions
+order by count(*) desc
+limit 1
+;
+
+-- name: FindRelatedEntries :many
+with
+    source_entry as (
+        select
+            e.id,
+            e.origin,
+            c.workspace_id,
+            ck.embedding_model,
+            ck.embedding_dimensions,
+            avg(ck.semantic_vector) as centroid
+        from entries e
+        join collections c on c.id = e.collection_id
+        join entry_chunks ck on ck.entry_id = e.id
+        where e.public_id = @entry_public_id and ck.semantic_vector is not null
+        group by e.id, e.origin, c.workspace_id, ck.embedding_model, ck.embedding_dimensions
+        -- the chunks of an entry can only be from different models while it is being re-embedded
+        order by count(*) desc
+        limit 1
+    ),
+    -- the closest chunks to the entry's centroid, every entry is then scored by its closest chunk
+    -- the `vector(1)` casts are replaced with the entry's dimensions (see `queries.WithDimensions`) to match their HNSW index
+    -- all the filters are applied here, the candidates would otherwise be taken by chunks of entries that are dropped afterwards
+    nearest_chunks as (
+        select
+            ck.entry_id,
+            ck.semantic_vector::vector(1) <=> (select centroid from source_entry) as distance
+        from entry_chunks ck
+        join entries e on e.id = ck.entry_id
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        join entries_queue q on q.entry_id = e.id
+        join workspace_members wm on wm.workspace_id = w.id
+        join collection_members cm on cm.collection_id = c.id
+        where
+            ck.semantic_vector is not null
+            and ck.deleted_at is null
+            -- the other versions of the entry are not related to it
+            and e.origin != (select origin from source_entry)
+            and e.entry_type != 'comment'
+            -- only the latest processed version of an entry can be related to it
+            and e.version = (
+                select max(v.version)
+                from entries v
+                join entries_queue vq on vq.entry_id = v.id
+                where
+                    v.origin = e.origin
+                    and v.deleted_at is null
+                    and vq.status = 'completed'
+            )
+            and c.workspace_id = (select workspace_id from source_entry)
+            and ck.embedding_model = (select embedding_model from source_entry)
+            and ck.embedding_dimensions = (select embedding_dimensions from source_entry)
+            and q.status = 'completed'
+            and e.archived_at is null
+            and wm.user_id = @user_id
+            and cm.user_id = @user_id
+            and e.deleted_at is null
+            and c.deleted_at is null
+            and w.deleted_at is null
+        order by ck.semantic_vector::vector(1) <=> (select centroid from source_entry)
+        limit @candidates::int
+    ),
+    related as (
+        select nc.entry_id, (1 - min(nc.distance))::float8 as similarity
+        from nearest_chunks nc
+        group by nc.entry_id
+    )
+select
+    e.public_id,
+    e.name,
+    e.entry_type as type,
+    e.file_id,
+    e.filesize_bytes,
+    e.created_at,
+    e.updated_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    w.display_name as workspace_name,
+    w.public_id as workspace_id,
+    w.slug as workspace_slug,
+    r.similarity
+from related r
+join entries e on e.id = r.entry_id
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+order by r.similarity desc
+limit @result_limit::int
+;
+
+-- name: FindCachedRelatedEntries :many
+-- Find the cached related entries of an entry, the cache stores the version that was related so the latest processed version of its entry is returned instead
+select
+    e.public_id,
+    e.name,
+    e.entry_type as type,
+    e.file_id,
+    e.filesize_bytes,
+    e.created_at,
+    e.updated_at,
+    c.name as collection_name,
+    c.public_id as collection_id,
+    c.slug as collection_slug,
+    w.display_name as workspace_name,
+    w.public_id as workspace_id,
+    w.slug as workspace_slug,
+    r.similarity
+from related_entries r
+join entries source on source.id = r.entry_id
+join entries related on related.id = r.related_entry_id
+join entries e on e.origin = related.origin
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join entries_queue q on q.entry_id = e.id
+join workspace_members wm on wm.workspace_id = w.id
+join collection_members cm on cm.collection_id = c.id
+where
+    source.public_id = @entry_public_id
+    and q.status = 'completed'
+    and e.version = (
+        select max(v.version)
+        from entries v
+        join entries_queue vq on vq.entry_id = v.id
+        where
+            v.origin = e.origin
+            and v.deleted_at is null
+            and vq.status = 'completed'
+    )
+    and e.archived_at is null
+    and wm.user_id = @user_id
+    and cm.user_id = @user_id
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+order by r.similarity desc
+limit @result_limit::int
+;
+
+-- name: DeleteRelatedEntries :exec
+delete from related_entries where entry_id = @entry_id;
+
+-- name: InsertRelatedEntries :execrows
+with
+    source_entry as (
+        select
+            e.id,
+            e.origin,
+            c.workspace_id,
+            ck.embedding_model,
+            ck.embedding_dimensions,
+            avg(ck.semantic_vector) as centroid
+        from entries e
+        join collections c on c.id = e.collection_id
+        join entry_chunks ck on ck.entry_id = e.id
+        where e.id = @entry_id and ck.semantic_vector is not null
+        group by e.id, e.origin, c.workspace_id, ck.embedding_model, ck.embedding_dimensions
+        -- the chunks of an entry can only be from different models while it is being re-embedded
+        order by count(*) desc
+        limit 1
+    ),
+    -- the cache is shared by all the members, the user-specific filters are applied when it is read
+    -- the `vector(1)` casts are replaced with the entry's dimensions, as in FindRelatedEntries
+    nearest_chunks as (
+        select
+            ck.entry_id,
+            ck.semantic_vector::vector(1) <=> (select centroid from source_entry) as distance
+        from entry_chunks ck
+        join entries e on e.id = ck.entry_id
+        join collections c on c.id = e.collection_id
+        join workspaces w on w.id = c.workspace_id
+        where
+            ck.semantic_vector is not null
+            and ck.deleted_at is null
+            and e.origin != (select origin from source_entry)
+            and e.entry_type != 'comment'
+            and e.version = (
+                select max(v.version)
+                from entries v
+                join entries_queue vq on vq.entry_id = v.id
+                where
+                    v.origin = e.origin
+                    and v.deleted_at is null
+                    and vq.status = 'completed'
+            )
+            and c.workspace_id = (select workspace_id from source_entry)
+            and ck.embedding_model = (select embedding_model from source_entry)
+            and ck.embedding_dimensions = (select embedding_dimensions from source_entry)
+            and e.deleted_at is null
+            and c.deleted_at is null
+            and w.deleted_at is null
+        order by ck.semantic_vector::vector(1) <=> (select centroid from source_entry)
+        limit @candidates::int
+    )
+insert into related_entries (entry_id, related_entry_id, similarity)
+select (select id from source_entry), nc.entry_id, (1 - min(nc.distance))::float8
+from nearest_chunks nc
+group by nc.entry_id
+order by min(nc.distance)
+limit @max_related::int
+;
+
+-- name: FindEntryEmbeddingProgress :one
+select ck.entry_id, (count(*) filter (where other.semantic_vector is null))::int as pending_chunks
+from entry_chunks ck
+join entry_chunks other on other.entry_id = ck.entry_id
+where
+    ck.id = @chunk_id
+    and other.deleted_at is null
+    and other.content is not null
+    and other.content != ''
+group by ck.entry_id
+;
@@ -0,0 +1,125 @@
+-- name: FindEntryVersion :one
+select * from entries where public_id = @entry_public_id and deleted_at is null;
+
+-- name: FindLatestEntryVersion :one
+-- Find the latest version of the entry the given version belongs to
+select *
+from entries
+where
+    origin = (select v.origin from entries v where v.public_id = @entry_public_id)
+    and deleted_at is null
+order by version desc
+limit 1
+;
+
+-- name: FindEntryVersions :many
+-- Find all the versions of the entry the given version belongs to, the latest version first
+select
+    e.public_id,
+    e.name,
+    e.version,
+    e.entry_type as type,
+    e.filesize_bytes,
+    e.created_at,
+    u.first_name as created_by_first_name,
+    u.last_name as created_by_last_name,
+    u.username as created_by_username,
+    q.status
+from entries e
+join users u on u.id = e.last_updated_by
+join entries_queue q on q.entry_id = e.id
+where
+    e.origin = (select v.origin from entries v where v.public_id = @entry_public_id)
+    and e.deleted_at is null
+order by e.version desc
+;
+
+-- name: FindEntryVersionChunks :many
+-- Find the chunks of a version of an entry in order, the chunks are not shared between versions
+select ck.id, ck.chunk_index, ck.content
+from entry_chunks ck
+where ck.entry_id = @entry_id and ck.deleted_at is null
+order by ck.chunk_index asc
+;
+
+-- name: CreateEntryVersion :one
+-- Create the next version of an entry from its latest version, every version points at the first version of the entry as its parent
+insert into entries (
+    origin,
+    parent_id,
+    version,
+    name,
+    meta,
+    content,
+    text_content,
+    file_id,
+    entry_type,
+    checksum,
+    filesize_bytes,
+    collection_id,
+    added_by,
+    last_updated_by,
+    archived_at
+)
+select
+    l.origin,
+    coalesce(l.parent_id, l.id),
+    (select max(v.version) + 1 from entries v where v.origin = l.origin),
+    @name,
+    @meta,
+    sqlc.narg('content'),
+    sqlc.narg('text_content'),
+    sqlc.narg('file_id'),
+    @entry_type,
+    sqlc.narg('checksum'),
+    @filesize_bytes,
+    l.collection_id,
+    l.added_by,
+    @user_id,
+    -- versions of an archived entry are archived along with it
+    l.archived_at
+from entries l
+where l.id = @latest_id
+returning *
+;
+
+-- name: CopyEntryTags :exec
+insert into entry_tags (entry_id, tag_id, added_by)
+select @target_entry_id, et.tag_id, et.added_by
+from entry_tags et
+where et.entry_id = @source_entry_id
+on conflict do nothing
+;
+
+-- name: CopyEntryChunks :execrows
+-- Copy the chunks of an entry along with their embeddings to another entry, the chunks are marked as valid from the given version
+insert into entry_chunks (
+    entry_id,
+    chunk_index,
+    min_version,
+    content,
+    language,
+    semantic_vector,
+    embedding_model,
+    embedding_dimensions,
+    embedding_status
+)
+select
+    @target_entry_id,
+    ck.chunk_index,
+    @min_version,
+    ck.content,
+    ck.language,
+    ck.semantic_vector,
+    ck.embedding_model,
+    ck.embedding_dimensions,
+    ck.embedding_status
+from entry_chunks ck
+where ck.entry_id = @source_entry_id and ck.deleted_at is null
+;
+
+-- name: EnqueueProcessedEntry :exec
+-- Add an entry to the queue as already processed, it is used for versions that reuse the chunks of another version
+insert into entries_queue (entry_id, payload, status)
+values (@entry_id, @payload, 'completed')
+;
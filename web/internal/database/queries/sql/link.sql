@@ -0,0 +1,199 @@
+-- name: DeleteEntryLinks :exec
+-- Delete the links written in an entry before they are replaced, only the latest version of an entry can replace its links
+delete from entry_links
+where
+    source_id = (
+        select coalesce(e.parent_id, e.id)
+        from entries e
+        where
+            e.id = @entry_id
+            and e.version = (
+                select max(v.version)
+                from entries v
+                where v.origin = e.origin and v.deleted_at is null
+            )
+    )
+;
+
+-- name: InsertEntryNameLinks :execrows
+-- Link an entry to the entries of its workspace with the given names, the names that do not match any entry are kept as unresolved links
+with
+    source as (
+        select coalesce(e.parent_id, e.id) as id, c.workspace_id
+        from entries e
+        join collections c on c.id = e.collection_id
+        where
+            e.id = @entry_id
+            and e.version = (
+                select max(v.version)
+                from entries v
+                where v.origin = e.origin and v.deleted_at is null
+            )
+    )
+insert into entry_links (workspace_id, source_id, target_id, target_name)
+select
+    s.workspace_id,
+    s.id,
+    (
+        -- the name of an entry is the name of its latest version, the oldest entry wins when several have the same name
+        select coalesce(t.parent_id, t.id)
+        from entries t
+        join collections tc on tc.id = t.collection_id
+        where
+            tc.workspace_id = s.workspace_id
+            and lower(t.name) = lower(n.name)
+            and t.entry_type != 'comment'
+            and t.deleted_at is null
+            and tc.deleted_at is null
+            and t.version = (
+                select max(v.version)
+                from entries v
+                where v.origin = t.origin and v.deleted_at is null
+            )
+        order by t.created_at asc, t.id asc
+        limit 1
+    ),
+    n.name
+from source s
+cross join unnest(@names::text[]) as n(name)
+on conflict do nothing
+;
+
+-- name: InsertEntryIdLinks :execrows
+-- Link an entry to the entries of its workspace with the given public IDs, they can be the ID of any version of an entry
+with
+    source as (
+        select coalesce(e.parent_id, e.id) as id, c.workspace_id
+        from entries e
+        join collections c on c.id = e.collection_id
+        where
+            e.id = @entry_id
+            and e.version = (
+                select max(v.version)
+                from entries v
+                where v.origin = e.origin and v.deleted_at is null
+            )
+    )
+insert into entry_links (workspace_id, source_id, target_id, target_public_id)
+select
+    s.workspace_id,
+    s.id,
+    (
+        select coalesce(t.parent_id, t.id)
+        from entries t
+        join collections tc on tc.id = t.collection_id
+        where
+            t.public_id = i.public_id
+            and tc.workspace_id = s.workspace_id
+            and t.entry_type != 'comment'
+    ),
+    i.public_id
+from source s
+cross join unnest(@public_ids::uuid[]) as i(public_id)
+on conflict do nothing
+;
+
+-- name: FindLinkSources :many
+-- Find the latest version of the given entries with the content their links are written in
+select distinct on (e.origin) e.id, e.content
+from entries e
+where e.origin = any(@origins::uuid[]) and e.deleted_at is null and e.entry_type != 'comment'
+order by e.origin, e.version desc
+;
+
+-- name: ResolveEntryLinks :execrows
+-- Point the unresolved links to the names of the given entries at them, it is done whenever entries are created or renamed
+update entry_links l
+set target_id = coalesce(e.parent_id, e.id)
+from entries e
+join collections c on c.id = e.collection_id
+where
+    e.public_id = any(@entry_public_ids::uuid[])
+    and e.entry_type != 'comment'
+    and e.deleted_at is null
+    and l.workspace_id = c.workspace_id
+    and l.target_id is null
+    and lower(l.target_name) = lower(e.name)
+;
+
+-- name: FindBacklinks :many
+-- Find the latest version of the entries that link to the entry the given version belongs to, only the entries in the collections the user is a member of are returned
+select
+    s.public_id,
+    s.name,
+    s.entry_type as type,
+    c.public_id as collection_id,
+    c.name as collection_name,
+    c.slug as collection_slug,
+    s.created_at,
+    s.updated_at
+from entries s
+join collections c on c.id = s.collection_id
+join collection_members cm on cm.collection_id = c.id
+where
+    coalesce(s.parent_id, s.id) in (
+        select l.source_id
+        from entry_links l
+        where
+            l.target_id = (
+                select coalesce(e.parent_id, e.id) from entries e where e.public_id = @entry_public_id
+            )
+    )
+    and s.entry_type != 'comment'
+    and s.deleted_at is null
+    and s.version = (
+        select max(v.version)
+        from entries v
+        where v.origin = s.origin and v.deleted_at is null
+    )
+    and cm.user_id = @user_id
+    and c.deleted_at is null
+order by lower(s.name) asc, s.id asc
+;
+
+-- name: FindOutgoingLinks :many
+-- Find the latest version of the entries linked to from the entry the given version belongs to, only the entries in the collections the user is a member of are returned
+select
+    t.public_id,
+    t.name,
+    t.entry_type as type,
+    c.public_id as collection_id,
+    c.name as collection_name,
+    c.slug as collection_slug,
+    t.created_at,
+    t.updated_at
+from entries t
+join collections c on c.id = t.collection_id
+join collection_members cm on cm.collection_id = c.id
+where
+    coalesce(t.parent_id, t.id) in (
+        select l.target_id
+        from entry_links l
+        where
+            l.source_id = (
+                select coalesce(e.parent_id, e.id) from entries e where e.public_id = @entry_public_id
+            )
+    )
+    and t.entry_type != 'comment'
+    and t.deleted_at is null
+    and t.version = (
+        select max(v.version)
+        from entries v
+        where v.origin = t.origin and v.deleted_at is null
+    )
+    and cm.user_id = @user_id
+    and c.deleted_at is null
+order by lower(t.name) asc, t.id asc
+;
+
+-- name: FindUnresolvedLinks :many
+-- Find the links written in an entry that do not point at any entry yet, in the order they were written
+select l.target_name, l.target_public_id
+from entry_links l
+where
+    l.source_id = (
+        select coalesce(e.parent_id, e.id) from entries e where e.public_id = @entry_public_id
+    )
+    and l.target_id is null
+order by l.id asc
+;
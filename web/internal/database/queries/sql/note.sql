@@ -0,0 +1,46 @@
+-- name: CreateNote :one
+-- Notes are markdown entries written in Hubble instead of being uploaded, they have no file and their content is the markdown source
+insert into entries (
+    name,
+    meta,
+    content,
+    text_content,
+    version,
+    entry_type,
+    filesize_bytes,
+    collection_id,
+    added_by,
+    last_updated_by
+)
+values (
+    @name,
+    @meta,
+    @content,
+    @text_content,
+    1,
+    'markdown',
+    @filesize_bytes,
+    @collection_id,
+    @user_id,
+    @user_id
+)
+returning *
+;
+
+-- name: ReuseChunkEmbeddings :execrows
+-- Copy the embeddings of the chunks of another version that have the same content, only the chunks that changed are left to be embedded
+update entry_chunks ck
+set
+    semantic_vector = p.semantic_vector,
+    embedding_model = p.embedding_model,
+    embedding_dimensions = p.embedding_dimensions,
+    embedding_status = 'done'
+from entry_chunks p
+where
+    ck.entry_id = @entry_id
+    and p.entry_id = @source_entry_id
+    and p.content = ck.content
+    and p.embedding_status = 'done'
+    and p.semantic_vector is not null
+    and p.deleted_at is null
+;
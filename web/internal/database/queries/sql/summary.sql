@@ -0,0 +1,67 @@
+-- name: FindEntrySummarySource :one
+-- Find an entry with the version of its current summaries and whether its workspace opted into summaries
+select
+    e.id,
+    e.name,
+    e.version,
+    e.archived_at,
+    coalesce(ws.summaries_enabled, false)::bool as summaries_enabled,
+    es.entry_version as summary_version
+from entries e
+join collections c on c.id = e.collection_id
+left join workspace_settings ws on ws.workspace_id = c.workspace_id
+left join entry_summaries es on es.entry_id = e.id
+where e.id = @entry_id and e.deleted_at is null
+;
+
+-- name: FindEntrySummaryChunks :many
+select content
+from entry_chunks
+where
+    entry_id = @entry_id
+    and deleted_at is null
+    and content is not null
+    and content != ''
+order by chunk_index asc
+;
+
+-- name: UpsertEntrySummary :exec
+insert into entry_summaries (entry_id, entry_version, summary_short, summary_long, chat_model)
+values (@entry_id, @entry_version, @summary_short, @summary_long, @chat_model)
+on conflict (entry_id) do update
+set
+    entry_version = excluded.entry_version,
+    summary_short = excluded.summary_short,
+    summary_long = excluded.summary_long,
+    chat_model = excluded.chat_model
+;
+
+-- name: FindUnsummarisedEntries :many
+-- Find the processed entries of the workspaces that opted into summaries whose summaries are missing or outdated, a page at a time
+-- only the latest version of an entry is summarised, comments and archived entries are skipped
+select e.id
+from entries e
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join workspace_settings ws on ws.workspace_id = w.id
+join entries_queue q on q.entry_id = e.id
+left join entry_summaries es on es.entry_id = e.id
+where
+    ws.summaries_enabled
+    and q.status = 'completed'
+    and (sqlc.narg('workspace_id')::integer is null or w.id = sqlc.narg('workspace_id')::integer)
+    and (es.entry_id is null or es.entry_version != e.version)
+    and e.entry_type != 'comment'
+    and e.version = (
+        select max(v.version)
+        from entries v
+        where v.origin = e.origin and v.deleted_at is null
+    )
+    and e.id > @after_id::int
+    and e.archived_at is null
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+order by e.id asc
+limit @page_size::int
+;
@@ -0,0 +1,130 @@
+-- name: CreateTag :one
+insert into tags (name, description, color, collection_id, created_by)
+values (@name, @description, @color, @collection_id, @created_by)
+returning *;
+
+-- name: TagNameExists :one
+select count(id) > 0 as exists
+from tags
+where
+    collection_id = @collection_id
+    and lower(name) = lower(@name)
+    and (sqlc.narg('exclude_id')::integer is null or id != sqlc.narg('exclude_id')::integer)
+;
+
+-- name: FindTag :one
+select *
+from tags
+where public_id = @public_id and collection_id = @collection_id
+;
+
+-- name: FindCollectionTags :many
+-- Find the tags of a collection with the number of entries they are assigned to
+select
+    t.id,
+    t.public_id,
+    t.name,
+    t.description,
+    t.color,
+    t.created_at,
+    t.updated_at,
+    count(e.id) as entry_count
+from tags t
+left join entry_tags et on et.tag_id = t.id
+-- tags are copied onto every version, only the latest version of an entry is counted
+left join entries e
+    on e.id = et.entry_id
+    and e.deleted_at is null
+    and e.version = (
+        select max(v.version) from entries v where v.origin = e.origin and v.deleted_at is null
+    )
+where t.collection_id = @collection_id
+group by t.id
+order by lower(t.name) asc
+;
+
+-- name: UpdateTag :one
+update tags
+set name = case
+        when sqlc.narg('name')::varchar is not null then @name::varchar
+        else name
+    end,
+    description = case
+        when sqlc.narg('description')::text is not null then @description::text
+        else description
+    end,
+    color = case
+        when sqlc.narg('color')::varchar is not null then @color::varchar
+        else color
+    end
+where id = @id
+returning *;
+
+-- name: DeleteTag :exec
+delete from tags where id = @id;
+
+-- name: TagEntries :one
+-- Assign the tags to every version of the entries, entries outside the tags' collection are skipped and the new assignments are counted once per entry
+with
+    tagged as (
+        insert into entry_tags (entry_id, tag_id, added_by)
+        select distinct v.id, t.id, @added_by::integer
+        from entries e
+        join entries v on v.origin = e.origin and v.deleted_at is null
+        join tags t on t.collection_id = v.collection_id
+        where
+            t.collection_id = @collection_id
+            and t.public_id = any(@tag_ids::uuid[])
+            and e.public_id = any(@entry_ids::uuid[])
+            and e.deleted_at is null
+        on conflict (entry_id, tag_id) do nothing
+        returning entry_id, tag_id
+    )
+select count(distinct (e.origin, tg.tag_id))
+from tagged tg
+join entries e on e.id = tg.entry_id
+;
+
+-- name: UntagEntries :one
+-- Remove the tags from every version of the entries, the removed assignments are counted once per entry
+with
+    untagged as (
+        delete from entry_tags et
+        using entries e, entries v, tags t
+        where
+            v.origin = e.origin
+            and et.entry_id = v.id
+            and et.tag_id = t.id
+            and t.collection_id = @collection_id
+            and t.public_id = any(@tag_ids::uuid[])
+            and e.public_id = any(@entry_ids::uuid[])
+        returning et.entry_id, et.tag_id
+    )
+select count(distinct (e.origin, u.tag_id))
+from untagged u
+join entries e on e.id = u.entry_id
+;
+
+-- name: FindEntriesTags :many
+select et.entry_id, t.public_id, t.name, t.color
+from entry_tags et
+join tags t on t.id = et.tag_id
+where et.entry_id = any(@entry_ids::integer[])
+order by et.entry_id, lower(t.name) asc
+;
+
+-- name: FindTagByName :one
+select *
+from tags
+where collection_id = @collection_id and lower(name) = lower(@name)
+;
+
+-- name: AddEntryTag :exec
+-- Assign the tag to every version of the entry
+insert into entry_tags (entry_id, tag_id, added_by)
+select v.id, @tag_id, @added_by
+from entries e
+join entries v on v.origin = e.origin and v.deleted_at is null
+where e.id = @entry_id
+on conflict (entry_id, tag_id) do nothing
+;
@@ -0,0 +1,134 @@
+-- name: FindTagSuggestionSource :one
+-- Find an entry with its summary, the version it was last classified at and its workspace's tag suggestion settings
+select
+    e.id,
+    e.name,
+    e.version,
+    e.collection_id,
+    e.added_by,
+    coalesce(ws.tag_suggestion_mode, 'off')::tag_suggestion_mode as tag_suggestion_mode,
+    coalesce(ws.tag_auto_apply_threshold, 0.8)::float8 as tag_auto_apply_threshold,
+    es.summary_long,
+    coalesce(tsr.entry_version, 0)::integer as suggested_version
+from entries e
+join collections c on c.id = e.collection_id
+left join workspace_settings ws on ws.workspace_id = c.workspace_id
+left join entry_summaries es on es.entry_id = e.id
+left join tag_suggestion_runs tsr on tsr.entry_id = e.id
+where e.id = @entry_id and e.deleted_at is null
+;
+
+-- name: FindRejectedTagSuggestions :many
+-- Find the most recently rejected suggestions of a collection, they are used as negative examples
+select e.name as entry_name, ts.name
+from tag_suggestions ts
+join entries e on e.id = ts.entry_id
+where
+    e.collection_id = @collection_id
+    and ts.status = 'rejected'
+    and e.deleted_at is null
+order by ts.updated_at desc
+limit @max_examples::integer
+;
+
+-- name: UpsertTagSuggestion :one
+-- Create a suggestion or refresh its confidence, suggestions that have already been accepted or rejected are left untouched and no row is returned
+insert into tag_suggestions (entry_id, name, confidence, entry_version)
+values (@entry_id, @name, @confidence, @entry_version)
+on conflict (entry_id, lower(name)) do update
+set
+    confidence = excluded.confidence,
+    entry_version = excluded.entry_version
+where tag_suggestions.status = 'pending'
+returning *
+;
+
+-- name: UpsertTagSuggestionRun :exec
+insert into tag_suggestion_runs (entry_id, entry_version, chat_model)
+values (@entry_id, @entry_version, @chat_model)
+on conflict (entry_id) do update
+set
+    entry_version = excluded.entry_version,
+    chat_model = excluded.chat_model
+;
+
+-- name: FindTagSuggestions :many
+-- Find the suggestions of a collection's entries, optionally for a single entry and with a single status
+select
+    ts.public_id,
+    ts.name,
+    ts.confidence,
+    ts.status,
+    ts.created_at,
+    e.public_id as entry_public_id,
+    e.name as entry_name,
+    t.public_id as tag_public_id
+from tag_suggestions ts
+join entries e on e.id = ts.entry_id
+left join tags t on t.id = ts.tag_id
+where
+    e.collection_id = @collection_id
+    and e.deleted_at is null
+    and (sqlc.narg('entry_id')::uuid is null or e.public_id = sqlc.narg('entry_id')::uuid)
+    and (sqlc.narg('status')::tag_suggestion_status is null or ts.status = sqlc.narg('status')::tag_suggestion_status)
+order by e.id desc, ts.confidence desc
+;
+
+-- name: FindPendingTagSuggestions :many
+-- Find the pending suggestions of a collection by their public IDs
+select ts.id, ts.entry_id, ts.name
+from tag_suggestions ts
+join entries e on e.id = ts.entry_id
+where
+    e.collection_id = @collection_id
+    and ts.public_id = any(@suggestion_ids::uuid[])
+    and ts.status = 'pending'
+    and e.deleted_at is null
+;
+
+-- name: AcceptTagSuggestion :exec
+update tag_suggestions
+set status = 'accepted', tag_id = @tag_id, reviewed_by = sqlc.narg('reviewed_by')
+where id = @id
+;
+
+-- name: RejectTagSuggestions :execrows
+update tag_suggestions ts
+set status = 'rejected', reviewed_by = @reviewed_by
+from entries e
+where
+    e.id = ts.entry_id
+    and e.collection_id = @collection_id
+    and ts.public_id = any(@suggestion_ids::uuid[])
+    and ts.status = 'pending'
+;
+
+-- name: FindUnclassifiedEntries :many
+-- Find the processed entries of the workspaces with tag suggestions enabled that have not been classified at their current version, a page at a time
+-- only the latest version of an entry is classified, comments and archived entries are skipped
+select e.id
+from entries e
+join collections c on c.id = e.collection_id
+join workspaces w on w.id = c.workspace_id
+join workspace_settings ws on ws.workspace_id = w.id
+join entries_queue q on q.entry_id = e.id
+left join tag_suggestion_runs tsr on tsr.entry_id = e.id
+where
+    ws.tag_suggestion_mode != 'off'
+    and q.status = 'completed'
+    and (sqlc.narg('workspace_id')::integer is null or w.id = sqlc.narg('workspace_id')::integer)
+    and (tsr.entry_id is null or tsr.entry_version != e.version)
+    and e.entry_type != 'comment'
+    and e.version = (
+        select max(v.version)
+        from entries v
+        where v.origin = e.origin and v.deleted_at is null
+    )
+    and e.id > @after_id::int
+    and e.archived_at is null
+    and e.deleted_at is null
+    and c.deleted_at is null
+    and w.deleted_at is null
+order by e.id asc
+limit @page_size::int
+;
@@ -288,3 +288,42 @@ update workspaces
 set deleted_at = now()
 where public_id = @workspace_id and deleted_at is null;
 
+
+-- name: FindWorkspaceSettings :one
+select *
+from workspace_settings
+where workspace_id = $1
+;
+
+-- name: UpsertWorkspaceSettings :one
+insert into workspace_settings (
+    workspace_id,
+    rrf_k,
+    rrf_semantic_weight,
+    rrf_full_text_weight,
+    summaries_enabled,
+    tag_suggestion_mode,
+    tag_auto_apply_threshold,
+    trash_retention_days
+)
+values (
+    @workspace_id,
+    @rrf_k,
+    @rrf_semantic_weight,
+    @rrf_full_text_weight,
+    @summaries_enabled,
+    @tag_suggestion_mode,
+    @tag_auto_apply_threshold,
+    @trash_retention_days
+)
+on conflict (workspace_id) do update
+set
+    rrf_k = excluded.rrf_k,
+    rrf_semantic_weight = excluded.rrf_semantic_weight,
+    rrf_full_text_weight = excluded.rrf_full_text_weight,
+    summaries_enabled = excluded.summaries_enabled,
+    tag_suggestion_mode = excluded.tag_suggestion_mode,
+    tag_auto_apply_threshold = excluded.tag_auto_apply_threshold,
+    trash_retention_days = excluded.trash_retention_days
+returning *
+;
@@ -467,6 +467,32 @@ func (q *Queries) FindWorkspaceMemeber(ctx context.Context, arg FindWorkspaceMem
 	return i, err
 }
 
+const findWorkspaceSettings = `-- name: FindWorkspaceSettings :one
+select workspace_id, rrf_k, rrf_semantic_weight, rrf_full_text_weight, created_at, updated_at, embedding_model, embedding_dimensions, summaries_enabled, tag_suggestion_mode, tag_auto_apply_threshold, trash_retention_days
+from workspace_settings
+where workspace_id = $1
+`
+
+func (q *Queries) FindWorkspaceSettings(ctx context.Context, workspaceID int32) (WorkspaceSetting, error) {
+	row := q.db.QueryRow(ctx, findWorkspaceSettings, workspaceID)
+	var i WorkspaceSetting
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.RrfK,
+		&i.RrfSemanticWeight,
+		&i.RrfFullTextWeight,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmbeddingModel,
+		&i.EmbeddingDimensions,
+		&i.SummariesEnabled,
+		&i.TagSuggestionMode,
+		&i.TagAutoApplyThreshold,
+		&i.TrashRetentionDays,
+	)
+	return i, err
+}
+
 const findWorkspaceWithMembershipStatus = `-- name: FindWorkspaceWithMembershipStatus :one
 select
     w.id, w.public_id, w.display_name, w.owner_id, w.description, w.avatar_id, w.enable_public_indexing, w.invite_only, w.created_at, w.updated_at, w.deleted_at, w.slug,
@@ -760,6 +786,79 @@ func (q *Queries) UpsertWorkspaceInvite(ctx context.Context, arg UpsertWorkspace
 	return i, err
 }
 
+const upsertWorkspaceSettings = `-- name: UpsertWorkspaceSettings :one
+insert into workspace_settings (
+    workspace_id,
+    rrf_k,
+    rrf_semantic_weight,
+    rrf_full_text_weight,
+    summaries_enabled,
+    tag_suggestion_mode,
+    tag_auto_apply_threshold,
+    trash_retention_days
+)
+values (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+on conflict (workspace_id) do update
+set
+    rrf_k = excluded.rrf_k,
+    rrf_semantic_weight = excluded.rrf_semantic_weight,
+    rrf_full_text_weight = excluded.rrf_full_text_weight,
+    summaries_enabled = excluded.summaries_enabled,
+    tag_suggestion_mode = excluded.tag_suggestion_mode,
+    tag_auto_apply_threshold = excluded.tag_auto_apply_threshold,
+    trash_retention_days = excluded.trash_retention_days
+returning workspace_id, rrf_k, rrf_semantic_weight, rrf_full_text_weight, created_at, updated_at, embedding_model, embedding_dimensions, summaries_enabled, tag_suggestion_mode, tag_auto_apply_threshold, trash_retention_days
+`
+
+type UpsertWorkspaceSettingsParams struct {
+	WorkspaceID           int32             `json:"workspace_id"`
+	RrfK                  int32             `json:"rrf_k"`
+	RrfSemanticWeight     float64           `json:"rrf_semantic_weight"`
+	RrfFullTextWeight     float64           `json:"rrf_full_text_weight"`
+	SummariesEnabled      bool              `json:"summaries_enabled"`
+	TagSuggestionMode     TagSuggestionMode `json:"tag_suggestion_mode"`
+	TagAutoApplyThreshold float64           `json:"tag_auto_apply_threshold"`
+	TrashRetentionDays    int32             `json:"trash_retention_days"`
+}
+
+func (q *Queries) UpsertWorkspaceSettings(ctx context.Context, arg UpsertWorkspaceSettingsParams) (WorkspaceSetting, error) {
+	row := q.db.QueryRow(ctx, upsertWorkspaceSettings,
+		arg.WorkspaceID,
+		arg.RrfK,
+		arg.RrfSemanticWeight,
+		arg.RrfFullTextWeight,
+		arg.SummariesEnabled,
+		arg.TagSuggestionMode,
+		arg.TagAutoApplyThreshold,
+		arg.TrashRetentionDays,
+	)
+	var i WorkspaceSetting
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.RrfK,
+		&i.RrfSemanticWeight,
+		&i.RrfFullTextWeight,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmbeddingModel,
+		&i.EmbeddingDimensions,
+		&i.SummariesEnabled,
+		&i.TagSuggestionMode,
+		&i.TagAutoApplyThreshold,
+		&i.TrashRetentionDays,
+	)
+	return i, err
+}
+
 const workspaceMemberExists = `-- name: WorkspaceMemberExists :one
 select (count(wm.id) > 0) as exists
 from workspace_members wm
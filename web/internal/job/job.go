@@ -9,7 +9,7 @@ import (
 
 //go:generate go tool github.com/abice/go-enum --marshal
 
-// ENUM(entry,chunk_embedding,entry_chunk_embedding)
+// ENUM(entry,chunk_embedding,entry_chunk_embedding,entry_summary,entry_tag_suggestion)
 type JobType string
 
 type Job interface {
@@ -31,6 +31,16 @@ type (
 	EntryChunkEmbeddingJob struct {
 		Entries []pgtype.UUID `json:"entries"`
 	}
+
+	// EntrySummaryJob summarises a processed entry, entries that already have summaries for their version are skipped
+	EntrySummaryJob struct {
+		ID int32 `json:"entry_id"`
+	}
+
+	// EntryTagSuggestionJob suggests tags for a processed entry, entries that were already classified at their version are skipped
+	EntryTagSuggestionJob struct {
+		ID int32 `json:"entry_id"`
+	}
 )
 
 func (e *EntryJob) Type() JobType {
@@ -73,3 +83,19 @@ func (s *EntryChunkEmbeddingJob) Bytes() []byte {
 
 	return b
 }
+
+func (s *EntrySummaryJob) Type() JobType {
+	return JobTypeEntrySummary
+}
+
+func (s *EntrySummaryJob) Bytes() []byte {
+	return fmt.Appendf(nil, `{"entry_id":%d}`, s.ID)
+}
+
+func (s *EntryTagSuggestionJob) Type() JobType {
+	return JobTypeEntryTagSuggestion
+}
+
+func (s *EntryTagSuggestionJob) Bytes() []byte {
+	return fmt.Appendf(nil, `{"entry_id":%d}`, s.ID)
+}
@@ -18,6 +18,10 @@ const (
 	JobTypeChunkEmbedding JobType = "chunk_embedding"
 	// JobTypeEntryChunkEmbedding is a JobType of type entry_chunk_embedding.
 	JobTypeEntryChunkEmbedding JobType = "entry_chunk_embedding"
+	// JobTypeEntrySummary is a JobType of type entry_summary.
+	JobTypeEntrySummary JobType = "entry_summary"
+	// JobTypeEntryTagSuggestion is a JobType of type entry_tag_suggestion.
+	JobTypeEntryTagSuggestion JobType = "entry_tag_suggestion"
 )
 
 var ErrInvalidJobType = errors.New("not a valid JobType")
@@ -38,6 +42,8 @@ var _JobTypeValue = map[string]JobType{
 	"entry":                 JobTypeEntry,
 	"chunk_embedding":       JobTypeChunkEmbedding,
 	"entry_chunk_embedding": JobTypeEntryChunkEmbedding,
+	"entry_summary":         JobTypeEntrySummary,
+	"entry_tag_suggestion":  JobTypeEntryTagSuggestion,
 }
 
 // ParseJobType attempts to convert a string to a JobType.
@@ -31,6 +31,9 @@ const (
 	ColUserInvite      = "user_invite"
 
 	ColLinkMetadata = "url_metadata"
+
+	ColEmbeddingCache     = "embedding_cache"
+	ColEmbeddingCacheSlot = "embedding_cache_slot" // The cache is a ring of slots, each slot holds the key of the embedding stored in it so that it can be evicted when the slot is reused
 )
 
 // Keys
@@ -89,4 +92,17 @@ var (
 		url = lib.Slugify(url)
 		return Key(NamespaceEntry, ColLinkMetadata, url)
 	}
+
+	KeyEmbeddingCache = func(cacheKey string) KeyContainer {
+		return Key(NamespaceSystem, ColEmbeddingCache, cacheKey)
+	}
+
+	KeyEmbeddingCacheSlot = func(slot int) KeyContainer {
+		return Key(NamespaceSystem, ColEmbeddingCacheSlot, strconv.Itoa(slot))
+	}
+
+	// KeyEmbeddingCacheCursor holds the next slot of the embedding cache ring
+	KeyEmbeddingCacheCursor = func() KeyContainer {
+		return Key(NamespaceSystem, ColEmbeddingCacheSlot, "cursor")
+	}
 )
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+)
+
+type (
+	// EmbeddingModel identifies the model that produced a semantic vector, vectors can only be compared with vectors from the same model and dimensions
+	EmbeddingModel struct {
+		Name       string `json:"name"`
+		Dimensions int32  `json:"dimensions"`
+	}
+
+	// EmbeddingMigration tracks the re-embedding of a workspace's chunks with a new model
+	EmbeddingMigration struct {
+		ID          int32 `json:"-"`
+		WorkspaceID int32 `json:"-"`
+		LastChunkID int32 `json:"-"`
+
+		FromModel EmbeddingModel                   `json:"from_model"`
+		ToModel   EmbeddingModel                   `json:"to_model"`
+		Status    queries.EmbeddingMigrationStatus `json:"status"     mirror:"type:'running' | 'completed' | 'failed' | 'cancelled'"`
+
+		TotalChunks     int32 `json:"total_chunks"`
+		ProcessedChunks int32 `json:"processed_chunks"`
+		// Progress is the percentage of the chunks that have been re-embedded
+		Progress float64 `json:"progress"`
+
+		LastError   string     `json:"last_error"   mirror:"optional:true"`
+		StartedAt   time.Time  `json:"started_at"`
+		CompletedAt *time.Time `json:"completed_at" mirror:"optional:true"`
+		UpdatedAt   time.Time  `json:"updated_at"`
+	}
+)
+
+// IsZero returns true if the model has not been set
+func (m EmbeddingModel) IsZero() bool {
+	return m.Name == "" || m.Dimensions <= 0
+}
+
+// Equal returns true if both models produce comparable vectors
+func (m EmbeddingModel) Equal(other EmbeddingModel) bool {
+	return m.Name == other.Name && m.Dimensions == other.Dimensions
+}
+
+func (m *EmbeddingMigration) From(migration *queries.EmbeddingMigration) *EmbeddingMigration {
+	*m = EmbeddingMigration{
+		ID:          migration.ID,
+		WorkspaceID: migration.WorkspaceID,
+		LastChunkID: migration.LastChunkID,
+		FromModel: EmbeddingModel{
+			Name:       migration.FromModel,
+			Dimensions: migration.FromDimensions,
+		},
+		ToModel: EmbeddingModel{
+			Name:       migration.ToModel,
+			Dimensions: migration.ToDimensions,
+		},
+		Status:          migration.Status,
+		TotalChunks:     migration.TotalChunks,
+		ProcessedChunks: migration.ProcessedChunks,
+		Progress:        embeddingMigrationProgress(migration),
+		LastError:       migration.LastError.String,
+		StartedAt:       migration.CreatedAt.Time,
+		CompletedAt:     nil,
+		UpdatedAt:       migration.UpdatedAt.Time,
+	}
+
+	if migration.CompletedAt.Valid {
+		m.CompletedAt = &migration.CompletedAt.Time
+	}
+
+	return m
+}
+
+func embeddingMigrationProgress(migration *queries.EmbeddingMigration) float64 {
+	if migration.Status == queries.EmbeddingMigrationStatusCompleted {
+		return 100
+	}
+
+	if migration.TotalChunks <= 0 {
+		return 0
+	}
+
+	// Chunks added while the migration is running are re-embedded too, so the count can go past the total
+	return min(float64(migration.ProcessedChunks)/float64(migration.TotalChunks)*100, 99)
+}
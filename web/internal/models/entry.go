@@ -20,6 +20,17 @@ const (
 	SearchTypeFuzzy    SearchSource = "fuzzy"
 )
 
+type EntryChunkChangeStatus string
+
+const (
+	// ChunkUnchanged is a chunk with the same content in both versions
+	ChunkUnchanged EntryChunkChangeStatus = "unchanged"
+	// ChunkAdded is a chunk that only exists in the newer version
+	ChunkAdded EntryChunkChangeStatus = "added"
+	// ChunkRemoved is a chunk that only exists in the older version
+	ChunkRemoved EntryChunkChangeStatus = "removed"
+)
+
 type (
 	CreatedEntry struct {
 		ID         pgtype.UUID        `json:"id"`
@@ -93,6 +104,87 @@ type (
 		Workspace  EntryRelation `json:"workspace"`
 
 		Metadata any `json:"metadata" mirror:"type:import('./types').FileMetadata | import('./types').Metadata"`
+
+		Tags []EntryTag `json:"tags"`
+
+		// Summary is only set for entries of workspaces that opted into summaries, once they have been summarised
+		Summary *EntrySummary `json:"summary" mirror:"optional:true"`
+	}
+
+	// EntrySummary is the summary of an entry generated by the chat model
+	EntrySummary struct {
+		// Short is a one or two sentences summary of the entry
+		Short string `json:"short"`
+		// Long is a few paragraphs covering the main points of the entry
+		Long string `json:"long"`
+		// Version is the version of the entry the summary was generated from
+		Version int32 `json:"version"`
+	}
+
+	// EntryVersion is a single version of an entry, every version is stored as a separate entry with the same origin
+	EntryVersion struct {
+		ID            pgtype.UUID         `json:"id"             mirror:"type:string"`
+		Name          string              `json:"name"`
+		Version       int32               `json:"version"`
+		Type          document.EntryType  `json:"type"           mirror:"type:'link' | 'audio' | 'video' | 'image' | 'pdf' | 'interchange' | 'epub' | 'word_document' | 'presentation' | 'spreadsheet' | 'html' | 'markdown' | 'plain_text' | 'archive' | 'code' | 'comment' | 'other'"`
+		FilesizeBytes int64               `json:"filesize_bytes"`
+		Status        queries.EntryStatus `json:"status"         mirror:"type:'queued' | 'processing' | 'completed' | 'failed' | 'canceled' | 'paused'"`
+		// IsLatest is true for the version that is listed and searched
+		IsLatest bool `json:"is_latest"`
+		// CreatedBy is the user who uploaded, refreshed or restored the version
+		CreatedBy EntryAddedBy `json:"created_by"`
+		CreatedAt time.Time    `json:"created_at"`
+	}
+
+	// TrashedEntry is the latest version of a deleted entry, it can be restored until it is purged
+	TrashedEntry struct {
+		PublicID      pgtype.UUID        `json:"id"             mirror:"type:string"`
+		OriginID      pgtype.UUID        `json:"origin"         mirror:"type:string"`
+		Name          string             `json:"name"`
+		Version       int32              `json:"version"`
+		Type          document.EntryType `json:"type"           mirror:"type:'link' | 'audio' | 'video' | 'image' | 'pdf' | 'interchange' | 'epub' | 'word_document' | 'presentation' | 'spreadsheet' | 'html' | 'markdown' | 'plain_text' | 'archive' | 'code' | 'comment' | 'other'"`
+		FilesizeBytes int64              `json:"filesize_bytes"`
+
+		AddedBy    EntryAddedBy  `json:"added_by"`
+		Collection EntryRelation `json:"collection"`
+
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+		DeletedAt time.Time `json:"deleted_at"`
+		// PurgeAt is when the entry is deleted for good, based on the retention period of the workspace
+		PurgeAt time.Time `json:"purge_at"`
+	}
+
+	// Comment is a comment on an entry, the replies to a comment are nested under it and ReplyTo is null for the comments made on the entry itself
+	Comment struct {
+		ID      pgtype.UUID  `json:"id"       mirror:"type:string"`
+		ReplyTo pgtype.UUID  `json:"reply_to" mirror:"type:string | null"`
+		Content string       `json:"content"`
+		Author  EntryAddedBy `json:"author"`
+		Replies []Comment    `json:"replies"`
+
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// EntryChunkChange is what happened to a chunk of an entry between two of its versions
+	EntryChunkChange struct {
+		Status EntryChunkChangeStatus `json:"status" mirror:"type:'unchanged' | 'added' | 'removed'"`
+		// FromIndex is the index of the chunk in the older version, -1 for added chunks
+		FromIndex int32 `json:"from_index"`
+		// ToIndex is the index of the chunk in the newer version, -1 for removed chunks
+		ToIndex int32 `json:"to_index"`
+		// Content is empty for unchanged chunks and for the changes past the size limit of the diff
+		Content string `json:"content"`
+	}
+
+	EntryChunkDiff struct {
+		Changes   []EntryChunkChange `json:"changes"`
+		Unchanged int                `json:"unchanged"`
+		Added     int                `json:"added"`
+		Removed   int                `json:"removed"`
+		// Truncated is true if the content of some changes was left out to keep the diff under its size limit
+		Truncated bool `json:"truncated"`
 	}
 
 	Chunk struct {
@@ -112,6 +204,12 @@ type (
 		SemanticVector pgvector.Vector `json:"-"`
 	}
 
+	// Highlight is a `[start, end)` range of characters in a snippet that matched the query
+	Highlight struct {
+		Start int `json:"start"`
+		End   int `json:"end"`
+	}
+
 	SearchResultChunkMetadata struct {
 		ID    int32 `json:"id"`
 		Index int32 `json:"index"`
@@ -122,18 +220,24 @@ type (
 		Rank          float32                   `json:"rank"`
 		Name          string                    `json:"name"`
 		Preview       string                    `json:"preview"`
+		Snippet       string                    `json:"snippet"`
+		Highlights    []Highlight               `json:"highlights"`
 		Type          document.EntryType        `json:"type"           mirror:"type:'link' | 'audio' | 'video' | 'image' | 'pdf' | 'interchange' | 'epub' | 'word_document' | 'presentation' | 'spreadsheet' | 'html' | 'markdown' | 'plain_text' | 'archive' | 'code' | 'comment' | 'other'"`
-		Source        SearchSource              `json:"search_type"    mirror:"type:'full_text' | 'semantic'"`
+		Source        SearchSource              `json:"search_type"    mirror:"type:'full_text' | 'semantic' | 'fuzzy'"`
 		Status        queries.EntryStatus       `json:"status"         mirror:"type:'queued' | 'processing' | 'completed' | 'failed' | 'canceled' | 'paused'"`
-		MatchedBy     []SearchSource            `json:"matched_by"     mirror:"type:Array<'full_text' | 'semantic' | 'keyword'>"`
+		MatchedBy     []SearchSource            `json:"matched_by"     mirror:"type:Array<'full_text' | 'semantic' | 'keyword' | 'fuzzy'>"`
 		TextScore     float64                   `json:"text_score"`
 		SemanticScore float64                   `json:"semantic_score"`
+		FuzzyScore    float64                   `json:"fuzzy_score"`
 		HybridScore   float64                   `json:"hybrid_score"`
 		Chunk         SearchResultChunkMetadata `json:"chunk"`
 		Metadata      any                       `json:"metadata"       mirror:"type:import('./types').FileMetadata | import('./types').Metadata"`
 		FileID        string                    `json:"file_id"        mirror:"optional:true"`
 		FilesizeBytes int64                     `json:"filesize_bytes"`
 
+		// CommentID is set when the chunk belongs to a comment, the result is then attributed to the entry the comment was made on
+		CommentID pgtype.UUID `json:"comment_id" mirror:"type:string | null"`
+
 		Collection EntryRelation `json:"collection"`
 		Workspace  EntryRelation `json:"workspace"`
 		CreatedAt  time.Time     `json:"created_at"`
@@ -142,37 +246,110 @@ type (
 	}
 
 	MatchedChunk struct {
-		ID            int32   `json:"id"`
-		Index         int32   `json:"index"`
-		Text          string  `json:"text"`
-		Rank          float32 `json:"rank"`
-		TextScore     float64 `json:"text_score"`
-		SemanticScore float64 `json:"semantic_score"`
-		HybridScore   float64 `json:"hybrid_score"`
+		ID            int32          `json:"id"`
+		Index         int32          `json:"index"`
+		Text          string         `json:"text"`
+		Snippet       string         `json:"snippet"`
+		Highlights    []Highlight    `json:"highlights"`
+		Rank          float32        `json:"rank"`
+		TextScore     float64        `json:"text_score"`
+		SemanticScore float64        `json:"semantic_score"`
+		FuzzyScore    float64        `json:"fuzzy_score"`
+		HybridScore   float64        `json:"hybrid_score"`
+		MatchedBy     []SearchSource `json:"matched_by"     mirror:"type:Array<'full_text' | 'semantic' | 'keyword' | 'fuzzy'>"`
+		// CommentID is set for the chunks of comments on the entry
+		CommentID pgtype.UUID `json:"comment_id" mirror:"type:string | null"`
 	}
 
 	CollapsedSearchResult struct {
-		ID               pgtype.UUID         `json:"id"                mirror:"type:string"`
+		ID               pgtype.UUID         `json:"id"                     mirror:"type:string"`
 		Name             string              `json:"name"`
-		Type             document.EntryType  `json:"type"              mirror:"type:'link' | 'audio' | 'video' | 'image' | 'pdf' | 'interchange' | 'epub' | 'word_document' | 'presentation' | 'spreadsheet' | 'html' | 'markdown' | 'plain_text' | 'archive' | 'code' | 'comment' | 'other'"`
+		Type             document.EntryType  `json:"type"                   mirror:"type:'link' | 'audio' | 'video' | 'image' | 'pdf' | 'interchange' | 'epub' | 'word_document' | 'presentation' | 'spreadsheet' | 'html' | 'markdown' | 'plain_text' | 'archive' | 'code' | 'comment' | 'other'"`
 		Matches          []MatchedChunk      `json:"matches"`
-		Status           queries.EntryStatus `json:"status"            mirror:"type:'queued' | 'processing' | 'completed' | 'failed' | 'canceled' | 'paused'"`
-		FileID           string              `json:"file_id"           mirror:"optional:true"`
+		Status           queries.EntryStatus `json:"status"                 mirror:"type:'queued' | 'processing' | 'completed' | 'failed' | 'canceled' | 'paused'"`
+		FileID           string              `json:"file_id"                mirror:"optional:true"`
 		FilesizeBytes    int64               `json:"filesize_bytes"`
 		RelevancePercent float64             `json:"relevance_percent"`
+		HybridScore      float64             `json:"hybrid_score"`
+		RerankScore      float64             `json:"rerank_score,omitempty" mirror:"optional:true"`
 		Collection       EntryRelation       `json:"collection"`
 		Workspace        EntryRelation       `json:"workspace"`
 		CreatedAt        time.Time           `json:"created_at"`
 		UpdatedAt        time.Time           `json:"updated_at"`
 		ArchivedAt       time.Time           `json:"archived_at"`
-		Metadata         any                 `json:"metadata"          mirror:"type:import('./types').FileMetadata | import('./types').Metadata"`
+		Metadata         any                 `json:"metadata"               mirror:"type:import('./types').FileMetadata | import('./types').Metadata"`
+	}
+
+	// RelatedEntry is an entry that is semantically similar to another entry ("more like this")
+	RelatedEntry struct {
+		ID            pgtype.UUID        `json:"id"             mirror:"type:string"`
+		Name          string             `json:"name"`
+		Type          document.EntryType `json:"type"           mirror:"type:'link' | 'audio' | 'video' | 'image' | 'pdf' | 'interchange' | 'epub' | 'word_document' | 'presentation' | 'spreadsheet' | 'html' | 'markdown' | 'plain_text' | 'archive' | 'code' | 'comment' | 'other'"`
+		FileID        string             `json:"file_id"        mirror:"optional:true"`
+		FilesizeBytes int64              `json:"filesize_bytes"`
+		// Similarity is the cosine similarity between the entry's closest chunk and the source entry's centroid
+		Similarity float64       `json:"similarity"`
+		Collection EntryRelation `json:"collection"`
+		Workspace  EntryRelation `json:"workspace"`
+		CreatedAt  time.Time     `json:"created_at"`
+		UpdatedAt  time.Time     `json:"updated_at"`
+	}
+
+	// LinkedEntry is the latest version of an entry on the other end of a link
+	LinkedEntry struct {
+		ID         pgtype.UUID        `json:"id"         mirror:"type:string"`
+		Name       string             `json:"name"`
+		Type       document.EntryType `json:"type"       mirror:"type:'link' | 'audio' | 'video' | 'image' | 'pdf' | 'interchange' | 'epub' | 'word_document' | 'presentation' | 'spreadsheet' | 'html' | 'markdown' | 'plain_text' | 'archive' | 'code' | 'comment' | 'other'"`
+		Collection EntryRelation      `json:"collection"`
+		CreatedAt  time.Time          `json:"created_at"`
+		UpdatedAt  time.Time          `json:"updated_at"`
+	}
+
+	// EntryLinks are the links written in an entry and the ones written in other entries to it
+	EntryLinks struct {
+		Backlinks []LinkedEntry `json:"backlinks"`
+		Outgoing  []LinkedEntry `json:"outgoing"`
+		// Unresolved are the links that do not match any entry yet, as they were written: a name or an entry URI
+		Unresolved []string `json:"unresolved"`
+	}
+
+	TypeFacet struct {
+		Type  document.EntryType `json:"type"  mirror:"type:'link' | 'audio' | 'video' | 'image' | 'pdf' | 'interchange' | 'epub' | 'word_document' | 'presentation' | 'spreadsheet' | 'html' | 'markdown' | 'plain_text' | 'archive' | 'code' | 'comment' | 'other'"`
+		Count int                `json:"count"`
+	}
+
+	CollectionFacet struct {
+		Collection EntryRelation `json:"collection"`
+		Count      int           `json:"count"`
+	}
+
+	// SearchFacets holds the number of matched entries per type and collection, used for drill-down filters
+	SearchFacets struct {
+		Types       []TypeFacet       `json:"types"`
+		Collections []CollectionFacet `json:"collections"`
 	}
 
 	HybridSearchResults struct {
 		CollapsedResults []CollapsedSearchResult `json:"results"`
+		Facets           SearchFacets            `json:"facets"`
 		MinHybridScore   float64                 `json:"min_hybrid_score"`
 		MaxHybridScore   float64                 `json:"max_hybrid_score"`
 	}
+
+	// Citation links a numbered marker in an answer (e.g. `[1]`) to the chunk it is based on
+	Citation struct {
+		Marker     int           `json:"marker"`
+		EntryID    pgtype.UUID   `json:"entry_id"    mirror:"type:string"`
+		EntryName  string        `json:"entry_name"`
+		ChunkIndex int32         `json:"chunk_index"`
+		Collection EntryRelation `json:"collection"`
+	}
+
+	// Answer is a generated answer to a question, grounded in the user's entries
+	Answer struct {
+		Answer    string     `json:"answer"`
+		Citations []Citation `json:"citations"`
+	}
 )
 
 func (c *Chunk) From(entryPublicId pgtype.UUID, chunk *queries.EntryChunk) {
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+)
+
+type (
+	Tag struct {
+		InternalID   int32       `json:"-"`
+		ID           pgtype.UUID `json:"id"            mirror:"type:string"`
+		Name         string      `json:"name"`
+		Description  string      `json:"description"`
+		Color        string      `json:"color"`
+		CollectionID int32       `json:"-"`
+		CreatedAt    time.Time   `json:"created_at"`
+		UpdatedAt    time.Time   `json:"updated_at"`
+
+		EntriesCount int64 `json:"entries_count"`
+	}
+
+	// EntryTag is a tag assigned to an entry
+	EntryTag struct {
+		ID    pgtype.UUID `json:"id"    mirror:"type:string"`
+		Name  string      `json:"name"`
+		Color string      `json:"color"`
+	}
+
+	// TagSuggestion is a tag suggested for an entry by the chat model, accepted suggestions reference the tag they were applied as
+	TagSuggestion struct {
+		ID         pgtype.UUID                 `json:"id"         mirror:"type:string"`
+		Name       string                      `json:"name"`
+		Confidence float64                     `json:"confidence"`
+		Status     queries.TagSuggestionStatus `json:"status"     mirror:"type:'pending' | 'accepted' | 'rejected'"`
+		Entry      EntryRelation               `json:"entry"`
+		// TagID is only set for accepted suggestions whose tag still exists
+		TagID     *pgtype.UUID `json:"tag_id"     mirror:"type:string,optional:true"`
+		CreatedAt time.Time    `json:"created_at"`
+	}
+)
+
+func (t *Tag) From(tag *queries.Tag) *Tag {
+	*t = Tag{
+		InternalID:   tag.ID,
+		ID:           tag.PublicID,
+		Name:         tag.Name,
+		Description:  tag.Description.String,
+		Color:        tag.Color.String,
+		CollectionID: tag.CollectionID,
+		CreatedAt:    tag.CreatedAt.Time,
+		UpdatedAt:    tag.UpdatedAt.Time,
+		EntriesCount: 0,
+	}
+
+	return t
+}
@@ -18,6 +18,17 @@ const (
 	InviteStatusExpired  InviteStatus = "expired"
 )
 
+const (
+	DefaultRRFK      = 60
+	DefaultRRFWeight = 1.0
+
+	// DefaultTagAutoApplyThreshold is the minimum confidence of the tag suggestions that are applied automatically
+	DefaultTagAutoApplyThreshold = 0.8
+
+	// DefaultTrashRetentionDays is the number of days deleted entries are kept in the trash before they are purged
+	DefaultTrashRetentionDays = 30
+)
+
 type (
 	MemberWithUserID struct {
 		MemberID int32 `json:"member_id"`
@@ -47,6 +58,34 @@ type (
 		IsMember bool `json:"is_member"`
 	}
 
+	// WorkspaceSettings holds the per-workspace tunables, a workspace without a settings row uses the defaults
+	WorkspaceSettings struct {
+		WorkspaceID int32 `json:"-"`
+
+		// RRFK is the `k` constant used in Reciprocal Rank Fusion, higher values flatten the difference between ranks
+		RRFK int32 `json:"rrf_k"`
+		// RRFSemanticWeight is the weight applied to the semantic search ranks
+		RRFSemanticWeight float64 `json:"rrf_semantic_weight"`
+		// RRFFullTextWeight is the weight applied to the full-text search ranks
+		RRFFullTextWeight float64 `json:"rrf_full_text_weight"`
+
+		// EmbeddingModel is the model the workspace's vectors are generated with, it can only be changed by re-embedding the workspace
+		EmbeddingModel EmbeddingModel `json:"embedding_model"`
+
+		// SummariesEnabled opts the workspace into summarising its entries with the chat model once they have been processed
+		SummariesEnabled bool `json:"summaries_enabled"`
+
+		// TagSuggestionMode is whether the chat model suggests tags for the workspace's entries, and whether the confident suggestions are applied without review
+		TagSuggestionMode queries.TagSuggestionMode `json:"tag_suggestion_mode" mirror:"type:'off' | 'suggest' | 'auto'"`
+		// TagAutoApplyThreshold is the minimum confidence of the suggestions that are applied automatically in the auto mode
+		TagAutoApplyThreshold float64 `json:"tag_auto_apply_threshold"`
+
+		// TrashRetentionDays is the number of days deleted entries are kept in the trash before they are purged for good
+		TrashRetentionDays int32 `json:"trash_retention_days"`
+
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
 	WorkspaceWithMembershipStatus struct {
 		ID               int32
 		PublicID         pgtype.UUID
@@ -115,6 +154,42 @@ func (w *Workspace) From(workspace *queries.Workspace) *Workspace {
 	return w
 }
 
+// DefaultWorkspaceSettings returns the settings used by workspaces that have never changed them
+func DefaultWorkspaceSettings(workspaceID int32) WorkspaceSettings {
+	return WorkspaceSettings{
+		WorkspaceID:           workspaceID,
+		RRFK:                  DefaultRRFK,
+		RRFSemanticWeight:     DefaultRRFWeight,
+		RRFFullTextWeight:     DefaultRRFWeight,
+		EmbeddingModel:        EmbeddingModel{Name: "", Dimensions: 0},
+		SummariesEnabled:      false,
+		TagSuggestionMode:     queries.TagSuggestionModeOff,
+		TagAutoApplyThreshold: DefaultTagAutoApplyThreshold,
+		TrashRetentionDays:    DefaultTrashRetentionDays,
+		UpdatedAt:             time.Time{},
+	}
+}
+
+func (s *WorkspaceSettings) From(settings *queries.WorkspaceSetting) *WorkspaceSettings {
+	*s = WorkspaceSettings{
+		WorkspaceID:       settings.WorkspaceID,
+		RRFK:              settings.RrfK,
+		RRFSemanticWeight: settings.RrfSemanticWeight,
+		RRFFullTextWeight: settings.RrfFullTextWeight,
+		EmbeddingModel: EmbeddingModel{
+			Name:       settings.EmbeddingModel.String,
+			Dimensions: settings.EmbeddingDimensions.Int32,
+		},
+		SummariesEnabled:      settings.SummariesEnabled,
+		TagSuggestionMode:     settings.TagSuggestionMode,
+		TagAutoApplyThreshold: settings.TagAutoApplyThreshold,
+		TrashRetentionDays:    settings.TrashRetentionDays,
+		UpdatedAt:             settings.UpdatedAt.Time,
+	}
+
+	return s
+}
+
 func (m WorkspaceWithMembershipStatus) IsValid() bool {
 	return m.Workspace != nil && m.MembershipStatus != nil
 }
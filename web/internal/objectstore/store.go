@@ -156,6 +156,34 @@ func (s *Store) GetPresignedUrl(ctx context.Context, fileId string) (*url.URL, e
 	return url, nil
 }
 
+// CopyFile duplicates the file of an entry under a new ID so that both entries own their file
+func (s *Store) CopyFile(ctx context.Context, sourceId string, targetId string) error {
+	//nolint:exhaustruct
+	_, err := s.client.CopyObject(
+		ctx,
+		minio.CopyDestOptions{Bucket: BucketEntries.String(), Object: targetId},
+		minio.CopySrcOptions{Bucket: BucketEntries.String(), Object: sourceId},
+	)
+	if err != nil {
+		return seer.Wrap("copy_object", err)
+	}
+
+	return nil
+}
+
+// DeleteFiles removes the files of entries from the store, files that do not exist are ignored
+func (s *Store) DeleteFiles(ctx context.Context, fileIds ...string) error {
+	for _, fileId := range fileIds {
+		//nolint:exhaustruct
+		err := s.client.RemoveObject(ctx, BucketEntries.String(), fileId, minio.RemoveObjectOptions{})
+		if err != nil {
+			return seer.Wrap("remove_object", err)
+		}
+	}
+
+	return nil
+}
+
 func (s *Store) Close() error {
 	return nil
 }
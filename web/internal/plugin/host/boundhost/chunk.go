@@ -9,14 +9,10 @@ import (
 	"github.com/tetratelabs/wazero/api"
 	"go.trulyao.dev/hubble/web/internal/plugin/host/alloc"
 	"go.trulyao.dev/hubble/web/internal/plugin/spec"
+	"go.trulyao.dev/hubble/web/pkg/document"
 	"go.trulyao.dev/hubble/web/schema"
 )
 
-const (
-	DefaultChunkSize    = 1_000
-	DefaultChunkOverlap = 0.075 * DefaultChunkSize // 5% overlap
-)
-
 type ChunkMethod int
 
 const (
@@ -25,7 +21,7 @@ const (
 )
 
 /*
-Chunk the text based on the DefaultChunkSize and DefaultChunkOverlap.
+Chunk the text based on the document.DefaultChunkSize and document.DefaultChunkOverlap.
 
 The overlap A.K.A. a sliding window is used to ensure that chunks carry over some context from previous chunks.
 
@@ -82,14 +78,7 @@ func (b *BoundHost) chunk(
 		chunks = chunker.ChunkSentences(text)
 
 	case ChunkMethodOverlap:
-		c := chunker.NewChunker(
-			DefaultChunkSize,
-			DefaultChunkOverlap,
-			chunker.DefaultSeparators,
-			false,
-			false,
-		)
-		chunks = c.Chunk(text)
+		chunks = document.ChunkWithOverlap(text)
 	default:
 		logger.errorf("unknown chunk method: %d", method)
 		return 0
@@ -121,6 +121,16 @@ func (i *Instance) allocateEntry(args *OnCreateArgs) (*bytes.Buffer, error) {
 		return nil, seer.Wrap("set_url_in_entry", err)
 	}
 
+	tags, err := entry.NewTags(int32(len(args.Tags)))
+	if err != nil {
+		return nil, seer.Wrap("new_tags_in_entry", err)
+	}
+	for i, tag := range args.Tags {
+		if err = tags.Set(i, tag.Name); err != nil {
+			return nil, seer.Wrap("set_tag_in_entry", err)
+		}
+	}
+
 	var buf bytes.Buffer
 	if err := capnp.NewEncoder(&buf).Encode(msg); err != nil {
 		return nil, seer.Wrap("encode_capnp_message", err)
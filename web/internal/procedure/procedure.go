@@ -8,9 +8,12 @@ var (
 	MfaLoadSession = "mfa.load-session"
 	MfaState       = "mfa.state"
 
-	FindWorkspace  = "workspace.find"
-	FindInvite     = "workspace.invite.find"
-	FindCollection = "collection.find"
+	FindWorkspace         = "workspace.find"
+	FindWorkspaceSettings = "workspace.settings.find"
+	FindInvite            = "workspace.invite.find"
+	FindCollection        = "collection.find"
+
+	FindEmbeddingMigration = "workspace.embeddings.migration"
 
 	LoadCollectionMemberStatus = "collection.member.status"
 	LoadWorkspaceMemberStatus  = "workspace.member.status"
@@ -19,6 +22,12 @@ var (
 	ListCollectionEntries = "collection.entries.all"
 	ListWorkspaceMembers  = "workspace.members.all"
 	ListCollectionMembers = "collection.members.all"
+	ListTags              = "collection.tags.all"
+	ListTagSuggestions    = "collection.tags.suggestions.all"
+	ListEntryVersions     = "entry.versions.all"
+	DiffEntryVersions     = "entry.versions.diff"
+	ListComments          = "entry.comments.all"
+	ListTrash             = "workspace.trash.all"
 
 	ListPluginSources = "plugin.source.list"
 	ListPlugins       = "plugin.list"
@@ -56,7 +65,10 @@ var (
 	ChangeWorkspaceMemberRole   = "workspace.member.role.update"
 	RemoveMemberFromWorkspace   = "workspace.member.remove"
 	UpdateWorkspaceDetails      = "workspace.details.update"
+	UpdateWorkspaceSettings     = "workspace.settings.update"
 	DeleteWorkspace             = "workspace.delete"
+	ReembedWorkspace            = "workspace.embeddings.reembed"
+	CancelWorkspaceReembed      = "workspace.embeddings.cancel"
 
 	CreateCollection            = "collection.create"
 	DeleteCollection            = "collection.delete"
@@ -65,12 +77,42 @@ var (
 	LeaveCollection             = "collection.leave"
 	UpdateCollectionDetails     = "collection.details.update"
 
-	GetLinkMetadata = "get-link-metadata"
-	ImportEntries   = "entry.import"
-	DeleteEntries   = "entry.delete"
-	RequeueEntries  = "entry.requeue"
-	FindEntry       = "entry.find"
-	SearchEntries   = "entry.search"
+	CreateTag    = "collection.tags.create"
+	UpdateTag    = "collection.tags.update"
+	DeleteTag    = "collection.tags.delete"
+	TagEntries   = "entry.tags.add"
+	UntagEntries = "entry.tags.remove"
+
+	AcceptTagSuggestions = "entry.tags.suggestions.accept"
+	RejectTagSuggestions = "entry.tags.suggestions.reject"
+
+	GetLinkMetadata    = "get-link-metadata"
+	ImportEntries      = "entry.import"
+	DeleteEntries      = "entry.delete"
+	ArchiveEntries     = "entry.archive"
+	UnarchiveEntries   = "entry.unarchive"
+	RequeueEntries     = "entry.requeue"
+	FindEntry          = "entry.find"
+	FindRelatedEntries = "entry.related"
+	FindEntryLinks     = "entry.links"
+	SearchEntries      = "entry.search"
+	AskQuestion        = "entry.ask"
+
+	CreateEntryVersion  = "entry.versions.create"
+	RestoreEntryVersion = "entry.versions.restore"
+
+	RestoreEntries = "entry.restore"
+	PurgeEntries   = "entry.purge"
+
+	MoveEntries = "entry.move"
+	CopyEntries = "entry.copy"
+
+	CreateComment = "entry.comments.create"
+	UpdateComment = "entry.comments.update"
+	DeleteComment = "entry.comments.delete"
+
+	CreateNote = "entry.notes.create"
+	UpdateNote = "entry.notes.update"
 
 	FindPluginSource   = "plugin.source.find"
 	AddPluginSource    = "plugin.source.add"
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/golang-queue/queue/core"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -27,6 +28,7 @@ type handler struct {
 	repos       repository.Repository
 	wasmRuntime *host.Runtime
 	llm         *llm.LLM
+	queueFn     job.QueueFn
 }
 
 func NewHandler(
@@ -42,6 +44,7 @@ func NewHandler(
 		objectStore: objectStore,
 		wasmRuntime: wasmRuntime,
 		llm:         llm,
+		queueFn:     nil,
 	}
 }
 
@@ -59,6 +62,17 @@ func (h *handler) HandleEntry(ctx context.Context, message core.TaskMessage) err
 		return seer.Wrap("find_by_id_in_handle_entry", err)
 	}
 
+	// Archived entries are left in the queue and sent to it again once they are unarchived
+	if !entry.ArchivedAt.IsZero() {
+		log.Info().Str("id", entry.PublicID.String()).Msg("skipping archived entry")
+		return nil
+	}
+
+	// Notes have no file for the plugins to process, they are chunked from their markdown content instead
+	if repository.IsNote(&entry) {
+		return h.rechunkNote(&entry)
+	}
+
 	// Find a installedPlugins that would work for this entry
 	installedPlugins, err := h.repos.PluginRepository().
 		FindOnCreatePluginForEntry(ctx, &repository.FindOnCreatePluginForEntryArgs{
@@ -158,6 +172,21 @@ func (h *handler) HandleEntry(ctx context.Context, message core.TaskMessage) err
 			Msg("failed to update entry status")
 	}
 
+	// The plugins write the markdown content of the entry, the links in it can only be saved once they are done
+	if succeeded {
+		h.syncLinks(entry.ID)
+	}
+
+	// The summary job checks whether the workspace opted in, it is skipped cheaply if it did not
+	if succeeded && h.llm.ChatEnabled() && h.queueFn != nil {
+		if err := h.queueFn(&job.EntrySummaryJob{ID: entry.ID}); err != nil {
+			log.Error().
+				Err(err).
+				Str("entry_id", entry.PublicID.String()).
+				Msg("failed to queue entry summary job")
+		}
+	}
+
 	return nil
 }
 
@@ -186,6 +215,7 @@ func (h *handler) HandleChunkEmbedding(ctx context.Context, message core.TaskMes
 	if err := h.repos.EntryRepository().UpdateSemanticVectorState(&repository.UpdateChunkSemanticVectorArgs{
 		ChunkID: payload.ID,
 		Vector:  []float32{},
+		Model:   models.EmbeddingModel{},
 		Status:  queries.EntryChunkEmbeddingStatusProcessing,
 		Error:   nil,
 	}); err != nil {
@@ -193,7 +223,15 @@ func (h *handler) HandleChunkEmbedding(ctx context.Context, message core.TaskMes
 		return seer.Wrap("update_chunk_embedding_status_in_queue", err)
 	}
 
-	embeddings, err := h.llm.GenerateEmbedding(ctx, payload.Content)
+	// The chunk is embedded with the workspace's model so that it can be compared with the rest of the workspace
+	model, err := h.repos.EmbeddingRepository().FindChunkModel(payload.ID, h.llm.EmbeddingModel())
+	if err != nil {
+		log.Error().Err(err).Int32("chunk_id", payload.ID).Msg("failed to find chunk embedding model")
+		return seer.Wrap("find_chunk_embedding_model_in_queue", err)
+	}
+
+	// Chunks embedded by the other workers at the same time are sent in the same request
+	embeddings, err := h.llm.EmbedChunk(ctx, model, payload.Content)
 	if err != nil {
 		return seer.Wrap(
 			"generate_embedding_in_queue",
@@ -218,6 +256,7 @@ func (h *handler) HandleChunkEmbedding(ctx context.Context, message core.TaskMes
 		UpdateSemanticVectorState(&repository.UpdateChunkSemanticVectorArgs{
 			ChunkID: payload.ID,
 			Vector:  embeddings,
+			Model:   model,
 			Status:  queries.EntryChunkEmbeddingStatusDone,
 			Error:   err,
 		}); err != nil {
@@ -226,5 +265,278 @@ func (h *handler) HandleChunkEmbedding(ctx context.Context, message core.TaskMes
 	}
 
 	log.Info().Int32("chunk_id", payload.ID).Msg("chunk embedding job completed")
+
+	// Refresh the cached related entries once the last chunk of the entry has been embedded
+	entryID, embedded, err := h.repos.EntryRepository().IsEntryEmbedded(payload.ID)
+	if err != nil {
+		log.Error().Err(err).Int32("chunk_id", payload.ID).Msg("failed to check entry embedding progress")
+		return nil
+	}
+
+	if embedded {
+		if err := h.repos.EntryRepository().RefreshRelatedEntries(entryID); err != nil {
+			log.Error().Err(err).Int32("entry_id", entryID).Msg("failed to refresh related entries")
+		}
+	}
+
+	return nil
+}
+
+func (h *handler) HandleEntrySummary(ctx context.Context, message core.TaskMessage) error {
+	payload := new(job.EntrySummaryJob)
+	if err := json.Unmarshal(message.Payload(), payload); err != nil {
+		return err
+	}
+
+	source, err := h.repos.SummaryRepository().FindSource(payload.ID)
+	if err != nil {
+		return seer.Wrap("find_summary_source_in_queue", err)
+	}
+
+	// Archived entries are summarised once they are unarchived and processed again
+	if source.Archived {
+		log.Info().Int32("entry_id", payload.ID).Msg("skipping summary of archived entry")
+		return nil
+	}
+
+	// Summaries are opt-in, and only regenerated when the entry's version changes
+	if !source.SummariesEnabled || source.SummaryVersion == source.Version {
+		h.queueTagSuggestion(payload.ID)
+		return nil
+	}
+
+	log.Info().Int32("entry_id", payload.ID).Msg("processing entry summary job")
+
+	chunks, err := h.repos.SummaryRepository().FindChunks(payload.ID)
+	if err != nil {
+		return seer.Wrap("find_summary_chunks_in_queue", err)
+	}
+
+	summary, err := h.llm.Summarise(ctx, source.Name, chunks)
+	if err != nil {
+		if errors.Is(err, llm.ErrNothingToSummarise) {
+			h.queueTagSuggestion(payload.ID)
+			return nil
+		}
+
+		log.Error().Err(err).Int32("entry_id", payload.ID).Msg("failed to summarise entry")
+		return seer.Wrap("summarise_entry_in_queue", err)
+	}
+
+	summary.Version = source.Version
+	if err := h.repos.SummaryRepository().Save(&repository.SaveSummaryArgs{
+		EntryID:   payload.ID,
+		Summary:   *summary,
+		ChatModel: h.config.LLM.ChatModel,
+	}); err != nil {
+		return seer.Wrap("save_summary_in_queue", err)
+	}
+
+	log.Info().Int32("entry_id", payload.ID).Msg("entry summary job completed")
+	h.queueTagSuggestion(payload.ID)
+	return nil
+}
+
+// rechunkNote chunks a note that was sent to the queue again and queues the embedding of its chunks
+func (h *handler) rechunkNote(entry *models.Entry) error {
+	if err := h.repos.NoteRepository().Rechunk(entry); err != nil {
+		return seer.Wrap("rechunk_note_in_handle_entry", err)
+	}
+
+	if h.queueFn == nil {
+		return nil
+	}
+
+	err := h.queueFn(&job.EntryChunkEmbeddingJob{Entries: []pgtype.UUID{entry.PublicID}})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("entry_id", entry.PublicID.String()).
+			Msg("failed to queue note embedding job")
+	}
+
+	if h.llm.ChatEnabled() {
+		if err := h.queueFn(&job.EntrySummaryJob{ID: entry.ID}); err != nil {
+			log.Error().
+				Err(err).
+				Str("entry_id", entry.PublicID.String()).
+				Msg("failed to queue entry summary job")
+		}
+	}
+
+	return nil
+}
+
+// syncLinks saves the links written in the content the plugins produced for an entry and resolves the links written to the name the plugins gave it, a failure is only logged since the entry itself was processed
+func (h *handler) syncLinks(entryID int32) {
+	entry, err := h.repos.EntryRepository().FindByID(&repository.FindbyIdArgs{
+		InternalID: entryID,
+		PublicID:   pgtype.UUID{Bytes: [16]byte{}, Valid: false},
+	})
+	if err != nil {
+		log.Error().Err(err).Int32("entry_id", entryID).Msg("failed to find entry to sync links")
+		return
+	}
+
+	if err := h.repos.LinkRepository().Sync(entry.ID, entry.Content.String); err != nil {
+		log.Error().
+			Err(err).
+			Str("entry_id", entry.PublicID.String()).
+			Msg("failed to sync entry links")
+	}
+
+	if err := h.repos.LinkRepository().Resolve([]pgtype.UUID{entry.PublicID}); err != nil {
+		log.Error().
+			Err(err).
+			Str("entry_id", entry.PublicID.String()).
+			Msg("failed to resolve links to entry")
+	}
+}
+
+// queueTagSuggestion queues the tag suggestion job of an entry, it follows the summary job so that the entry can be classified using its summary
+func (h *handler) queueTagSuggestion(entryID int32) {
+	if h.queueFn == nil {
+		return
+	}
+
+	if err := h.queueFn(&job.EntryTagSuggestionJob{ID: entryID}); err != nil {
+		log.Error().
+			Err(err).
+			Int32("entry_id", entryID).
+			Msg("failed to queue entry tag suggestion job")
+	}
+}
+
+func (h *handler) HandleEntryTagSuggestion(ctx context.Context, message core.TaskMessage) error {
+	payload := new(job.EntryTagSuggestionJob)
+	if err := json.Unmarshal(message.Payload(), payload); err != nil {
+		return err
+	}
+
+	source, err := h.repos.TagSuggestionRepository().FindSource(payload.ID)
+	if err != nil {
+		return seer.Wrap("find_tag_suggestion_source_in_queue", err)
+	}
+
+	// Tag suggestions are opt-in, and entries are only classified once per version
+	if source.Mode == queries.TagSuggestionModeOff || source.SuggestedVersion == source.Version {
+		return nil
+	}
+
+	log.Info().Int32("entry_id", payload.ID).Msg("processing entry tag suggestion job")
+
+	input, err := h.makeTagSuggestionInput(&source)
+	if err != nil {
+		return seer.Wrap("make_tag_suggestion_input_in_queue", err)
+	}
+
+	suggested, err := h.llm.SuggestTags(ctx, input)
+	if err != nil {
+		if errors.Is(err, llm.ErrNothingToSummarise) {
+			return nil
+		}
+
+		log.Error().Err(err).Int32("entry_id", payload.ID).Msg("failed to suggest entry tags")
+		return seer.Wrap("suggest_entry_tags_in_queue", err)
+	}
+
+	suggestions := make([]repository.PendingTagSuggestion, 0, len(suggested))
+	for _, tag := range suggested {
+		//nolint:exhaustruct
+		suggestions = append(suggestions, repository.PendingTagSuggestion{
+			Name:       tag.Name,
+			Confidence: tag.Confidence,
+		})
+	}
+
+	pending, err := h.repos.TagSuggestionRepository().Save(&repository.SaveTagSuggestionsArgs{
+		EntryID:      payload.ID,
+		EntryVersion: source.Version,
+		ChatModel:    h.config.LLM.ChatModel,
+		Suggestions:  suggestions,
+	})
+	if err != nil {
+		return seer.Wrap("save_tag_suggestions_in_queue", err)
+	}
+
+	// In the auto mode, the confident suggestions are applied on behalf of the user who added the entry
+	if source.Mode == queries.TagSuggestionModeAuto {
+		confident := make([]repository.PendingTagSuggestion, 0, len(pending))
+		for _, suggestion := range pending {
+			if suggestion.Confidence >= source.AutoApplyThreshold {
+				confident = append(confident, suggestion)
+			}
+		}
+
+		if _, err := h.repos.TagSuggestionRepository().Accept(&repository.AcceptTagSuggestionsArgs{
+			CollectionID: source.CollectionID,
+			UserID:       source.AddedBy,
+			AutoApplied:  true,
+			Suggestions:  confident,
+		}); err != nil {
+			return seer.Wrap("apply_tag_suggestions_in_queue", err)
+		}
+	}
+
+	log.Info().
+		Int32("entry_id", payload.ID).
+		Int("suggestions", len(pending)).
+		Msg("entry tag suggestion job completed")
 	return nil
 }
+
+// makeTagSuggestionInput collects the entry's summary (or its chunks), the collection's vocabulary and the negative examples
+func (h *handler) makeTagSuggestionInput(
+	source *repository.TagSuggestionSource,
+) (*llm.TagSuggestionInput, error) {
+	text := source.Summary
+	if text == "" {
+		chunks, err := h.repos.SummaryRepository().FindChunks(source.EntryID)
+		if err != nil {
+			return nil, err
+		}
+
+		text = strings.Join(chunks, "\n\n")
+	}
+
+	tags, err := h.repos.TagRepository().FindAll(source.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	entryTags, err := h.repos.TagRepository().FindEntriesTags([]int32{source.EntryID})
+	if err != nil {
+		return nil, err
+	}
+
+	rejected, err := h.repos.TagSuggestionRepository().
+		FindRejected(source.CollectionID, llm.MaxRejectedTagExamples)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &llm.TagSuggestionInput{
+		Title:      source.Name,
+		Text:       text,
+		Vocabulary: make([]string, 0, len(tags)),
+		Assigned:   make([]string, 0, len(entryTags[source.EntryID])),
+		Rejected:   make([]llm.RejectedTag, 0, len(rejected)),
+	}
+
+	for _, tag := range tags {
+		input.Vocabulary = append(input.Vocabulary, tag.Name)
+	}
+
+	for _, tag := range entryTags[source.EntryID] {
+		input.Assigned = append(input.Assigned, tag.Name)
+	}
+
+	for _, suggestion := range rejected {
+		input.Rejected = append(input.Rejected, llm.RejectedTag{
+			EntryName: suggestion.EntryName,
+			Tag:       suggestion.Name,
+		})
+	}
+
+	return input, nil
+}
@@ -24,11 +24,17 @@ const DefaultRetryInterval = 2 * 60 // 2 minutes
 const (
 	DefaultEntryQueueSize     = 15
 	DefaultEmbeddingQueueSize = 10
+	// Summaries make several chat completions per entry, only a few entries are summarised at a time
+	DefaultSummaryQueueSize = 2
+	// Tag suggestions make a single chat completion per entry
+	DefaultTagSuggestionQueueSize = 2
 )
 
 const (
-	DefaultChunkEmbeddingDuration  = 2 * time.Minute // Chunk embedding jobs are allowed to run for this long
-	DefaultEntryProcessingDuration = 5 * time.Minute // Entry processing jobs are allowed to run for this long
+	DefaultChunkEmbeddingDuration  = 2 * time.Minute  // Chunk embedding jobs are allowed to run for this long
+	DefaultEntryProcessingDuration = 5 * time.Minute  // Entry processing jobs are allowed to run for this long
+	DefaultSummaryDuration         = 10 * time.Minute // Entry summary jobs are allowed to run for this long
+	DefaultTagSuggestionDuration   = 2 * time.Minute  // Entry tag suggestion jobs are allowed to run for this long
 )
 
 type Queue struct {
@@ -41,6 +47,8 @@ type Queue struct {
 
 	entries        *queue.Queue
 	chunkEmbedding *queue.Queue
+	summaries      *queue.Queue
+	tagSuggestions *queue.Queue
 }
 
 // New creates a new queue instance
@@ -52,7 +60,7 @@ func New(
 	llm *llm.LLM,
 ) *Queue {
 	handler := NewHandler(config, repos, objectStore, wasmRuntime, llm)
-	return &Queue{
+	q := &Queue{
 		repos:       repos,
 		config:      config,
 		handler:     handler,
@@ -66,12 +74,34 @@ func New(
 			queue.WithLogger(&logger{}),
 		),
 		chunkEmbedding: queue.NewPool(
-			DefaultEmbeddingQueueSize,
+			embeddingQueueSize(config),
 			queue.WithRetryInterval(DefaultRetryInterval),
 			queue.WithFn(handler.HandleChunkEmbedding),
 			queue.WithLogger(&logger{}),
 		),
+		summaries: queue.NewPool(
+			DefaultSummaryQueueSize,
+			queue.WithRetryInterval(DefaultRetryInterval),
+			queue.WithFn(handler.HandleEntrySummary),
+			queue.WithLogger(&logger{}),
+		),
+		tagSuggestions: queue.NewPool(
+			DefaultTagSuggestionQueueSize,
+			queue.WithRetryInterval(DefaultRetryInterval),
+			queue.WithFn(handler.HandleEntryTagSuggestion),
+			queue.WithLogger(&logger{}),
+		),
 	}
+
+	// Processed entries are queued for their follow-up jobs (e.g. summaries and tag suggestions) by the handler
+	handler.queueFn = q.Add
+
+	return q
+}
+
+// embeddingQueueSize returns the number of embedding workers, there need to be enough workers waiting on a batch to fill it up
+func embeddingQueueSize(config *config.Config) int64 {
+	return max(DefaultEmbeddingQueueSize, int64(config.LLM.EmbeddingBatchSize))
 }
 
 func (q *Queue) Start() error {
@@ -87,6 +117,11 @@ func (q *Queue) Start() error {
 		q.chunkEmbedding.Start()
 	}
 
+	if q.config.LLM.EnabledChat() {
+		q.summaries.Start()
+		q.tagSuggestions.Start()
+	}
+
 	return nil
 }
 
@@ -95,6 +130,10 @@ func (q *Queue) Close() error {
 	if !q.config.LLM.EnabledEmbeddings() {
 		q.chunkEmbedding.Release()
 	}
+	if q.config.LLM.EnabledChat() {
+		q.summaries.Release()
+		q.tagSuggestions.Release()
+	}
 	return nil
 }
 
@@ -135,6 +174,47 @@ func (q *Queue) Load() error {
 		}
 	}
 
+	// Load the entries that are missing their summaries (e.g. the workspace opted in while the application was down)
+	if q.config.LLM.EnabledChat() {
+		var afterID int32
+		for {
+			ids, err := q.repos.SummaryRepository().FindUnsummarisedIDs(0, afterID)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				if err := q.Add(&appjob.EntrySummaryJob{ID: id}); err != nil {
+					return seer.Wrap("load_job_into_queue", err)
+				}
+			}
+
+			if len(ids) < repository.UnsummarisedEntriesPageSize {
+				break
+			}
+			afterID = ids[len(ids)-1]
+		}
+
+		afterID = 0
+		for {
+			ids, err := q.repos.TagSuggestionRepository().FindUnclassifiedIDs(0, afterID)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				if err := q.Add(&appjob.EntryTagSuggestionJob{ID: id}); err != nil {
+					return seer.Wrap("load_job_into_queue", err)
+				}
+			}
+
+			if len(ids) < repository.UnclassifiedEntriesPageSize {
+				break
+			}
+			afterID = ids[len(ids)-1]
+		}
+	}
+
 	return nil
 }
 
@@ -210,6 +290,32 @@ func (q *Queue) Add(payload appjob.Job) error {
 
 		return nil
 
+	case *appjob.EntrySummaryJob:
+		if !q.config.LLM.EnabledChat() {
+			return nil
+		}
+
+		//nolint:exhaustruct
+		return q.summaries.Queue(payload, job.AllowOption{
+			RetryDelay: job.Time(DefaultRetryInterval),
+			RetryMin:   job.Time(time.Minute * 5),
+			RetryMax:   job.Time(time.Minute * 30),
+			Timeout:    job.Time(DefaultSummaryDuration),
+		})
+
+	case *appjob.EntryTagSuggestionJob:
+		if !q.config.LLM.EnabledChat() {
+			return nil
+		}
+
+		//nolint:exhaustruct
+		return q.tagSuggestions.Queue(payload, job.AllowOption{
+			RetryDelay: job.Time(DefaultRetryInterval),
+			RetryMin:   job.Time(time.Minute * 5),
+			RetryMax:   job.Time(time.Minute * 30),
+			Timeout:    job.Time(DefaultTagSuggestionDuration),
+		})
+
 	default:
 		return ErrUnsupportedJobType
 	}
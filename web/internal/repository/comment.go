@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+// CommentNameLength is the maximum number of characters of a comment used as its name
+const CommentNameLength = 100
+
+var (
+	ErrCommentNotFound  = apperrors.BadRequest("comment not found or has been deleted")
+	ErrCommentOnComment = apperrors.BadRequest(
+		"comments cannot be commented on, reply to them instead",
+	)
+	ErrReplyToOtherEntry = apperrors.BadRequest(
+		"replies can only be made to comments on the same entry",
+	)
+)
+
+type (
+	CreateCommentArgs struct {
+		WorkspaceSlug string
+		// Entry is any version of the entry the comment is made on
+		Entry *models.Entry
+		// ReplyTo is the public ID of the comment being replied to, it is not set for comments made on the entry itself
+		ReplyTo pgtype.UUID
+		UserID  int32
+		// Content is the markdown body of the comment
+		Content string
+	}
+
+	UpdateCommentArgs struct {
+		WorkspaceSlug string
+		CommentID     pgtype.UUID
+		UserID        int32
+		Content       string
+	}
+
+	CommentRepository interface {
+		// FindAll returns the comments made on any version of an entry, replies are nested under the comment they reply to
+		FindAll(entryID pgtype.UUID) ([]models.Comment, error)
+
+		// Create adds a comment to an entry or a reply to one of its comments, the comment is chunked so that it can be searched
+		Create(args *CreateCommentArgs) (models.CreatedEntry, error)
+
+		// Update replaces the content of a comment and chunks it again
+		Update(args *UpdateCommentArgs) (models.CreatedEntry, error)
+
+		// Delete deletes a comment along with all the replies made to it
+		Delete(workspaceSlug string, commentID pgtype.UUID) error
+	}
+
+	commentRepo struct {
+		*baseRepo
+	}
+)
+
+// FindAll implements CommentRepository.
+func (c *commentRepo) FindAll(entryID pgtype.UUID) ([]models.Comment, error) {
+	rows, err := c.queries.FindComments(context.TODO(), entryID)
+	if err != nil {
+		return nil, seer.Wrap("find_comments", err)
+	}
+
+	comments := make([]models.Comment, 0, len(rows))
+	for _, row := range rows {
+		comments = append(comments, models.Comment{
+			ID:      row.PublicID,
+			ReplyTo: row.ReplyToID,
+			Content: row.Content.String,
+			Author: models.EntryAddedBy{
+				FirstName: row.AddedByFirstName,
+				LastName:  row.AddedByLastName,
+				Username:  row.AddedByUsername,
+			},
+			Replies:   []models.Comment{},
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+		})
+	}
+
+	return ThreadComments(comments), nil
+}
+
+// Create implements CommentRepository.
+func (c *commentRepo) Create(args *CreateCommentArgs) (models.CreatedEntry, error) {
+	if args.Entry.Type == document.EntryTypeComment {
+		return models.CreatedEntry{}, ErrCommentOnComment
+	}
+
+	// Comments are attached to the first version of an entry so that they are shared by all its versions
+	rootID := args.Entry.ID
+	if args.Entry.ParentID.Valid {
+		rootID = args.Entry.ParentID.Int32
+	}
+
+	parentID := rootID
+	if args.ReplyTo.Valid {
+		reply, err := c.find(args.WorkspaceSlug, args.ReplyTo)
+		if err != nil {
+			return models.CreatedEntry{}, err
+		}
+
+		threads, err := c.queries.FindCommentedEntries(
+			context.TODO(),
+			[]pgtype.UUID{args.ReplyTo},
+		)
+		if err != nil {
+			return models.CreatedEntry{}, seer.Wrap("find_commented_entries", err)
+		}
+
+		if len(threads) == 0 || threads[0].RootID != rootID {
+			return models.CreatedEntry{}, ErrReplyToOtherEntry
+		}
+
+		parentID = reply.ID
+	}
+
+	tx, err := c.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return models.CreatedEntry{}, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := c.queries.WithTx(tx)
+
+	text := document.MarkdownToText(args.Content)
+	comment, err := queriesWithTx.CreateComment(context.TODO(), queries.CreateCommentParams{
+		Name:        commentName(text),
+		Content:     lib.PgText(args.Content),
+		TextContent: lib.PgText(text),
+		UserID:      args.UserID,
+		ParentID:    parentID,
+	})
+	if err != nil {
+		return models.CreatedEntry{}, seer.Wrap("create_comment", err)
+	}
+
+	// Comments do not go through the plugins, they are chunked right away and only have to be embedded
+	err = queriesWithTx.EnqueueProcessedEntry(context.TODO(), queries.EnqueueProcessedEntryParams{
+		EntryID: comment.ID,
+		Payload: document.QueuePayload{Type: document.EntryTypeComment},
+	})
+	if err != nil {
+		return models.CreatedEntry{}, seer.Wrap("enqueue_processed_comment", err)
+	}
+
+	if _, err := insertTextChunks(queriesWithTx, comment.ID, 1, text); err != nil {
+		return models.CreatedEntry{}, err
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return models.CreatedEntry{}, err
+	}
+
+	return createdEntry(&comment), nil
+}
+
+// Update implements CommentRepository.
+func (c *commentRepo) Update(args *UpdateCommentArgs) (models.CreatedEntry, error) {
+	existing, err := c.find(args.WorkspaceSlug, args.CommentID)
+	if err != nil {
+		return models.CreatedEntry{}, err
+	}
+
+	tx, err := c.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return models.CreatedEntry{}, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := c.queries.WithTx(tx)
+
+	text := document.MarkdownToText(args.Content)
+	comment, err := queriesWithTx.UpdateComment(context.TODO(), queries.UpdateCommentParams{
+		Name:        commentName(text),
+		Content:     lib.PgText(args.Content),
+		TextContent: lib.PgText(text),
+		UserID:      args.UserID,
+		CommentID:   existing.ID,
+	})
+	if err != nil {
+		return models.CreatedEntry{}, seer.Wrap("update_comment", err)
+	}
+
+	err = queriesWithTx.DeleteCommentChunks(context.TODO(), lib.PgInt4(comment.ID))
+	if err != nil {
+		return models.CreatedEntry{}, seer.Wrap("delete_comment_chunks", err)
+	}
+
+	if _, err := insertTextChunks(queriesWithTx, comment.ID, 1, text); err != nil {
+		return models.CreatedEntry{}, err
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return models.CreatedEntry{}, err
+	}
+
+	return createdEntry(&comment), nil
+}
+
+// Delete implements CommentRepository.
+func (c *commentRepo) Delete(workspaceSlug string, commentID pgtype.UUID) error {
+	comment, err := c.find(workspaceSlug, commentID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.queries.DeleteComment(context.TODO(), comment.ID); err != nil {
+		return seer.Wrap("delete_comment", err)
+	}
+
+	return nil
+}
+
+func (c *commentRepo) find(workspaceSlug string, commentID pgtype.UUID) (queries.Entry, error) {
+	comment, err := c.queries.FindComment(context.TODO(), queries.FindCommentParams{
+		CommentPublicID: commentID,
+		WorkspaceSlug:   lib.PgText(workspaceSlug),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return queries.Entry{}, ErrCommentNotFound
+		}
+		return queries.Entry{}, seer.Wrap("find_comment", err)
+	}
+
+	return comment, nil
+}
+
+// insertTextChunks chunks the text of an entry written in Hubble, the chunks are embedded once the entry is sent to the queue
+func insertTextChunks(
+	q *queries.Queries,
+	entryID int32,
+	minVersion int32,
+	text string,
+) (int, error) {
+	chunks := document.ChunkWithOverlap(text)
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+
+	params := make([]queries.InsertChunksParams, 0, len(chunks))
+	for i, chunk := range chunks {
+		params = append(params, queries.InsertChunksParams{
+			EntryID:    lib.PgInt4(entryID),
+			Index:      int32(i + 1),
+			MinVersion: minVersion,
+			Content:    lib.PgText(chunk),
+			Language:   lib.PgText(lib.NormalizePgLanguage("")),
+		})
+	}
+
+	if _, err := q.InsertChunks(context.TODO(), params); err != nil {
+		return 0, seer.Wrap("insert_text_chunks", err)
+	}
+
+	return len(chunks), nil
+}
+
+// commentName is the first line of a comment, shortened to CommentNameLength characters
+func commentName(text string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "Comment"
+	}
+
+	if utf8.RuneCountInString(name) <= CommentNameLength {
+		return name
+	}
+
+	runes := []rune(name)
+	return strings.TrimSpace(string(runes[:CommentNameLength-1])) + "…"
+}
+
+// ThreadComments nests the replies under the comments they reply to, the order of the comments is kept at every level of a thread
+func ThreadComments(comments []models.Comment) []models.Comment {
+	known := make(map[pgtype.UUID]bool, len(comments))
+	for i := range comments {
+		known[comments[i].ID] = true
+	}
+
+	roots := make([]int, 0)
+	replies := make(map[pgtype.UUID][]int)
+	for i := range comments {
+		if comments[i].ReplyTo.Valid && known[comments[i].ReplyTo] {
+			replies[comments[i].ReplyTo] = append(replies[comments[i].ReplyTo], i)
+			continue
+		}
+
+		roots = append(roots, i)
+	}
+
+	var thread func(indexes []int) []models.Comment
+	thread = func(indexes []int) []models.Comment {
+		result := make([]models.Comment, 0, len(indexes))
+		for _, i := range indexes {
+			comment := comments[i]
+			comment.Replies = thread(replies[comment.ID])
+			result = append(result, comment)
+		}
+
+		return result
+	}
+
+	return thread(roots)
+}
+
+var _ CommentRepository = (*commentRepo)(nil)
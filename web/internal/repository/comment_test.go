@@ -0,0 +1,86 @@
+package repository_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/internal/repository"
+)
+
+func commentID(n byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{n}, Valid: true}
+}
+
+func comment(id byte, replyTo byte) models.Comment {
+	c := models.Comment{ID: commentID(id), Content: string('a' + id)} //nolint:exhaustruct
+	if replyTo != 0 {
+		c.ReplyTo = commentID(replyTo)
+	}
+
+	return c
+}
+
+// flatten renders a thread as "a(b,c(d))" so that the structure can be compared at once
+func flatten(comments []models.Comment) string {
+	parts := make([]string, 0, len(comments))
+	for _, c := range comments {
+		part := c.Content
+		if len(c.Replies) > 0 {
+			part += "(" + flatten(c.Replies) + ")"
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func Test_ThreadComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []models.Comment
+		expected string
+	}{
+		{
+			name:     "no comments",
+			comments: []models.Comment{},
+			expected: "",
+		},
+		{
+			name:     "top-level comments only",
+			comments: []models.Comment{comment(1, 0), comment(2, 0)},
+			expected: "b,c",
+		},
+		{
+			name: "nested replies",
+			comments: []models.Comment{
+				comment(1, 0),
+				comment(2, 1),
+				comment(3, 0),
+				comment(4, 2),
+				comment(5, 1),
+			},
+			expected: "b(c(e),f),d",
+		},
+		{
+			name:     "replies listed before their comment",
+			comments: []models.Comment{comment(2, 1), comment(1, 0)},
+			expected: "b(c)",
+		},
+		{
+			name:     "reply to an unknown comment",
+			comments: []models.Comment{comment(1, 0), comment(2, 9)},
+			expected: "b,c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := flatten(repository.ThreadComments(tt.comments))
+			if actual != tt.expected {
+				t.Errorf("expected thread %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
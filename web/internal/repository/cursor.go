@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+)
+
+// Cursor scopes, a cursor issued for one listing is rejected by the others
+const (
+	CursorScopeEntries = "entries"
+	CursorScopeSearch  = "search"
+)
+
+var ErrInvalidCursor = apperrors.BadRequest("invalid pagination cursor")
+
+type (
+	/*
+		Cursor is the position of the last item of a page in a keyset-paginated listing.
+
+		Items are always ordered by their sort key (descending) and then by their ID (descending), the next page starts right after the item the cursor points to.
+		Since the position is not an offset, items that are added while a client is scrolling do not shift the next pages and cause duplicates.
+	*/
+	Cursor struct {
+		// Timestamp is the sort key of entry listings (`coalesce(updated_at, created_at)`)
+		Timestamp time.Time `json:"t,omitzero"`
+
+		// Score is the sort key of search results
+		Score float64 `json:"s,omitempty"`
+
+		// ID is the public ID of the last item, it breaks ties between items with the same sort key
+		ID pgtype.UUID `json:"id"`
+	}
+
+	CursorParams struct {
+		// Cursor is the opaque token returned as `next_cursor` by the previous page, an empty cursor starts from the first page
+		Cursor  string `json:"cursor"                                      mirror:"optional:true"`
+		PerPage int32  `json:"per_page" validate:"omitempty,min=5,max=100" mirror:"optional:true"`
+	}
+)
+
+// Limit returns the number of rows to fetch, one more than the page size to know if there is a next page
+func (p CursorParams) Limit() int32 {
+	return p.PageSize() + 1
+}
+
+// PageSize returns the requested page size or the default page size
+func (p CursorParams) PageSize() int32 {
+	if p.PerPage < 1 {
+		return DefaultPerPage
+	}
+
+	return p.PerPage
+}
+
+/*
+EncodeCursor signs and encodes a cursor into an opaque token.
+
+The token is the base64 (URL-safe) encoding of `<signature><payload>`, where the signature is the HMAC-SHA256 of the scope and the JSON payload, the same way signed cookies are encoded.
+*/
+func EncodeCursor(scope string, secret string, cursor *Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	token := append(signCursor(scope, secret, payload), payload...)
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// DecodeCursor verifies and decodes a cursor token, nil is returned for an empty token
+func DecodeCursor(scope string, secret string, token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) <= sha256.Size {
+		return nil, ErrInvalidCursor
+	}
+
+	signature, payload := raw[:sha256.Size], raw[sha256.Size:]
+	if !hmac.Equal(signature, signCursor(scope, secret, payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || !cursor.ID.Valid {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func signCursor(scope string, secret string, payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte("cursor:" + scope + ":"))
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,92 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/internal/repository"
+)
+
+const cursorSecret = "cursor-secret"
+
+func Test_Cursor(t *testing.T) {
+	cursor := &repository.Cursor{
+		Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Score:     0,
+		ID:        pgtype.UUID{Bytes: [16]byte{1, 2, 3}, Valid: true},
+	}
+
+	token, err := repository.EncodeCursor(repository.CursorScopeEntries, cursorSecret, cursor)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	decoded, err := repository.DecodeCursor(repository.CursorScopeEntries, cursorSecret, token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !decoded.Timestamp.Equal(cursor.Timestamp) || decoded.ID != cursor.ID {
+		t.Errorf("expected %+v, got %+v", cursor, decoded)
+	}
+
+	tampered := []byte(token)
+	tampered[len(tampered)-2] ^= 1
+
+	tests := []struct {
+		name   string
+		scope  string
+		secret string
+		token  string
+	}{
+		{name: "tampered token", scope: repository.CursorScopeEntries, secret: cursorSecret, token: string(tampered)},
+		{name: "wrong scope", scope: repository.CursorScopeSearch, secret: cursorSecret, token: token},
+		{name: "wrong secret", scope: repository.CursorScopeEntries, secret: "other-secret", token: token},
+		{name: "malformed token", scope: repository.CursorScopeEntries, secret: cursorSecret, token: "not a cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := repository.DecodeCursor(tt.scope, tt.secret, tt.token); !errors.Is(err, repository.ErrInvalidCursor) {
+				t.Errorf("expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func Test_PaginateSearchResults(t *testing.T) {
+	result := func(id byte, score float64) models.CollapsedSearchResult {
+		//nolint:exhaustruct
+		return models.CollapsedSearchResult{
+			ID:          pgtype.UUID{Bytes: [16]byte{id}, Valid: true},
+			HybridScore: score,
+			// The relevance is relative to the best result, it is not stable across requests
+			RelevancePercent: 100,
+		}
+	}
+
+	results := []models.CollapsedSearchResult{
+		result(1, 0.5), result(2, 1), result(3, 0.5), result(4, 0.2), result(5, 0.8),
+	}
+
+	page, next := repository.PaginateSearchResults(results, nil, 2)
+	if len(page) != 2 || page[0].ID.Bytes[0] != 2 || page[1].ID.Bytes[0] != 5 || next == nil {
+		t.Fatalf("unexpected first page: %v, next: %v", page, next)
+	}
+
+	// A result that ranks before the cursor shows up between requests, it must not shift the next page
+	results = append(results, result(6, 0.9))
+
+	page, next = repository.PaginateSearchResults(results, next, 2)
+	if len(page) != 2 || page[0].ID.Bytes[0] != 3 || page[1].ID.Bytes[0] != 1 || next == nil {
+		t.Fatalf("unexpected second page: %v, next: %v", page, next)
+	}
+
+	page, next = repository.PaginateSearchResults(results, next, 2)
+	if len(page) != 1 || page[0].ID.Bytes[0] != 4 || next != nil {
+		t.Fatalf("unexpected last page: %v, next: %v", page, next)
+	}
+}
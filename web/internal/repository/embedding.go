@@ -0,0 +1,481 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pgvector/pgvector-go"
+	"github.com/rs/zerolog/log"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+// MaxEmbeddingMigrationErrors is the number of batches that can fail in a row before a migration is marked as failed
+const MaxEmbeddingMigrationErrors = 5
+
+var ErrNoRunningEmbeddingMigration = apperrors.BadRequest(
+	"there is no re-embedding in progress for this workspace",
+)
+
+type (
+	StartEmbeddingMigrationArgs struct {
+		WorkspaceID int32
+		StartedBy   int32
+		From        models.EmbeddingModel
+		To          models.EmbeddingModel
+	}
+
+	CacheEmbeddingArgs struct {
+		// Key is the content address of the embedding (see `llm.EmbeddingCacheKey`)
+		Key    string
+		Model  models.EmbeddingModel
+		Vector []float32
+	}
+
+	SaveEmbeddingMigrationBatchArgs struct {
+		MigrationID int32
+		// Chunks is the batch that was embedded, the migration resumes after the last chunk
+		Chunks  []UnindexedChunk
+		Vectors [][]float32
+	}
+
+	EmbeddingRepository interface {
+		// AdoptModel records the model of the vectors generated before models were tracked (the others are reset to be embedded again) and pins the workspaces that have no model yet to it
+		AdoptModel(model models.EmbeddingModel) error
+
+		// FindChunkModel returns the model a chunk has to be embedded with, the chunk's workspace is pinned to the fallback if it has no model yet
+		FindChunkModel(chunkID int32, fallback models.EmbeddingModel) (models.EmbeddingModel, error)
+
+		// StartMigration creates a migration that re-embeds all the chunks of a workspace with a new model
+		StartMigration(args *StartEmbeddingMigrationArgs) (models.EmbeddingMigration, error)
+
+		// FindLatestMigration returns the most recent migration of a workspace, nil is returned if the workspace has never been re-embedded
+		FindLatestMigration(workspaceID int32) (*models.EmbeddingMigration, error)
+
+		// FindRunningMigrations returns the migrations that have not completed yet
+		FindRunningMigrations() ([]models.EmbeddingMigration, error)
+
+		// FindMigrationChunks returns the next batch of chunks to re-embed
+		FindMigrationChunks(migration *models.EmbeddingMigration, limit int32) ([]UnindexedChunk, error)
+
+		// SaveMigrationBatch stores the new vectors of a batch aside and moves the migration past the batch
+		SaveMigrationBatch(args *SaveEmbeddingMigrationBatchArgs) error
+
+		// RecordMigrationError records a failed batch, the migration is marked as failed (and its vectors discarded) once too many batches have failed in a row
+		RecordMigrationError(migrationID int32, err error) (queries.EmbeddingMigrationStatus, error)
+
+		// CompleteMigration swaps the workspace's vectors for the new ones and switches the workspace to the new model
+		CompleteMigration(migration *models.EmbeddingMigration) error
+
+		// CancelMigration stops the running migration of a workspace and discards the vectors it generated
+		CancelMigration(workspaceID int32) error
+
+		// FindCachedEmbedding returns the cached embedding of a key, false is returned on a cache miss
+		FindCachedEmbedding(key string) ([]float32, bool, error)
+
+		// RecordCachedEmbeddingHits adds the hits of every key and marks the keys as recently used
+		RecordCachedEmbeddingHits(hits map[string]int32) error
+
+		// CacheEmbedding stores an embedding in the cache
+		CacheEmbedding(args *CacheEmbeddingArgs) error
+
+		// EvictCachedEmbeddings removes the least recently used embeddings over the limit and returns the number of evicted embeddings
+		EvictCachedEmbeddings(maxEntries int32) (int64, error)
+	}
+
+	embeddingRepo struct {
+		*baseRepo
+	}
+)
+
+// AdoptModel implements EmbeddingRepository.
+func (e *embeddingRepo) AdoptModel(model models.EmbeddingModel) error {
+	adopted, err := e.queries.AdoptUnversionedEmbeddings(
+		context.TODO(),
+		queries.AdoptUnversionedEmbeddingsParams{
+			EmbeddingModel:      model.Name,
+			EmbeddingDimensions: model.Dimensions,
+		},
+	)
+	if err != nil {
+		return seer.Wrap("adopt_unversioned_embeddings", err)
+	}
+
+	reset, err := e.queries.ResetUnversionedEmbeddings(context.TODO(), model.Dimensions)
+	if err != nil {
+		return seer.Wrap("reset_unversioned_embeddings", err)
+	}
+
+	pinned, err := e.queries.PinWorkspaceEmbeddingModel(
+		context.TODO(),
+		queries.PinWorkspaceEmbeddingModelParams{
+			EmbeddingModel:      model.Name,
+			EmbeddingDimensions: model.Dimensions,
+			WorkspaceID:         lib.PgInt4(0),
+		},
+	)
+	if err != nil {
+		return seer.Wrap("pin_workspace_embedding_models", err)
+	}
+
+	if adopted > 0 || reset > 0 || pinned > 0 {
+		log.Info().
+			Str("model", model.Name).
+			Int64("chunks", adopted).
+			Int64("reset", reset).
+			Int64("workspaces", pinned).
+			Msg("adopted existing embeddings")
+	}
+
+	return nil
+}
+
+// FindChunkModel implements EmbeddingRepository.
+func (e *embeddingRepo) FindChunkModel(
+	chunkID int32,
+	fallback models.EmbeddingModel,
+) (models.EmbeddingModel, error) {
+	row, err := e.queries.FindChunkEmbeddingModel(context.TODO(), chunkID)
+	if err != nil {
+		return models.EmbeddingModel{}, seer.Wrap("find_chunk_embedding_model", err)
+	}
+
+	if row.EmbeddingModel.Valid && row.EmbeddingDimensions.Int32 > 0 {
+		return models.EmbeddingModel{
+			Name:       row.EmbeddingModel.String,
+			Dimensions: row.EmbeddingDimensions.Int32,
+		}, nil
+	}
+
+	if _, err := e.queries.PinWorkspaceEmbeddingModel(
+		context.TODO(),
+		queries.PinWorkspaceEmbeddingModelParams{
+			EmbeddingModel:      fallback.Name,
+			EmbeddingDimensions: fallback.Dimensions,
+			WorkspaceID:         lib.PgInt4(row.WorkspaceID),
+		},
+	); err != nil {
+		return models.EmbeddingModel{}, seer.Wrap("pin_workspace_embedding_model", err)
+	}
+
+	return fallback, nil
+}
+
+// StartMigration implements EmbeddingRepository.
+func (e *embeddingRepo) StartMigration(
+	args *StartEmbeddingMigrationArgs,
+) (models.EmbeddingMigration, error) {
+	row, err := e.queries.CreateEmbeddingMigration(
+		context.TODO(),
+		queries.CreateEmbeddingMigrationParams{
+			WorkspaceID:    args.WorkspaceID,
+			StartedBy:      lib.PgInt4(args.StartedBy),
+			FromModel:      args.From.Name,
+			FromDimensions: args.From.Dimensions,
+			ToModel:        args.To.Name,
+			ToDimensions:   args.To.Dimensions,
+		},
+	)
+	if err != nil {
+		return models.EmbeddingMigration{}, seer.Wrap("create_embedding_migration", err)
+	}
+
+	migration := models.EmbeddingMigration{} //nolint:exhaustruct
+	migration.From(&row)
+	return migration, nil
+}
+
+// FindLatestMigration implements EmbeddingRepository.
+func (e *embeddingRepo) FindLatestMigration(
+	workspaceID int32,
+) (*models.EmbeddingMigration, error) {
+	row, err := e.queries.FindLatestEmbeddingMigration(context.TODO(), workspaceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, seer.Wrap("find_latest_embedding_migration", err)
+	}
+
+	return new(models.EmbeddingMigration).From(&row), nil
+}
+
+// FindRunningMigrations implements EmbeddingRepository.
+func (e *embeddingRepo) FindRunningMigrations() ([]models.EmbeddingMigration, error) {
+	rows, err := e.queries.FindRunningEmbeddingMigrations(context.TODO())
+	if err != nil {
+		return nil, seer.Wrap("find_running_embedding_migrations", err)
+	}
+
+	migrations := make([]models.EmbeddingMigration, len(rows))
+	for i := range rows {
+		migrations[i].From(&rows[i])
+	}
+
+	return migrations, nil
+}
+
+// FindMigrationChunks implements EmbeddingRepository.
+func (e *embeddingRepo) FindMigrationChunks(
+	migration *models.EmbeddingMigration,
+	limit int32,
+) ([]UnindexedChunk, error) {
+	rows, err := e.queries.FindEmbeddingMigrationChunks(
+		context.TODO(),
+		queries.FindEmbeddingMigrationChunksParams{
+			WorkspaceID:  migration.WorkspaceID,
+			AfterChunkID: migration.LastChunkID,
+			BatchSize:    limit,
+		},
+	)
+	if err != nil {
+		return nil, seer.Wrap("find_embedding_migration_chunks", err)
+	}
+
+	chunks := make([]UnindexedChunk, 0, len(rows))
+	for _, row := range rows {
+		chunks = append(chunks, UnindexedChunk{ID: row.ID, Content: row.Content.String})
+	}
+
+	return chunks, nil
+}
+
+// SaveMigrationBatch implements EmbeddingRepository.
+func (e *embeddingRepo) SaveMigrationBatch(args *SaveEmbeddingMigrationBatchArgs) error {
+	if len(args.Chunks) == 0 {
+		return nil
+	}
+
+	tx, err := e.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := e.queries.WithTx(tx)
+
+	// Chunks without a vector are left out, they are reset and embedded again once the migration completes
+	for i, chunk := range args.Chunks {
+		if i >= len(args.Vectors) || len(args.Vectors[i]) == 0 {
+			continue
+		}
+
+		if err := queriesWithTx.InsertEmbeddingMigrationVector(
+			context.TODO(),
+			queries.InsertEmbeddingMigrationVectorParams{
+				MigrationID:    args.MigrationID,
+				ChunkID:        chunk.ID,
+				SemanticVector: pgvector.NewVector(args.Vectors[i]),
+			},
+		); err != nil {
+			return seer.Wrap("insert_embedding_migration_vector", err)
+		}
+	}
+
+	// The progress is only moved if the migration is still running, it might have been cancelled while the batch was being embedded
+	updated, err := queriesWithTx.UpdateEmbeddingMigrationProgress(
+		context.TODO(),
+		queries.UpdateEmbeddingMigrationProgressParams{
+			ProcessedChunks: int32(len(args.Chunks)),
+			LastChunkID:     args.Chunks[len(args.Chunks)-1].ID,
+			MigrationID:     args.MigrationID,
+		},
+	)
+	if err != nil {
+		return seer.Wrap("update_embedding_migration_progress", err)
+	}
+
+	if updated == 0 {
+		return nil
+	}
+
+	return tx.Commit(context.TODO())
+}
+
+// RecordMigrationError implements EmbeddingRepository.
+func (e *embeddingRepo) RecordMigrationError(
+	migrationID int32,
+	migrationErr error,
+) (queries.EmbeddingMigrationStatus, error) {
+	status, err := e.queries.RecordEmbeddingMigrationError(
+		context.TODO(),
+		queries.RecordEmbeddingMigrationErrorParams{
+			LastError:   lib.PgText(migrationErr.Error()),
+			MaxErrors:   MaxEmbeddingMigrationErrors,
+			MigrationID: migrationID,
+		},
+	)
+	if err != nil {
+		return status, seer.Wrap("record_embedding_migration_error", err)
+	}
+
+	if status == queries.EmbeddingMigrationStatusFailed {
+		if err := e.queries.DeleteEmbeddingMigrationVectors(context.TODO(), migrationID); err != nil {
+			return status, seer.Wrap("delete_embedding_migration_vectors", err)
+		}
+	}
+
+	return status, nil
+}
+
+/*
+CompleteMigration implements EmbeddingRepository.
+
+Everything happens in a single transaction, so searches switch from the old vectors (and model) to the new ones at once. Chunks that are still on the old model afterwards (e.g. they were edited while the migration was running) are reset and picked up by the embedding cron.
+*/
+func (e *embeddingRepo) CompleteMigration(migration *models.EmbeddingMigration) error {
+	tx, err := e.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := e.queries.WithTx(tx)
+
+	applied, err := queriesWithTx.ApplyEmbeddingMigrationVectors(context.TODO(), migration.ID)
+	if err != nil {
+		return seer.Wrap("apply_embedding_migration_vectors", err)
+	}
+
+	if err := queriesWithTx.SetWorkspaceEmbeddingModel(
+		context.TODO(),
+		queries.SetWorkspaceEmbeddingModelParams{
+			WorkspaceID:         migration.WorkspaceID,
+			EmbeddingModel:      migration.ToModel.Name,
+			EmbeddingDimensions: migration.ToModel.Dimensions,
+		},
+	); err != nil {
+		return seer.Wrap("set_workspace_embedding_model", err)
+	}
+
+	reset, err := queriesWithTx.ResetOutdatedChunkEmbeddings(
+		context.TODO(),
+		queries.ResetOutdatedChunkEmbeddingsParams{
+			WorkspaceID:         migration.WorkspaceID,
+			EmbeddingModel:      migration.ToModel.Name,
+			EmbeddingDimensions: migration.ToModel.Dimensions,
+		},
+	)
+	if err != nil {
+		return seer.Wrap("reset_outdated_chunk_embeddings", err)
+	}
+
+	// The migration may have been cancelled while it was being applied, everything is rolled back if so
+	completed, err := queriesWithTx.CompleteEmbeddingMigration(context.TODO(), migration.ID)
+	if err != nil {
+		return seer.Wrap("complete_embedding_migration", err)
+	}
+	if completed == 0 {
+		return ErrNoRunningEmbeddingMigration
+	}
+
+	if err := queriesWithTx.DeleteEmbeddingMigrationVectors(context.TODO(), migration.ID); err != nil {
+		return seer.Wrap("delete_embedding_migration_vectors", err)
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int32("workspace_id", migration.WorkspaceID).
+		Str("model", migration.ToModel.Name).
+		Int64("applied", applied).
+		Int64("reset", reset).
+		Msg("completed embedding migration")
+
+	return nil
+}
+
+// CancelMigration implements EmbeddingRepository.
+func (e *embeddingRepo) CancelMigration(workspaceID int32) error {
+	tx, err := e.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := e.queries.WithTx(tx)
+
+	migrationID, err := queriesWithTx.CancelEmbeddingMigration(context.TODO(), workspaceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNoRunningEmbeddingMigration
+		}
+
+		return seer.Wrap("cancel_embedding_migration", err)
+	}
+
+	if err := queriesWithTx.DeleteEmbeddingMigrationVectors(context.TODO(), migrationID); err != nil {
+		return seer.Wrap("delete_embedding_migration_vectors", err)
+	}
+
+	return tx.Commit(context.TODO())
+}
+
+// FindCachedEmbedding implements EmbeddingRepository.
+func (e *embeddingRepo) FindCachedEmbedding(key string) ([]float32, bool, error) {
+	vector, err := e.queries.FindCachedEmbedding(context.TODO(), key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+
+		return nil, false, seer.Wrap("find_cached_embedding", err)
+	}
+
+	return vector.Slice(), true, nil
+}
+
+// RecordCachedEmbeddingHits implements EmbeddingRepository.
+func (e *embeddingRepo) RecordCachedEmbeddingHits(hits map[string]int32) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	params := queries.RecordCachedEmbeddingHitsParams{
+		CacheKeys: make([]string, 0, len(hits)),
+		Hits:      make([]int32, 0, len(hits)),
+	}
+	for key, count := range hits {
+		params.CacheKeys = append(params.CacheKeys, key)
+		params.Hits = append(params.Hits, count)
+	}
+
+	if err := e.queries.RecordCachedEmbeddingHits(context.TODO(), params); err != nil {
+		return seer.Wrap("record_cached_embedding_hits", err)
+	}
+
+	return nil
+}
+
+// CacheEmbedding implements EmbeddingRepository.
+func (e *embeddingRepo) CacheEmbedding(args *CacheEmbeddingArgs) error {
+	if err := e.queries.CacheEmbedding(context.TODO(), queries.CacheEmbeddingParams{
+		CacheKey:            args.Key,
+		EmbeddingModel:      args.Model.Name,
+		EmbeddingDimensions: args.Model.Dimensions,
+		SemanticVector:      pgvector.NewVector(args.Vector),
+	}); err != nil {
+		return seer.Wrap("cache_embedding", err)
+	}
+
+	return nil
+}
+
+// EvictCachedEmbeddings implements EmbeddingRepository.
+func (e *embeddingRepo) EvictCachedEmbeddings(maxEntries int32) (int64, error) {
+	evicted, err := e.queries.EvictCachedEmbeddings(context.TODO(), maxEntries)
+	if err != nil {
+		return 0, seer.Wrap("evict_cached_embeddings", err)
+	}
+
+	return evicted, nil
+}
+
+var _ EmbeddingRepository = (*embeddingRepo)(nil)
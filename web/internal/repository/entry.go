@@ -26,6 +26,8 @@ import (
 const (
 	DefaultFullTextWeight = 0.35
 	DefaultSemanticWeight = 0.65
+	// DefaultFuzzyWeight keeps fuzzy-only matches below the exact full-text and semantic matches
+	DefaultFuzzyWeight = 0.5
 )
 
 const KeywordScore = 0.075 // When a keyword is matched, it will add this score to the final score
@@ -40,15 +42,63 @@ type (
 		Workspace  PublicIdOrSlug
 		Collection PublicIdOrSlug
 		UserID     int32
+		// Tags is a list of tag names, only the entries that have all of them are returned
+		Tags []string
+
+		// IncludeArchived adds the archived entries to the listing
+		IncludeArchived bool
+		// ArchivedOnly only lists the archived entries, it takes precedence over IncludeArchived
+		ArchivedOnly bool
+
+		// After switches to keyset pagination, only the entries after the cursor are returned and the page number is ignored
+		After *Cursor
 	}
 
 	FindEntriesByWorkspaceResult struct {
+		// TotalCount is only computed for page-number pagination
 		TotalCount int64
 		Entries    []models.Entry
+
+		// NextCursor points at the last entry of the page, it is nil if there are no more entries
+		NextCursor *Cursor
 	}
 
 	DeleteEntriesArgs struct {
-		Entries []pgtype.UUID `json:"entries"`
+		WorkspaceSlug string        `json:"workspace_slug"`
+		Entries       []pgtype.UUID `json:"entries"`
+	}
+
+	ListTrashArgs struct {
+		WorkspaceSlug string
+		UserID        int32
+	}
+
+	ListTrashResult struct {
+		TotalCount int64
+		Entries    []models.TrashedEntry
+	}
+
+	RestoredEntries struct {
+		Entries []pgtype.UUID
+		// QueuedIDs are the internal IDs of the entries that were deleted before they could be processed, they have to be sent to the queue again
+		QueuedIDs []int32
+	}
+
+	PurgedEntries struct {
+		Entries []pgtype.UUID
+		// FileIDs are the files that are not referenced by any entry anymore, they have to be removed from the object store
+		FileIDs []string
+	}
+
+	ArchiveEntriesArgs struct {
+		WorkspaceSlug string
+		Entries       []pgtype.UUID
+	}
+
+	UnarchivedEntries struct {
+		Entries []pgtype.UUID
+		// QueuedIDs are the internal IDs of the entries that were archived before they could be processed, they have to be sent to the queue again
+		QueuedIDs []int32
 	}
 
 	EntryOwnership struct {
@@ -106,18 +156,75 @@ type (
 	UpdateChunkSemanticVectorArgs struct {
 		ChunkID int32
 		Vector  []float32
-		Status  queries.EntryChunkEmbeddingStatus
-		Error   error
+		// Model is the model that generated the vector, it is only required when the status is done
+		Model  models.EmbeddingModel
+		Status queries.EntryChunkEmbeddingStatus
+		Error  error
+	}
+
+	// SearchFilters narrows down hybrid search results, all fields are optional and empty fields are ignored
+	SearchFilters struct {
+		Types           []document.EntryType
+		CollectionIDs   []pgtype.UUID
+		CollectionSlugs []string
+		// AddedBy is a list of usernames
+		AddedBy []string
+		// Tags is a list of tag names, entries must have all of them
+		Tags []string
+
+		CreatedAfter  time.Time
+		CreatedBefore time.Time
+		UpdatedAfter  time.Time
+		UpdatedBefore time.Time
+
+		// Statuses defaults to completed entries only when empty
+		Statuses []queries.EntryStatus
+
+		// Title is a list of terms that must all appear in the entry's title
+		Title []string
+		// Excluded is a list of terms and phrases that must not appear in the entry's title or the matched chunk
+		Excluded []string
+
+		// IncludeArchived adds the archived entries to the results
+		IncludeArchived bool
+		// ArchivedOnly only searches the archived entries, it takes precedence over IncludeArchived
+		ArchivedOnly bool
+
+		// matchNone is set when merged filters have nothing in common, no entry can match them
+		matchNone bool
+	}
+
+	// RRFParams configures Reciprocal Rank Fusion of the semantic and full-text result lists
+	RRFParams struct {
+		K              float64
+		SemanticWeight float64
+		FullTextWeight float64
 	}
 
 	HybridSearchArgs struct {
 		Context        context.Context
-		TextQuery      string
+		Query          *SearchQuery
 		SemanticVector []float32
+		// EmbeddingModel is the model that generated the semantic vector, only chunks embedded with the same model are compared with it (the vector is nil and the semantic leg skipped if embeddings are disabled or the query only has operators)
+		EmbeddingModel models.EmbeddingModel
 
 		UserID     int32
 		Workspace  PublicIdOrSlug
+		Filters    SearchFilters
 		Pagination PaginationParams
+
+		// RankFusion switches the ranking to Reciprocal Rank Fusion when set, otherwise the database score is used
+		RankFusion *RRFParams
+
+		// FuzzyMinResults is the number of entries matched by the full-text search below which the trigram fuzzy search is also run, 0 disables it
+		FuzzyMinResults int
+	}
+
+	FindRelatedEntriesArgs struct {
+		Context context.Context
+		EntryID pgtype.UUID
+		UserID  int32
+		Limit   int32
 	}
 
 	// Cache entry
@@ -155,9 +262,42 @@ type (
 		// UpdateEntry updates an existing entry
 		UpdateEntry(args *UpdateEntryArgs) error
 
-		// Delete multiple entries
+		// Delete moves multiple entries along with all their versions to the trash
 		Delete(args DeleteEntriesArgs) ([]pgtype.UUID, error)
 
+		// ListTrash returns the deleted entries of a workspace that the user has access to
+		ListTrash(args *ListTrashArgs, pagination PaginationParams) (ListTrashResult, error)
+
+		// Restore brings deleted entries back from the trash, their chunks are searchable again without being re-embedded
+		Restore(args DeleteEntriesArgs) (RestoredEntries, error)
+
+		// Purge permanently deletes entries in the trash along with all their versions and chunks
+		Purge(args DeleteEntriesArgs) (PurgedEntries, error)
+
+		// PurgeExpired permanently deletes up to `limit` entries that have been in the trash for longer than the retention period of their workspace
+		PurgeExpired(limit int32) (PurgedEntries, error)
+
+		// RecordFailedFileDeletions records the files of purged entries that could not be removed from the object store
+		RecordFailedFileDeletions(fileIDs []string, reason error) error
+
+		// FindFailedFileDeletions returns up to `limit` files whose removal should be retried
+		FindFailedFileDeletions(limit int32) ([]string, error)
+
+		// ClearFailedFileDeletions forgets the given files once they have been removed from the object store
+		ClearFailedFileDeletions(fileIDs []string) error
+
+		// Archive archives entries along with all their versions, archived entries are hidden from listings and search
+		Archive(args ArchiveEntriesArgs) ([]pgtype.UUID, error)
+
+		// Unarchive restores archived entries along with all their versions
+		Unarchive(args ArchiveEntriesArgs) (UnarchivedEntries, error)
+
+		// Move moves entries along with all their versions to another collection, possibly in another workspace
+		Move(args MoveEntriesArgs) ([]pgtype.UUID, error)
+
+		// Copy duplicates the latest version of entries into another collection along with their chunks and files, the copies start their own version history
+		Copy(args *CopyEntriesArgs) (CopiedEntries, error)
+
 		// GetOwnership returns the ownership status of entries for a given user
 		GetOwnerships(userID int32, entryIDs []pgtype.UUID) ([]EntryOwnership, error)
 
@@ -190,6 +330,24 @@ type (
 
 		// QueryWithHybridSearch searches for a query using hybrid search
 		QueryWithHybridSearch(args *HybridSearchArgs) (*models.HybridSearchResults, error)
+
+		// CountSearchFacets counts the entries matched by a search per type and collection, the candidates are the results that survived the ranking
+		CountSearchFacets(
+			args *HybridSearchArgs,
+			candidates []models.CollapsedSearchResult,
+		) (models.SearchFacets, error)
+
+		// FindRelatedEntries computes the entries that are semantically similar to the given entry
+		FindRelatedEntries(args *FindRelatedEntriesArgs) ([]models.RelatedEntry, error)
+
+		// FindCachedRelatedEntries returns the cached related entries, they are computed on the fly if the entry has not been cached yet
+		FindCachedRelatedEntries(args *FindRelatedEntriesArgs) ([]models.RelatedEntry, error)
+
+		// RefreshRelatedEntries recomputes the cached related entries for an entry
+		RefreshRelatedEntries(entryID int32) error
+
+		// IsEntryEmbedded returns the ID of the chunk's entry and whether all of its chunks have been embedded
+		IsEntryEmbedded(chunkID int32) (int32, bool, error)
 	}
 
 	entryRepo struct {
@@ -210,13 +368,17 @@ We do this in the following way:
 
     hybrid_score = (semantic_score * DefaultSemanticWeight) + (text_score * DefaultFullTextWeight) + KeywordScore
 
-  - We merge the results from both searches into a single list
+  - If `RankFusion` is set, the hybrid score is instead replaced with the (normalized) Reciprocal Rank Fusion score of the chunk across both lists, see `FuseWithRRF`
+
+  - If the full-text search matches fewer than `FuzzyMinResults` entries, we run a trigram (`pg_trgm`) word-similarity search over the entry names and chunk contents to catch misspellings, these results are scored with `fuzzy_score * DefaultFuzzyWeight`
+
+  - We merge the results from all searches into a single list
 
   - We sort the results by the hybrid score
 
   - Finally we return the results
 
-NOTE: while fuzzy search *could* increase the results, it is not used here because it is not very performant and somewhat unnecessary at the moment
+NOTE: the fuzzy search only runs as a fallback because trigram matching is a lot slower than the other legs, even with the GIN indexes
 */
 func (e *entryRepo) QueryWithHybridSearch(
 	args *HybridSearchArgs,
@@ -227,18 +389,43 @@ func (e *entryRepo) QueryWithHybridSearch(
 	}
 
 	searchResult := make([]models.SearchResult, 0)
+	if args.Filters.MatchesNothing() {
+		return e.RerankResults(args.Query, searchResult), nil
+	}
+
+	// The semantic leg can only use the HNSW index of the model's dimensions
+	searchQueries := e.queries
+	if args.EmbeddingModel.Dimensions > 0 {
+		searchQueries = e.queries.WithDimensions(args.EmbeddingModel.Dimensions)
+	}
 
 	// Search with semantic vector
-	rows, err := e.queries.QueryWithHybridSearch(
+	rows, err := searchQueries.QueryWithHybridSearch(
 		ctx,
 		queries.QueryWithHybridSearchParams{
-			Embedding:         pgvector.NewVector(args.SemanticVector),
-			WorkspacePublicID: args.Workspace.PublicID,
-			WorkspaceSlug:     lib.PgText(args.Workspace.Slug),
-			UserID:            args.UserID,
-			Query:             args.TextQuery,
-			Limit:             args.Pagination.Limit(),
-			Offset:            args.Pagination.Offset(),
+			Embedding:           args.SemanticVector,
+			EmbeddingModel:      lib.PgText(args.EmbeddingModel.Name),
+			EmbeddingDimensions: lib.PgInt4(args.EmbeddingModel.Dimensions),
+			WorkspacePublicID:   args.Workspace.PublicID,
+			WorkspaceSlug:       lib.PgText(args.Workspace.Slug),
+			UserID:              args.UserID,
+			Query:               args.Query.FullTextQuery(),
+			Limit:               args.Pagination.Limit(),
+			Offset:              args.Pagination.Offset(),
+			Statuses:            args.Filters.statuses(),
+			EntryTypes:          args.Filters.entryTypes(),
+			CollectionIds:       nilIfEmpty(args.Filters.CollectionIDs),
+			CollectionSlugs:     nilIfEmpty(args.Filters.CollectionSlugs),
+			AddedBy:             nilIfEmpty(args.Filters.AddedBy),
+			Tags:                args.Filters.tags(),
+			CreatedAfter:        lib.PgTimestamptz(args.Filters.CreatedAfter),
+			CreatedBefore:       lib.PgTimestamptz(args.Filters.CreatedBefore),
+			UpdatedAfter:        lib.PgTimestamptz(args.Filters.UpdatedAfter),
+			UpdatedBefore:       lib.PgTimestamptz(args.Filters.UpdatedBefore),
+			TitlePatterns:       likePatterns(args.Filters.Title),
+			ExcludedPatterns:    likePatterns(args.Filters.Excluded),
+			ArchivedOnly:        args.Filters.ArchivedOnly,
+			IncludeArchived:     args.Filters.IncludeArchived,
 		},
 	)
 	if err != nil {
@@ -251,14 +438,25 @@ func (e *entryRepo) QueryWithHybridSearch(
 	for i := range rows {
 		row := &rows[i]
 		meta, _ := models.UnmarshalEntryMetadata(row.Meta, document.EntryType(row.Type))
+
+		// Only the full-text leg of the query produces a text score
+		source := models.SearchTypeSemantic
+		if row.TextScore.Valid {
+			source = models.SearchTypeFullText
+		}
+
+		snippet, highlights := parseHeadline(row.Headline.String)
+
 		searchResult = append(searchResult, models.SearchResult{
-			ID:      row.PublicID,
-			Rank:    float32(row.Rank),
-			Name:    row.Title,
-			Preview: row.ChunkContent.String,
-			Type:    document.EntryType(row.Type),
-			Status:  row.Status,
-			Source:  models.SearchTypeSemantic,
+			ID:         row.PublicID,
+			Rank:       float32(row.Rank),
+			Name:       row.Title,
+			Preview:    row.ChunkContent.String,
+			Snippet:    snippet,
+			Highlights: highlights,
+			Type:       document.EntryType(row.Type),
+			Status:     row.Status,
+			Source:     source,
 			Chunk: models.SearchResultChunkMetadata{
 				ID:    row.ChunkID,
 				Index: row.ChunkIndex,
@@ -279,6 +477,241 @@ func (e *entryRepo) QueryWithHybridSearch(
 			HybridScore:   row.Score,
 			TextScore:     row.TextScore.Float64,
 			SemanticScore: row.SemanticScore,
+			FuzzyScore:    0,
+			MatchedBy:     []models.SearchSource{},
+			Metadata:      meta,
+			FileID:        row.FileID.String,
+			FilesizeBytes: row.FilesizeBytes,
+		})
+	}
+
+	if args.RankFusion != nil {
+		searchResult = FuseWithRRF(searchResult, args.RankFusion)
+	}
+
+	if !args.Query.FilterOnly() && NeedsFuzzyFallback(searchResult, args.FuzzyMinResults) {
+		searchResult, err = e.appendFuzzyResults(ctx, args, searchResult)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	searchResult, err = e.attributeComments(ctx, &args.Filters, searchResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.RerankResults(args.Query, searchResult), nil
+}
+
+// CountSearchFacets implements EntryRepository.
+// The counts cover every entry that matches the full-text query with the same filters, not just the candidate window of the search, semantic and fuzzy matches are only known through the candidates
+func (e *entryRepo) CountSearchFacets(
+	args *HybridSearchArgs,
+	candidates []models.CollapsedSearchResult,
+) (models.SearchFacets, error) {
+	ctx := args.Context
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	if args.Filters.MatchesNothing() {
+		return BuildFacets(nil), nil
+	}
+
+	candidateIDs := make([]pgtype.UUID, 0, len(candidates))
+	for i := range candidates {
+		candidateIDs = append(candidateIDs, candidates[i].ID)
+	}
+
+	rows, err := e.queries.CountSearchFacets(ctx, queries.CountSearchFacetsParams{
+		CandidateIds:      candidateIDs,
+		Query:             args.Query.FullTextQuery(),
+		WorkspacePublicID: args.Workspace.PublicID,
+		WorkspaceSlug:     lib.PgText(args.Workspace.Slug),
+		UserID:            args.UserID,
+		Statuses:          args.Filters.statuses(),
+		EntryTypes:        args.Filters.entryTypes(),
+		CollectionIds:     nilIfEmpty(args.Filters.CollectionIDs),
+		CollectionSlugs:   nilIfEmpty(args.Filters.CollectionSlugs),
+		AddedBy:           nilIfEmpty(args.Filters.AddedBy),
+		Tags:              args.Filters.tags(),
+		CreatedAfter:      lib.PgTimestamptz(args.Filters.CreatedAfter),
+		CreatedBefore:     lib.PgTimestamptz(args.Filters.CreatedBefore),
+		UpdatedAfter:      lib.PgTimestamptz(args.Filters.UpdatedAfter),
+		UpdatedBefore:     lib.PgTimestamptz(args.Filters.UpdatedBefore),
+		TitlePatterns:     likePatterns(args.Filters.Title),
+		ExcludedPatterns:  likePatterns(args.Filters.Excluded),
+		ArchivedOnly:      args.Filters.ArchivedOnly,
+		IncludeArchived:   args.Filters.IncludeArchived,
+	})
+	if err != nil {
+		return models.SearchFacets{}, seer.Wrap("count_search_facets", err)
+	}
+
+	counts := make([]FacetCount, 0, len(rows))
+	for i := range rows {
+		counts = append(counts, FacetCount{
+			Type: rows[i].Type,
+			Collection: models.EntryRelation{
+				ID:   rows[i].CollectionID,
+				Name: rows[i].CollectionName,
+				Slug: rows[i].CollectionSlug.String,
+			},
+			Count: int(rows[i].Entries),
+		})
+	}
+
+	return BuildFacets(counts), nil
+}
+
+// attributeComments replaces the comments in the results with the latest version of the entries they were made on, comments on entries that are deleted or excluded by the archive, type or tag filters are dropped
+func (e *entryRepo) attributeComments(
+	ctx context.Context,
+	filters *SearchFilters,
+	searchResult []models.SearchResult,
+) ([]models.SearchResult, error) {
+	commentIDs := make([]pgtype.UUID, 0)
+	for i := range searchResult {
+		if searchResult[i].Type == document.EntryTypeComment {
+			commentIDs = append(commentIDs, searchResult[i].ID)
+		}
+	}
+
+	if len(commentIDs) == 0 {
+		return searchResult, nil
+	}
+
+	rows, err := e.queries.FindCommentedEntries(ctx, lib.UniqueSlice(commentIDs))
+	if err != nil {
+		return nil, seer.Wrap("find_commented_entries", err)
+	}
+
+	entries := make(map[pgtype.UUID]*queries.FindCommentedEntriesRow, len(rows))
+	for i := range rows {
+		entries[rows[i].CommentID] = &rows[i]
+	}
+
+	attributed := make([]models.SearchResult, 0, len(searchResult))
+	for _, result := range searchResult {
+		if result.Type != document.EntryTypeComment {
+			attributed = append(attributed, result)
+			continue
+		}
+
+		entry, ok := entries[result.ID]
+		if !ok {
+			continue
+		}
+
+		archived := entry.ArchivedAt.Valid
+		if (filters.ArchivedOnly && !archived) ||
+			(!filters.ArchivedOnly && !filters.IncludeArchived && archived) {
+			continue
+		}
+
+		if !filters.MatchesCommentedEntry(entry.Type, entry.Tags) {
+			continue
+		}
+
+		meta, _ := models.UnmarshalEntryMetadata(entry.Meta, entry.Type)
+		result.CommentID = result.ID
+		result.ID = entry.PublicID
+		result.Name = entry.Name
+		result.Type = entry.Type
+		result.Status = entry.Status
+		result.Metadata = meta
+		result.FileID = entry.FileID.String
+		result.FilesizeBytes = entry.FilesizeBytes
+		result.CreatedAt = entry.CreatedAt.Time
+		result.UpdatedAt = entry.UpdatedAt.Time
+		result.ArchivedAt = entry.ArchivedAt.Time
+		attributed = append(attributed, result)
+	}
+
+	return attributed, nil
+}
+
+// appendFuzzyResults runs the trigram search and adds the chunks that were not found by the other legs, chunks that were already found are only tagged as fuzzy matches
+func (e *entryRepo) appendFuzzyResults(
+	ctx context.Context,
+	args *HybridSearchArgs,
+	searchResult []models.SearchResult,
+) ([]models.SearchResult, error) {
+	rows, err := e.queries.QueryWithFuzzySearch(ctx, queries.QueryWithFuzzySearchParams{
+		Limit:             args.Pagination.Limit(),
+		Offset:            args.Pagination.Offset(),
+		Query:             args.Query.Text(),
+		Statuses:          args.Filters.statuses(),
+		WorkspacePublicID: args.Workspace.PublicID,
+		WorkspaceSlug:     lib.PgText(args.Workspace.Slug),
+		UserID:            args.UserID,
+		EntryTypes:        args.Filters.entryTypes(),
+		CollectionIds:     nilIfEmpty(args.Filters.CollectionIDs),
+		CollectionSlugs:   nilIfEmpty(args.Filters.CollectionSlugs),
+		AddedBy:           nilIfEmpty(args.Filters.AddedBy),
+		Tags:              args.Filters.tags(),
+		CreatedAfter:      lib.PgTimestamptz(args.Filters.CreatedAfter),
+		CreatedBefore:     lib.PgTimestamptz(args.Filters.CreatedBefore),
+		UpdatedAfter:      lib.PgTimestamptz(args.Filters.UpdatedAfter),
+		UpdatedBefore:     lib.PgTimestamptz(args.Filters.UpdatedBefore),
+		TitlePatterns:     likePatterns(args.Filters.Title),
+		ExcludedPatterns:  likePatterns(args.Filters.Excluded),
+		ArchivedOnly:      args.Filters.ArchivedOnly,
+		IncludeArchived:   args.Filters.IncludeArchived,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return searchResult, nil
+		}
+		return nil, seer.Wrap("query_with_fuzzy_search", err)
+	}
+
+	found := make(map[int32]int, len(searchResult))
+	for i := range searchResult {
+		found[searchResult[i].Chunk.ID] = i
+	}
+
+	for i := range rows {
+		row := &rows[i]
+
+		if idx, ok := found[row.ChunkID]; ok {
+			searchResult[idx].FuzzyScore = row.FuzzyScore
+			continue
+		}
+
+		meta, _ := models.UnmarshalEntryMetadata(row.Meta, row.Type)
+		searchResult = append(searchResult, models.SearchResult{
+			ID:         row.PublicID,
+			Rank:       float32(i + 1),
+			Name:       row.Title,
+			Preview:    row.ChunkContent.String,
+			Snippet:    "",
+			Highlights: []models.Highlight{},
+			Type:       row.Type,
+			Status:     row.Status,
+			Source:     models.SearchTypeFuzzy,
+			Chunk: models.SearchResultChunkMetadata{
+				ID:    row.ChunkID,
+				Index: row.ChunkIndex,
+			},
+			Collection: models.EntryRelation{
+				ID:   row.CollectionID,
+				Name: row.CollectionName,
+				Slug: row.CollectionSlug.String,
+			},
+			Workspace: models.EntryRelation{
+				ID:   row.WorkspaceID,
+				Name: row.WorkspaceName,
+				Slug: row.WorkspaceSlug.String,
+			},
+			CreatedAt:     row.CreatedAt.Time,
+			UpdatedAt:     row.UpdatedAt.Time,
+			ArchivedAt:    row.ArchivedAt.Time,
+			HybridScore:   row.FuzzyScore * DefaultFuzzyWeight,
+			TextScore:     0,
+			SemanticScore: 0,
+			FuzzyScore:    row.FuzzyScore,
 			MatchedBy:     []models.SearchSource{},
 			Metadata:      meta,
 			FileID:        row.FileID.String,
@@ -286,11 +719,11 @@ func (e *entryRepo) QueryWithHybridSearch(
 		})
 	}
 
-	return e.RerankResults(args.TextQuery, searchResult), nil
+	return searchResult, nil
 }
 
 func (e *entryRepo) RerankResults(
-	query string,
+	query *SearchQuery,
 	results []models.SearchResult,
 ) *models.HybridSearchResults {
 	dedupMap := map[int32]*models.SearchResult{}
@@ -301,9 +734,21 @@ func (e *entryRepo) RerankResults(
 		}
 	}
 
-	containskeyword := func(result *models.SearchResult, query string) bool {
-		return strings.Contains(strings.ToLower(result.Name), strings.ToLower(query)) ||
-			strings.Contains(strings.ToLower(result.Preview), strings.ToLower(query))
+	keywords := make([]string, 0, len(query.Keywords()))
+	for _, keyword := range query.Keywords() {
+		keywords = append(keywords, strings.ToLower(keyword))
+	}
+
+	// Every term and phrase has to appear in either the name or the preview for the keyword bonus
+	containskeyword := func(result *models.SearchResult) bool {
+		name, preview := strings.ToLower(result.Name), strings.ToLower(result.Preview)
+		for _, keyword := range keywords {
+			if !strings.Contains(name, keyword) && !strings.Contains(preview, keyword) {
+				return false
+			}
+		}
+
+		return len(keywords) > 0
 	}
 
 	// Merge vector results into the dedup map
@@ -318,11 +763,20 @@ func (e *entryRepo) RerankResults(
 			addMatchedBy(result, models.SearchTypeSemantic)
 		}
 
-		if containskeyword(result, query) {
+		if result.FuzzyScore != 0.0 {
+			addMatchedBy(result, models.SearchTypeFuzzy)
+		}
+
+		if containskeyword(result) {
 			result.HybridScore += KeywordScore
 			addMatchedBy(result, models.SearchTypeKeyword)
 		}
 
+		// The same chunk can be returned by both legs, only the full-text one has a snippet
+		if existing, ok := dedupMap[result.Chunk.ID]; ok && result.Snippet == "" {
+			result.Snippet, result.Highlights = existing.Snippet, existing.Highlights
+		}
+
 		dedupMap[result.Chunk.ID] = result
 	}
 
@@ -395,8 +849,10 @@ func (e *entryRepo) UpdateSemanticVectorState(args *UpdateChunkSemanticVectorArg
 		}
 
 		return e.queries.UpdateChunkSemanticVector(ctx, queries.UpdateChunkSemanticVectorParams{
-			SemanticVector: pgvector.NewVector(args.Vector),
-			ChunkID:        args.ChunkID,
+			SemanticVector:      pgvector.NewVector(args.Vector),
+			EmbeddingModel:      lib.PgText(args.Model.Name),
+			EmbeddingDimensions: lib.PgInt4(args.Model.Dimensions),
+			ChunkID:             args.ChunkID,
 		})
 
 	case queries.EntryChunkEmbeddingStatusFailed:
@@ -502,6 +958,11 @@ func (e *entryRepo) FindByID(args *FindbyIdArgs) (models.Entry, error) {
 
 	meta, _ := models.UnmarshalEntryMetadata(row.Meta, row.Type)
 
+	tags, err := e.TagRepository().FindEntriesTags([]int32{row.ID})
+	if err != nil {
+		return models.Entry{}, err
+	}
+
 	return models.Entry{
 		ID:            row.ID,
 		PublicID:      row.PublicID,
@@ -535,6 +996,8 @@ func (e *entryRepo) FindByID(args *FindbyIdArgs) (models.Entry, error) {
 			Slug: row.WorkspaceSlug.String,
 		},
 		Metadata: meta,
+		Tags:     entryTags(tags, row.ID),
+		Summary:  entrySummary(row.SummaryShort, row.SummaryLong, row.SummaryVersion),
 	}, nil
 }
 
@@ -598,6 +1061,7 @@ func (e *entryRepo) CreateChunks(args *CreateChunksArgs) (int64, error) {
 	queriesWithTx := e.queries.WithTx(tx)
 
 	idsMap := map[pgtype.UUID]int32{}
+	versionsMap := map[pgtype.UUID]int32{}
 	ids := make([]pgtype.UUID, 0, len(idsMap))
 	for _, arg := range args.Chunks {
 		_, ok := idsMap[arg.EntryID]
@@ -618,15 +1082,17 @@ func (e *entryRepo) CreateChunks(args *CreateChunksArgs) (int64, error) {
 	for _, resolved := range resolvedIds {
 		if resolved.PublicID.Valid {
 			idsMap[resolved.PublicID] = resolved.ID
+			versionsMap[resolved.PublicID] = resolved.Version
 		}
 	}
 
 	params := make([]queries.InsertChunksParams, 0)
 	for _, arg := range args.Chunks {
 		id := idsMap[arg.EntryID]
+		// Chunks are only valid from the version of the entry they were created for
 		minVersion := arg.MinimumVersion
 		if minVersion == 0 {
-			minVersion = 1
+			minVersion = max(versionsMap[arg.EntryID], 1)
 		}
 		params = append(params, queries.InsertChunksParams{
 			EntryID:    lib.PgInt4(id),
@@ -689,31 +1155,238 @@ func (e *entryRepo) GetOwnerships(userID int32, entryIDs []pgtype.UUID) ([]Entry
 	return ownership, nil
 }
 
+// Archive implements EntryRepository.
+func (e *entryRepo) Archive(args ArchiveEntriesArgs) ([]pgtype.UUID, error) {
+	archived, err := e.queries.ArchiveEntries(context.TODO(), queries.ArchiveEntriesParams{
+		EntryPublicIds: args.Entries,
+		WorkspaceSlug:  lib.PgText(args.WorkspaceSlug),
+	})
+	if err != nil {
+		return nil, seer.Wrap("archive_entries", err)
+	}
+
+	return archived, nil
+}
+
+// Unarchive implements EntryRepository.
+func (e *entryRepo) Unarchive(args ArchiveEntriesArgs) (UnarchivedEntries, error) {
+	rows, err := e.queries.UnarchiveEntries(context.TODO(), queries.UnarchiveEntriesParams{
+		EntryPublicIds: args.Entries,
+		WorkspaceSlug:  lib.PgText(args.WorkspaceSlug),
+	})
+	if err != nil {
+		return UnarchivedEntries{}, seer.Wrap("unarchive_entries", err)
+	}
+
+	result := UnarchivedEntries{
+		Entries:   make([]pgtype.UUID, 0, len(rows)),
+		QueuedIDs: make([]int32, 0),
+	}
+
+	for _, row := range rows {
+		result.Entries = append(result.Entries, row.PublicID)
+		if row.QueuedEntryID.Valid {
+			result.QueuedIDs = append(result.QueuedIDs, row.QueuedEntryID.Int32)
+		}
+	}
+
+	return result, nil
+}
+
 // Delete implements EntryRepository.
 func (e *entryRepo) Delete(args DeleteEntriesArgs) ([]pgtype.UUID, error) {
-	tx, err := e.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	// The queue records are kept so that entries restored before they were processed can be picked up again
+	deletedIds, err := e.queries.DeleteEntries(context.TODO(), queries.DeleteEntriesParams{
+		EntryPublicIds: args.Entries,
+		WorkspaceSlug:  lib.PgText(args.WorkspaceSlug),
+	})
 	if err != nil {
-		return nil, seer.Wrap("begin_tx", err)
+		return nil, seer.Wrap("delete_entries", err)
 	}
 
-	q := e.queries.WithTx(tx)
+	return deletedIds, nil
+}
 
-	// Remove all queued entries first
-	if _, err = q.DequeueEntries(context.TODO(), args.Entries); err != nil {
-		return nil, seer.Wrap("dequeue_entries", tx.Rollback(context.TODO()))
+// ListTrash implements EntryRepository.
+func (e *entryRepo) ListTrash(
+	args *ListTrashArgs,
+	pagination PaginationParams,
+) (ListTrashResult, error) {
+	result := ListTrashResult{TotalCount: 0, Entries: make([]models.TrashedEntry, 0)}
+
+	rows, err := e.queries.ListTrash(context.TODO(), queries.ListTrashParams{
+		Limit:         pagination.Limit(),
+		Offset:        pagination.Offset(),
+		UserID:        args.UserID,
+		WorkspaceSlug: lib.PgText(args.WorkspaceSlug),
+	})
+	if err != nil {
+		return result, seer.Wrap("list_trash", err)
 	}
 
-	// Delete entries
-	deletedIds, err := q.DeleteEntries(context.TODO(), args.Entries)
+	for i := range rows {
+		row := &rows[i]
+		if result.TotalCount == 0 {
+			result.TotalCount = row.TotalEntries
+		}
+
+		result.Entries = append(result.Entries, models.TrashedEntry{
+			PublicID:      row.PublicID,
+			OriginID:      row.Origin,
+			Name:          row.Name,
+			Version:       row.Version,
+			Type:          document.EntryType(row.Type),
+			FilesizeBytes: row.FilesizeBytes,
+			AddedBy: models.EntryAddedBy{
+				FirstName: row.AddedByFirstName,
+				LastName:  row.AddedByLastName,
+				Username:  row.AddedByUsername,
+			},
+			Collection: models.EntryRelation{
+				ID:   row.CollectionID,
+				Name: row.CollectionName,
+				Slug: row.CollectionSlug.String,
+			},
+			CreatedAt: row.CreatedAt.Time,
+			UpdatedAt: row.UpdatedAt.Time,
+			DeletedAt: row.DeletedAt.Time,
+			PurgeAt:   row.PurgeAt.Time,
+		})
+	}
+
+	if len(result.Entries) > int(pagination.PerPage) {
+		result.Entries = lib.WithMaxSize(result.Entries, pagination.PerPage)
+	}
+
+	return result, nil
+}
+
+// Restore implements EntryRepository.
+func (e *entryRepo) Restore(args DeleteEntriesArgs) (RestoredEntries, error) {
+	rows, err := e.queries.RestoreEntries(context.TODO(), queries.RestoreEntriesParams{
+		EntryPublicIds: args.Entries,
+		WorkspaceSlug:  lib.PgText(args.WorkspaceSlug),
+	})
 	if err != nil {
-		return nil, seer.Wrap("delete_entries", tx.Rollback(context.TODO()))
+		return RestoredEntries{}, seer.Wrap("restore_entries", err)
 	}
 
-	if err := tx.Commit(context.TODO()); err != nil {
-		return nil, seer.Wrap("commit_tx", err)
+	result := RestoredEntries{
+		Entries:   make([]pgtype.UUID, 0, len(rows)),
+		QueuedIDs: make([]int32, 0),
 	}
 
-	return deletedIds, nil
+	for _, row := range rows {
+		result.Entries = append(result.Entries, row.PublicID)
+		if row.QueuedEntryID.Valid {
+			result.QueuedIDs = append(result.QueuedIDs, row.QueuedEntryID.Int32)
+		}
+	}
+
+	return result, nil
+}
+
+// Purge implements EntryRepository.
+func (e *entryRepo) Purge(args DeleteEntriesArgs) (PurgedEntries, error) {
+	rows, err := e.queries.PurgeEntries(context.TODO(), queries.PurgeEntriesParams{
+		EntryPublicIds: args.Entries,
+		WorkspaceSlug:  lib.PgText(args.WorkspaceSlug),
+	})
+	if err != nil {
+		return PurgedEntries{}, seer.Wrap("purge_entries", err)
+	}
+
+	purged := make([]purgedEntry, 0, len(rows))
+	for _, row := range rows {
+		purged = append(purged, purgedEntry{PublicID: row.PublicID, FileID: row.FileID})
+	}
+
+	return makePurgedEntries(purged), nil
+}
+
+// PurgeExpired implements EntryRepository.
+func (e *entryRepo) PurgeExpired(limit int32) (PurgedEntries, error) {
+	rows, err := e.queries.PurgeExpiredEntries(context.TODO(), limit)
+	if err != nil {
+		return PurgedEntries{}, seer.Wrap("purge_expired_entries", err)
+	}
+
+	purged := make([]purgedEntry, 0, len(rows))
+	for _, row := range rows {
+		purged = append(purged, purgedEntry{PublicID: row.PublicID, FileID: row.FileID})
+	}
+
+	return makePurgedEntries(purged), nil
+}
+
+// RecordFailedFileDeletions implements EntryRepository.
+func (e *entryRepo) RecordFailedFileDeletions(fileIDs []string, reason error) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	lastError := ""
+	if reason != nil {
+		lastError = reason.Error()
+	}
+
+	err := e.queries.RecordFailedFileDeletions(
+		context.TODO(),
+		queries.RecordFailedFileDeletionsParams{FileIds: fileIDs, LastError: lastError},
+	)
+	if err != nil {
+		return seer.Wrap("record_failed_file_deletions", err)
+	}
+
+	return nil
+}
+
+// FindFailedFileDeletions implements EntryRepository.
+func (e *entryRepo) FindFailedFileDeletions(limit int32) ([]string, error) {
+	fileIDs, err := e.queries.FindFailedFileDeletions(context.TODO(), limit)
+	if err != nil {
+		return nil, seer.Wrap("find_failed_file_deletions", err)
+	}
+
+	return fileIDs, nil
+}
+
+// ClearFailedFileDeletions implements EntryRepository.
+func (e *entryRepo) ClearFailedFileDeletions(fileIDs []string) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	if err := e.queries.DeleteFailedFileDeletions(context.TODO(), fileIDs); err != nil {
+		return seer.Wrap("delete_failed_file_deletions", err)
+	}
+
+	return nil
+}
+
+type purgedEntry struct {
+	PublicID pgtype.UUID
+	FileID   pgtype.Text
+}
+
+// makePurgedEntries dedupes the entries and files of the purged versions, a file can be shared by restored versions of the same entry
+func makePurgedEntries(rows []purgedEntry) PurgedEntries {
+	result := PurgedEntries{
+		Entries: make([]pgtype.UUID, 0, len(rows)),
+		FileIDs: make([]string, 0),
+	}
+
+	for _, row := range rows {
+		if !slices.Contains(result.Entries, row.PublicID) {
+			result.Entries = append(result.Entries, row.PublicID)
+		}
+
+		if row.FileID.Valid && !slices.Contains(result.FileIDs, row.FileID.String) {
+			result.FileIDs = append(result.FileIDs, row.FileID.String)
+		}
+	}
+
+	return result
 }
 
 // FindByWorkspaceID implements EntryRepository.
@@ -724,17 +1397,10 @@ func (e *entryRepo) FindAllWithPagination(
 	result := FindEntriesByWorkspaceResult{
 		TotalCount: 0,
 		Entries:    make([]models.Entry, 0),
+		NextCursor: nil,
 	}
 
-	rows, err := e.queries.FindEntries(context.TODO(), queries.FindEntriesParams{
-		Limit:              pagination.Limit(),
-		Offset:             pagination.Offset(),
-		WorkspacePublicID:  args.Workspace.PublicID,
-		WorkspaceSlug:      lib.PgText(args.Workspace.Slug),
-		CollectionPublicID: args.Collection.PublicID,
-		CollectionSlug:     lib.PgText(args.Collection.Slug),
-		UserID:             args.UserID,
-	})
+	rows, err := e.findEntries(args, pagination)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return result, nil
@@ -784,14 +1450,91 @@ func (e *entryRepo) FindAllWithPagination(
 				Name: row.WorkspaceName,
 				Slug: row.WorkspaceSlug.String,
 			},
+			Tags:    nil,
+			Summary: nil,
 		})
 	}
 
-	result.Entries = lib.WithMaxSize(result.Entries, pagination.PerPage)
+	if len(result.Entries) > int(pagination.PerPage) {
+		result.Entries = lib.WithMaxSize(result.Entries, pagination.PerPage)
+		result.NextCursor = entryCursor(&result.Entries[len(result.Entries)-1])
+	}
+
+	ids := make([]int32, 0, len(result.Entries))
+	for i := range result.Entries {
+		ids = append(ids, result.Entries[i].ID)
+	}
+
+	tags, err := e.TagRepository().FindEntriesTags(ids)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range result.Entries {
+		result.Entries[i].Tags = entryTags(tags, result.Entries[i].ID)
+	}
 
 	return result, nil
 }
 
+// findEntries runs the keyset query if a cursor was provided, otherwise the page is loaded with an offset
+func (e *entryRepo) findEntries(
+	args *FindEntriesArgs,
+	pagination PaginationParams,
+) ([]queries.FindEntriesRow, error) {
+	if args.After == nil {
+		return e.queries.FindEntries(context.TODO(), queries.FindEntriesParams{
+			Limit:              pagination.Limit(),
+			Offset:             pagination.Offset(),
+			WorkspacePublicID:  args.Workspace.PublicID,
+			WorkspaceSlug:      lib.PgText(args.Workspace.Slug),
+			CollectionPublicID: args.Collection.PublicID,
+			CollectionSlug:     lib.PgText(args.Collection.Slug),
+			UserID:             args.UserID,
+			Tags:               normalizeTagNames(args.Tags),
+			ArchivedOnly:       args.ArchivedOnly,
+			IncludeArchived:    args.IncludeArchived,
+		})
+	}
+
+	rows, err := e.queries.FindEntriesAfterCursor(
+		context.TODO(),
+		queries.FindEntriesAfterCursorParams{
+			Limit:              pagination.Limit(),
+			WorkspacePublicID:  args.Workspace.PublicID,
+			WorkspaceSlug:      lib.PgText(args.Workspace.Slug),
+			CollectionPublicID: args.Collection.PublicID,
+			CollectionSlug:     lib.PgText(args.Collection.Slug),
+			UserID:             args.UserID,
+			Tags:               normalizeTagNames(args.Tags),
+			ArchivedOnly:       args.ArchivedOnly,
+			IncludeArchived:    args.IncludeArchived,
+			CursorSortKey:      lib.PgTimestamptz(args.After.Timestamp),
+			CursorID:           args.After.ID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]queries.FindEntriesRow, 0, len(rows))
+	for i := range rows {
+		entries = append(entries, queries.FindEntriesRow(rows[i]))
+	}
+
+	return entries, nil
+}
+
+// entryCursor returns the cursor pointing at the given entry in the `coalesce(updated_at, created_at) desc, public_id desc` order
+func entryCursor(entry *models.Entry) *Cursor {
+	sortKey := entry.UpdatedAt
+	if sortKey.IsZero() {
+		sortKey = entry.CreatedAt
+	}
+
+	return &Cursor{Timestamp: sortKey, Score: 0, ID: entry.PublicID}
+}
+
 func (e *entryRepo) EnqueueEntries(entries []EnqueueEntryParams) error {
 	items := make([]queries.EnqueueEntriesParams, 0, len(entries))
 	for _, entry := range entries {
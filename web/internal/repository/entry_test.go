@@ -0,0 +1,140 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/repository"
+)
+
+// addVersion creates the next version of an entry, it is processed and keeps the file of the entry unless another one is given
+func (f *relatedFixture) addVersion(
+	t *testing.T,
+	entryID int32,
+	fileID string,
+) (int32, pgtype.UUID) {
+	t.Helper()
+
+	var (
+		ctx      = context.Background()
+		id       int32
+		publicID pgtype.UUID
+	)
+
+	err := f.pool.QueryRow(ctx, `
+		insert into entries (
+			origin, parent_id, version, name, meta, entry_type, file_id,
+			collection_id, added_by, last_updated_by
+		)
+		select
+			l.origin, coalesce(l.parent_id, l.id), l.version + 1, l.name, l.meta, l.entry_type,
+			coalesce(nullif($2, ''), l.file_id), l.collection_id, l.added_by, l.last_updated_by
+		from entries l
+		where l.id = $1
+		returning id, public_id`, entryID, fileID).Scan(&id, &publicID)
+	if err != nil {
+		t.Fatalf("failed to create version of entry %d: %v", entryID, err)
+	}
+
+	_, err = f.pool.Exec(ctx, `
+		insert into entries_queue (entry_id, payload, status) values ($1, '{}', 'completed')`, id)
+	if err != nil {
+		t.Fatalf("failed to queue version of entry %d: %v", entryID, err)
+	}
+
+	return id, publicID
+}
+
+// setFile sets the file of an entry
+func (f *relatedFixture) setFile(t *testing.T, entryID int32, fileID string) {
+	t.Helper()
+
+	_, err := f.pool.Exec(
+		context.Background(),
+		"update entries set file_id = $2 where id = $1",
+		entryID,
+		fileID,
+	)
+	if err != nil {
+		t.Fatalf("failed to set the file of entry %d: %v", entryID, err)
+	}
+}
+
+// trash moves every version of an entry to the trash
+func (f *relatedFixture) trash(t *testing.T, entryID int32) {
+	t.Helper()
+
+	_, err := f.pool.Exec(context.Background(), `
+		update entries set deleted_at = now()
+		where origin = (select origin from entries where id = $1)`, entryID)
+	if err != nil {
+		t.Fatalf("failed to trash entry %d: %v", entryID, err)
+	}
+}
+
+func Test_Purge_OrphanedFiles(t *testing.T) {
+	pool := testPool(t)
+	f := newRelatedFixture(t, pool)
+
+	// The restored version of the report keeps its file, which is also shared with a copy that is not purged
+	reportID, report := f.addEntry(t, "report", "[1,0,0]", 1)
+	f.setFile(t, reportID, "shared-file")
+	f.addVersion(t, reportID, "")
+	f.trash(t, reportID)
+
+	copyID, copied := f.addEntry(t, "report copy", "[1,0,0]", 1)
+	f.setFile(t, copyID, "shared-file")
+
+	// Every version of the notes has its own file
+	notesID, notes := f.addEntry(t, "notes", "[0,1,0]", 1)
+	f.setFile(t, notesID, "notes-v1")
+	f.addVersion(t, notesID, "notes-v2")
+	f.trash(t, notesID)
+
+	purged, err := repository.New(pool, nil, nil).EntryRepository().Purge(
+		repository.DeleteEntriesArgs{
+			WorkspaceSlug: f.workspaceSlug,
+			// Entries that are not in the trash cannot be purged
+			Entries: []pgtype.UUID{report, notes, copied},
+		},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	slices.SortFunc(purged.Entries, func(a, b pgtype.UUID) int {
+		return slices.Compare(a.Bytes[:], b.Bytes[:])
+	})
+	expected := []pgtype.UUID{report, notes}
+	slices.SortFunc(expected, func(a, b pgtype.UUID) int {
+		return slices.Compare(a.Bytes[:], b.Bytes[:])
+	})
+	if !slices.Equal(purged.Entries, expected) {
+		t.Errorf("expected the report and the notes to be purged, got %v", purged.Entries)
+	}
+
+	slices.Sort(purged.FileIDs)
+	if !slices.Equal(purged.FileIDs, []string{"notes-v1", "notes-v2"}) {
+		t.Errorf("expected only the files of the notes to be removed, got %v", purged.FileIDs)
+	}
+
+	rows, err := pool.Query(
+		context.Background(),
+		"select id from entries where id = any($1::int[]) order by id",
+		[]int32{reportID, notesID, copyID},
+	)
+	if err != nil {
+		t.Fatalf("failed to find the remaining entries: %v", err)
+	}
+	remaining, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	if err != nil {
+		t.Fatalf("failed to read the remaining entries: %v", err)
+	}
+
+	if !slices.Equal(remaining, []int32{copyID}) {
+		t.Errorf("expected only the copy to remain, got %v", remaining)
+	}
+}
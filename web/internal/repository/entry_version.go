@@ -0,0 +1,392 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/diff"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/hubble/web/pkg/ograph"
+	"go.trulyao.dev/seer"
+)
+
+var ErrLatestEntryVersion = apperrors.BadRequest(
+	"this version is already the latest version of the entry",
+)
+
+// MaxChunkDiffBytes is the total size of the chunk contents returned by DiffChunks
+const MaxChunkDiffBytes = diff.DefaultMaxBytes
+
+type (
+	CreateEntryVersionArgs struct {
+		// EntryID is the public ID of any version of the entry
+		EntryID pgtype.UUID
+		UserID  int32
+		// File replaces the file of a file entry
+		File *models.FileEntry
+		// Link is the refreshed metadata of a link entry
+		Link *ograph.Metadata
+	}
+
+	RestoreEntryVersionArgs struct {
+		// EntryID is the public ID of the version to restore
+		EntryID pgtype.UUID
+		UserID  int32
+	}
+
+	CreatedEntryVersion struct {
+		Entry   models.CreatedEntry
+		Version int32
+		// Queued is false if the version reuses the chunks of the version it was restored from and does not need to be processed
+		Queued bool
+	}
+
+	EntryVersionRepository interface {
+		// FindLatest returns the latest version of the entry the given version belongs to
+		FindLatest(entryID pgtype.UUID) (models.Entry, error)
+
+		// FindAll returns all the versions of the entry the given version belongs to, the latest version first
+		FindAll(entryID pgtype.UUID) ([]models.EntryVersion, error)
+
+		// Create adds a new version of an entry with a replaced file or refreshed link, the tags of the previous version are carried over and the version is queued for processing
+		Create(args *CreateEntryVersionArgs) (CreatedEntryVersion, error)
+
+		// Restore adds a new version of an entry that is a copy of an older version, the chunks and their embeddings are copied so that the entry does not need to be processed again
+		Restore(args *RestoreEntryVersionArgs) (CreatedEntryVersion, error)
+
+		// DiffChunks compares the chunks of two versions of an entry by their content, only the content of the added and removed chunks is returned
+		DiffChunks(fromID int32, toID int32) (models.EntryChunkDiff, error)
+	}
+
+	entryVersionRepo struct {
+		*baseRepo
+	}
+)
+
+// FindLatest implements EntryVersionRepository.
+func (e *entryVersionRepo) FindLatest(entryID pgtype.UUID) (models.Entry, error) {
+	latest, err := e.queries.FindLatestEntryVersion(context.TODO(), entryID)
+	if err != nil {
+		return models.Entry{}, err
+	}
+
+	//nolint:exhaustruct
+	return e.EntryRepository().FindByID(&FindbyIdArgs{InternalID: latest.ID})
+}
+
+// FindAll implements EntryVersionRepository.
+func (e *entryVersionRepo) FindAll(entryID pgtype.UUID) ([]models.EntryVersion, error) {
+	rows, err := e.queries.FindEntryVersions(context.TODO(), entryID)
+	if err != nil {
+		return nil, seer.Wrap("find_entry_versions", err)
+	}
+
+	versions := make([]models.EntryVersion, 0, len(rows))
+	for i, row := range rows {
+		versions = append(versions, models.EntryVersion{
+			ID:            row.PublicID,
+			Name:          row.Name,
+			Version:       row.Version,
+			Type:          row.Type,
+			FilesizeBytes: row.FilesizeBytes,
+			Status:        row.Status,
+			IsLatest:      i == 0,
+			CreatedBy: models.EntryAddedBy{
+				FirstName: row.CreatedByFirstName,
+				LastName:  row.CreatedByLastName,
+				Username:  row.CreatedByUsername,
+			},
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+
+	return versions, nil
+}
+
+// Create implements EntryVersionRepository.
+func (e *entryVersionRepo) Create(args *CreateEntryVersionArgs) (CreatedEntryVersion, error) {
+	tx, err := e.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return CreatedEntryVersion{}, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := e.queries.WithTx(tx)
+
+	latest, err := queriesWithTx.FindLatestEntryVersion(context.TODO(), args.EntryID)
+	if err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("find_latest_entry_version", err)
+	}
+
+	// The content is extracted again by the plugins once the version has been processed
+	params := queries.CreateEntryVersionParams{
+		Name:          latest.Name,
+		Meta:          latest.Meta,
+		Content:       pgtype.Text{}, //nolint:exhaustruct
+		TextContent:   pgtype.Text{}, //nolint:exhaustruct
+		FileID:        latest.FileID,
+		EntryType:     latest.EntryType,
+		Checksum:      pgtype.Text{}, //nolint:exhaustruct
+		FilesizeBytes: latest.FilesizeBytes,
+		UserID:        args.UserID,
+		LatestID:      latest.ID,
+	}
+
+	switch {
+	case args.File != nil:
+		meta, err := json.Marshal(models.FileMetadata{
+			Extension:        path.Ext(args.File.OriginalName),
+			OriginalFilename: args.File.OriginalName,
+			MimeType:         args.File.MimeType,
+			ExtraMetadata:    json.RawMessage{},
+		})
+		if err != nil {
+			return CreatedEntryVersion{}, seer.Wrap("marshal_file_metadata", err)
+		}
+
+		params.Meta = meta
+		params.FileID = lib.PgText(args.File.FileID)
+		params.EntryType = args.File.Type
+		params.FilesizeBytes = args.File.Filesize
+
+	case args.Link != nil:
+		meta, err := json.Marshal(args.Link)
+		if err != nil {
+			return CreatedEntryVersion{}, seer.Wrap("marshal_link_metadata", err)
+		}
+
+		params.Meta = meta
+		if title := strings.TrimSpace(args.Link.Title); title != "" {
+			params.Name = title
+		}
+	}
+
+	created, err := queriesWithTx.CreateEntryVersion(context.TODO(), params)
+	if err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("create_entry_version", err)
+	}
+
+	if err := queriesWithTx.CopyEntryTags(context.TODO(), queries.CopyEntryTagsParams{
+		TargetEntryID: created.ID,
+		SourceEntryID: latest.ID,
+	}); err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("copy_entry_tags", err)
+	}
+
+	if _, err := queriesWithTx.EnqueueEntries(
+		context.TODO(),
+		[]queries.EnqueueEntriesParams{
+			{EntryID: created.ID, Payload: document.QueuePayload{Type: created.EntryType}},
+		},
+	); err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("enqueue_entry_version", err)
+	}
+
+	// Refreshed links can get a new title, the links written with it can now be resolved
+	if err := resolveEntryLinks(queriesWithTx, []pgtype.UUID{created.PublicID}); err != nil {
+		return CreatedEntryVersion{}, err
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return CreatedEntryVersion{}, err
+	}
+
+	return CreatedEntryVersion{
+		Entry:   createdEntry(&created),
+		Version: created.Version,
+		Queued:  true,
+	}, nil
+}
+
+// Restore implements EntryVersionRepository.
+func (e *entryVersionRepo) Restore(args *RestoreEntryVersionArgs) (CreatedEntryVersion, error) {
+	tx, err := e.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return CreatedEntryVersion{}, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := e.queries.WithTx(tx)
+
+	target, err := queriesWithTx.FindEntryVersion(context.TODO(), args.EntryID)
+	if err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("find_entry_version", err)
+	}
+
+	latest, err := queriesWithTx.FindLatestEntryVersion(context.TODO(), args.EntryID)
+	if err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("find_latest_entry_version", err)
+	}
+
+	if target.ID == latest.ID {
+		return CreatedEntryVersion{}, ErrLatestEntryVersion
+	}
+
+	// The restored version shares the file of the version it was restored from
+	created, err := queriesWithTx.CreateEntryVersion(
+		context.TODO(),
+		queries.CreateEntryVersionParams{
+			Name:          target.Name,
+			Meta:          target.Meta,
+			Content:       target.Content,
+			TextContent:   target.TextContent,
+			FileID:        target.FileID,
+			EntryType:     target.EntryType,
+			Checksum:      target.Checksum,
+			FilesizeBytes: target.FilesizeBytes,
+			UserID:        args.UserID,
+			LatestID:      latest.ID,
+		},
+	)
+	if err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("create_entry_version", err)
+	}
+
+	// Tags belong to the entry rather than to a version, they are carried over from the latest version
+	if err := queriesWithTx.CopyEntryTags(context.TODO(), queries.CopyEntryTagsParams{
+		TargetEntryID: created.ID,
+		SourceEntryID: latest.ID,
+	}); err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("copy_entry_tags", err)
+	}
+
+	copied, err := queriesWithTx.CopyEntryChunks(context.TODO(), queries.CopyEntryChunksParams{
+		TargetEntryID: lib.PgInt4(created.ID),
+		MinVersion:    created.Version,
+		SourceEntryID: lib.PgInt4(target.ID),
+	})
+	if err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("copy_entry_chunks", err)
+	}
+
+	// Versions that were never processed successfully have nothing to copy, they are processed again instead
+	payload := document.QueuePayload{Type: created.EntryType}
+	queued := copied == 0
+	if queued {
+		_, err = queriesWithTx.EnqueueEntries(
+			context.TODO(),
+			[]queries.EnqueueEntriesParams{{EntryID: created.ID, Payload: payload}},
+		)
+	} else {
+		err = queriesWithTx.EnqueueProcessedEntry(
+			context.TODO(),
+			queries.EnqueueProcessedEntryParams{EntryID: created.ID, Payload: payload},
+		)
+	}
+	if err != nil {
+		return CreatedEntryVersion{}, seer.Wrap("enqueue_entry_version", err)
+	}
+
+	// The links of the entry are the ones written in the restored content, it can also bring an older name back
+	if err := syncEntryLinks(queriesWithTx, created.ID, created.Content.String); err != nil {
+		return CreatedEntryVersion{}, err
+	}
+
+	if err := resolveEntryLinks(queriesWithTx, []pgtype.UUID{created.PublicID}); err != nil {
+		return CreatedEntryVersion{}, err
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return CreatedEntryVersion{}, err
+	}
+
+	return CreatedEntryVersion{
+		Entry:   createdEntry(&created),
+		Version: created.Version,
+		Queued:  queued,
+	}, nil
+}
+
+// DiffChunks implements EntryVersionRepository.
+func (e *entryVersionRepo) DiffChunks(fromID int32, toID int32) (models.EntryChunkDiff, error) {
+	from, err := e.queries.FindEntryVersionChunks(context.TODO(), lib.PgInt4(fromID))
+	if err != nil {
+		return models.EntryChunkDiff{}, seer.Wrap("find_from_version_chunks", err)
+	}
+
+	to, err := e.queries.FindEntryVersionChunks(context.TODO(), lib.PgInt4(toID))
+	if err != nil {
+		return models.EntryChunkDiff{}, seer.Wrap("find_to_version_chunks", err)
+	}
+
+	contents := func(chunks []queries.FindEntryVersionChunksRow) []string {
+		values := make([]string, 0, len(chunks))
+		for _, chunk := range chunks {
+			values = append(values, chunk.Content.String)
+		}
+		return values
+	}
+
+	edits, _ := diff.Compare(contents(from), contents(to))
+
+	result := models.EntryChunkDiff{
+		Changes:   make([]models.EntryChunkChange, 0, len(edits)),
+		Unchanged: 0,
+		Added:     0,
+		Removed:   0,
+		Truncated: false,
+	}
+
+	contentBytes := 0
+	for _, edit := range edits {
+		change := models.EntryChunkChange{
+			Status:    models.ChunkUnchanged,
+			FromIndex: -1,
+			ToIndex:   -1,
+			Content:   "",
+		}
+
+		var content string
+		switch edit.Kind {
+		case diff.KindEqual:
+			result.Unchanged++
+		case diff.KindInsert:
+			change.Status = models.ChunkAdded
+			content = to[edit.NewIndex].Content.String
+			result.Added++
+		case diff.KindDelete:
+			change.Status = models.ChunkRemoved
+			content = from[edit.OldIndex].Content.String
+			result.Removed++
+		}
+
+		if edit.OldIndex >= 0 {
+			change.FromIndex = from[edit.OldIndex].ChunkIndex
+		}
+
+		if edit.NewIndex >= 0 {
+			change.ToIndex = to[edit.NewIndex].ChunkIndex
+		}
+
+		// The changes are still listed once the limit is reached, only without their content
+		if contentBytes+len(content) > MaxChunkDiffBytes {
+			result.Truncated = true
+		} else {
+			change.Content = content
+			contentBytes += len(content)
+		}
+
+		result.Changes = append(result.Changes, change)
+	}
+
+	return result, nil
+}
+
+func createdEntry(entry *queries.Entry) models.CreatedEntry {
+	return models.CreatedEntry{
+		ID:         entry.PublicID,
+		InternalID: entry.ID,
+		Name:       entry.Name,
+		Type:       entry.EntryType,
+	}
+}
+
+var _ EntryVersionRepository = (*entryVersionRepo)(nil)
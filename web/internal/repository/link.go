@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+type (
+	LinkRepository interface {
+		// Sync replaces the links of an entry with the ones written in the content of its latest version, older versions are ignored
+		Sync(entryID int32, content string) error
+
+		// Resolve points the unresolved links to the names of the given entries at them
+		Resolve(entryIDs []pgtype.UUID) error
+
+		// FindAll returns the links written in the entry the given version belongs to and the links written to it, only the entries the user can see are included
+		FindAll(entryID pgtype.UUID, userID int32) (models.EntryLinks, error)
+	}
+
+	linkRepo struct {
+		*baseRepo
+	}
+)
+
+// Sync implements LinkRepository.
+func (l *linkRepo) Sync(entryID int32, content string) error {
+	tx, err := l.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	if err := syncEntryLinks(l.queries.WithTx(tx), entryID, content); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.TODO())
+}
+
+// Resolve implements LinkRepository.
+func (l *linkRepo) Resolve(entryIDs []pgtype.UUID) error {
+	return resolveEntryLinks(l.queries, entryIDs)
+}
+
+// FindAll implements LinkRepository.
+func (l *linkRepo) FindAll(entryID pgtype.UUID, userID int32) (models.EntryLinks, error) {
+	backlinks, err := l.queries.FindBacklinks(context.TODO(), queries.FindBacklinksParams{
+		EntryPublicID: entryID,
+		UserID:        userID,
+	})
+	if err != nil {
+		return models.EntryLinks{}, seer.Wrap("find_backlinks", err)
+	}
+
+	outgoing, err := l.queries.FindOutgoingLinks(context.TODO(), queries.FindOutgoingLinksParams{
+		EntryPublicID: entryID,
+		UserID:        userID,
+	})
+	if err != nil {
+		return models.EntryLinks{}, seer.Wrap("find_outgoing_links", err)
+	}
+
+	unresolved, err := l.queries.FindUnresolvedLinks(context.TODO(), entryID)
+	if err != nil {
+		return models.EntryLinks{}, seer.Wrap("find_unresolved_links", err)
+	}
+
+	links := models.EntryLinks{
+		Backlinks:  make([]models.LinkedEntry, 0, len(backlinks)),
+		Outgoing:   make([]models.LinkedEntry, 0, len(outgoing)),
+		Unresolved: make([]string, 0, len(unresolved)),
+	}
+
+	for i := range backlinks {
+		links.Backlinks = append(links.Backlinks, linkedEntryFromRow(&backlinks[i]))
+	}
+
+	for i := range outgoing {
+		row := queries.FindBacklinksRow(outgoing[i])
+		links.Outgoing = append(links.Outgoing, linkedEntryFromRow(&row))
+	}
+
+	for _, row := range unresolved {
+		if row.TargetName.Valid {
+			links.Unresolved = append(links.Unresolved, row.TargetName.String)
+			continue
+		}
+
+		uri := document.EntryURIPrefix + row.TargetPublicID.String()
+		links.Unresolved = append(links.Unresolved, uri)
+	}
+
+	return links, nil
+}
+
+// syncEntryLinks parses the links written in the content of an entry and replaces the links of the entry with them
+func syncEntryLinks(q *queries.Queries, entryID int32, content string) error {
+	links := document.ParseLinks(content)
+
+	if err := q.DeleteEntryLinks(context.TODO(), entryID); err != nil {
+		return seer.Wrap("delete_entry_links", err)
+	}
+
+	if len(links.Names) > 0 {
+		_, err := q.InsertEntryNameLinks(context.TODO(), queries.InsertEntryNameLinksParams{
+			EntryID: entryID,
+			Names:   links.Names,
+		})
+		if err != nil {
+			return seer.Wrap("insert_entry_name_links", err)
+		}
+	}
+
+	if len(links.IDs) > 0 {
+		ids := make([]pgtype.UUID, 0, len(links.IDs))
+		for _, id := range links.IDs {
+			ids = append(ids, lib.PgUUIDString(id))
+		}
+
+		_, err := q.InsertEntryIdLinks(context.TODO(), queries.InsertEntryIdLinksParams{
+			EntryID:   entryID,
+			PublicIds: ids,
+		})
+		if err != nil {
+			return seer.Wrap("insert_entry_id_links", err)
+		}
+	}
+
+	return nil
+}
+
+// resolveEntryLinks points the links written before the given entries were created or renamed at them, links written with an entry URI never have to be resolved again
+func resolveEntryLinks(q *queries.Queries, entryIDs []pgtype.UUID) error {
+	if len(entryIDs) == 0 {
+		return nil
+	}
+
+	if _, err := q.ResolveEntryLinks(context.TODO(), entryIDs); err != nil {
+		return seer.Wrap("resolve_entry_links", err)
+	}
+
+	return nil
+}
+
+func linkedEntryFromRow(row *queries.FindBacklinksRow) models.LinkedEntry {
+	return models.LinkedEntry{
+		ID:   row.PublicID,
+		Name: row.Name,
+		Type: row.Type,
+		Collection: models.EntryRelation{
+			ID:   row.CollectionID,
+			Name: row.CollectionName,
+			Slug: row.CollectionSlug.String,
+		},
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+}
+
+var _ LinkRepository = (*linkRepo)(nil)
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+// uniqueViolation is the Postgres error code of unique constraint violations
+const uniqueViolation = "23505"
+
+var (
+	ErrNoteNotFound = apperrors.BadRequest("note not found or has been deleted")
+	ErrNotANote     = apperrors.BadRequest(
+		"only notes written in Hubble can be edited, upload a new version of this entry instead",
+	)
+	ErrNoteVersionConflict = apperrors.New(
+		"this note was changed since you opened it, reload it to get the latest version first",
+		http.StatusConflict,
+	)
+)
+
+type (
+	CreateNoteArgs struct {
+		CollectionID int32
+		UserID       int32
+		Title        string
+		// Content is the markdown body of the note
+		Content string
+	}
+
+	UpdateNoteArgs struct {
+		// EntryID is the public ID of any version of the note
+		EntryID pgtype.UUID
+		UserID  int32
+		// Version is the version the changes were made on, they are only saved if it is still the latest version of the note
+		Version int32
+		Title   string
+		Content string
+	}
+
+	SavedNote struct {
+		Entry   models.CreatedEntry
+		Version int32
+		// Chunks is the number of chunks of the saved version
+		Chunks int
+		// Reused is the number of chunks that kept the embeddings of the previous version, the others have to be embedded
+		Reused int
+	}
+
+	NoteRepository interface {
+		// Create adds a note to a collection, the note is chunked and its links are saved right away instead of going through the plugins
+		Create(args *CreateNoteArgs) (SavedNote, error)
+
+		// Update saves the changes made to a note as its next version
+		Update(args *UpdateNoteArgs) (SavedNote, error)
+
+		// Rechunk chunks a version of a note again from its content, it is used when the note is sent to the queue again
+		Rechunk(entry *models.Entry) error
+	}
+
+	noteRepo struct {
+		*baseRepo
+	}
+)
+
+// Create implements NoteRepository.
+func (n *noteRepo) Create(args *CreateNoteArgs) (SavedNote, error) {
+	meta, err := noteMetadata(args.Title)
+	if err != nil {
+		return SavedNote{}, err
+	}
+
+	tx, err := n.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return SavedNote{}, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := n.queries.WithTx(tx)
+
+	text := document.MarkdownToText(args.Content)
+	note, err := queriesWithTx.CreateNote(context.TODO(), queries.CreateNoteParams{
+		Name:          args.Title,
+		Meta:          meta,
+		Content:       lib.PgText(args.Content),
+		TextContent:   lib.PgText(text),
+		FilesizeBytes: int64(len(args.Content)),
+		CollectionID:  args.CollectionID,
+		UserID:        args.UserID,
+	})
+	if err != nil {
+		return SavedNote{}, seer.Wrap("create_note", err)
+	}
+
+	err = queriesWithTx.EnqueueProcessedEntry(context.TODO(), queries.EnqueueProcessedEntryParams{
+		EntryID: note.ID,
+		Payload: document.QueuePayload{Type: note.EntryType},
+	})
+	if err != nil {
+		return SavedNote{}, seer.Wrap("enqueue_processed_note", err)
+	}
+
+	chunks, err := insertTextChunks(queriesWithTx, note.ID, note.Version, text)
+	if err != nil {
+		return SavedNote{}, err
+	}
+
+	if err := syncEntryLinks(queriesWithTx, note.ID, args.Content); err != nil {
+		return SavedNote{}, err
+	}
+
+	if err := resolveEntryLinks(queriesWithTx, []pgtype.UUID{note.PublicID}); err != nil {
+		return SavedNote{}, err
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return SavedNote{}, err
+	}
+
+	return SavedNote{
+		Entry:   createdEntry(&note),
+		Version: note.Version,
+		Chunks:  chunks,
+		Reused:  0,
+	}, nil
+}
+
+// Update implements NoteRepository.
+func (n *noteRepo) Update(args *UpdateNoteArgs) (SavedNote, error) {
+	meta, err := noteMetadata(args.Title)
+	if err != nil {
+		return SavedNote{}, err
+	}
+
+	tx, err := n.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return SavedNote{}, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := n.queries.WithTx(tx)
+
+	latest, err := queriesWithTx.FindLatestEntryVersion(context.TODO(), args.EntryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SavedNote{}, ErrNoteNotFound
+		}
+		return SavedNote{}, seer.Wrap("find_latest_entry_version", err)
+	}
+
+	if latest.EntryType != document.EntryTypeMarkdown || latest.FileID.Valid {
+		return SavedNote{}, ErrNotANote
+	}
+
+	if latest.Version != args.Version {
+		return SavedNote{}, ErrNoteVersionConflict
+	}
+
+	text := document.MarkdownToText(args.Content)
+	created, err := queriesWithTx.CreateEntryVersion(
+		context.TODO(),
+		queries.CreateEntryVersionParams{
+			Name:          args.Title,
+			Meta:          meta,
+			Content:       lib.PgText(args.Content),
+			TextContent:   lib.PgText(text),
+			FileID:        pgtype.Text{}, //nolint:exhaustruct
+			EntryType:     latest.EntryType,
+			Checksum:      pgtype.Text{}, //nolint:exhaustruct
+			FilesizeBytes: int64(len(args.Content)),
+			UserID:        args.UserID,
+			LatestID:      latest.ID,
+		},
+	)
+	if err != nil {
+		// Another save of the same version created the next version first
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return SavedNote{}, ErrNoteVersionConflict
+		}
+		return SavedNote{}, seer.Wrap("create_entry_version", err)
+	}
+
+	if err := queriesWithTx.CopyEntryTags(context.TODO(), queries.CopyEntryTagsParams{
+		TargetEntryID: created.ID,
+		SourceEntryID: latest.ID,
+	}); err != nil {
+		return SavedNote{}, seer.Wrap("copy_entry_tags", err)
+	}
+
+	err = queriesWithTx.EnqueueProcessedEntry(context.TODO(), queries.EnqueueProcessedEntryParams{
+		EntryID: created.ID,
+		Payload: document.QueuePayload{Type: created.EntryType},
+	})
+	if err != nil {
+		return SavedNote{}, seer.Wrap("enqueue_processed_note", err)
+	}
+
+	chunks, err := insertTextChunks(queriesWithTx, created.ID, created.Version, text)
+	if err != nil {
+		return SavedNote{}, err
+	}
+
+	// Chunks that did not change keep their embeddings, only the new ones are left pending
+	reused, err := queriesWithTx.ReuseChunkEmbeddings(
+		context.TODO(),
+		queries.ReuseChunkEmbeddingsParams{
+			EntryID:       lib.PgInt4(created.ID),
+			SourceEntryID: lib.PgInt4(latest.ID),
+		},
+	)
+	if err != nil {
+		return SavedNote{}, seer.Wrap("reuse_chunk_embeddings", err)
+	}
+
+	if err := syncEntryLinks(queriesWithTx, created.ID, args.Content); err != nil {
+		return SavedNote{}, err
+	}
+
+	// The title of the note may have changed, links written with the new title can now be resolved
+	if err := resolveEntryLinks(queriesWithTx, []pgtype.UUID{created.PublicID}); err != nil {
+		return SavedNote{}, err
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return SavedNote{}, err
+	}
+
+	return SavedNote{
+		Entry:   createdEntry(&created),
+		Version: created.Version,
+		Chunks:  chunks,
+		Reused:  int(reused),
+	}, nil
+}
+
+// Rechunk implements NoteRepository.
+func (n *noteRepo) Rechunk(entry *models.Entry) error {
+	tx, err := n.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := n.queries.WithTx(tx)
+
+	_, err = queriesWithTx.DeleteEntryChunksByPublicId(
+		context.TODO(),
+		queries.DeleteEntryChunksByPublicIdParams{
+			EntryPublicIds: []pgtype.UUID{entry.PublicID},
+			WorkspaceID:    entry.Workspace.ID,
+		},
+	)
+	if err != nil {
+		return seer.Wrap("delete_entry_chunks", err)
+	}
+
+	text := document.MarkdownToText(entry.Content.String)
+	if _, err := insertTextChunks(queriesWithTx, entry.ID, entry.Version, text); err != nil {
+		return err
+	}
+
+	if err := syncEntryLinks(queriesWithTx, entry.ID, entry.Content.String); err != nil {
+		return err
+	}
+
+	if err := queriesWithTx.UpdateEntryStatus(context.TODO(), queries.UpdateEntryStatusParams{
+		Status:  queries.EntryStatusCompleted,
+		EntryID: entry.ID,
+	}); err != nil {
+		return seer.Wrap("update_entry_status", err)
+	}
+
+	return tx.Commit(context.TODO())
+}
+
+// IsNote reports whether an entry is a note written in Hubble, notes are the only markdown entries without a file
+func IsNote(entry *models.Entry) bool {
+	return entry.Type == document.EntryTypeMarkdown && entry.FileID == ""
+}
+
+// noteMetadata describes a note the same way as an uploaded markdown file so that both are displayed alike
+func noteMetadata(title string) ([]byte, error) {
+	meta, err := json.Marshal(models.FileMetadata{
+		OriginalFilename: title + ".md",
+		MimeType:         "text/markdown",
+		Extension:        ".md",
+		ExtraMetadata:    json.RawMessage{},
+	})
+	if err != nil {
+		return nil, seer.Wrap("marshal_note_metadata", err)
+	}
+
+	return meta, nil
+}
+
+var _ NoteRepository = (*noteRepo)(nil)
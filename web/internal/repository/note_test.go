@@ -0,0 +1,240 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/internal/repository"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+)
+
+func Test_IsNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    models.Entry
+		expected bool
+	}{
+		{
+			name:     "markdown without a file",
+			entry:    models.Entry{Type: document.EntryTypeMarkdown}, //nolint:exhaustruct
+			expected: true,
+		},
+		{
+			name: "uploaded markdown file",
+			//nolint:exhaustruct
+			entry:    models.Entry{Type: document.EntryTypeMarkdown, FileID: "notes.md"},
+			expected: false,
+		},
+		{
+			name:     "link",
+			entry:    models.Entry{Type: document.EntryTypeLink}, //nolint:exhaustruct
+			expected: false,
+		},
+		{
+			name:     "comment",
+			entry:    models.Entry{Type: document.EntryTypeComment}, //nolint:exhaustruct
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := repository.IsNote(&tt.entry); actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+// noteParagraphs is long enough to be split into several chunks
+func noteParagraphs(last string) string {
+	paragraphs := make([]string, 0, 4)
+	for _, word := range []string{"apples", "bananas", "cherries"} {
+		paragraphs = append(paragraphs, strings.Repeat(word+" grow in the orchard. ", 30))
+	}
+
+	return strings.Join(append(paragraphs, strings.Repeat(last+" ", 60)), "\n\n")
+}
+
+func Test_NoteUpdate_VersionConflict(t *testing.T) {
+	pool := testPool(t)
+	f := newRelatedFixture(t, pool)
+	notes := repository.New(pool, nil, nil).NoteRepository()
+
+	note, err := notes.Create(&repository.CreateNoteArgs{
+		CollectionID: f.collectionID,
+		UserID:       f.userID,
+		Title:        "Conflicting note",
+		Content:      "First draft",
+	})
+	if err != nil {
+		t.Fatalf("failed to create note: %v", err)
+	}
+
+	update := func(version int32, content string) (repository.SavedNote, error) {
+		return notes.Update(&repository.UpdateNoteArgs{
+			EntryID: note.Entry.ID,
+			UserID:  f.userID,
+			Version: version,
+			Title:   "Conflicting note",
+			Content: content,
+		})
+	}
+
+	saved, err := update(note.Version, "Second draft")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if saved.Version != 2 {
+		t.Errorf("expected version 2, got %d", saved.Version)
+	}
+
+	// The changes were made on the first version, which is not the latest anymore
+	_, err = update(note.Version, "Stale draft")
+	if !errors.Is(err, repository.ErrNoteVersionConflict) {
+		t.Errorf("expected a version conflict, got %v", err)
+	}
+
+	// Another save creates the third version while this one is being saved, it is only visible once committed
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	//nolint:exhaustruct
+	if _, err := queries.New(tx).CreateEntryVersion(ctx, queries.CreateEntryVersionParams{
+		Name:      "Conflicting note",
+		Meta:      []byte("{}"),
+		Content:   lib.PgText("Concurrent draft"),
+		EntryType: document.EntryTypeMarkdown,
+		UserID:    f.userID,
+		LatestID:  saved.Entry.InternalID,
+	}); err != nil {
+		t.Fatalf("failed to create the concurrent version: %v", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := update(saved.Version, "Racing draft")
+		result <- err
+	}()
+
+	// The racing save waits on the unique index until the concurrent version is committed
+	waitForLock(t, pool)
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("failed to commit the concurrent version: %v", err)
+	}
+
+	if err := <-result; !errors.Is(err, repository.ErrNoteVersionConflict) {
+		t.Errorf("expected a version conflict, got %v", err)
+	}
+}
+
+func Test_NoteUpdate_ReusesUnchangedChunks(t *testing.T) {
+	pool := testPool(t)
+	f := newRelatedFixture(t, pool)
+	notes := repository.New(pool, nil, nil).NoteRepository()
+
+	original, changed := noteParagraphs("plums"), noteParagraphs("pears")
+
+	note, err := notes.Create(&repository.CreateNoteArgs{
+		CollectionID: f.collectionID,
+		UserID:       f.userID,
+		Title:        "Orchard",
+		Content:      original,
+	})
+	if err != nil {
+		t.Fatalf("failed to create note: %v", err)
+	}
+
+	// The chunks of the first version are embedded
+	if _, err := pool.Exec(context.Background(), `
+		update entry_chunks
+		set
+			semantic_vector = '[1,0,0]',
+			embedding_model = 'test-model',
+			embedding_dimensions = 3,
+			embedding_status = 'done'
+		where entry_id = $1`, note.Entry.InternalID); err != nil {
+		t.Fatalf("failed to embed chunks: %v", err)
+	}
+
+	saved, err := notes.Update(&repository.UpdateNoteArgs{
+		EntryID: note.Entry.ID,
+		UserID:  f.userID,
+		Version: note.Version,
+		Title:   "Orchard",
+		Content: changed,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	before := document.ChunkWithOverlap(document.MarkdownToText(original))
+	after := document.ChunkWithOverlap(document.MarkdownToText(changed))
+
+	unchanged := 0
+	for _, chunk := range after {
+		if slices.Contains(before, chunk) {
+			unchanged++
+		}
+	}
+
+	if unchanged == 0 || unchanged == len(after) {
+		t.Fatalf("expected some of the %d chunks to change, %d did not", len(after), unchanged)
+	}
+	if saved.Chunks != len(after) || saved.Reused != unchanged {
+		t.Errorf(
+			"expected %d chunks with %d reused, got %d with %d reused",
+			len(after),
+			unchanged,
+			saved.Chunks,
+			saved.Reused,
+		)
+	}
+
+	var pending int
+	if err := pool.QueryRow(context.Background(), `
+		select count(*) from entry_chunks
+		where entry_id = $1 and embedding_status != 'done' and semantic_vector is null`,
+		saved.Entry.InternalID,
+	).Scan(&pending); err != nil {
+		t.Fatalf("failed to count pending chunks: %v", err)
+	}
+	if pending != len(after)-unchanged {
+		t.Errorf("expected %d chunks to be embedded, got %d", len(after)-unchanged, pending)
+	}
+}
+
+// waitForLock waits until a statement is blocked on a lock held by another transaction
+func waitForLock(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		var waiting bool
+		err := pool.QueryRow(
+			context.Background(),
+			"select exists (select 1 from pg_locks where not granted)",
+		).Scan(&waiting)
+		if err != nil {
+			t.Fatalf("failed to check locks: %v", err)
+		}
+		if waiting {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("timed out waiting for the statement to be blocked")
+}
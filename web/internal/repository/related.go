@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+const (
+	DefaultRelatedEntriesLimit = 10
+
+	// MaxRelatedEntries is the number of related entries cached for every entry
+	MaxRelatedEntries = 25
+
+	// RelatedCandidateChunks is the number of nearest chunks considered before they are grouped by entry
+	RelatedCandidateChunks = 200
+)
+
+/*
+FindRelatedEntries computes the entries that are the most similar to the given entry.
+
+The entry is represented by the centroid (average) of its chunks' semantic vectors, the nearest chunks to the centroid are then grouped by entry and every entry is scored by its closest chunk (max-sim).
+Only entries in the same workspace that the user can access through the workspace and collection memberships are returned.
+*/
+func (e *entryRepo) FindRelatedEntries(args *FindRelatedEntriesArgs) ([]models.RelatedEntry, error) {
+	ctx := args.Context
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	dimensions, err := e.queries.FindEntryEmbeddingDimensions(
+		ctx,
+		queries.FindEntryEmbeddingDimensionsParams{EntryPublicID: args.EntryID},
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.RelatedEntry{}, nil
+		}
+		return nil, seer.Wrap("find_entry_embedding_dimensions", err)
+	}
+
+	rows, err := e.queries.WithDimensions(dimensions).FindRelatedEntries(
+		ctx,
+		queries.FindRelatedEntriesParams{
+			EntryPublicID: args.EntryID,
+			Candidates:    RelatedCandidateChunks,
+			UserID:        args.UserID,
+			ResultLimit:   relatedEntriesLimit(args.Limit),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []models.RelatedEntry{}, nil
+		}
+		return nil, seer.Wrap("find_related_entries", err)
+	}
+
+	related := make([]models.RelatedEntry, 0, len(rows))
+	for i := range rows {
+		related = append(related, relatedEntryFromRow(&rows[i]))
+	}
+
+	return related, nil
+}
+
+// FindCachedRelatedEntries implements EntryRepository.
+func (e *entryRepo) FindCachedRelatedEntries(
+	args *FindRelatedEntriesArgs,
+) ([]models.RelatedEntry, error) {
+	ctx := args.Context
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	rows, err := e.queries.FindCachedRelatedEntries(ctx, queries.FindCachedRelatedEntriesParams{
+		EntryPublicID: args.EntryID,
+		UserID:        args.UserID,
+		ResultLimit:   relatedEntriesLimit(args.Limit),
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, seer.Wrap("find_cached_related_entries", err)
+	}
+
+	// The cache is only filled once all the chunks have been embedded
+	if len(rows) == 0 {
+		return e.FindRelatedEntries(args)
+	}
+
+	related := make([]models.RelatedEntry, 0, len(rows))
+	for i := range rows {
+		row := queries.FindRelatedEntriesRow(rows[i])
+		related = append(related, relatedEntryFromRow(&row))
+	}
+
+	return related, nil
+}
+
+// RefreshRelatedEntries implements EntryRepository.
+func (e *entryRepo) RefreshRelatedEntries(entryID int32) error {
+	tx, err := e.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := e.queries.WithTx(tx)
+
+	if err := queriesWithTx.DeleteRelatedEntries(context.TODO(), entryID); err != nil {
+		return seer.Wrap("delete_related_entries", err)
+	}
+
+	dimensions, err := queriesWithTx.FindEntryEmbeddingDimensions(
+		context.TODO(),
+		queries.FindEntryEmbeddingDimensionsParams{EntryID: lib.PgInt4(entryID)},
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tx.Commit(context.TODO())
+		}
+		return seer.Wrap("find_entry_embedding_dimensions", err)
+	}
+
+	if _, err := queriesWithTx.WithDimensions(dimensions).InsertRelatedEntries(
+		context.TODO(),
+		queries.InsertRelatedEntriesParams{
+			EntryID:    entryID,
+			Candidates: RelatedCandidateChunks,
+			MaxRelated: MaxRelatedEntries,
+		},
+	); err != nil {
+		return seer.Wrap("insert_related_entries", err)
+	}
+
+	return tx.Commit(context.TODO())
+}
+
+// IsEntryEmbedded implements EntryRepository.
+func (e *entryRepo) IsEntryEmbedded(chunkID int32) (int32, bool, error) {
+	progress, err := e.queries.FindEntryEmbeddingProgress(context.TODO(), chunkID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return progress.EntryID.Int32, progress.EntryID.Valid && progress.PendingChunks == 0, nil
+}
+
+func relatedEntriesLimit(limit int32) int32 {
+	if limit <= 0 {
+		return DefaultRelatedEntriesLimit
+	}
+
+	return min(limit, MaxRelatedEntries)
+}
+
+func relatedEntryFromRow(row *queries.FindRelatedEntriesRow) models.RelatedEntry {
+	return models.RelatedEntry{
+		ID:            row.PublicID,
+		Name:          row.Name,
+		Type:          row.Type,
+		FileID:        row.FileID.String,
+		FilesizeBytes: row.FilesizeBytes,
+		Similarity:    row.Similarity,
+		Collection: models.EntryRelation{
+			ID:   row.CollectionID,
+			Name: row.CollectionName,
+			Slug: row.CollectionSlug.String,
+		},
+		Workspace: models.EntryRelation{
+			ID:   row.WorkspaceID,
+			Name: row.WorkspaceName,
+			Slug: row.WorkspaceSlug.String,
+		},
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,203 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.trulyao.dev/hubble/web/internal/database"
+	"go.trulyao.dev/hubble/web/internal/database/migrations"
+	"go.trulyao.dev/hubble/web/internal/repository"
+)
+
+// testDSNEnv points the database tests at a disposable Postgres database (with pgvector), they are skipped when it is not set
+const testDSNEnv = "HUBBLE_TEST_POSTGRES_DSN"
+
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	if err := migrations.Migrate(dsn, migrations.Up); err != nil &&
+		!errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	pool, err := database.InitializePool(dsn, false)
+	if err != nil {
+		t.Fatalf("failed to connect to the database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// relatedFixture is a workspace with a single member and collection
+type relatedFixture struct {
+	pool          *pgxpool.Pool
+	userID        int32
+	workspaceID   int32
+	workspaceSlug string
+	collectionID  int32
+}
+
+func newRelatedFixture(t *testing.T, pool *pgxpool.Pool) *relatedFixture {
+	t.Helper()
+
+	var (
+		ctx    = context.Background()
+		suffix = fmt.Sprintf("related-%d", time.Now().UnixNano())
+		f      = &relatedFixture{pool: pool, workspaceSlug: suffix} //nolint:exhaustruct
+	)
+
+	err := pool.QueryRow(ctx, `
+		insert into users (first_name, last_name, email, username, hashed_password)
+		values ('Test', 'User', $1 || '@example.com', $1, '')
+		returning id`, suffix).Scan(&f.userID)
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	err = pool.QueryRow(ctx, `
+		insert into workspaces (namespaced_name, display_name, owner_id, slug)
+		values ($1, $1, $2, $1)
+		returning id`, suffix, f.userID).Scan(&f.workspaceID)
+	if err != nil {
+		t.Fatalf("failed to create workspace: %v", err)
+	}
+
+	err = pool.QueryRow(ctx, `
+		insert into collections (name, workspace_id, slug, owner_id)
+		values ($1, $2, $1, $3)
+		returning id`, suffix, f.workspaceID, f.userID).Scan(&f.collectionID)
+	if err != nil {
+		t.Fatalf("failed to create collection: %v", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		insert into workspace_members (workspace_id, user_id, bitmask_role) values ($1, $2, 0)`,
+		f.workspaceID, f.userID)
+	if err != nil {
+		t.Fatalf("failed to add workspace member: %v", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		insert into collection_members (collection_id, user_id, bitmask_role) values ($1, $2, 0)`,
+		f.collectionID, f.userID)
+	if err != nil {
+		t.Fatalf("failed to add collection member: %v", err)
+	}
+
+	return f
+}
+
+// addEntry creates a processed entry whose chunks all have the given vector
+func (f *relatedFixture) addEntry(
+	t *testing.T,
+	name string,
+	vector string,
+	chunks int,
+) (int32, pgtype.UUID) {
+	t.Helper()
+
+	var (
+		ctx      = context.Background()
+		id       int32
+		publicID pgtype.UUID
+	)
+
+	err := f.pool.QueryRow(ctx, `
+		insert into entries (name, entry_type, collection_id, added_by, last_updated_by)
+		values ($1, 'plain_text', $2, $3, $3)
+		returning id, public_id`, name, f.collectionID, f.userID).Scan(&id, &publicID)
+	if err != nil {
+		t.Fatalf("failed to create entry %q: %v", name, err)
+	}
+
+	_, err = f.pool.Exec(ctx, `
+		insert into entries_queue (entry_id, payload, status) values ($1, '{}', 'completed')`, id)
+	if err != nil {
+		t.Fatalf("failed to queue entry %q: %v", name, err)
+	}
+
+	_, err = f.pool.Exec(ctx, `
+		insert into entry_chunks (
+			entry_id, chunk_index, min_version, content,
+			semantic_vector, embedding_model, embedding_dimensions
+		)
+		select $1, i, 1, $2, $3::vector, 'test-model', 3
+		from generate_series(0, $4::int - 1) as i`, id, name, vector, chunks)
+	if err != nil {
+		t.Fatalf("failed to chunk entry %q: %v", name, err)
+	}
+
+	return id, publicID
+}
+
+func Test_FindRelatedEntries_FiltersCandidates(t *testing.T) {
+	pool := testPool(t)
+	f := newRelatedFixture(t, pool)
+
+	sourceID, source := f.addEntry(t, "source", "[1,0,0]", 1)
+	relatedID, related := f.addEntry(t, "related", "[0.6,0.8,0]", 1)
+
+	// The trashed entry has more chunks than there are candidates, and they are all closer to the source than the related entry
+	trashedID, _ := f.addEntry(
+		t,
+		"trashed",
+		"[1,0.01,0]",
+		repository.RelatedCandidateChunks+50,
+	)
+	if _, err := pool.Exec(
+		context.Background(),
+		"update entries set deleted_at = now() where id = $1",
+		trashedID,
+	); err != nil {
+		t.Fatalf("failed to trash entry: %v", err)
+	}
+
+	entries := repository.New(pool, nil, nil).EntryRepository()
+
+	found, err := entries.FindRelatedEntries(&repository.FindRelatedEntriesArgs{
+		Context: context.Background(),
+		EntryID: source,
+		UserID:  f.userID,
+		Limit:   0,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(found) != 1 || found[0].ID != related {
+		t.Errorf("expected only the related entry, got %+v", found)
+	}
+
+	if err := entries.RefreshRelatedEntries(sourceID); err != nil {
+		t.Fatalf("expected no error refreshing the cache, got %v", err)
+	}
+
+	rows, err := pool.Query(
+		context.Background(),
+		"select related_entry_id from related_entries where entry_id = $1",
+		sourceID,
+	)
+	if err != nil {
+		t.Fatalf("failed to read the cache: %v", err)
+	}
+	cached, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	if err != nil {
+		t.Fatalf("failed to read the cache: %v", err)
+	}
+	if len(cached) != 1 || cached[0] != relatedID {
+		t.Errorf("expected only the related entry to be cached, got %v", cached)
+	}
+}
@@ -25,8 +25,16 @@ type baseRepo struct {
 	workspaceRepo   WorkspaceRepository
 	collectionRepo  CollectionRepository
 	entryRepo       EntryRepository
+	versionRepo     EntryVersionRepository
+	commentRepo     CommentRepository
+	noteRepo        NoteRepository
+	linkRepo        LinkRepository
 	pluginRepo      PluginRepository
 	pluginStoreRepo PluginStoreRepository
+	embeddingRepo   EmbeddingRepository
+	summaryRepo     SummaryRepository
+	tagRepo         TagRepository
+	suggestionRepo  TagSuggestionRepository
 
 	// Mutex for thread safety
 	mu sync.Mutex
@@ -45,8 +53,16 @@ type Repository interface {
 	WorkspaceRepository() WorkspaceRepository
 	CollectionRepository() CollectionRepository
 	EntryRepository() EntryRepository
+	EntryVersionRepository() EntryVersionRepository
+	CommentRepository() CommentRepository
+	NoteRepository() NoteRepository
+	LinkRepository() LinkRepository
 	PluginRepository() PluginRepository
 	PluginStoreRepository() PluginStoreRepository
+	EmbeddingRepository() EmbeddingRepository
+	SummaryRepository() SummaryRepository
+	TagRepository() TagRepository
+	TagSuggestionRepository() TagSuggestionRepository
 }
 
 func New(pool *pgxpool.Pool, store kv.Store, otpManager otp.Manager) Repository {
@@ -136,6 +152,46 @@ func (r *baseRepo) EntryRepository() EntryRepository {
 	return r.entryRepo
 }
 
+func (r *baseRepo) EntryVersionRepository() EntryVersionRepository {
+	r.withLock(func() {
+		if r.versionRepo == nil {
+			r.versionRepo = &entryVersionRepo{baseRepo: r}
+		}
+	})
+
+	return r.versionRepo
+}
+
+func (r *baseRepo) CommentRepository() CommentRepository {
+	r.withLock(func() {
+		if r.commentRepo == nil {
+			r.commentRepo = &commentRepo{baseRepo: r}
+		}
+	})
+
+	return r.commentRepo
+}
+
+func (r *baseRepo) NoteRepository() NoteRepository {
+	r.withLock(func() {
+		if r.noteRepo == nil {
+			r.noteRepo = &noteRepo{baseRepo: r}
+		}
+	})
+
+	return r.noteRepo
+}
+
+func (r *baseRepo) LinkRepository() LinkRepository {
+	r.withLock(func() {
+		if r.linkRepo == nil {
+			r.linkRepo = &linkRepo{baseRepo: r}
+		}
+	})
+
+	return r.linkRepo
+}
+
 func (r *baseRepo) PluginRepository() PluginRepository {
 	r.withLock(func() {
 		if r.pluginRepo == nil {
@@ -156,4 +212,44 @@ func (r *baseRepo) PluginStoreRepository() PluginStoreRepository {
 	return r.pluginStoreRepo
 }
 
+func (r *baseRepo) EmbeddingRepository() EmbeddingRepository {
+	r.withLock(func() {
+		if r.embeddingRepo == nil {
+			r.embeddingRepo = &embeddingRepo{baseRepo: r}
+		}
+	})
+
+	return r.embeddingRepo
+}
+
+func (r *baseRepo) SummaryRepository() SummaryRepository {
+	r.withLock(func() {
+		if r.summaryRepo == nil {
+			r.summaryRepo = &summaryRepo{baseRepo: r}
+		}
+	})
+
+	return r.summaryRepo
+}
+
+func (r *baseRepo) TagRepository() TagRepository {
+	r.withLock(func() {
+		if r.tagRepo == nil {
+			r.tagRepo = &tagRepo{baseRepo: r}
+		}
+	})
+
+	return r.tagRepo
+}
+
+func (r *baseRepo) TagSuggestionRepository() TagSuggestionRepository {
+	r.withLock(func() {
+		if r.suggestionRepo == nil {
+			r.suggestionRepo = &tagSuggestionRepo{baseRepo: r}
+		}
+	})
+
+	return r.suggestionRepo
+}
+
 var _ Repository = (*baseRepo)(nil)
@@ -1,16 +1,31 @@
 package repository
 
 import (
+	"bytes"
+	"cmp"
 	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
 	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	"go.trulyao.dev/hubble/web/pkg/lib"
 )
 
 const DefaultConcMinSize = 8
 
+const (
+	// SearchCandidateChunks is the number of chunks fetched by every leg of an unpaginated search
+	SearchCandidateChunks int32 = 75
+
+	// PaginatedSearchCandidateChunks is the number of chunks fetched by every leg when the results are paginated, this bounds how deep a client can scroll
+	PaginatedSearchCandidateChunks int32 = 250
+)
+
 func ApplyMinThreshold(
 	results []models.CollapsedSearchResult,
 	threshold float64,
@@ -38,6 +53,404 @@ func ApplyMinThreshold(
 	return results
 }
 
+/*
+PaginateSearchResults returns the page of results after the cursor and the cursor of the next page (nil on the last page).
+
+Paginated results are ordered by their hybrid score and then by their ID, so results are compared by their position in that order instead of their index in the slice; a result that only shows up in a later request (e.g. an entry that finished processing mid-scroll) is never repeated on the next pages.
+*/
+func PaginateSearchResults(
+	results []models.CollapsedSearchResult,
+	after *Cursor,
+	pageSize int32,
+) ([]models.CollapsedSearchResult, *Cursor) {
+	slices.SortStableFunc(results, compareSearchResults)
+
+	start := 0
+	if after != nil {
+		start = len(results)
+		for i := range results {
+			if isAfterCursor(&results[i], after) {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := min(start+int(pageSize), len(results))
+	page := results[start:end]
+	if end == len(results) || len(page) == 0 {
+		return page, nil
+	}
+
+	last := &page[len(page)-1]
+	return page, &Cursor{Timestamp: time.Time{}, Score: last.HybridScore, ID: last.ID}
+}
+
+// Results are compared by their raw score, the relevance is relative to the best result which can change between requests
+func compareSearchResults(a, b models.CollapsedSearchResult) int {
+	if c := cmp.Compare(b.HybridScore, a.HybridScore); c != 0 {
+		return c
+	}
+
+	return bytes.Compare(b.ID.Bytes[:], a.ID.Bytes[:])
+}
+
+func isAfterCursor(result *models.CollapsedSearchResult, cursor *Cursor) bool {
+	if result.HybridScore != cursor.Score {
+		return result.HybridScore < cursor.Score
+	}
+
+	return bytes.Compare(result.ID.Bytes[:], cursor.ID.Bytes[:]) < 0
+}
+
+// FacetCount is the number of matched entries of a type in a collection
+type FacetCount struct {
+	Type       document.EntryType
+	Collection models.EntryRelation
+	Count      int
+}
+
+// BuildFacets sums the counts of matched entries per entry type and collection
+func BuildFacets(counts []FacetCount) models.SearchFacets {
+	var (
+		types       = make(map[document.EntryType]int)
+		collections = make(map[pgtype.UUID]*models.CollectionFacet)
+	)
+
+	for i := range counts {
+		types[counts[i].Type] += counts[i].Count
+
+		collection := counts[i].Collection
+		if facet, ok := collections[collection.ID]; ok {
+			facet.Count += counts[i].Count
+			continue
+		}
+		collections[collection.ID] = &models.CollectionFacet{
+			Collection: collection,
+			Count:      counts[i].Count,
+		}
+	}
+
+	facets := models.SearchFacets{
+		Types:       make([]models.TypeFacet, 0, len(types)),
+		Collections: make([]models.CollectionFacet, 0, len(collections)),
+	}
+
+	for t, count := range types {
+		facets.Types = append(facets.Types, models.TypeFacet{
+			Type:  t,
+			Count: count,
+		})
+	}
+	for _, facet := range collections {
+		facets.Collections = append(facets.Collections, *facet)
+	}
+
+	// Highest counts first, ties are broken by name to keep the order stable across requests
+	slices.SortFunc(facets.Types, func(a, b models.TypeFacet) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(string(a.Type), string(b.Type))
+	})
+	slices.SortFunc(facets.Collections, func(a, b models.CollectionFacet) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Collection.Name, b.Collection.Name)
+	})
+
+	return facets
+}
+
+func (f *SearchFilters) entryTypes() []string {
+	if len(f.Types) == 0 {
+		return nil
+	}
+
+	types := make([]string, 0, len(f.Types))
+	for _, t := range f.Types {
+		types = append(types, t.String())
+	}
+
+	return types
+}
+
+func (f *SearchFilters) statuses() []queries.EntryStatus {
+	return nilIfEmpty(f.Statuses)
+}
+
+func (f *SearchFilters) tags() []string {
+	return normalizeTagNames(f.Tags)
+}
+
+// MatchesCommentedEntry reports whether the entry a comment was made on passes the type and tag filters, the search lets comments through these filters since comments have neither the type nor the tags of their entry
+func (f *SearchFilters) MatchesCommentedEntry(entryType document.EntryType, tags []string) bool {
+	if len(f.Types) > 0 &&
+		!slices.Contains(f.Types, entryType) &&
+		!slices.Contains(f.Types, document.EntryTypeComment) {
+		return false
+	}
+
+	for _, tag := range f.tags() {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+Merge narrows the current filters down with the filters from `other`:
+  - lists of alternatives (types, collections, authors and statuses) are intersected when both sides set them
+  - tags, title terms and excluded terms already have to all match, they are combined
+  - date ranges are narrowed to the stricter bound
+
+Collections given by slug on one side and by ID on the other cannot be intersected, they have to be resolved with ResolveCollections first.
+*/
+func (f *SearchFilters) Merge(other *SearchFilters) {
+	f.Types = intersect(f, f.Types, other.Types)
+	f.CollectionIDs = intersect(f, f.CollectionIDs, other.CollectionIDs)
+	f.CollectionSlugs = intersect(f, f.CollectionSlugs, other.CollectionSlugs)
+	f.AddedBy = intersect(f, f.AddedBy, other.AddedBy)
+	f.Statuses = intersect(f, f.Statuses, other.Statuses)
+	f.Tags = append(f.Tags, other.Tags...)
+	f.Title = append(f.Title, other.Title...)
+	f.Excluded = append(f.Excluded, other.Excluded...)
+	f.matchNone = f.matchNone || other.matchNone
+
+	if other.CreatedAfter.After(f.CreatedAfter) {
+		f.CreatedAfter = other.CreatedAfter
+	}
+	if other.UpdatedAfter.After(f.UpdatedAfter) {
+		f.UpdatedAfter = other.UpdatedAfter
+	}
+	if !other.CreatedBefore.IsZero() &&
+		(f.CreatedBefore.IsZero() || other.CreatedBefore.Before(f.CreatedBefore)) {
+		f.CreatedBefore = other.CreatedBefore
+	}
+	if !other.UpdatedBefore.IsZero() &&
+		(f.UpdatedBefore.IsZero() || other.UpdatedBefore.Before(f.UpdatedBefore)) {
+		f.UpdatedBefore = other.UpdatedBefore
+	}
+}
+
+// HasCollections reports whether the filters are restricted to some collections
+func (f *SearchFilters) HasCollections() bool {
+	return len(f.CollectionIDs) > 0 || len(f.CollectionSlugs) > 0
+}
+
+// ResolveCollections replaces the collection slugs with the IDs of the given collections, slugs that do not belong to any of them cannot match anything
+func (f *SearchFilters) ResolveCollections(collections []models.Collection) {
+	if len(f.CollectionSlugs) == 0 {
+		return
+	}
+
+	ids := make(map[string]pgtype.UUID, len(collections))
+	for i := range collections {
+		ids[collections[i].Slug] = lib.PgUUIDString(collections[i].ID)
+	}
+
+	resolved := slices.Clone(f.CollectionIDs)
+	for _, slug := range f.CollectionSlugs {
+		if id, ok := ids[slug]; ok && !slices.Contains(resolved, id) {
+			resolved = append(resolved, id)
+		}
+	}
+
+	if len(resolved) == 0 {
+		f.matchNone = true
+	}
+	f.CollectionIDs, f.CollectionSlugs = resolved, nil
+}
+
+// MatchesNothing reports whether the filters exclude every entry, e.g. `type:pdf` merged with a filter on images
+func (f *SearchFilters) MatchesNothing() bool {
+	return f.matchNone
+}
+
+// intersect keeps the values of `a` that are also in `b`, an empty side does not restrict anything
+func intersect[T comparable](f *SearchFilters, a, b []T) []T {
+	switch {
+	case len(b) == 0:
+		return a
+	case len(a) == 0:
+		return b
+	}
+
+	values := make([]T, 0, min(len(a), len(b)))
+	for _, value := range a {
+		if slices.Contains(b, value) && !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+
+	// Both sides asked for something but none of it overlaps
+	if len(values) == 0 {
+		f.matchNone = true
+	}
+
+	return values
+}
+
+// likePatterns turns the terms into case-insensitive substring patterns for `ilike`
+func likePatterns(terms []string) []string {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	patterns := make([]string, 0, len(terms))
+	for _, term := range terms {
+		patterns = append(patterns, "%"+escaper.Replace(term)+"%")
+	}
+
+	return patterns
+}
+
+// Markers `ts_headline` wraps the matched words with in the full-text search query
+const (
+	headlineStartSel = '\x02'
+	headlineStopSel  = '\x03'
+)
+
+// parseHeadline removes the highlight markers from a `ts_headline` snippet and returns the character ranges they surrounded
+func parseHeadline(headline string) (string, []models.Highlight) {
+	highlights := []models.Highlight{}
+	if headline == "" {
+		return "", highlights
+	}
+
+	var (
+		sb    strings.Builder
+		pos   = 0
+		start = -1
+	)
+
+	for _, r := range headline {
+		switch r {
+		case headlineStartSel:
+			start = pos
+		case headlineStopSel:
+			if start != -1 && pos > start {
+				highlights = append(highlights, models.Highlight{Start: start, End: pos})
+			}
+			start = -1
+		default:
+			sb.WriteRune(r)
+			pos++
+		}
+	}
+
+	return sb.String(), highlights
+}
+
+// NeedsFuzzyFallback reports whether the fuzzy search should also run, the semantic leg always fills its candidate window so only the entries matched by the full-text search are counted
+func NeedsFuzzyFallback(results []models.SearchResult, minResults int) bool {
+	entries := make(map[pgtype.UUID]struct{}, len(results))
+	for i := range results {
+		// Chunks found by both legs keep the semantic source once fused, they still carry the text score
+		if results[i].Source == models.SearchTypeFullText || results[i].TextScore != 0 {
+			entries[results[i].ID] = struct{}{}
+		}
+	}
+
+	return len(entries) < minResults
+}
+
+// nilIfEmpty makes sure empty filter lists are sent as NULL, an empty array would otherwise match nothing
+func nilIfEmpty[T any](items []T) []T {
+	if len(items) == 0 {
+		return nil
+	}
+
+	return items
+}
+
+/*
+FuseWithRRF merges the semantic and full-text results using Reciprocal Rank Fusion.
+
+Each list is ranked on its own (semantic by ascending cosine distance, full-text by descending `ts_rank`) and every chunk is scored with:
+
+	score = sum(weight / (k + rank))
+
+The score is then divided by the best possible score (ranked first in both lists) so that it stays within [0, 1] and the keyword bonus in `RerankResults` keeps the same relative weight.
+A chunk that appears in both lists is collapsed into a single result carrying both the text and the semantic score.
+*/
+func FuseWithRRF(results []models.SearchResult, params *RRFParams) []models.SearchResult {
+	var (
+		semantic = make([]*models.SearchResult, 0, len(results))
+		fullText = make([]*models.SearchResult, 0, len(results))
+	)
+
+	for i := range results {
+		if results[i].Source == models.SearchTypeFullText {
+			fullText = append(fullText, &results[i])
+			continue
+		}
+
+		semantic = append(semantic, &results[i])
+	}
+
+	sort.SliceStable(semantic, func(i, j int) bool {
+		return semantic[i].SemanticScore < semantic[j].SemanticScore
+	})
+	sort.SliceStable(fullText, func(i, j int) bool {
+		return fullText[i].TextScore > fullText[j].TextScore
+	})
+
+	k := params.K
+	if k <= 0 {
+		k = models.DefaultRRFK
+	}
+
+	maxScore := (params.SemanticWeight + params.FullTextWeight) / (k + 1)
+	if maxScore == 0 {
+		return results
+	}
+
+	var (
+		fused = make([]models.SearchResult, 0, len(results))
+		index = make(map[int32]int, len(results))
+	)
+
+	add := func(result *models.SearchResult, rank int, weight float64) *models.SearchResult {
+		idx, ok := index[result.Chunk.ID]
+		if !ok {
+			fusedResult := *result
+			fusedResult.HybridScore = 0
+			fusedResult.Rank = float32(rank)
+
+			fused = append(fused, fusedResult)
+			idx = len(fused) - 1
+			index[result.Chunk.ID] = idx
+		}
+
+		current := &fused[idx]
+		current.HybridScore += (weight / (k + float64(rank))) / maxScore
+		if float32(rank) < current.Rank {
+			current.Rank = float32(rank)
+		}
+
+		return current
+	}
+
+	for i, result := range semantic {
+		current := add(result, i+1, params.SemanticWeight)
+		current.SemanticScore = result.SemanticScore
+	}
+
+	for i, result := range fullText {
+		current := add(result, i+1, params.FullTextWeight)
+		current.TextScore = result.TextScore
+		current.Snippet = result.Snippet
+		current.Highlights = result.Highlights
+	}
+
+	return fused
+}
+
 func collectIntoSortedSlice(
 	resultsMap map[pgtype.UUID]models.CollapsedSearchResult,
 ) []models.CollapsedSearchResult {
@@ -120,11 +533,16 @@ func groupByEntry(
 		entry.Matches = append(entry.Matches, models.MatchedChunk{
 			ID:            result.Chunk.ID,
 			Text:          result.Preview,
+			Snippet:       result.Snippet,
+			Highlights:    result.Highlights,
 			Rank:          result.Rank,
 			Index:         result.Chunk.Index,
 			TextScore:     result.TextScore,
 			SemanticScore: result.SemanticScore,
+			FuzzyScore:    result.FuzzyScore,
 			HybridScore:   result.HybridScore,
+			MatchedBy:     result.MatchedBy,
+			CommentID:     result.CommentID,
 		})
 
 		// Update min and max hybrid score
@@ -151,6 +569,7 @@ func calculateRelevancePercentile(
 
 	// Calculate for the highest ranking entry
 	maxHybridScore := results[0].Matches[0].HybridScore
+	results[0].HybridScore = maxHybridScore
 	results[0].RelevancePercent = maxHybridScore / maxHybridScore * 100
 
 	// Calculate for the other entries
@@ -167,6 +586,7 @@ func calculateRelevancePercentile(
 		}
 		avg := averageTopN(topScores, -1)
 
+		entry.HybridScore = avg
 		entry.RelevancePercent = (avg / maxHybridScore) * 100
 	}
 }
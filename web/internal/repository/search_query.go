@@ -0,0 +1,380 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.trulyao.dev/hubble/web/pkg/document"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+)
+
+// Supported search query operators
+const (
+	OperatorType       = "type"
+	OperatorCollection = "collection"
+	OperatorBy         = "by"
+	OperatorBefore     = "before"
+	OperatorAfter      = "after"
+	OperatorTitle      = "title"
+	OperatorTag        = "tag"
+)
+
+// SearchQueryField is the validation error field parse errors are reported under
+const SearchQueryField = "query"
+
+var searchDateLayouts = []string{time.DateOnly, time.RFC3339}
+
+type (
+	// SearchQuery is the structured representation of a raw search query
+	SearchQuery struct {
+		Raw string
+
+		// Terms are the bare words in the query
+		Terms []string
+
+		// Phrases are the quoted parts of the query, they are matched exactly in the full-text search
+		Phrases []string
+
+		// Filters holds the filters extracted from the field operators (`type:`, `collection:`, `by:`, `before:`, `after:`, `title:`, `tag:`) and negated terms
+		Filters SearchFilters
+
+		// text holds the terms and phrases in the order they appeared
+		text []queryPart
+
+		// matchAny makes the full-text search match any of the terms instead of all of them
+		matchAny bool
+	}
+
+	queryPart struct {
+		value  string
+		phrase bool
+	}
+
+	// queryToken is a single whitespace-separated token, start and end are rune offsets into the raw query
+	queryToken struct {
+		value    string
+		quoted   bool
+		negated  bool
+		operator string
+		start    int
+		end      int
+	}
+
+	searchQueryParser struct {
+		input  []rune
+		pos    int
+		query  *SearchQuery
+		errors *apperrors.ValidationError
+		// operators is the number of field operators applied to the query
+		operators int
+	}
+)
+
+/*
+ParseSearchQuery parses a raw search query into free text and structured filters.
+
+The following syntax is supported:
+
+	type:pdf             only entries of the given type
+	collection:research  only entries in the collection with the given slug
+	by:alice             only entries added by the given username
+	before:2025-01-01    only entries created before the given date
+	after:2025-01-01     only entries created after the given date
+	title:foo            only entries with the given text in their title
+	tag:reading          only entries with the given tag, repeat the operator to require several tags
+	"exact phrase"       the phrase must appear as-is
+	-exclude             entries containing the term (or -"phrase") are excluded
+
+A query made only of operators (e.g. `type:pdf`) lists the matching entries, most recently updated first.
+
+Operator values can be quoted (e.g. `title:"annual report"`), and unknown `key:value` tokens are treated as plain terms so things like URLs and times still work.
+
+Parse errors are returned as a validation error under the `query` field, each message ends with the position of the offending token as `(position start-end)`, where start and end are zero-based character offsets and end is exclusive.
+*/
+func ParseSearchQuery(raw string) (*SearchQuery, error) {
+	parser := &searchQueryParser{
+		input:     []rune(raw),
+		pos:       0,
+		query:     &SearchQuery{Raw: raw}, //nolint:exhaustruct
+		errors:    apperrors.NewValidationError(),
+		operators: 0,
+	}
+
+	for {
+		token, ok := parser.next()
+		if !ok {
+			break
+		}
+
+		parser.apply(token)
+	}
+
+	// Operators on their own list the entries that match them, excluded terms alone would match almost everything
+	if len(parser.errors.Errs) == 0 && len(parser.query.text) == 0 && parser.operators == 0 {
+		parser.fail(0, len(parser.input), "query must contain at least one search term or operator")
+	}
+
+	if len(parser.errors.Errs) > 0 {
+		return nil, parser.errors
+	}
+
+	return parser.query, nil
+}
+
+// NewTextSearchQuery creates a query from natural-language text (e.g. a question) without parsing operators, the full-text search matches entries that contain any of the words
+func NewTextSearchQuery(text string) *SearchQuery {
+	query := &SearchQuery{Raw: text, matchAny: true} //nolint:exhaustruct
+	for _, term := range strings.FieldsFunc(text, isQuestionSeparator) {
+		query.Terms = append(query.Terms, term)
+		query.text = append(query.text, queryPart{value: term, phrase: false})
+	}
+
+	return query
+}
+
+// Text returns the free text of the query (terms and phrases without any operators), this is what is embedded for the semantic search
+func (q *SearchQuery) Text() string {
+	parts := make([]string, 0, len(q.text))
+	for _, part := range q.text {
+		parts = append(parts, part.value)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// FilterOnly reports whether the query only has operators, there is nothing to rank the entries by
+func (q *SearchQuery) FilterOnly() bool {
+	return len(q.text) == 0
+}
+
+// FullTextQuery returns the query in the `websearch_to_tsquery` syntax, phrases are quoted and excluded terms are negated
+// It is empty for filter-only queries, the excluded terms are still applied through the filters
+func (q *SearchQuery) FullTextQuery() string {
+	if q.FilterOnly() {
+		return ""
+	}
+
+	parts := make([]string, 0, len(q.text)+len(q.Filters.Excluded))
+	for _, part := range q.text {
+		if part.phrase {
+			parts = append(parts, `"`+part.value+`"`)
+			continue
+		}
+		parts = append(parts, part.value)
+	}
+
+	if q.matchAny {
+		return strings.Join(parts, " or ")
+	}
+
+	for _, excluded := range q.Filters.Excluded {
+		if strings.ContainsFunc(excluded, unicode.IsSpace) {
+			parts = append(parts, `-"`+excluded+`"`)
+			continue
+		}
+		parts = append(parts, "-"+excluded)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Keywords returns the terms and phrases that are matched literally against the results
+func (q *SearchQuery) Keywords() []string {
+	keywords := make([]string, 0, len(q.text))
+	for _, part := range q.text {
+		keywords = append(keywords, part.value)
+	}
+
+	return keywords
+}
+
+func (p *searchQueryParser) fail(start, end int, format string, args ...any) {
+	p.errors.Add(
+		SearchQueryField,
+		fmt.Sprintf("%s (position %d-%d)", fmt.Sprintf(format, args...), start, end),
+	)
+}
+
+// next reads the next token from the input, false is returned once the input has been consumed
+func (p *searchQueryParser) next() (queryToken, bool) {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+
+	if p.pos >= len(p.input) {
+		return queryToken{}, false //nolint:exhaustruct
+	}
+
+	token := queryToken{start: p.pos} //nolint:exhaustruct
+	if p.input[p.pos] == '-' {
+		token.negated = true
+		p.pos++
+	}
+
+	// Look for a known operator before reading the value
+	wordEnd := p.pos
+	for wordEnd < len(p.input) && !unicode.IsSpace(p.input[wordEnd]) && p.input[wordEnd] != ':' {
+		wordEnd++
+	}
+	if wordEnd < len(p.input) && p.input[wordEnd] == ':' {
+		if key := strings.ToLower(string(p.input[p.pos:wordEnd])); isSearchOperator(key) {
+			token.operator = key
+			p.pos = wordEnd + 1
+		}
+	}
+
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		quoteStart := p.pos
+		closing := -1
+		for i := p.pos + 1; i < len(p.input); i++ {
+			if p.input[i] == '"' {
+				closing = i
+				break
+			}
+		}
+
+		if closing == -1 {
+			p.fail(quoteStart, len(p.input), "unterminated quote")
+			token.value = strings.TrimSpace(string(p.input[quoteStart+1:]))
+			p.pos = len(p.input)
+		} else {
+			token.value = strings.TrimSpace(string(p.input[quoteStart+1 : closing]))
+			p.pos = closing + 1
+		}
+		token.quoted = true
+	} else {
+		valueStart := p.pos
+		for p.pos < len(p.input) && !unicode.IsSpace(p.input[p.pos]) {
+			p.pos++
+		}
+		token.value = string(p.input[valueStart:p.pos])
+	}
+
+	token.end = p.pos
+	return token, true
+}
+
+func (p *searchQueryParser) apply(token queryToken) {
+	if token.operator != "" {
+		p.applyOperator(token)
+		return
+	}
+
+	if token.value == "" {
+		switch {
+		case token.quoted:
+			p.fail(token.start, token.end, "empty phrase")
+		case token.negated:
+			p.fail(token.start, token.end, "expected a term after \"-\"")
+		}
+		return
+	}
+
+	if token.negated {
+		p.query.Filters.Excluded = append(p.query.Filters.Excluded, token.value)
+		return
+	}
+
+	if token.quoted {
+		p.query.Phrases = append(p.query.Phrases, token.value)
+	} else {
+		p.query.Terms = append(p.query.Terms, token.value)
+	}
+	p.query.text = append(p.query.text, queryPart{value: token.value, phrase: token.quoted})
+}
+
+func (p *searchQueryParser) applyOperator(token queryToken) {
+	if token.negated {
+		p.fail(token.start, token.end, "the %q operator cannot be negated", token.operator)
+		return
+	}
+
+	if token.value == "" {
+		p.fail(token.start, token.end, "missing value for the %q operator", token.operator)
+		return
+	}
+
+	p.operators++
+	filters := &p.query.Filters
+	switch token.operator {
+	case OperatorType:
+		entryType, err := document.ParseEntryType(strings.ToLower(token.value))
+		if err != nil {
+			p.fail(token.start, token.end, "%q is not a valid entry type", token.value)
+			return
+		}
+		filters.Types = append(filters.Types, entryType)
+
+	case OperatorCollection:
+		filters.CollectionSlugs = append(filters.CollectionSlugs, token.value)
+
+	case OperatorBy:
+		filters.AddedBy = append(filters.AddedBy, strings.TrimPrefix(token.value, "@"))
+
+	case OperatorTitle:
+		filters.Title = append(filters.Title, token.value)
+
+	case OperatorTag:
+		filters.Tags = append(filters.Tags, strings.TrimPrefix(token.value, "#"))
+
+	case OperatorBefore, OperatorAfter:
+		date, ok := parseSearchDate(token.value)
+		if !ok {
+			p.fail(
+				token.start,
+				token.end,
+				"%q is not a valid date, expected YYYY-MM-DD",
+				token.value,
+			)
+			return
+		}
+
+		// Multiple date operators narrow the range instead of replacing each other
+		if token.operator == OperatorBefore {
+			if filters.CreatedBefore.IsZero() || date.Before(filters.CreatedBefore) {
+				filters.CreatedBefore = date
+			}
+			return
+		}
+
+		// `after:2025-01-01` excludes the day itself when only a date is given
+		if len(token.value) == len(time.DateOnly) {
+			date = date.AddDate(0, 0, 1)
+		}
+		if date.After(filters.CreatedAfter) {
+			filters.CreatedAfter = date
+		}
+	}
+}
+
+// isQuestionSeparator splits natural-language text into words, punctuation is dropped so it is not mistaken for the websearch syntax (e.g. a leading "-" or quotes)
+func isQuestionSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isSearchOperator(key string) bool {
+	switch key {
+	case OperatorType,
+		OperatorCollection,
+		OperatorBy,
+		OperatorBefore,
+		OperatorAfter,
+		OperatorTitle,
+		OperatorTag:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseSearchDate(value string) (time.Time, bool) {
+	for _, layout := range searchDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.trulyao.dev/hubble/web/internal/repository"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+)
+
+func Test_ParseSearchQuery(t *testing.T) {
+	query, err := repository.ParseSearchQuery(
+		`type:pdf collection:research by:@alice before:2025-01-01 title:"annual report" tag:#reading tag:"to do" "exact phrase" budget -draft -"old version" http://example.com`,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if want := `exact phrase budget http://example.com`; query.Text() != want {
+		t.Errorf("expected text %q, got %q", want, query.Text())
+	}
+
+	if want := `"exact phrase" budget http://example.com -draft -"old version"`; query.FullTextQuery() != want {
+		t.Errorf("expected full-text query %q, got %q", want, query.FullTextQuery())
+	}
+
+	filters := query.Filters
+	if !slices.Equal(filters.Types, []document.EntryType{document.EntryTypePdf}) {
+		t.Errorf("expected type filter [pdf], got %v", filters.Types)
+	}
+	if !slices.Equal(filters.CollectionSlugs, []string{"research"}) {
+		t.Errorf("expected collection filter [research], got %v", filters.CollectionSlugs)
+	}
+	if !slices.Equal(filters.AddedBy, []string{"alice"}) {
+		t.Errorf("expected added by filter [alice], got %v", filters.AddedBy)
+	}
+	if !slices.Equal(filters.Title, []string{"annual report"}) {
+		t.Errorf("expected title filter [annual report], got %v", filters.Title)
+	}
+	if !slices.Equal(filters.Tags, []string{"reading", "to do"}) {
+		t.Errorf("expected tag filter [reading to do], got %v", filters.Tags)
+	}
+	if !slices.Equal(filters.Excluded, []string{"draft", "old version"}) {
+		t.Errorf("expected excluded terms [draft old version], got %v", filters.Excluded)
+	}
+	if want := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); !filters.CreatedBefore.Equal(want) {
+		t.Errorf("expected created before %s, got %s", want, filters.CreatedBefore)
+	}
+}
+
+func Test_ParseSearchQuery_OperatorsOnly(t *testing.T) {
+	query, err := repository.ParseSearchQuery("type:pdf -draft")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !query.FilterOnly() {
+		t.Error("expected a filter-only query")
+	}
+	if query.FullTextQuery() != "" {
+		t.Errorf("expected an empty full-text query, got %q", query.FullTextQuery())
+	}
+	if !slices.Equal(query.Filters.Types, []document.EntryType{document.EntryTypePdf}) {
+		t.Errorf("expected type filter [pdf], got %v", query.Filters.Types)
+	}
+	if !slices.Equal(query.Filters.Excluded, []string{"draft"}) {
+		t.Errorf("expected excluded terms [draft], got %v", query.Filters.Excluded)
+	}
+}
+
+func Test_ParseSearchQuery_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "invalid type", query: "notes type:pdfx", want: `"pdfx" is not a valid entry type (position 6-15)`},
+		{name: "invalid date", query: "notes before:yesterday", want: "(position 6-22)"},
+		{name: "missing value", query: "notes by:", want: `missing value for the "by" operator (position 6-9)`},
+		{name: "unterminated quote", query: `notes "exact`, want: "unterminated quote (position 6-12)"},
+		{name: "negated operator", query: "notes -type:pdf", want: "(position 6-15)"},
+		{name: "dangling negation", query: "notes - foo", want: "(position 6-7)"},
+		{name: "only excluded terms", query: "-draft -old", want: "(position 0-11)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repository.ParseSearchQuery(tt.query)
+
+			var validationErr *apperrors.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+
+			messages := validationErr.Errs[repository.SearchQueryField]
+			if len(messages) != 1 || !strings.Contains(messages[0], tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, messages)
+			}
+		})
+	}
+}
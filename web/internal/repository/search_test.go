@@ -0,0 +1,392 @@
+package repository_test
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/internal/repository"
+	"go.trulyao.dev/hubble/web/pkg/document"
+)
+
+func chunkResult(id int32, source models.SearchSource, text, semantic float64) models.SearchResult {
+	//nolint:exhaustruct
+	return models.SearchResult{
+		Source:        source,
+		TextScore:     text,
+		SemanticScore: semantic,
+		Chunk:         models.SearchResultChunkMetadata{ID: id},
+	}
+}
+
+func Test_FuseWithRRF(t *testing.T) {
+	results := []models.SearchResult{
+		chunkResult(1, models.SearchTypeSemantic, 0, 0.10),
+		chunkResult(2, models.SearchTypeSemantic, 0, 0.30),
+		chunkResult(3, models.SearchTypeSemantic, 0, 0.20),
+		chunkResult(2, models.SearchTypeFullText, 0.8, 0),
+		chunkResult(4, models.SearchTypeFullText, 0.2, 0),
+	}
+
+	params := &repository.RRFParams{K: 60, SemanticWeight: 1, FullTextWeight: 1}
+	fused := repository.FuseWithRRF(results, params)
+
+	if len(fused) != 4 {
+		t.Fatalf("expected 4 fused results, got %d", len(fused))
+	}
+
+	scores := make(map[int32]models.SearchResult, len(fused))
+	for _, result := range fused {
+		scores[result.Chunk.ID] = result
+	}
+
+	maxScore := 2.0 / 61
+	tests := []struct {
+		name string
+		id   int32
+		want float64
+	}{
+		{name: "first semantic result", id: 1, want: (1.0 / 61) / maxScore},
+		{name: "chunk in both lists", id: 2, want: (1.0/63 + 1.0/61) / maxScore},
+		{name: "second semantic result", id: 3, want: (1.0 / 62) / maxScore},
+		{name: "second full-text result", id: 4, want: (1.0 / 62) / maxScore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scores[tt.id].HybridScore
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected score %f, got %f", tt.want, got)
+			}
+		})
+	}
+
+	merged := scores[2]
+	if merged.TextScore != 0.8 || merged.SemanticScore != 0.30 {
+		t.Errorf(
+			"expected merged chunk to keep both scores, got text=%f semantic=%f",
+			merged.TextScore,
+			merged.SemanticScore,
+		)
+	}
+}
+
+func Test_FuseWithRRF_Weights(t *testing.T) {
+	results := []models.SearchResult{
+		chunkResult(1, models.SearchTypeSemantic, 0, 0.10),
+		chunkResult(2, models.SearchTypeFullText, 0.9, 0),
+	}
+
+	params := &repository.RRFParams{K: 60, SemanticWeight: 0, FullTextWeight: 1}
+	fused := repository.FuseWithRRF(results, params)
+
+	for _, result := range fused {
+		if result.Chunk.ID == 1 && result.HybridScore != 0 {
+			t.Errorf(
+				"expected semantic-only chunk to score 0 with a zero weight, got %f",
+				result.HybridScore,
+			)
+		}
+
+		if result.Chunk.ID == 2 && math.Abs(result.HybridScore-1) > 1e-9 {
+			t.Errorf("expected top full-text chunk to score 1, got %f", result.HybridScore)
+		}
+	}
+}
+
+func Test_BuildFacets(t *testing.T) {
+	notes := models.EntryRelation{ID: pgtype.UUID{Bytes: [16]byte{1}, Valid: true}, Name: "Notes"}
+	papers := models.EntryRelation{ID: pgtype.UUID{Bytes: [16]byte{2}, Valid: true}, Name: "Papers"}
+	books := models.EntryRelation{ID: pgtype.UUID{Bytes: [16]byte{3}, Valid: true}, Name: "Books"}
+
+	tests := []struct {
+		name   string
+		counts []repository.FacetCount
+		want   models.SearchFacets
+	}{
+		{
+			name:   "no matches",
+			counts: []repository.FacetCount{},
+			want: models.SearchFacets{
+				Types:       []models.TypeFacet{},
+				Collections: []models.CollectionFacet{},
+			},
+		},
+		{
+			name: "counts are summed across types and collections",
+			counts: []repository.FacetCount{
+				{Type: document.EntryTypePdf, Collection: papers, Count: 120},
+				{Type: document.EntryTypeMarkdown, Collection: notes, Count: 4},
+				{Type: document.EntryTypePdf, Collection: notes, Count: 2},
+				{Type: document.EntryTypeLink, Collection: books, Count: 6},
+			},
+			want: models.SearchFacets{
+				Types: []models.TypeFacet{
+					{Type: document.EntryTypePdf, Count: 122},
+					{Type: document.EntryTypeLink, Count: 6},
+					{Type: document.EntryTypeMarkdown, Count: 4},
+				},
+				Collections: []models.CollectionFacet{
+					{Collection: papers, Count: 120},
+					{Collection: books, Count: 6},
+					{Collection: notes, Count: 6},
+				},
+			},
+		},
+		{
+			name: "ties are ordered by name",
+			counts: []repository.FacetCount{
+				{Type: document.EntryTypePdf, Collection: papers, Count: 3},
+				{Type: document.EntryTypeEpub, Collection: books, Count: 3},
+			},
+			want: models.SearchFacets{
+				Types: []models.TypeFacet{
+					{Type: document.EntryTypeEpub, Count: 3},
+					{Type: document.EntryTypePdf, Count: 3},
+				},
+				Collections: []models.CollectionFacet{
+					{Collection: books, Count: 3},
+					{Collection: papers, Count: 3},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := repository.BuildFacets(tt.counts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected facets %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_SearchFilters_Merge(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name        string
+		filters     repository.SearchFilters
+		other       repository.SearchFilters
+		wantTypes   []document.EntryType
+		wantTags    []string
+		wantAfter   time.Time
+		wantNothing bool
+	}{
+		{
+			name: "types are intersected",
+			filters: repository.SearchFilters{
+				Types: []document.EntryType{document.EntryTypePdf, document.EntryTypeEpub},
+			},
+			other:     repository.SearchFilters{Types: []document.EntryType{document.EntryTypePdf}},
+			wantTypes: []document.EntryType{document.EntryTypePdf},
+		},
+		{
+			name:    "an empty side does not restrict anything",
+			filters: repository.SearchFilters{},
+			other: repository.SearchFilters{
+				Types: []document.EntryType{document.EntryTypeLink},
+			},
+			wantTypes: []document.EntryType{document.EntryTypeLink},
+		},
+		{
+			name: "types without overlap match nothing",
+			filters: repository.SearchFilters{
+				Types: []document.EntryType{document.EntryTypeImage},
+			},
+			other: repository.SearchFilters{
+				Types: []document.EntryType{document.EntryTypePdf},
+			},
+			wantTypes:   []document.EntryType{},
+			wantNothing: true,
+		},
+		{
+			name:     "tags are combined",
+			filters:  repository.SearchFilters{Tags: []string{"reading"}},
+			other:    repository.SearchFilters{Tags: []string{"work"}},
+			wantTags: []string{"reading", "work"},
+		},
+		{
+			name:      "dates are narrowed",
+			filters:   repository.SearchFilters{CreatedAfter: day(1)},
+			other:     repository.SearchFilters{CreatedAfter: day(10)},
+			wantAfter: day(10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filters.Merge(&tt.other)
+
+			if !slices.Equal(tt.filters.Types, tt.wantTypes) {
+				t.Errorf("expected types %v, got %v", tt.wantTypes, tt.filters.Types)
+			}
+			if !slices.Equal(tt.filters.Tags, tt.wantTags) {
+				t.Errorf("expected tags %v, got %v", tt.wantTags, tt.filters.Tags)
+			}
+			if !tt.filters.CreatedAfter.Equal(tt.wantAfter) {
+				t.Errorf("expected created after %s, got %s", tt.wantAfter, tt.filters.CreatedAfter)
+			}
+			if tt.filters.MatchesNothing() != tt.wantNothing {
+				t.Errorf(
+					"expected matches nothing %t, got %t",
+					tt.wantNothing,
+					tt.filters.MatchesNothing(),
+				)
+			}
+		})
+	}
+}
+
+func Test_SearchFilters_ResolveCollections(t *testing.T) {
+	collections := []models.Collection{
+		{ID: "00000000-0000-0000-0000-000000000001", Slug: "research"},
+		{ID: "00000000-0000-0000-0000-000000000002", Slug: "reading"},
+	}
+	research := pgtype.UUID{Bytes: [16]byte{15: 1}, Valid: true}
+	reading := pgtype.UUID{Bytes: [16]byte{15: 2}, Valid: true}
+
+	requested := repository.SearchFilters{CollectionIDs: []pgtype.UUID{research, reading}}
+	query := repository.SearchFilters{CollectionSlugs: []string{"reading"}}
+
+	requested.ResolveCollections(collections)
+	query.ResolveCollections(collections)
+	requested.Merge(&query)
+
+	if !slices.Equal(requested.CollectionIDs, []pgtype.UUID{reading}) {
+		t.Errorf("expected only the reading collection, got %v", requested.CollectionIDs)
+	}
+	if len(requested.CollectionSlugs) != 0 || requested.MatchesNothing() {
+		t.Errorf("expected the slugs to be resolved, got %v", requested.CollectionSlugs)
+	}
+
+	unknown := repository.SearchFilters{CollectionSlugs: []string{"archive"}}
+	unknown.ResolveCollections(collections)
+	if !unknown.MatchesNothing() {
+		t.Error("expected an unknown collection to match nothing")
+	}
+}
+
+func Test_SearchFilters_MatchesCommentedEntry(t *testing.T) {
+	tests := []struct {
+		name      string
+		filters   repository.SearchFilters
+		entryType document.EntryType
+		tags      []string
+		want      bool
+	}{
+		{
+			name:      "no filters",
+			filters:   repository.SearchFilters{},
+			entryType: document.EntryTypePdf,
+			want:      true,
+		},
+		{
+			name:      "matching type",
+			filters:   repository.SearchFilters{Types: []document.EntryType{document.EntryTypePdf}},
+			entryType: document.EntryTypePdf,
+			want:      true,
+		},
+		{
+			name:      "other type",
+			filters:   repository.SearchFilters{Types: []document.EntryType{document.EntryTypePdf}},
+			entryType: document.EntryTypeLink,
+			want:      false,
+		},
+		{
+			name: "comments requested",
+			filters: repository.SearchFilters{
+				Types: []document.EntryType{document.EntryTypeComment},
+			},
+			entryType: document.EntryTypeLink,
+			want:      true,
+		},
+		{
+			name:      "all tags",
+			filters:   repository.SearchFilters{Tags: []string{" Go ", "research"}},
+			entryType: document.EntryTypePdf,
+			tags:      []string{"research", "go", "notes"},
+			want:      true,
+		},
+		{
+			name:      "missing tag",
+			filters:   repository.SearchFilters{Tags: []string{"go", "research"}},
+			entryType: document.EntryTypePdf,
+			tags:      []string{"go"},
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filters.MatchesCommentedEntry(tt.entryType, tt.tags); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_NeedsFuzzyFallback(t *testing.T) {
+	entryResult := func(entry byte, source models.SearchSource, text float64) models.SearchResult {
+		result := chunkResult(int32(entry), source, text, 0.4)
+		result.ID = pgtype.UUID{Bytes: [16]byte{entry}, Valid: true}
+		return result
+	}
+
+	semanticOnly := make([]models.SearchResult, 0, 10)
+	for i := range byte(10) {
+		semanticOnly = append(semanticOnly, entryResult(i+1, models.SearchTypeSemantic, 0))
+	}
+
+	tests := []struct {
+		name       string
+		results    []models.SearchResult
+		minResults int
+		want       bool
+	}{
+		{
+			name:       "semantic candidates do not count",
+			results:    semanticOnly,
+			minResults: 5,
+			want:       true,
+		},
+		{
+			name: "chunks of the same entry are counted once",
+			results: []models.SearchResult{
+				entryResult(1, models.SearchTypeFullText, 0.3),
+				entryResult(1, models.SearchTypeFullText, 0.2),
+				entryResult(2, models.SearchTypeSemantic, 0),
+			},
+			minResults: 2,
+			want:       true,
+		},
+		{
+			name: "fused chunks keep their text score",
+			results: []models.SearchResult{
+				entryResult(1, models.SearchTypeSemantic, 0.3),
+				entryResult(2, models.SearchTypeFullText, 0.2),
+			},
+			minResults: 2,
+			want:       false,
+		},
+		{
+			name:       "disabled",
+			results:    []models.SearchResult{},
+			minResults: 0,
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repository.NeedsFuzzyFallback(tt.results, tt.minResults); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+// UnsummarisedEntriesPageSize is the number of entries returned by a single FindUnsummarisedIDs call
+const UnsummarisedEntriesPageSize = 500
+
+type (
+	// SummarySource is the entry a summary is generated from
+	SummarySource struct {
+		EntryID int32
+		Name    string
+		Version int32
+		// SummariesEnabled is true if the entry's workspace opted into summaries
+		SummariesEnabled bool
+		// SummaryVersion is the version of the entry the current summaries were generated from, 0 if the entry has never been summarised
+		SummaryVersion int32
+		// Archived is true if the entry is archived, archived entries are not summarised
+		Archived bool
+	}
+
+	SaveSummaryArgs struct {
+		EntryID int32
+		Summary models.EntrySummary
+		// ChatModel is the model that generated the summary
+		ChatModel string
+	}
+
+	SummaryRepository interface {
+		// FindSource returns the entry to summarise along with the state of its current summaries
+		FindSource(entryID int32) (SummarySource, error)
+
+		// FindChunks returns the content of the entry's chunks in order
+		FindChunks(entryID int32) ([]string, error)
+
+		// Save creates or replaces the summaries of an entry, the entry is re-indexed with them
+		Save(args *SaveSummaryArgs) error
+
+		// FindUnsummarisedIDs returns the next page of the latest versions of the processed entries (comments and archived entries excluded) after `afterID` of the opted-in workspaces whose summaries are missing or outdated, all workspaces are checked if the workspace ID is 0
+		FindUnsummarisedIDs(workspaceID int32, afterID int32) ([]int32, error)
+	}
+
+	summaryRepo struct {
+		*baseRepo
+	}
+)
+
+// FindSource implements SummaryRepository.
+func (s *summaryRepo) FindSource(entryID int32) (SummarySource, error) {
+	row, err := s.queries.FindEntrySummarySource(context.TODO(), entryID)
+	if err != nil {
+		return SummarySource{}, seer.Wrap("find_entry_summary_source", err)
+	}
+
+	return SummarySource{
+		EntryID:          row.ID,
+		Name:             row.Name,
+		Version:          row.Version,
+		SummariesEnabled: row.SummariesEnabled,
+		SummaryVersion:   row.SummaryVersion.Int32,
+		Archived:         row.ArchivedAt.Valid,
+	}, nil
+}
+
+// FindChunks implements SummaryRepository.
+func (s *summaryRepo) FindChunks(entryID int32) ([]string, error) {
+	rows, err := s.queries.FindEntrySummaryChunks(context.TODO(), lib.PgInt4(entryID))
+	if err != nil {
+		return nil, seer.Wrap("find_entry_summary_chunks", err)
+	}
+
+	chunks := make([]string, 0, len(rows))
+	for _, row := range rows {
+		chunks = append(chunks, row.String)
+	}
+
+	return chunks, nil
+}
+
+// Save implements SummaryRepository.
+func (s *summaryRepo) Save(args *SaveSummaryArgs) error {
+	if err := s.queries.UpsertEntrySummary(context.TODO(), queries.UpsertEntrySummaryParams{
+		EntryID:      args.EntryID,
+		EntryVersion: args.Summary.Version,
+		SummaryShort: args.Summary.Short,
+		SummaryLong:  args.Summary.Long,
+		ChatModel:    args.ChatModel,
+	}); err != nil {
+		return seer.Wrap("upsert_entry_summary", err)
+	}
+
+	return nil
+}
+
+// FindUnsummarisedIDs implements SummaryRepository.
+func (s *summaryRepo) FindUnsummarisedIDs(workspaceID int32, afterID int32) ([]int32, error) {
+	ids, err := s.queries.FindUnsummarisedEntries(
+		context.TODO(),
+		queries.FindUnsummarisedEntriesParams{
+			WorkspaceID: lib.PgInt4(workspaceID),
+			AfterID:     afterID,
+			PageSize:    UnsummarisedEntriesPageSize,
+		},
+	)
+	if err != nil {
+		return nil, seer.Wrap("find_unsummarised_entries", err)
+	}
+
+	return ids, nil
+}
+
+// entrySummary returns the summary of an entry, nil is returned if the entry has not been summarised
+func entrySummary(short, long pgtype.Text, version pgtype.Int4) *models.EntrySummary {
+	if !short.Valid || !long.Valid {
+		return nil
+	}
+
+	return &models.EntrySummary{Short: short.String, Long: long.String, Version: version.Int32}
+}
+
+var _ SummaryRepository = (*summaryRepo)(nil)
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+var ErrTagNotFound = apperrors.BadRequest("this tag does not exist in this collection")
+
+type (
+	CreateTagArgs struct {
+		CollectionID int32
+		UserID       int32
+		Name         string
+		Description  string
+		Color        string
+	}
+
+	// UpdateTagArgs updates the details of a tag, empty fields are left unchanged
+	UpdateTagArgs struct {
+		Tag         *models.Tag
+		Name        string
+		Description string
+		Color       string
+	}
+
+	TagEntriesArgs struct {
+		CollectionID int32
+		UserID       int32
+		TagIDs       []pgtype.UUID
+		EntryIDs     []pgtype.UUID
+	}
+
+	TagRepository interface {
+		// Create creates a tag in a collection, tag names are unique (case-insensitively) within a collection
+		Create(args *CreateTagArgs) (models.Tag, error)
+
+		// FindByID finds a tag in a collection by its public ID
+		FindByID(collectionID int32, tagID pgtype.UUID) (models.Tag, error)
+
+		// FindAll returns the tags of a collection with the number of entries they are assigned to
+		FindAll(collectionID int32) ([]models.Tag, error)
+
+		// Update updates the details of a tag
+		Update(args *UpdateTagArgs) (models.Tag, error)
+
+		// Delete deletes a tag and removes it from all entries
+		Delete(tagID int32) error
+
+		// TagEntries assigns the tags to the entries, entries that already have a tag are skipped and the number of new assignments is returned
+		TagEntries(args *TagEntriesArgs) (int64, error)
+
+		// UntagEntries removes the tags from the entries and returns the number of removed assignments
+		UntagEntries(args *TagEntriesArgs) (int64, error)
+
+		// FindEntriesTags returns the tags of the given entries, keyed by the entries' internal IDs
+		FindEntriesTags(entryIDs []int32) (map[int32][]models.EntryTag, error)
+	}
+
+	tagRepo struct {
+		*baseRepo
+	}
+)
+
+// Create implements TagRepository.
+func (t *tagRepo) Create(args *CreateTagArgs) (models.Tag, error) {
+	if err := t.checkName(args.CollectionID, args.Name, 0); err != nil {
+		return models.Tag{}, err
+	}
+
+	row, err := t.queries.CreateTag(context.TODO(), queries.CreateTagParams{
+		Name:         args.Name,
+		Description:  lib.PgText(args.Description),
+		Color:        lib.PgText(args.Color),
+		CollectionID: args.CollectionID,
+		CreatedBy:    args.UserID,
+	})
+	if err != nil {
+		return models.Tag{}, seer.Wrap("create_tag", err)
+	}
+
+	tag := models.Tag{} //nolint:exhaustruct
+	tag.From(&row)
+	return tag, nil
+}
+
+// FindByID implements TagRepository.
+func (t *tagRepo) FindByID(collectionID int32, tagID pgtype.UUID) (models.Tag, error) {
+	row, err := t.queries.FindTag(context.TODO(), queries.FindTagParams{
+		PublicID:     tagID,
+		CollectionID: collectionID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Tag{}, ErrTagNotFound
+		}
+		return models.Tag{}, seer.Wrap("find_tag", err)
+	}
+
+	tag := models.Tag{} //nolint:exhaustruct
+	tag.From(&row)
+	return tag, nil
+}
+
+// FindAll implements TagRepository.
+func (t *tagRepo) FindAll(collectionID int32) ([]models.Tag, error) {
+	rows, err := t.queries.FindCollectionTags(context.TODO(), collectionID)
+	if err != nil {
+		return nil, seer.Wrap("find_collection_tags", err)
+	}
+
+	tags := make([]models.Tag, 0, len(rows))
+	for _, row := range rows {
+		tags = append(tags, models.Tag{
+			InternalID:   row.ID,
+			ID:           row.PublicID,
+			Name:         row.Name,
+			Description:  row.Description.String,
+			Color:        row.Color.String,
+			CollectionID: collectionID,
+			CreatedAt:    row.CreatedAt.Time,
+			UpdatedAt:    row.UpdatedAt.Time,
+			EntriesCount: row.EntryCount,
+		})
+	}
+
+	return tags, nil
+}
+
+// Update implements TagRepository.
+func (t *tagRepo) Update(args *UpdateTagArgs) (models.Tag, error) {
+	if args.Name != "" && !strings.EqualFold(args.Name, args.Tag.Name) {
+		if err := t.checkName(args.Tag.CollectionID, args.Name, args.Tag.InternalID); err != nil {
+			return models.Tag{}, err
+		}
+	}
+
+	row, err := t.queries.UpdateTag(context.TODO(), queries.UpdateTagParams{
+		Name:        lib.PgText(args.Name),
+		Description: lib.PgText(args.Description),
+		Color:       lib.PgText(args.Color),
+		ID:          args.Tag.InternalID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Tag{}, ErrTagNotFound
+		}
+		return models.Tag{}, seer.Wrap("update_tag", err)
+	}
+
+	tag := models.Tag{} //nolint:exhaustruct
+	tag.From(&row)
+	return tag, nil
+}
+
+// Delete implements TagRepository.
+func (t *tagRepo) Delete(tagID int32) error {
+	if err := t.queries.DeleteTag(context.TODO(), tagID); err != nil {
+		return seer.Wrap("delete_tag", err)
+	}
+
+	return nil
+}
+
+// TagEntries implements TagRepository.
+func (t *tagRepo) TagEntries(args *TagEntriesArgs) (int64, error) {
+	count, err := t.queries.TagEntries(context.TODO(), queries.TagEntriesParams{
+		AddedBy:      args.UserID,
+		CollectionID: args.CollectionID,
+		TagIds:       args.TagIDs,
+		EntryIds:     args.EntryIDs,
+	})
+	if err != nil {
+		return 0, seer.Wrap("tag_entries", err)
+	}
+
+	return count, nil
+}
+
+// UntagEntries implements TagRepository.
+func (t *tagRepo) UntagEntries(args *TagEntriesArgs) (int64, error) {
+	count, err := t.queries.UntagEntries(context.TODO(), queries.UntagEntriesParams{
+		CollectionID: args.CollectionID,
+		TagIds:       args.TagIDs,
+		EntryIds:     args.EntryIDs,
+	})
+	if err != nil {
+		return 0, seer.Wrap("untag_entries", err)
+	}
+
+	return count, nil
+}
+
+// FindEntriesTags implements TagRepository.
+func (t *tagRepo) FindEntriesTags(entryIDs []int32) (map[int32][]models.EntryTag, error) {
+	tags := make(map[int32][]models.EntryTag, len(entryIDs))
+	if len(entryIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := t.queries.FindEntriesTags(context.TODO(), entryIDs)
+	if err != nil {
+		return nil, seer.Wrap("find_entries_tags", err)
+	}
+
+	for _, row := range rows {
+		tags[row.EntryID] = append(tags[row.EntryID], models.EntryTag{
+			ID:    row.PublicID,
+			Name:  row.Name,
+			Color: row.Color.String,
+		})
+	}
+
+	return tags, nil
+}
+
+// entryTags returns the tags of an entry, entries without tags get an empty list
+func entryTags(tags map[int32][]models.EntryTag, entryID int32) []models.EntryTag {
+	if entryTags, ok := tags[entryID]; ok {
+		return entryTags
+	}
+
+	return []models.EntryTag{}
+}
+
+// checkName returns a validation error if another tag in the collection already has the name
+func (t *tagRepo) checkName(collectionID int32, name string, excludeID int32) error {
+	exists, err := t.queries.TagNameExists(context.TODO(), queries.TagNameExistsParams{
+		CollectionID: collectionID,
+		Name:         name,
+		ExcludeID:    lib.PgInt4(excludeID),
+	})
+	if err != nil {
+		return seer.Wrap("check_tag_name", err)
+	}
+
+	if exists {
+		return apperrors.NewValidationError(apperrors.ErrorMap{
+			"name": {"A tag with this name already exists in this collection"},
+		})
+	}
+
+	return nil
+}
+
+// normalizeTagNames lowercases and deduplicates tag names for the case-insensitive tag filters, nil is returned if there are no names
+func normalizeTagNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+
+	return nilIfEmpty(normalized)
+}
+
+var _ TagRepository = (*tagRepo)(nil)
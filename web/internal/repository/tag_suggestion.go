@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+// UnclassifiedEntriesPageSize is the number of entries returned by a single FindUnclassifiedIDs call
+const UnclassifiedEntriesPageSize = 500
+
+type (
+	// TagSuggestionSource is the entry tags are suggested for
+	TagSuggestionSource struct {
+		EntryID      int32
+		Name         string
+		Version      int32
+		CollectionID int32
+		AddedBy      int32
+		// Summary is the long summary of the entry, empty if it has not been summarised
+		Summary            string
+		Mode               queries.TagSuggestionMode
+		AutoApplyThreshold float64
+		// SuggestedVersion is the version of the entry tags were last suggested for, 0 if it has never been classified
+		SuggestedVersion int32
+	}
+
+	// PendingTagSuggestion is a suggestion that has not been accepted or rejected yet
+	PendingTagSuggestion struct {
+		ID         int32
+		EntryID    int32
+		Name       string
+		Confidence float64
+	}
+
+	// RejectedTagSuggestion is a suggestion a user rejected, along with the name of its entry
+	RejectedTagSuggestion struct {
+		EntryName string
+		Name      string
+	}
+
+	SaveTagSuggestionsArgs struct {
+		EntryID      int32
+		EntryVersion int32
+		// ChatModel is the model that suggested the tags
+		ChatModel   string
+		Suggestions []PendingTagSuggestion
+	}
+
+	FindTagSuggestionsArgs struct {
+		CollectionID int32
+		// EntryID optionally limits the suggestions to a single entry
+		EntryID pgtype.UUID
+		// Status optionally limits the suggestions to a single status
+		Status queries.TagSuggestionStatus
+	}
+
+	AcceptTagSuggestionsArgs struct {
+		CollectionID int32
+		// UserID is the user the tags are created and assigned by
+		UserID int32
+		// AutoApplied is true if the suggestions are accepted without a review, they are not attributed to the user
+		AutoApplied bool
+		Suggestions []PendingTagSuggestion
+	}
+
+	TagSuggestionRepository interface {
+		// FindSource returns the entry to suggest tags for along with its workspace's settings
+		FindSource(entryID int32) (TagSuggestionSource, error)
+
+		// FindRejected returns the most recently rejected suggestions of a collection
+		FindRejected(collectionID int32, limit int32) ([]RejectedTagSuggestion, error)
+
+		// Save stores the suggestions of an entry and records the version it was classified at, suggestions that were already reviewed are skipped and the pending ones are returned
+		Save(args *SaveTagSuggestionsArgs) ([]PendingTagSuggestion, error)
+
+		// FindAll returns the suggestions of a collection's entries
+		FindAll(args *FindTagSuggestionsArgs) ([]models.TagSuggestion, error)
+
+		// FindPending returns the pending suggestions of a collection by their public IDs
+		FindPending(collectionID int32, suggestionIDs []pgtype.UUID) ([]PendingTagSuggestion, error)
+
+		// Accept assigns the suggested tags to their entries, tags that do not exist in the collection yet are created
+		Accept(args *AcceptTagSuggestionsArgs) (int64, error)
+
+		// Reject rejects the pending suggestions and returns the number of rejected suggestions
+		Reject(collectionID int32, userID int32, suggestionIDs []pgtype.UUID) (int64, error)
+
+		// FindUnclassifiedIDs returns the next page of processed entries after `afterID` of the workspaces with tag suggestions enabled that have not been classified at their latest version, all workspaces are checked if the workspace ID is 0
+		FindUnclassifiedIDs(workspaceID int32, afterID int32) ([]int32, error)
+	}
+
+	tagSuggestionRepo struct {
+		*baseRepo
+	}
+)
+
+// FindSource implements TagSuggestionRepository.
+func (t *tagSuggestionRepo) FindSource(entryID int32) (TagSuggestionSource, error) {
+	row, err := t.queries.FindTagSuggestionSource(context.TODO(), entryID)
+	if err != nil {
+		return TagSuggestionSource{}, seer.Wrap("find_tag_suggestion_source", err)
+	}
+
+	return TagSuggestionSource{
+		EntryID:            row.ID,
+		Name:               row.Name,
+		Version:            row.Version,
+		CollectionID:       row.CollectionID,
+		AddedBy:            row.AddedBy,
+		Summary:            row.SummaryLong.String,
+		Mode:               row.TagSuggestionMode,
+		AutoApplyThreshold: row.TagAutoApplyThreshold,
+		SuggestedVersion:   row.SuggestedVersion,
+	}, nil
+}
+
+// FindRejected implements TagSuggestionRepository.
+func (t *tagSuggestionRepo) FindRejected(
+	collectionID int32,
+	limit int32,
+) ([]RejectedTagSuggestion, error) {
+	rows, err := t.queries.FindRejectedTagSuggestions(
+		context.TODO(),
+		queries.FindRejectedTagSuggestionsParams{CollectionID: collectionID, MaxExamples: limit},
+	)
+	if err != nil {
+		return nil, seer.Wrap("find_rejected_tag_suggestions", err)
+	}
+
+	rejected := make([]RejectedTagSuggestion, 0, len(rows))
+	for _, row := range rows {
+		rejected = append(rejected, RejectedTagSuggestion{EntryName: row.EntryName, Name: row.Name})
+	}
+
+	return rejected, nil
+}
+
+// Save implements TagSuggestionRepository.
+func (t *tagSuggestionRepo) Save(args *SaveTagSuggestionsArgs) ([]PendingTagSuggestion, error) {
+	tx, err := t.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := t.queries.WithTx(tx)
+
+	pending := make([]PendingTagSuggestion, 0, len(args.Suggestions))
+	for _, suggestion := range args.Suggestions {
+		row, err := queriesWithTx.UpsertTagSuggestion(
+			context.TODO(),
+			queries.UpsertTagSuggestionParams{
+				EntryID:      args.EntryID,
+				Name:         suggestion.Name,
+				Confidence:   suggestion.Confidence,
+				EntryVersion: args.EntryVersion,
+			},
+		)
+		if err != nil {
+			// The suggestion has already been accepted or rejected
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+
+			return nil, seer.Wrap("upsert_tag_suggestion", err)
+		}
+
+		pending = append(pending, PendingTagSuggestion{
+			ID:         row.ID,
+			EntryID:    row.EntryID,
+			Name:       row.Name,
+			Confidence: row.Confidence,
+		})
+	}
+
+	if err := queriesWithTx.UpsertTagSuggestionRun(
+		context.TODO(),
+		queries.UpsertTagSuggestionRunParams{
+			EntryID:      args.EntryID,
+			EntryVersion: args.EntryVersion,
+			ChatModel:    args.ChatModel,
+		},
+	); err != nil {
+		return nil, seer.Wrap("upsert_tag_suggestion_run", err)
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// FindAll implements TagSuggestionRepository.
+func (t *tagSuggestionRepo) FindAll(args *FindTagSuggestionsArgs) ([]models.TagSuggestion, error) {
+	rows, err := t.queries.FindTagSuggestions(context.TODO(), queries.FindTagSuggestionsParams{
+		CollectionID: args.CollectionID,
+		EntryID:      args.EntryID,
+		Status: queries.NullTagSuggestionStatus{
+			TagSuggestionStatus: args.Status,
+			Valid:               args.Status != "",
+		},
+	})
+	if err != nil {
+		return nil, seer.Wrap("find_tag_suggestions", err)
+	}
+
+	suggestions := make([]models.TagSuggestion, 0, len(rows))
+	for _, row := range rows {
+		suggestion := models.TagSuggestion{
+			ID:         row.PublicID,
+			Name:       row.Name,
+			Confidence: row.Confidence,
+			Status:     row.Status,
+			Entry:      models.EntryRelation{ID: row.EntryPublicID, Name: row.EntryName, Slug: ""},
+			TagID:      nil,
+			CreatedAt:  row.CreatedAt.Time,
+		}
+
+		if row.TagPublicID.Valid {
+			suggestion.TagID = &row.TagPublicID
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
+
+// FindPending implements TagSuggestionRepository.
+func (t *tagSuggestionRepo) FindPending(
+	collectionID int32,
+	suggestionIDs []pgtype.UUID,
+) ([]PendingTagSuggestion, error) {
+	rows, err := t.queries.FindPendingTagSuggestions(
+		context.TODO(),
+		queries.FindPendingTagSuggestionsParams{
+			CollectionID:  collectionID,
+			SuggestionIds: suggestionIDs,
+		},
+	)
+	if err != nil {
+		return nil, seer.Wrap("find_pending_tag_suggestions", err)
+	}
+
+	pending := make([]PendingTagSuggestion, 0, len(rows))
+	for _, row := range rows {
+		pending = append(pending, PendingTagSuggestion{
+			ID:         row.ID,
+			EntryID:    row.EntryID,
+			Name:       row.Name,
+			Confidence: 0,
+		})
+	}
+
+	return pending, nil
+}
+
+// Accept implements TagSuggestionRepository.
+func (t *tagSuggestionRepo) Accept(args *AcceptTagSuggestionsArgs) (int64, error) {
+	if len(args.Suggestions) == 0 {
+		return 0, nil
+	}
+
+	tx, err := t.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := t.queries.WithTx(tx)
+
+	reviewedBy := lib.PgInt4(args.UserID)
+	if args.AutoApplied {
+		reviewedBy = pgtype.Int4{Int32: 0, Valid: false}
+	}
+
+	for _, suggestion := range args.Suggestions {
+		tag, err := queriesWithTx.FindTagByName(context.TODO(), queries.FindTagByNameParams{
+			CollectionID: args.CollectionID,
+			Name:         suggestion.Name,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			//nolint:exhaustruct
+			tag, err = queriesWithTx.CreateTag(context.TODO(), queries.CreateTagParams{
+				Name:         suggestion.Name,
+				CollectionID: args.CollectionID,
+				CreatedBy:    args.UserID,
+			})
+		}
+		if err != nil {
+			return 0, seer.Wrap("find_or_create_suggested_tag", err)
+		}
+
+		if err := queriesWithTx.AddEntryTag(context.TODO(), queries.AddEntryTagParams{
+			EntryID: suggestion.EntryID,
+			TagID:   tag.ID,
+			AddedBy: args.UserID,
+		}); err != nil {
+			return 0, seer.Wrap("add_suggested_entry_tag", err)
+		}
+
+		if err := queriesWithTx.AcceptTagSuggestion(
+			context.TODO(),
+			queries.AcceptTagSuggestionParams{
+				TagID:      lib.PgInt4(tag.ID),
+				ReviewedBy: reviewedBy,
+				ID:         suggestion.ID,
+			},
+		); err != nil {
+			return 0, seer.Wrap("accept_tag_suggestion", err)
+		}
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return 0, err
+	}
+
+	return int64(len(args.Suggestions)), nil
+}
+
+// Reject implements TagSuggestionRepository.
+func (t *tagSuggestionRepo) Reject(
+	collectionID int32,
+	userID int32,
+	suggestionIDs []pgtype.UUID,
+) (int64, error) {
+	count, err := t.queries.RejectTagSuggestions(
+		context.TODO(),
+		queries.RejectTagSuggestionsParams{
+			ReviewedBy:    lib.PgInt4(userID),
+			CollectionID:  collectionID,
+			SuggestionIds: suggestionIDs,
+		},
+	)
+	if err != nil {
+		return 0, seer.Wrap("reject_tag_suggestions", err)
+	}
+
+	return count, nil
+}
+
+// FindUnclassifiedIDs implements TagSuggestionRepository.
+func (t *tagSuggestionRepo) FindUnclassifiedIDs(workspaceID int32, afterID int32) ([]int32, error) {
+	ids, err := t.queries.FindUnclassifiedEntries(
+		context.TODO(),
+		queries.FindUnclassifiedEntriesParams{
+			WorkspaceID: lib.PgInt4(workspaceID),
+			AfterID:     afterID,
+			PageSize:    UnclassifiedEntriesPageSize,
+		},
+	)
+	if err != nil {
+		return nil, seer.Wrap("find_unclassified_entries", err)
+	}
+
+	return ids, nil
+}
+
+var _ TagSuggestionRepository = (*tagSuggestionRepo)(nil)
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	"go.trulyao.dev/hubble/web/internal/database/queries"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/document"
+	"go.trulyao.dev/hubble/web/pkg/lib"
+	"go.trulyao.dev/seer"
+)
+
+// CopyFilesTimeout is the maximum time spent removing the copied files of a failed copy
+const CopyFilesTimeout = time.Minute
+
+type (
+	// FileCopier duplicates the files of entries, it is implemented by the object store
+	FileCopier interface {
+		CopyFile(ctx context.Context, sourceId string, targetId string) error
+		DeleteFiles(ctx context.Context, fileIds ...string) error
+	}
+
+	MoveEntriesArgs struct {
+		WorkspaceSlug string
+		Entries       []pgtype.UUID
+		// CollectionID is the internal ID of the collection the entries are moved to
+		CollectionID int32
+	}
+
+	CopyEntriesArgs struct {
+		Context       context.Context
+		WorkspaceSlug string
+		Entries       []pgtype.UUID
+		UserID        int32
+		// CollectionID is the internal ID of the collection the entries are copied to
+		CollectionID int32
+		// CrossWorkspace removes the data plugins stored in the entries for the workspace they are copied from
+		CrossWorkspace bool
+		Files          FileCopier
+	}
+
+	CopiedEntries struct {
+		Entries []models.CreatedEntry
+		// QueuedIDs are the internal IDs of the copies that could not reuse the chunks of their entries, they have to be sent to the queue
+		QueuedIDs []int32
+	}
+)
+
+// Move implements EntryRepository.
+func (e *entryRepo) Move(args MoveEntriesArgs) ([]pgtype.UUID, error) {
+	tx, err := e.pool.BeginTx(context.TODO(), pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := e.queries.WithTx(tx)
+
+	rows, err := queriesWithTx.MoveEntries(context.TODO(), queries.MoveEntriesParams{
+		EntryPublicIds: args.Entries,
+		WorkspaceSlug:  lib.PgText(args.WorkspaceSlug),
+		CollectionID:   args.CollectionID,
+	})
+	if err != nil {
+		return nil, seer.Wrap("move_entries", err)
+	}
+
+	moved := make([]pgtype.UUID, 0, len(rows))
+	origins := make([]pgtype.UUID, 0, len(rows))
+	for _, row := range rows {
+		moved = append(moved, row.PublicID)
+		origins = append(origins, row.Origin)
+	}
+
+	// Entries moved to a workspace that uses another model have to be embedded again to be found by semantic search
+	if len(origins) > 0 {
+		if _, err := queriesWithTx.ResetForeignChunkEmbeddings(context.TODO(), origins); err != nil {
+			return nil, seer.Wrap("reset_foreign_chunk_embeddings", err)
+		}
+	}
+
+	// The links written in the entries are matched again against the entries of the workspace they were moved to
+	if err := syncMovedEntryLinks(queriesWithTx, origins); err != nil {
+		return nil, err
+	}
+
+	// The links written in the target workspace before the entries were moved to it can now be resolved
+	if err := resolveEntryLinks(queriesWithTx, moved); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(context.TODO()); err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// syncMovedEntryLinks replaces the links written in the moved entries, the links of an entry belong to the workspace it is in
+func syncMovedEntryLinks(q *queries.Queries, origins []pgtype.UUID) error {
+	if len(origins) == 0 {
+		return nil
+	}
+
+	sources, err := q.FindLinkSources(context.TODO(), origins)
+	if err != nil {
+		return seer.Wrap("find_link_sources", err)
+	}
+
+	for i := range sources {
+		if err := syncEntryLinks(q, sources[i].ID, sources[i].Content.String); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Copy implements EntryRepository.
+func (e *entryRepo) Copy(args *CopyEntriesArgs) (CopiedEntries, error) {
+	tx, err := e.pool.BeginTx(args.Context, pgx.TxOptions{}) //nolint:all
+	if err != nil {
+		return CopiedEntries{}, err
+	}
+	defer tx.Rollback(context.TODO()) //nolint:errcheck
+
+	queriesWithTx := e.queries.WithTx(tx)
+
+	sources, err := queriesWithTx.FindLatestEntryVersions(
+		args.Context,
+		queries.FindLatestEntryVersionsParams{
+			EntryPublicIds: args.Entries,
+			WorkspaceSlug:  lib.PgText(args.WorkspaceSlug),
+		},
+	)
+	if err != nil {
+		return CopiedEntries{}, seer.Wrap("find_latest_entry_versions", err)
+	}
+
+	// The files are not part of the transaction, the ones that were copied are removed if the copy fails
+	copiedFiles := make([]string, 0)
+	committed := false
+	defer func() {
+		if committed || len(copiedFiles) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), CopyFilesTimeout)
+		defer cancel()
+		if err := args.Files.DeleteFiles(ctx, copiedFiles...); err != nil {
+			log.Error().
+				Err(err).
+				Strs("file_ids", copiedFiles).
+				Msg("failed to remove the files of a failed copy")
+		}
+	}()
+
+	result := CopiedEntries{
+		Entries:   make([]models.CreatedEntry, 0, len(sources)),
+		QueuedIDs: make([]int32, 0),
+	}
+	origins := make([]pgtype.UUID, 0, len(sources))
+
+	for i := range sources {
+		source := &sources[i].Entry
+
+		meta := source.Meta
+		if args.CrossWorkspace {
+			if meta, err = stripPluginMetadata(meta); err != nil {
+				return CopiedEntries{}, seer.Wrap("strip_plugin_metadata", err)
+			}
+		}
+
+		fileID := pgtype.Text{} //nolint:exhaustruct
+		if source.FileID.Valid {
+			fileID = lib.PgText(uuid.NewString())
+			err := args.Files.CopyFile(args.Context, source.FileID.String, fileID.String)
+			if err != nil {
+				return CopiedEntries{}, seer.Wrap("copy_entry_file", err)
+			}
+			copiedFiles = append(copiedFiles, fileID.String)
+		}
+
+		created, err := queriesWithTx.CopyEntry(args.Context, queries.CopyEntryParams{
+			Meta:         meta,
+			FileID:       fileID,
+			CollectionID: args.CollectionID,
+			UserID:       args.UserID,
+			SourceID:     source.ID,
+		})
+		if err != nil {
+			return CopiedEntries{}, seer.Wrap("copy_entry", err)
+		}
+
+		// Only the chunks of processed entries are complete, the others are processed again instead
+		copied := int64(0)
+		if sources[i].Status == queries.EntryStatusCompleted {
+			copied, err = queriesWithTx.CopyEntryChunks(args.Context, queries.CopyEntryChunksParams{
+				TargetEntryID: lib.PgInt4(created.ID),
+				MinVersion:    created.Version,
+				SourceEntryID: lib.PgInt4(source.ID),
+			})
+			if err != nil {
+				return CopiedEntries{}, seer.Wrap("copy_entry_chunks", err)
+			}
+		}
+
+		payload := document.QueuePayload{Type: created.EntryType}
+		if copied == 0 {
+			_, err = queriesWithTx.EnqueueEntries(
+				args.Context,
+				[]queries.EnqueueEntriesParams{{EntryID: created.ID, Payload: payload}},
+			)
+			result.QueuedIDs = append(result.QueuedIDs, created.ID)
+		} else {
+			err = queriesWithTx.EnqueueProcessedEntry(
+				args.Context,
+				queries.EnqueueProcessedEntryParams{EntryID: created.ID, Payload: payload},
+			)
+		}
+		if err != nil {
+			return CopiedEntries{}, seer.Wrap("enqueue_copied_entry", err)
+		}
+
+		// Queued copies save their links once they are processed
+		if copied != 0 {
+			err := syncEntryLinks(queriesWithTx, created.ID, created.Content.String)
+			if err != nil {
+				return CopiedEntries{}, err
+			}
+		}
+
+		result.Entries = append(result.Entries, createdEntry(&created))
+		origins = append(origins, created.Origin)
+	}
+
+	// Copies made in a workspace that uses another model have to be embedded again to be found by semantic search
+	if len(origins) > 0 {
+		if _, err := queriesWithTx.ResetForeignChunkEmbeddings(args.Context, origins); err != nil {
+			return CopiedEntries{}, seer.Wrap("reset_foreign_chunk_embeddings", err)
+		}
+	}
+
+	copiedIDs := make([]pgtype.UUID, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		copiedIDs = append(copiedIDs, entry.ID)
+	}
+
+	if err := resolveEntryLinks(queriesWithTx, copiedIDs); err != nil {
+		return CopiedEntries{}, err
+	}
+
+	if err := tx.Commit(args.Context); err != nil {
+		return CopiedEntries{}, err
+	}
+	committed = true
+
+	return result, nil
+}
+
+// stripPluginMetadata removes the metadata plugins stored in an entry, it only makes sense in the workspace the plugins ran in
+func stripPluginMetadata(meta []byte) ([]byte, error) {
+	if len(meta) == 0 {
+		return meta, nil
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(meta, &fields); err != nil {
+		return nil, err
+	}
+
+	if _, ok := fields["extra_metadata"]; !ok {
+		return meta, nil
+	}
+
+	delete(fields, "extra_metadata")
+	return json.Marshal(fields)
+}
@@ -0,0 +1,199 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/repository"
+)
+
+var errCopyFailed = errors.New("copy failed")
+
+// fakeFiles records the files that are copied and removed, the copy fails once `failAfter` files were copied
+type fakeFiles struct {
+	copied    map[string]string
+	deleted   []string
+	failAfter int
+}
+
+func (f *fakeFiles) CopyFile(_ context.Context, sourceID string, targetID string) error {
+	if f.failAfter > 0 && len(f.copied) == f.failAfter {
+		return errCopyFailed
+	}
+
+	f.copied[sourceID] = targetID
+	return nil
+}
+
+func (f *fakeFiles) DeleteFiles(_ context.Context, fileIDs ...string) error {
+	f.deleted = append(f.deleted, fileIDs...)
+	return nil
+}
+
+// copiedEntry is the row of a copy
+type copiedEntry struct {
+	name         string
+	version      int32
+	origin       pgtype.UUID
+	fileID       pgtype.Text
+	meta         []byte
+	collectionID int32
+}
+
+func findCopiedEntry(t *testing.T, f *relatedFixture, id int32) copiedEntry {
+	t.Helper()
+
+	var entry copiedEntry
+	err := f.pool.QueryRow(context.Background(), `
+		select name, version, origin, file_id, meta, collection_id
+		from entries where id = $1`, id).Scan(
+		&entry.name,
+		&entry.version,
+		&entry.origin,
+		&entry.fileID,
+		&entry.meta,
+		&entry.collectionID,
+	)
+	if err != nil {
+		t.Fatalf("failed to find copy %d: %v", id, err)
+	}
+
+	return entry
+}
+
+func Test_Copy_LatestVersionAcrossWorkspaces(t *testing.T) {
+	pool := testPool(t)
+	source := newRelatedFixture(t, pool)
+	target := newRelatedFixture(t, pool)
+	ctx := context.Background()
+
+	reportID, report := source.addEntry(t, "report", "[1,0,0]", 1)
+	source.setFile(t, reportID, "report-v1")
+	latestID, _ := source.addVersion(t, reportID, "report-v2")
+
+	meta := `{"original_filename": "report.pdf", "extra_metadata": {"plugin": "data"}}`
+	if _, err := pool.Exec(ctx, `
+		update entries set name = 'report v2', meta = $2 where id = $1`, latestID, meta); err != nil {
+		t.Fatalf("failed to update the latest version: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, `
+		insert into entry_chunks (
+			entry_id, chunk_index, min_version, content,
+			semantic_vector, embedding_model, embedding_dimensions, embedding_status
+		)
+		select $1, i, 2, 'chunk ' || i, '[1,0,0]', 'test-model', 3, 'done'
+		from generate_series(0, 2) as i`, latestID); err != nil {
+		t.Fatalf("failed to chunk the latest version: %v", err)
+	}
+
+	// The target workspace embeds its entries with another model
+	if _, err := pool.Exec(ctx, `
+		insert into workspace_settings (workspace_id, embedding_model, embedding_dimensions)
+		values ($1, 'other-model', 3)`, target.workspaceID); err != nil {
+		t.Fatalf("failed to set the embedding model of the target workspace: %v", err)
+	}
+
+	files := &fakeFiles{copied: make(map[string]string)} //nolint:exhaustruct
+	copied, err := repository.New(pool, nil, nil).EntryRepository().Copy(
+		&repository.CopyEntriesArgs{
+			Context:        ctx,
+			WorkspaceSlug:  source.workspaceSlug,
+			Entries:        []pgtype.UUID{report},
+			UserID:         target.userID,
+			CollectionID:   target.collectionID,
+			CrossWorkspace: true,
+			Files:          files,
+		},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(copied.Entries) != 1 || len(copied.QueuedIDs) != 0 {
+		t.Fatalf("expected a single processed copy, got %+v", copied)
+	}
+
+	entry := findCopiedEntry(t, source, copied.Entries[0].InternalID)
+	if entry.name != "report v2" || entry.version != 1 || entry.collectionID != target.collectionID {
+		t.Errorf("expected the latest version to be copied as a new entry, got %+v", entry)
+	}
+	if entry.origin == report {
+		t.Error("expected the copy to be a separate entry")
+	}
+
+	if fileID, ok := files.copied["report-v2"]; !ok || entry.fileID.String != fileID {
+		t.Errorf("expected the file of the latest version to be copied, got %v", files.copied)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(entry.meta, &fields); err != nil {
+		t.Fatalf("failed to decode the metadata: %v", err)
+	}
+	if _, ok := fields["extra_metadata"]; ok || fields["original_filename"] == nil {
+		t.Errorf("expected only the plugin metadata to be removed, got %s", entry.meta)
+	}
+
+	var chunks, pending int
+	if err := pool.QueryRow(ctx, `
+		select count(*), count(*) filter (where embedding_status = 'pending' and semantic_vector is null)
+		from entry_chunks where entry_id = $1`, copied.Entries[0].InternalID,
+	).Scan(&chunks, &pending); err != nil {
+		t.Fatalf("failed to count the chunks of the copy: %v", err)
+	}
+	if chunks != 3 || pending != 3 {
+		t.Errorf("expected 3 chunks to be embedded again, got %d of %d", pending, chunks)
+	}
+}
+
+func Test_Copy_RemovesFilesOfFailedCopy(t *testing.T) {
+	pool := testPool(t)
+	source := newRelatedFixture(t, pool)
+	target := newRelatedFixture(t, pool)
+
+	entries := make([]pgtype.UUID, 0, 2)
+	for i := range 2 {
+		id, publicID := source.addEntry(t, fmt.Sprintf("file %d", i), "[1,0,0]", 1)
+		source.setFile(t, id, fmt.Sprintf("file-%d", i))
+		entries = append(entries, publicID)
+	}
+
+	files := &fakeFiles{copied: make(map[string]string), failAfter: 1} //nolint:exhaustruct
+	_, err := repository.New(pool, nil, nil).EntryRepository().Copy(&repository.CopyEntriesArgs{
+		Context:        context.Background(),
+		WorkspaceSlug:  source.workspaceSlug,
+		Entries:        entries,
+		UserID:         target.userID,
+		CollectionID:   target.collectionID,
+		CrossWorkspace: true,
+		Files:          files,
+	})
+	if !errors.Is(err, errCopyFailed) {
+		t.Fatalf("expected the copy to fail, got %v", err)
+	}
+
+	if len(files.copied) != 1 || len(files.deleted) != 1 {
+		t.Fatalf("expected the copied file to be removed, got %v and %v", files.copied, files.deleted)
+	}
+	for _, copiedID := range files.copied {
+		if files.deleted[0] != copiedID {
+			t.Errorf("expected %q to be removed, got %q", copiedID, files.deleted[0])
+		}
+	}
+
+	var count int
+	if err := pool.QueryRow(
+		context.Background(),
+		"select count(*) from entries where collection_id = $1",
+		target.collectionID,
+	).Scan(&count); err != nil {
+		t.Fatalf("failed to count the copies: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no copy to be saved, got %d", count)
+	}
+}
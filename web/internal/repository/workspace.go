@@ -172,9 +172,57 @@ type (
 
 		// RemoveMember removes a member from a workspace and all its collections
 		RemoveMember(args RemoveWorkspaceMemberArgs) error
+
+		// FindSettings finds the settings of a workspace (or the defaults if they have never been changed)
+		FindSettings(workspaceID int32) (models.WorkspaceSettings, error)
+
+		// UpdateSettings creates or replaces the settings of a workspace
+		UpdateSettings(settings *models.WorkspaceSettings) (models.WorkspaceSettings, error)
 	}
 )
 
+// FindSettings implements WorkspaceRepository.
+func (w *workspaceRepo) FindSettings(workspaceID int32) (models.WorkspaceSettings, error) {
+	row, err := w.queries.FindWorkspaceSettings(context.TODO(), workspaceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.DefaultWorkspaceSettings(workspaceID), nil
+		}
+
+		return models.WorkspaceSettings{}, seer.Wrap("find_workspace_settings", err)
+	}
+
+	settings := models.WorkspaceSettings{} //nolint:exhaustruct
+	settings.From(&row)
+	return settings, nil
+}
+
+// UpdateSettings implements WorkspaceRepository.
+func (w *workspaceRepo) UpdateSettings(
+	settings *models.WorkspaceSettings,
+) (models.WorkspaceSettings, error) {
+	row, err := w.queries.UpsertWorkspaceSettings(
+		context.TODO(),
+		queries.UpsertWorkspaceSettingsParams{
+			WorkspaceID:           settings.WorkspaceID,
+			RrfK:                  settings.RRFK,
+			RrfSemanticWeight:     settings.RRFSemanticWeight,
+			RrfFullTextWeight:     settings.RRFFullTextWeight,
+			SummariesEnabled:      settings.SummariesEnabled,
+			TagSuggestionMode:     settings.TagSuggestionMode,
+			TagAutoApplyThreshold: settings.TagAutoApplyThreshold,
+			TrashRetentionDays:    settings.TrashRetentionDays,
+		},
+	)
+	if err != nil {
+		return models.WorkspaceSettings{}, seer.Wrap("upsert_workspace_settings", err)
+	}
+
+	updated := models.WorkspaceSettings{} //nolint:exhaustruct
+	updated.From(&row)
+	return updated, nil
+}
+
 // FindByInternalID implements WorkspaceRepository.
 func (w *workspaceRepo) FindByInternalID(workspaceID int32) (*models.Workspace, error) {
 	row, err := w.queries.FindWorkspaceByID(context.TODO(), workspaceID)
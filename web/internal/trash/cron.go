@@ -0,0 +1,145 @@
+// This cron is used to purge the entries that have been in the trash for longer than the retention period of their workspace
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/rs/zerolog/log"
+	"go.trulyao.dev/hubble/web/internal/objectstore"
+	"go.trulyao.dev/hubble/web/internal/repository"
+)
+
+const (
+	DefaultPurgeInterval  = time.Hour       // Interval between two purges of the expired entries
+	DefaultPurgeBatchSize = 100             // Maximum number of entries purged in a single batch
+	MaxPurgeBatches       = 50              // Maximum number of batches purged per run, the remaining entries are purged in the next run
+	PurgeFilesTimeout     = time.Minute * 5 // Maximum time spent removing the files of a single batch
+)
+
+type Cron struct {
+	repository  repository.Repository
+	objectStore *objectstore.Store
+
+	scheduler gocron.Scheduler
+}
+
+func NewCron(repo repository.Repository, objectStore *objectstore.Store) (*Cron, error) {
+	if repo == nil {
+		return nil, errors.New("repository is nil")
+	}
+
+	if objectStore == nil {
+		return nil, errors.New("object store is nil")
+	}
+
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	c := Cron{
+		repository:  repo,
+		objectStore: objectStore,
+		scheduler:   scheduler,
+	}
+
+	return &c, nil
+}
+
+func (c *Cron) Start() error {
+	_, err := c.scheduler.NewJob(
+		gocron.DurationJob(DefaultPurgeInterval),
+		gocron.NewTask(c.purgeExpiredEntries),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	c.scheduler.Start()
+	return nil
+}
+
+func (c *Cron) Stop() error {
+	return c.scheduler.Shutdown()
+}
+
+// purgeExpiredEntries deletes the expired entries in batches, along with the files that are not referenced by any entry anymore
+func (c *Cron) purgeExpiredEntries() {
+	c.retryFailedFileDeletions()
+
+	total := 0
+
+	for range MaxPurgeBatches {
+		purged, err := c.repository.EntryRepository().PurgeExpired(DefaultPurgeBatchSize)
+		if err != nil {
+			log.Error().Err(err).Str("source", "trash_cron").Msg("failed to purge expired entries")
+			return
+		}
+
+		c.deleteFiles(purged.FileIDs)
+
+		total += len(purged.Entries)
+		if len(purged.Entries) == 0 {
+			break
+		}
+	}
+
+	log.Info().Str("source", "trash_cron").Int("count", total).Msg("purged expired entries")
+}
+
+// retryFailedFileDeletions removes the files that could not be removed in a previous run
+func (c *Cron) retryFailedFileDeletions() {
+	fileIDs, err := c.repository.EntryRepository().FindFailedFileDeletions(DefaultPurgeBatchSize)
+	if err != nil {
+		log.Error().Err(err).Str("source", "trash_cron").Msg("failed to find failed file deletions")
+		return
+	}
+
+	if !c.deleteFiles(fileIDs) {
+		return
+	}
+
+	if err := c.repository.EntryRepository().ClearFailedFileDeletions(fileIDs); err != nil {
+		log.Error().
+			Err(err).
+			Str("source", "trash_cron").
+			Strs("file_ids", fileIDs).
+			Msg("failed to clear failed file deletions")
+	}
+}
+
+// deleteFiles removes the files from the object store and records them to be retried in the next run if that fails, removing a file that is already gone is a no-op so the whole batch is recorded
+func (c *Cron) deleteFiles(fileIDs []string) bool {
+	if len(fileIDs) == 0 {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), PurgeFilesTimeout)
+	defer cancel()
+
+	err := c.objectStore.DeleteFiles(ctx, fileIDs...)
+	if err == nil {
+		return true
+	}
+
+	log.Error().
+		Err(err).
+		Str("source", "trash_cron").
+		Strs("file_ids", fileIDs).
+		Msg("failed to delete purged files")
+
+	if err := c.repository.EntryRepository().RecordFailedFileDeletions(fileIDs, err); err != nil {
+		log.Error().
+			Err(err).
+			Str("source", "trash_cron").
+			Strs("file_ids", fileIDs).
+			Msg("failed to record failed file deletions")
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+package diff
+
+import "slices"
+
+type Kind string
+
+const (
+	KindEqual  Kind = "equal"
+	KindInsert Kind = "insert"
+	KindDelete Kind = "delete"
+)
+
+// MaxEditDistance is the number of differences after which the search for the shortest edit script is abandoned and the remaining elements are replaced as a whole, it keeps the memory used by very different documents bounded
+const MaxEditDistance = 1000
+
+// Edit is a single step of the script that turns the old sequence into the new one
+type Edit struct {
+	Kind Kind
+	// OldIndex is the index of the element in the old sequence, -1 for insertions
+	OldIndex int
+	// NewIndex is the index of the element in the new sequence, -1 for deletions
+	NewIndex int
+}
+
+/*
+Compare returns the shortest edit script between two sequences using Myers' algorithm.
+
+The common prefix and suffix are matched before running the algorithm, and if the sequences differ by more than MaxEditDistance elements, the remaining elements are reported as deleted and inserted as a whole (the second return value is false in that case).
+*/
+func Compare[T comparable](old, new []T) ([]Edit, bool) {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix &&
+		old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, max(len(old), len(new)))
+	for i := range prefix {
+		edits = append(edits, Edit{Kind: KindEqual, OldIndex: i, NewIndex: i})
+	}
+
+	middle, exact := myers(old[prefix:len(old)-suffix], new[prefix:len(new)-suffix])
+	for _, edit := range middle {
+		if edit.OldIndex >= 0 {
+			edit.OldIndex += prefix
+		}
+		if edit.NewIndex >= 0 {
+			edit.NewIndex += prefix
+		}
+		edits = append(edits, edit)
+	}
+
+	for i := range suffix {
+		edits = append(edits, Edit{
+			Kind:     KindEqual,
+			OldIndex: len(old) - suffix + i,
+			NewIndex: len(new) - suffix + i,
+		})
+	}
+
+	return edits, exact
+}
+
+func myers[T comparable](old, new []T) ([]Edit, bool) {
+	n, m := len(old), len(new)
+	if n == 0 && m == 0 {
+		return nil, true
+	}
+
+	limit := min(n+m, MaxEditDistance)
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+
+	// trace[d] holds the furthest x of the diagonals [-d-1, d+1] before the d-th step
+	trace := make([][]int, 0, limit+1)
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && old[x] == new[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, n, m), true
+			}
+		}
+	}
+
+	return replace(n, m), false
+}
+
+func backtrack(trace [][]int, n, m int) []Edit {
+	edits := make([]Edit, 0, n+m)
+
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, Edit{Kind: KindEqual, OldIndex: x - 1, NewIndex: y - 1})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, Edit{Kind: KindInsert, OldIndex: -1, NewIndex: y - 1})
+			} else {
+				edits = append(edits, Edit{Kind: KindDelete, OldIndex: x - 1, NewIndex: -1})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	slices.Reverse(edits)
+	return edits
+}
+
+func replace(n, m int) []Edit {
+	edits := make([]Edit, 0, n+m)
+	for i := range n {
+		edits = append(edits, Edit{Kind: KindDelete, OldIndex: i, NewIndex: -1})
+	}
+	for i := range m {
+		edits = append(edits, Edit{Kind: KindInsert, OldIndex: -1, NewIndex: i})
+	}
+
+	return edits
+}
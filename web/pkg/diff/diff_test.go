@@ -0,0 +1,176 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.trulyao.dev/hubble/web/pkg/diff"
+)
+
+func Test_Compare(t *testing.T) {
+	type test struct {
+		name string
+		old  string
+		new  string
+		want string
+	}
+
+	// Each edit is rendered as a single character, `=` for equal, `+` for insert and `-` for delete
+	tests := []test{
+		{name: "both empty", old: "", new: "", want: ""},
+		{name: "identical", old: "abc", new: "abc", want: "==="},
+		{name: "insert into empty", old: "", new: "ab", want: "++"},
+		{name: "delete everything", old: "ab", new: "", want: "--"},
+		{name: "insert in the middle", old: "ac", new: "abc", want: "=+="},
+		{name: "delete in the middle", old: "abc", new: "ac", want: "=-="},
+		{name: "replace in the middle", old: "abc", new: "axc", want: "=-+="},
+		{name: "replace everything", old: "ab", new: "cd", want: "--++"},
+		{name: "myers example", old: "abcabba", new: "cbabac", want: "--=+==-=+"},
+	}
+
+	for _, tt := range tests {
+		edits, exact := diff.Compare([]byte(tt.old), []byte(tt.new))
+		if !exact {
+			t.Errorf("%s: expected an exact diff", tt.name)
+		}
+
+		var (
+			sb      strings.Builder
+			rebuilt []byte
+		)
+		for _, edit := range edits {
+			switch edit.Kind {
+			case diff.KindEqual:
+				sb.WriteString("=")
+				if tt.old[edit.OldIndex] != tt.new[edit.NewIndex] {
+					t.Errorf("%s: %+v matches different elements", tt.name, edit)
+				}
+				rebuilt = append(rebuilt, tt.new[edit.NewIndex])
+			case diff.KindInsert:
+				sb.WriteString("+")
+				rebuilt = append(rebuilt, tt.new[edit.NewIndex])
+			case diff.KindDelete:
+				sb.WriteString("-")
+			}
+		}
+
+		if got := sb.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+
+		// Applying the edits to the old sequence must give the new one
+		if string(rebuilt) != tt.new {
+			t.Errorf("%s: the edits rebuild %q, want %q", tt.name, rebuilt, tt.new)
+		}
+	}
+}
+
+func Test_Text(t *testing.T) {
+	type test struct {
+		name        string
+		old         string
+		new         string
+		opts        diff.Options
+		wantAdded   int
+		wantRemoved int
+		wantHunks   int
+		wantUnified string
+	}
+
+	tests := []test{
+		{
+			name:        "no changes",
+			old:         "one\ntwo\n",
+			new:         "one\ntwo\n",
+			opts:        diff.Options{Context: 3},
+			wantAdded:   0,
+			wantRemoved: 0,
+			wantHunks:   0,
+			wantUnified: "",
+		},
+		{
+			name:        "modified line",
+			old:         "one\ntwo\nthree\n",
+			new:         "one\n2\nthree\n",
+			opts:        diff.Options{Context: 1},
+			wantAdded:   1,
+			wantRemoved: 1,
+			wantHunks:   1,
+			wantUnified: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		},
+		{
+			name:        "no context",
+			old:         "one\ntwo\nthree\n",
+			new:         "one\n2\nthree\n",
+			opts:        diff.Options{Context: 0},
+			wantAdded:   1,
+			wantRemoved: 1,
+			wantHunks:   1,
+			wantUnified: "--- a\n+++ b\n@@ -2 +2 @@\n-two\n+2\n",
+		},
+		{
+			name:        "insert into empty text",
+			old:         "",
+			new:         "hello\n",
+			opts:        diff.Options{Context: 3, OldLabel: "v1", NewLabel: "v2"},
+			wantAdded:   1,
+			wantRemoved: 0,
+			wantHunks:   1,
+			wantUnified: "--- v1\n+++ v2\n@@ -0,0 +1 @@\n+hello\n",
+		},
+		{
+			name:        "distant changes are split into hunks",
+			old:         "a\nb\nc\nd\ne\nf\ng\nh\n",
+			new:         "A\nb\nc\nd\ne\nf\ng\nH\n",
+			opts:        diff.Options{Context: 1},
+			wantAdded:   2,
+			wantRemoved: 2,
+			wantHunks:   2,
+			wantUnified: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -7,2 +7,2 @@\n g\n-h\n+H\n",
+		},
+		{
+			name:        "word diff",
+			old:         "the quick fox\n",
+			new:         "the slow fox\n",
+			opts:        diff.Options{Context: 3, Words: true},
+			wantAdded:   1,
+			wantRemoved: 1,
+			wantHunks:   1,
+			wantUnified: "--- a\n+++ b\n@@ -1 +1 @@\nthe [-quick-]{+slow+} fox\n",
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := diff.Text(tt.old, tt.new, tt.opts)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if result.Added != tt.wantAdded || result.Removed != tt.wantRemoved {
+			t.Errorf(
+				"%s: got +%d -%d, want +%d -%d",
+				tt.name,
+				result.Added,
+				result.Removed,
+				tt.wantAdded,
+				tt.wantRemoved,
+			)
+		}
+
+		if len(result.Hunks) != tt.wantHunks {
+			t.Errorf("%s: got %d hunks, want %d", tt.name, len(result.Hunks), tt.wantHunks)
+		}
+
+		if result.Unified != tt.wantUnified {
+			t.Errorf("%s: got unified diff\n%s\nwant\n%s", tt.name, result.Unified, tt.wantUnified)
+		}
+	}
+}
+
+func Test_Text_MaxBytes(t *testing.T) {
+	_, err := diff.Text("short", strings.Repeat("long", 10), diff.Options{MaxBytes: 10})
+	if err != diff.ErrInputTooLarge {
+		t.Errorf("got %v, want %v", err, diff.ErrInputTooLarge)
+	}
+}
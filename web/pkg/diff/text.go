@@ -0,0 +1,414 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultContext is the number of unchanged lines shown around the changes of a hunk
+	DefaultContext = 3
+	// DefaultMaxBytes is the maximum size of each side of a diff if no limit was given
+	DefaultMaxBytes = 2 << 20
+)
+
+var ErrInputTooLarge = errors.New("the documents are too large to be compared")
+
+type (
+	Options struct {
+		// Context is the number of unchanged lines around the changes of a hunk
+		Context int
+		// MaxBytes is the maximum size of each side, DefaultMaxBytes is used if it is 0
+		MaxBytes int
+		// Words computes the word-level changes of modified lines and renders the unified diff with inline markers
+		Words bool
+		// OldLabel and NewLabel are used in the header of the unified diff
+		OldLabel string
+		NewLabel string
+	}
+
+	// Word is a run of words and spaces of a modified line
+	Word struct {
+		Kind Kind   `json:"kind" mirror:"type:'equal' | 'insert' | 'delete'"`
+		Text string `json:"text"`
+	}
+
+	Line struct {
+		Kind    Kind   `json:"kind" mirror:"type:'equal' | 'insert' | 'delete'"`
+		Content string `json:"content"`
+		// OldNumber is the 1-based line number in the old text, 0 for inserted lines
+		OldNumber int `json:"old_number"`
+		// NewNumber is the 1-based line number in the new text, 0 for deleted lines
+		NewNumber int `json:"new_number"`
+		// Words is only set for modified lines in word mode, deleted lines only have equal and deleted words and inserted lines only have equal and inserted words
+		Words []Word `json:"words,omitempty" mirror:"optional:true"`
+	}
+
+	Hunk struct {
+		OldStart int    `json:"old_start"`
+		OldLines int    `json:"old_lines"`
+		NewStart int    `json:"new_start"`
+		NewLines int    `json:"new_lines"`
+		Added    int    `json:"added"`
+		Removed  int    `json:"removed"`
+		Lines    []Line `json:"lines"`
+	}
+
+	Result struct {
+		Hunks   []Hunk `json:"hunks"`
+		Added   int    `json:"added"`
+		Removed int    `json:"removed"`
+		// Unified is the diff in the unified format, or in the `[-removed-]{+added+}` format in word mode
+		Unified string `json:"unified"`
+		// Approximate is true if the texts had too many differences to find the shortest diff
+		Approximate bool `json:"approximate"`
+	}
+)
+
+// Text compares two texts line by line
+func Text(old, new string, opts Options) (Result, error) {
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	if len(old) > maxBytes || len(new) > maxBytes {
+		return Result{}, ErrInputTooLarge
+	}
+
+	oldLines, newLines := SplitLines(old), SplitLines(new)
+
+	// Lines are compared by their interned IDs rather than by their content
+	ids := make(map[string]int, len(oldLines)+len(newLines))
+	intern := func(lines []string) []int {
+		interned := make([]int, 0, len(lines))
+		for _, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			interned = append(interned, id)
+		}
+		return interned
+	}
+
+	edits, exact := Compare(intern(oldLines), intern(newLines))
+
+	result := Result{
+		Hunks:       makeHunks(edits, oldLines, newLines, max(opts.Context, 0)),
+		Added:       0,
+		Removed:     0,
+		Unified:     "",
+		Approximate: !exact,
+	}
+
+	for i := range result.Hunks {
+		if opts.Words {
+			addWords(&result.Hunks[i])
+		}
+
+		result.Added += result.Hunks[i].Added
+		result.Removed += result.Hunks[i].Removed
+	}
+
+	result.Unified = unified(result.Hunks, opts)
+	return result, nil
+}
+
+// SplitLines splits a text into lines without their line endings, a trailing line ending does not start a new line
+func SplitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func makeHunks(edits []Edit, oldLines, newLines []string, context int) []Hunk {
+	hunks := make([]Hunk, 0)
+
+	// Changes that are less than two contexts apart share the same hunk
+	start, end := -1, 0
+	for i, edit := range edits {
+		if edit.Kind == KindEqual {
+			continue
+		}
+
+		lo, hi := max(i-context, 0), min(i+context+1, len(edits))
+		if start >= 0 && lo <= end {
+			end = hi
+			continue
+		}
+
+		if start >= 0 {
+			hunks = append(hunks, makeHunk(edits, start, end, oldLines, newLines))
+		}
+		start, end = lo, hi
+	}
+
+	if start >= 0 {
+		hunks = append(hunks, makeHunk(edits, start, end, oldLines, newLines))
+	}
+
+	return hunks
+}
+
+func makeHunk(edits []Edit, start, end int, oldLines, newLines []string) Hunk {
+	// The lines before the hunk are needed for the start of sides without any line in the hunk
+	oldBefore, newBefore := 0, 0
+	for _, edit := range edits[:start] {
+		if edit.OldIndex >= 0 {
+			oldBefore = edit.OldIndex + 1
+		}
+		if edit.NewIndex >= 0 {
+			newBefore = edit.NewIndex + 1
+		}
+	}
+
+	hunk := Hunk{
+		OldStart: oldBefore,
+		OldLines: 0,
+		NewStart: newBefore,
+		NewLines: 0,
+		Added:    0,
+		Removed:  0,
+		Lines:    make([]Line, 0, end-start),
+	}
+
+	for _, edit := range edits[start:end] {
+		line := Line{Kind: edit.Kind, Content: "", OldNumber: 0, NewNumber: 0, Words: nil}
+
+		if edit.OldIndex >= 0 {
+			line.Content = oldLines[edit.OldIndex]
+			line.OldNumber = edit.OldIndex + 1
+			hunk.OldLines++
+		}
+
+		if edit.NewIndex >= 0 {
+			line.Content = newLines[edit.NewIndex]
+			line.NewNumber = edit.NewIndex + 1
+			hunk.NewLines++
+		}
+
+		switch edit.Kind {
+		case KindInsert:
+			hunk.Added++
+		case KindDelete:
+			hunk.Removed++
+		}
+
+		hunk.Lines = append(hunk.Lines, line)
+	}
+
+	if hunk.OldLines > 0 {
+		hunk.OldStart++
+	}
+	if hunk.NewLines > 0 {
+		hunk.NewStart++
+	}
+
+	return hunk
+}
+
+// addWords pairs the deleted lines of a hunk with the lines inserted right after them and computes their word-level changes
+func addWords(hunk *Hunk) {
+	for i := 0; i < len(hunk.Lines); {
+		if hunk.Lines[i].Kind != KindDelete {
+			i++
+			continue
+		}
+
+		deleted := i
+		for i < len(hunk.Lines) && hunk.Lines[i].Kind == KindDelete {
+			i++
+		}
+
+		inserted := i
+		for i < len(hunk.Lines) && hunk.Lines[i].Kind == KindInsert {
+			i++
+		}
+
+		pairs := min(inserted-deleted, i-inserted)
+		for p := range pairs {
+			oldLine, newLine := &hunk.Lines[deleted+p], &hunk.Lines[inserted+p]
+			oldLine.Words, newLine.Words = Words(oldLine.Content, newLine.Content)
+		}
+	}
+}
+
+// Words compares two lines word by word, the changes are returned for both sides
+func Words(old, new string) ([]Word, []Word) {
+	oldWords, newWords := make([]Word, 0), make([]Word, 0)
+	for _, word := range compareWords(old, new) {
+		if word.Kind != KindInsert {
+			oldWords = appendWord(oldWords, word.Kind, word.Text)
+		}
+		if word.Kind != KindDelete {
+			newWords = appendWord(newWords, word.Kind, word.Text)
+		}
+	}
+
+	return oldWords, newWords
+}
+
+// compareWords returns the words of both lines in order, deleted words come before the words inserted in their place
+func compareWords(old, new string) []Word {
+	oldTokens, newTokens := tokenize(old), tokenize(new)
+	edits, _ := Compare(oldTokens, newTokens)
+
+	words := make([]Word, 0)
+	for _, edit := range edits {
+		switch edit.Kind {
+		case KindEqual, KindDelete:
+			words = appendWord(words, edit.Kind, oldTokens[edit.OldIndex])
+		case KindInsert:
+			words = appendWord(words, edit.Kind, newTokens[edit.NewIndex])
+		}
+	}
+
+	return words
+}
+
+func appendWord(words []Word, kind Kind, text string) []Word {
+	if len(words) > 0 && words[len(words)-1].Kind == kind {
+		words[len(words)-1].Text += text
+		return words
+	}
+
+	return append(words, Word{Kind: kind, Text: text})
+}
+
+// tokenize splits a line into runs of letters and digits, runs of spaces and single punctuation characters
+func tokenize(line string) []string {
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		default:
+			return 0
+		}
+	}
+
+	tokens := make([]string, 0)
+	start, previous := 0, -1
+	for i, r := range line {
+		current := class(r)
+		if i > start && (current != previous || current == 0) {
+			tokens = append(tokens, line[start:i])
+			start = i
+		}
+		previous = current
+	}
+
+	if start < len(line) {
+		tokens = append(tokens, line[start:])
+	}
+
+	return tokens
+}
+
+func unified(hunks []Hunk, opts Options) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", label(opts.OldLabel, "a"), label(opts.NewLabel, "b"))
+
+	for i := range hunks {
+		hunk := &hunks[i]
+		fmt.Fprintf(
+			&sb,
+			"@@ -%s +%s @@\n",
+			hunkRange(hunk.OldStart, hunk.OldLines),
+			hunkRange(hunk.NewStart, hunk.NewLines),
+		)
+
+		if opts.Words {
+			writeWordLines(&sb, hunk.Lines)
+			continue
+		}
+
+		for j := range hunk.Lines {
+			line := &hunk.Lines[j]
+			switch line.Kind {
+			case KindEqual:
+				sb.WriteString(" ")
+			case KindInsert:
+				sb.WriteString("+")
+			case KindDelete:
+				sb.WriteString("-")
+			}
+			sb.WriteString(line.Content)
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// writeWordLines renders the lines of a hunk like `git diff --word-diff=plain`, a modified line is rendered once with both its deleted and inserted words
+func writeWordLines(sb *strings.Builder, lines []Line) {
+	// Deleted lines with word-level changes are rendered along with the inserted line they were paired with
+	pending := make([]string, 0)
+
+	for i := range lines {
+		line := &lines[i]
+		switch {
+		case line.Kind == KindDelete && len(line.Words) > 0:
+			pending = append(pending, line.Content)
+			continue
+
+		case line.Kind == KindInsert && len(line.Words) > 0 && len(pending) > 0:
+			for _, word := range compareWords(pending[0], line.Content) {
+				switch word.Kind {
+				case KindEqual:
+					sb.WriteString(word.Text)
+				case KindInsert:
+					sb.WriteString("{+" + word.Text + "+}")
+				case KindDelete:
+					sb.WriteString("[-" + word.Text + "-]")
+				}
+			}
+			pending = pending[1:]
+
+		case line.Kind == KindInsert:
+			sb.WriteString("{+" + line.Content + "+}")
+
+		case line.Kind == KindDelete:
+			sb.WriteString("[-" + line.Content + "-]")
+
+		default:
+			sb.WriteString(line.Content)
+		}
+
+		sb.WriteString("\n")
+	}
+}
+
+func label(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+// hunkRange formats a side of a hunk header, the length is omitted for single lines like GNU diff does
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, lines)
+}
@@ -0,0 +1,21 @@
+package document
+
+import "github.com/jonathanhecl/chunker"
+
+const (
+	DefaultChunkSize    = 1_000
+	DefaultChunkOverlap = 0.075 * DefaultChunkSize // 5% overlap
+)
+
+// ChunkWithOverlap splits text into chunks of DefaultChunkSize that carry over DefaultChunkOverlap characters of the previous chunk
+func ChunkWithOverlap(text string) []string {
+	c := chunker.NewChunker(
+		DefaultChunkSize,
+		DefaultChunkOverlap,
+		chunker.DefaultSeparators,
+		false,
+		false,
+	)
+
+	return c.Chunk(text)
+}
@@ -0,0 +1,70 @@
+package document
+
+import (
+	"regexp"
+	"strings"
+)
+
+// EntryURIPrefix is the prefix of the URIs that reference an entry by the public ID of any of its versions
+const EntryURIPrefix = "hubble://entry/"
+
+var (
+	wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+	entryURIPattern = regexp.MustCompile(
+		`hubble://entry/([0-9a-fA-F]{8}(?:-[0-9a-fA-F]{4}){3}-[0-9a-fA-F]{12})`,
+	)
+	// Links in code blocks and spans are examples rather than references
+	codePattern = regexp.MustCompile("(?s)```.*?```|~~~.*?~~~|`[^`\n]+`")
+)
+
+// Links are the references to other entries found in markdown content
+type Links struct {
+	// Names are the names written as [[Entry Name]], an alias can follow the name as [[Entry Name|alias]]
+	Names []string
+	// IDs are the lowercase public IDs written as hubble://entry/<uuid>
+	IDs []string
+}
+
+/*
+ParseLinks finds the wiki links and entry URIs in markdown content, every link is only returned once and the ones in code are ignored.
+
+Example:
+
+ParseLinks("See [[Release notes]]")  Links{Names: ["Release notes"], IDs: []}
+*/
+func ParseLinks(markdown string) Links {
+	markdown = codePattern.ReplaceAllString(markdown, "")
+
+	links := Links{Names: []string{}, IDs: []string{}}
+	seen := make(map[string]bool)
+
+	for _, match := range wikiLinkPattern.FindAllStringSubmatch(markdown, -1) {
+		name, _, _ := strings.Cut(match[1], "|")
+		name = strings.Join(strings.Fields(name), " ")
+
+		// [[hubble://entry/<uuid>]] is picked up as an entry URI below
+		if name == "" || strings.HasPrefix(name, EntryURIPrefix) {
+			continue
+		}
+
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		links.Names = append(links.Names, name)
+	}
+
+	for _, match := range entryURIPattern.FindAllStringSubmatch(markdown, -1) {
+		id := strings.ToLower(match[1])
+		if seen[EntryURIPrefix+id] {
+			continue
+		}
+
+		seen[EntryURIPrefix+id] = true
+		links.IDs = append(links.IDs, id)
+	}
+
+	return links
+}
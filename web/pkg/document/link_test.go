@@ -0,0 +1,89 @@
+package document_test
+
+import (
+	"slices"
+	"testing"
+
+	"go.trulyao.dev/hubble/web/pkg/document"
+)
+
+func Test_ParseLinks(t *testing.T) {
+	const id = "0b6f7c7e-4f3a-4d0e-9c55-2f8f3e1a6b21"
+
+	tests := []struct {
+		name          string
+		input         string
+		expectedNames []string
+		expectedIDs   []string
+	}{
+		{
+			name:          "no links",
+			input:         "just some [text](https://example.com)",
+			expectedNames: []string{},
+			expectedIDs:   []string{},
+		},
+		{
+			name:          "wiki links",
+			input:         "see [[Release notes]] and [[Roadmap]]",
+			expectedNames: []string{"Release notes", "Roadmap"},
+			expectedIDs:   []string{},
+		},
+		{
+			name:          "alias and extra spaces",
+			input:         "see [[  Release   notes |the notes]]",
+			expectedNames: []string{"Release notes"},
+			expectedIDs:   []string{},
+		},
+		{
+			name:          "duplicates in different cases",
+			input:         "[[Roadmap]], [[roadmap]] and [[ROADMAP]]",
+			expectedNames: []string{"Roadmap"},
+			expectedIDs:   []string{},
+		},
+		{
+			name: "entry URIs",
+			input: "[the plan](hubble://entry/" + id + ") and " +
+				"hubble://entry/0B6F7C7E-4F3A-4D0E-9C55-2F8F3E1A6B21",
+			expectedNames: []string{},
+			expectedIDs:   []string{id},
+		},
+		{
+			name:          "entry URI in a wiki link",
+			input:         "[[hubble://entry/" + id + "]]",
+			expectedNames: []string{},
+			expectedIDs:   []string{id},
+		},
+		{
+			name:          "invalid entry URI",
+			input:         "hubble://entry/not-a-uuid",
+			expectedNames: []string{},
+			expectedIDs:   []string{},
+		},
+		{
+			name:          "links in code",
+			input:         "`[[Inline]]`\n```\n[[Fenced]]\n```\n[[Kept]]",
+			expectedNames: []string{"Kept"},
+			expectedIDs:   []string{},
+		},
+		{
+			name:          "empty and nested brackets",
+			input:         "[[ ]] and [[a [b] c]]",
+			expectedNames: []string{},
+			expectedIDs:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := document.ParseLinks(tt.input)
+
+			if !slices.Equal(links.Names, tt.expectedNames) {
+				t.Errorf("expected names %q, got %q", tt.expectedNames, links.Names)
+			}
+
+			if !slices.Equal(links.IDs, tt.expectedIDs) {
+				t.Errorf("expected IDs %q, got %q", tt.expectedIDs, links.IDs)
+			}
+		})
+	}
+}
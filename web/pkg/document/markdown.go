@@ -0,0 +1,47 @@
+package document
+
+import (
+	"regexp"
+	"strings"
+)
+
+// markdownRule replaces the markdown syntax matched by pattern, the text it wraps is kept through the replacement's capture groups
+type markdownRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// The rules run in order, images are stripped before links since they share the same syntax
+var markdownRules = []markdownRule{
+	{regexp.MustCompile("(?m)^[ \\t]*(```|~~~).*$"), ""},
+	{regexp.MustCompile("`([^`\n]+)`"), "$1"},
+	{regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile(`<(https?://[^>\s]+)>`), "$1"},
+	{regexp.MustCompile(`</?[a-zA-Z][^>]*>`), ""},
+	{regexp.MustCompile(`(?m)^ {0,3}((\*[ \t]*){3,}|(-[ \t]*){3,}|(_[ \t]*){3,})$`), ""},
+	{regexp.MustCompile(`(?m)^ {0,3}#{1,6}[ \t]+`), ""},
+	{regexp.MustCompile(`(?m)^ {0,3}>[ \t]?`), ""},
+	{regexp.MustCompile(`(?m)^[ \t]*([-*+]|\d+[.)])[ \t]+(\[[ xX]\][ \t]+)?`), ""},
+	{regexp.MustCompile(`(\*\*|__)([^\n]+?)(\*\*|__)`), "$2"},
+	{regexp.MustCompile(`\*([^*\n]+)\*`), "$1"},
+	{regexp.MustCompile(`(^|\W)_([^_\n]+)_(\W|$)`), "$1$2$3"},
+	{regexp.MustCompile(`~~([^~\n]+)~~`), "$1"},
+	{regexp.MustCompile(`\n{3,}`), "\n\n"},
+}
+
+/*
+MarkdownToText strips the markdown syntax from a document and keeps its text, it is used for the full-text content of markdown entries.
+
+Example:
+
+MarkdownToText("# Hello **world**")  "Hello world"
+*/
+func MarkdownToText(markdown string) string {
+	text := strings.ReplaceAll(markdown, "\r\n", "\n")
+	for _, rule := range markdownRules {
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+
+	return strings.TrimSpace(text)
+}
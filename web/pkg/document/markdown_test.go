@@ -0,0 +1,59 @@
+package document_test
+
+import (
+	"testing"
+
+	"go.trulyao.dev/hubble/web/pkg/document"
+)
+
+func Test_MarkdownToText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain text", input: "hello world", expected: "hello world"},
+		{name: "heading", input: "## Release notes", expected: "Release notes"},
+		{
+			name:     "emphasis",
+			input:    "a **bold**, *italic* and ~~gone~~ word",
+			expected: "a bold, italic and gone word",
+		},
+		{
+			name:     "underscores in words",
+			input:    "use snake_case_names and _this_",
+			expected: "use snake_case_names and this",
+		},
+		{name: "link", input: "see [the docs](https://example.com/docs)", expected: "see the docs"},
+		{name: "image", input: "![diagram](diagram.png)", expected: "diagram"},
+		{name: "autolink", input: "<https://example.com>", expected: "https://example.com"},
+		{name: "inline code", input: "run `make build`", expected: "run make build"},
+		{
+			name:     "code block",
+			input:    "before\n```go\nfmt.Println(\"hi\")\n```\nafter",
+			expected: "before\n\nfmt.Println(\"hi\")\n\nafter",
+		},
+		{name: "blockquote", input: "> quoted\n> text", expected: "quoted\ntext"},
+		{
+			name:     "lists",
+			input:    "- one\n* two\n1. three\n- [x] done",
+			expected: "one\ntwo\nthree\ndone",
+		},
+		{name: "horizontal rule", input: "above\n\n---\n\nbelow", expected: "above\n\nbelow"},
+		{
+			name:     "html",
+			input:    "<details><summary>More</summary>hidden</details>",
+			expected: "Morehidden",
+		},
+		{name: "windows line endings", input: "# Title\r\nbody", expected: "Title\nbody"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := document.MarkdownToText(tt.input)
+			if actual != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package apperrors
 
 import (
+	"encoding/json"
+	"net/http"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -56,6 +58,15 @@ func ErrorHandler(err error) (robin.Serializable, int) {
 	return message, code
 }
 
+// WriteHttpError writes the error in the same format as robin's error responses, this is used by plain HTTP handlers (e.g. streams) that are not procedures
+func WriteHttpError(w http.ResponseWriter, err error) {
+	message, code := ErrorHandler(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": message})
+}
+
 func uppercaseFirstLetter(s string) string {
 	if len(s) == 0 {
 		return s
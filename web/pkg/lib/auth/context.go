@@ -1,12 +1,16 @@
 package auth
 
 import (
+	"context"
+
 	"go.trulyao.dev/hubble/web/internal/models"
 	"go.trulyao.dev/hubble/web/internal/repository"
 	apperrors "go.trulyao.dev/hubble/web/pkg/errors"
 	"go.trulyao.dev/robin"
 )
 
+type sessionContextKey struct{}
+
 func ExtractAuthSession(ctx *robin.Context) (models.AuthSession, error) {
 	auth, ok := ctx.Get(StateKeySession).(models.AuthSession)
 	if !ok {
@@ -29,3 +33,18 @@ func ExtractUser(ctx *robin.Context, repo repository.UserRepository) (*models.Us
 
 	return user, nil
 }
+
+// WithAuthSession returns a copy of the request context that holds the session, this is the plain HTTP equivalent of the robin context state
+func WithAuthSession(ctx context.Context, session models.AuthSession) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+// ExtractAuthSessionFromContext returns the session stored in a request context by `WithAuthSession`
+func ExtractAuthSessionFromContext(ctx context.Context) (models.AuthSession, error) {
+	auth, ok := ctx.Value(sessionContextKey{}).(models.AuthSession)
+	if !ok {
+		return models.AuthSession{}, apperrors.ErrIncompleteSession
+	}
+
+	return auth, nil
+}
@@ -3,6 +3,7 @@ package lib
 import (
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -36,6 +37,10 @@ func PgInt4(i int32) pgtype.Int4 {
 	return pgtype.Int4{Valid: i != 0, Int32: i}
 }
 
+func PgTimestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: !t.IsZero()}
+}
+
 func PgUUIDString(uuid string) pgtype.UUID {
 	if uuid == "" {
 		return pgtype.UUID{Bytes: [16]byte{}, Valid: false}
@@ -0,0 +1,243 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	openai "github.com/sashabaranov/go-openai"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/seer"
+)
+
+const (
+	DefaultMaxContextTokens = 4000
+
+	// MaxAnswerChunks is the number of top chunks retrieved for a question before they are packed into the context budget
+	MaxAnswerChunks = 20
+
+	// NoContextAnswer is returned without calling the model when nothing relevant was retrieved
+	NoContextAnswer = "I could not find anything related to this question in your entries."
+)
+
+var (
+	ErrChatDisabled = errors.New("chat model is not configured")
+
+	citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+)
+
+const answerPrompt = `You are an assistant that answers questions using ONLY the numbered sources from the user's knowledge base provided below.
+
+Cite the sources every statement is based on with their number in square brackets, e.g. [1] or [2][3]. Do not cite sources that were not provided and do not use any outside knowledge.
+
+If the sources do not contain the answer, say that you could not find it in the knowledge base.`
+
+// ContextChunk is a retrieved chunk that can be added to a prompt and cited in the answer
+type ContextChunk struct {
+	EntryID    pgtype.UUID
+	EntryName  string
+	ChunkIndex int32
+	Collection models.EntryRelation
+	Content    string
+	Score      float64
+}
+
+// ChatEnabled returns true if a chat model has been configured
+func (l *LLM) ChatEnabled() bool {
+	return l.config.EnabledChat()
+}
+
+// Answer answers the question with the chat model using the retrieved chunks as the only sources, the chunks are expected to be ordered by relevance
+func (l *LLM) Answer(
+	ctx context.Context,
+	question string,
+	chunks []ContextChunk,
+) (*models.Answer, error) {
+	if !l.ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+
+	messages, sources := BuildAnswerPrompt(question, chunks, l.maxContextTokens())
+	if len(sources) == 0 {
+		return &models.Answer{Answer: NoContextAnswer, Citations: []models.Citation{}}, nil
+	}
+
+	//nolint:exhaustruct
+	response, err := l.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       l.config.ChatModel,
+		Temperature: 0,
+		Messages:    messages,
+	})
+	if err != nil {
+		return nil, seer.Wrap("create_answer_chat_completion", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, seer.New("create_answer_chat_completion", "no choices returned")
+	}
+
+	answer := strings.TrimSpace(response.Choices[0].Message.Content)
+	return &models.Answer{Answer: answer, Citations: ExtractCitations(answer, sources)}, nil
+}
+
+/*
+StreamAnswer is the streaming version of Answer, `onDelta` is called with every piece of content as it is generated and the full answer is returned once the stream ends.
+
+The upstream request is bound to the context, cancelling it (e.g. when the client disconnects) stops the generation.
+*/
+func (l *LLM) StreamAnswer(
+	ctx context.Context,
+	question string,
+	chunks []ContextChunk,
+	onDelta func(content string) error,
+) (*models.Answer, error) {
+	if !l.ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+
+	messages, sources := BuildAnswerPrompt(question, chunks, l.maxContextTokens())
+	if len(sources) == 0 {
+		if err := onDelta(NoContextAnswer); err != nil {
+			return nil, err
+		}
+
+		return &models.Answer{Answer: NoContextAnswer, Citations: []models.Citation{}}, nil
+	}
+
+	//nolint:exhaustruct
+	stream, err := l.client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
+		Model:       l.config.ChatModel,
+		Temperature: 0,
+		Messages:    messages,
+		Stream:      true,
+	})
+	if err != nil {
+		return nil, seer.Wrap("create_answer_chat_completion_stream", err)
+	}
+	defer stream.Close()
+
+	var sb strings.Builder
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, seer.Wrap("receive_answer_chat_completion_stream", err)
+		}
+
+		if len(response.Choices) == 0 || response.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		delta := response.Choices[0].Delta.Content
+		sb.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+
+	answer := strings.TrimSpace(sb.String())
+	return &models.Answer{Answer: answer, Citations: ExtractCitations(answer, sources)}, nil
+}
+
+func (l *LLM) maxContextTokens() int {
+	if l.config.MaxContextTokens <= 0 {
+		return DefaultMaxContextTokens
+	}
+
+	return l.config.MaxContextTokens
+}
+
+/*
+BuildAnswerPrompt builds the grounded prompt for a question.
+
+The chunks are added as numbered sources in their order until the context budget is spent, chunks that do not fit in the remaining budget are skipped. The sources that made it into the prompt are returned, source `[n]` is `sources[n-1]`.
+*/
+func BuildAnswerPrompt(
+	question string,
+	chunks []ContextChunk,
+	maxContextTokens int,
+) ([]openai.ChatCompletionMessage, []ContextChunk) {
+	var (
+		sb      strings.Builder
+		sources = make([]ContextChunk, 0, len(chunks))
+		budget  = maxContextTokens
+	)
+
+	for _, chunk := range chunks {
+		content := strings.TrimSpace(chunk.Content)
+		if content == "" {
+			continue
+		}
+
+		source := fmt.Sprintf(
+			"[%d] %s (part %d)\n%s\n\n",
+			len(sources)+1,
+			chunk.EntryName,
+			chunk.ChunkIndex+1,
+			content,
+		)
+
+		tokens := EstimateTokens(source)
+		if tokens > budget {
+			continue
+		}
+
+		budget -= tokens
+		sb.WriteString(source)
+		sources = append(sources, chunk)
+	}
+
+	//nolint:exhaustruct
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: answerPrompt},
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: fmt.Sprintf("Sources:\n\n%sQuestion: %s", sb.String(), question),
+		},
+	}
+
+	return messages, sources
+}
+
+// EstimateTokens roughly estimates the number of tokens in a text (~4 characters per token for English text), this avoids shipping a tokenizer for every model
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// ExtractCitations returns the sources cited in the answer (e.g. `[1]` or `[1, 3]`) in the order they are first cited, markers that do not point to a source are ignored
+func ExtractCitations(answer string, sources []ContextChunk) []models.Citation {
+	var (
+		citations = make([]models.Citation, 0)
+		seen      = make(map[int]bool)
+	)
+
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, part := range strings.Split(match[1], ",") {
+			marker, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || marker < 1 || marker > len(sources) || seen[marker] {
+				continue
+			}
+			seen[marker] = true
+
+			source := sources[marker-1]
+			citations = append(citations, models.Citation{
+				Marker:     marker,
+				EntryID:    source.EntryID,
+				EntryName:  source.EntryName,
+				ChunkIndex: source.ChunkIndex,
+				Collection: source.Collection,
+			})
+		}
+	}
+
+	return citations
+}
@@ -0,0 +1,218 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func Test_Answer(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		if body.Model != "chat-model" {
+			t.Errorf("expected the chat model to be used, got %q", body.Model)
+		}
+		prompt = body.Messages[len(body.Messages)-1].Content
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"object": "chat.completion",
+			"choices": []map[string]any{
+				{
+					"index":         0,
+					"finish_reason": "stop",
+					"message": map[string]any{
+						"role":    "assistant",
+						"content": "The budget is 5000 [2]. It was approved in March [1, 2] by the board [7].",
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	//nolint:exhaustruct
+	conf := &config.Config{}
+	conf.LLM.BaseURL = server.URL
+	conf.LLM.ChatModel = "chat-model"
+	conf.LLM.MaxContextTokens = 100
+
+	service, err := llm.NewService(conf, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	//nolint:exhaustruct
+	chunks := []llm.ContextChunk{
+		{EntryID: uuid(1), EntryName: "Minutes", ChunkIndex: 0, Content: "The budget was approved in March."},
+		{EntryID: uuid(2), EntryName: "Budget", ChunkIndex: 3, Content: "The total budget is 5000."},
+		{EntryID: uuid(3), EntryName: "Too long", ChunkIndex: 0, Content: strings.Repeat("lorem ipsum ", 100)},
+	}
+
+	answer, err := service.Answer(context.Background(), "What is the budget?", chunks)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.Contains(prompt, "[2] Budget (part 4)") || !strings.Contains(prompt, "What is the budget?") {
+		t.Errorf("expected the sources and the question in the prompt, got %q", prompt)
+	}
+
+	if strings.Contains(prompt, "lorem ipsum") {
+		t.Errorf("expected the chunk over the context budget to be skipped")
+	}
+
+	if len(answer.Citations) != 2 {
+		t.Fatalf("expected 2 citations, got %+v", answer.Citations)
+	}
+
+	if first := answer.Citations[0]; first.Marker != 2 || first.EntryID != uuid(2) || first.ChunkIndex != 3 {
+		t.Errorf("expected the first citation to point to the second source, got %+v", first)
+	}
+
+	if second := answer.Citations[1]; second.Marker != 1 || second.EntryID != uuid(1) {
+		t.Errorf("expected the second citation to point to the first source, got %+v", second)
+	}
+}
+
+func Test_Answer_NoContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("the model should not be called without any sources")
+	}))
+	defer server.Close()
+
+	//nolint:exhaustruct
+	conf := &config.Config{}
+	conf.LLM.BaseURL = server.URL
+	conf.LLM.ChatModel = "chat-model"
+
+	service, err := llm.NewService(conf, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	answer, err := service.Answer(context.Background(), "What is the budget?", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if answer.Answer != llm.NoContextAnswer || len(answer.Citations) != 0 {
+		t.Errorf("expected the no context answer, got %+v", answer)
+	}
+}
+
+func Test_StreamAnswer(t *testing.T) {
+	upstreamCancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		for _, delta := range []string{"The budget ", "is 5000 [1]."} {
+			chunk, _ := json.Marshal(map[string]any{
+				"object":  "chat.completion.chunk",
+				"choices": []map[string]any{{"index": 0, "delta": map[string]any{"content": delta}}},
+			})
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+		}
+
+		// The "hang" key simulates a generation that is still running when the client disconnects
+		if r.Header.Get("Authorization") != "Bearer hang" {
+			_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+
+		// Keep the stream open until the client goes away
+		<-r.Context().Done()
+		close(upstreamCancelled)
+	}))
+	defer server.Close()
+
+	newService := func(apiKey string) *llm.LLM {
+		//nolint:exhaustruct
+		conf := &config.Config{}
+		conf.LLM.BaseURL = server.URL
+		conf.LLM.ApiKey = apiKey
+		conf.LLM.ChatModel = "chat-model"
+
+		service, err := llm.NewService(conf, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create service: %v", err)
+		}
+		return service
+	}
+
+	//nolint:exhaustruct
+	chunks := []llm.ContextChunk{
+		{EntryID: uuid(1), EntryName: "Budget", ChunkIndex: 2, Content: "The total budget is 5000."},
+	}
+
+	t.Run("streams deltas", func(t *testing.T) {
+		var deltas []string
+		answer, err := newService("key").StreamAnswer(
+			context.Background(),
+			"What is the budget?",
+			chunks,
+			func(content string) error {
+				deltas = append(deltas, content)
+				return nil
+			},
+		)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(deltas) != 2 || answer.Answer != "The budget is 5000 [1]." {
+			t.Errorf("unexpected deltas %q or answer %q", deltas, answer.Answer)
+		}
+
+		if len(answer.Citations) != 1 || answer.Citations[0].ChunkIndex != 2 {
+			t.Errorf("expected a citation to the first source, got %+v", answer.Citations)
+		}
+	})
+
+	t.Run("cancels the upstream request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, err := newService("hang").StreamAnswer(ctx, "What is the budget?", chunks, func(string) error {
+			cancel() // the client disconnected
+			return nil
+		})
+		if err == nil {
+			t.Fatalf("expected an error after the context was cancelled")
+		}
+
+		select {
+		case <-upstreamCancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the upstream request to be cancelled")
+		}
+	})
+}
+
+func uuid(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
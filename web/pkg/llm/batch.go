@@ -0,0 +1,295 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+	"go.trulyao.dev/hubble/web/internal/models"
+)
+
+const (
+	DefaultEmbeddingBatchSize     = 64
+	DefaultEmbeddingBatchTokens   = 8000
+	DefaultEmbeddingFlushInterval = 250 * time.Millisecond
+	DefaultEmbeddingRetryBackoff  = 1 * time.Second
+
+	// EmbeddingBatchTimeout is the time budget for a batch, including the retries of its halves
+	EmbeddingBatchTimeout = 2 * time.Minute
+
+	// EmbeddingBatchRetries is the number of times a request is retried after a transient error
+	EmbeddingBatchRetries = 3
+)
+
+var (
+	ErrMissingEmbedding = errors.New("no embedding was returned for the text")
+
+	errEmbeddingCount = errors.New("unexpected number of embeddings")
+)
+
+// EmbedFunc embeds multiple texts in a single request, the embeddings are returned in the same order as the texts
+type EmbedFunc func(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	texts []string,
+) ([][]float32, error)
+
+type EmbeddingBatcherOptions struct {
+	// MaxBatchSize is the maximum number of texts sent in a single request
+	MaxBatchSize int
+
+	// MaxBatchTokens is the maximum (estimated) number of tokens sent in a single request, a text over the limit is sent on its own
+	MaxBatchTokens int
+
+	// FlushInterval is how long a text waits for the batch to fill up before the batch is sent anyway
+	FlushInterval time.Duration
+
+	// RetryBackoff is how long the first retry of a transient error waits, it doubles after every retry
+	RetryBackoff time.Duration
+}
+
+/*
+EmbeddingBatcher collects the texts embedded concurrently (e.g. by the chunk embedding workers) and embeds them in batches.
+
+Texts are grouped by model since a request can only target one model. A batch is sent once it is full or once its first text has waited for `FlushInterval`.
+Transient errors (timeouts, rate limits and server errors) are retried with an exponential backoff. If the server rejects the input instead, the batch is split in half and each half is retried until the failing texts are isolated, so one bad chunk does not fail the rest of the batch.
+*/
+type EmbeddingBatcher struct {
+	embed   EmbedFunc
+	options EmbeddingBatcherOptions
+
+	mu      sync.Mutex
+	pending map[models.EmbeddingModel]*embeddingBatch
+}
+
+type embeddingBatch struct {
+	requests []*embeddingRequest
+	tokens   int
+	timer    *time.Timer
+}
+
+type embeddingRequest struct {
+	text   string
+	result chan embeddingResult
+}
+
+type embeddingResult struct {
+	vector []float32
+	err    error
+}
+
+func NewEmbeddingBatcher(embed EmbedFunc, options EmbeddingBatcherOptions) *EmbeddingBatcher {
+	if options.MaxBatchSize <= 0 {
+		options.MaxBatchSize = DefaultEmbeddingBatchSize
+	}
+
+	if options.MaxBatchTokens <= 0 {
+		options.MaxBatchTokens = DefaultEmbeddingBatchTokens
+	}
+
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = DefaultEmbeddingFlushInterval
+	}
+
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DefaultEmbeddingRetryBackoff
+	}
+
+	return &EmbeddingBatcher{
+		embed:   embed,
+		options: options,
+		mu:      sync.Mutex{},
+		pending: make(map[models.EmbeddingModel]*embeddingBatch),
+	}
+}
+
+// Embed adds the text to the next batch of the model and waits for its embedding
+func (b *EmbeddingBatcher) Embed(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	text string,
+) ([]float32, error) {
+	request := &embeddingRequest{text: text, result: make(chan embeddingResult, 1)}
+	b.enqueue(model, request)
+
+	select {
+	case result := <-request.result:
+		return result.vector, result.err
+	case <-ctx.Done():
+		// The batch is still sent, the result is dropped
+		return nil, ctx.Err()
+	}
+}
+
+func (b *EmbeddingBatcher) enqueue(model models.EmbeddingModel, request *embeddingRequest) {
+	tokens := EstimateTokens(request.text)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch := b.pending[model]
+
+	// Send the current batch first if the text would put it over the token budget
+	if batch != nil && batch.tokens+tokens > b.options.MaxBatchTokens {
+		b.flushLocked(model, batch)
+		batch = nil
+	}
+
+	if batch == nil {
+		batch = &embeddingBatch{requests: []*embeddingRequest{}, tokens: 0, timer: nil}
+		batch.timer = time.AfterFunc(b.options.FlushInterval, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			// The batch may have been sent already because it filled up
+			if b.pending[model] == batch {
+				b.flushLocked(model, batch)
+			}
+		})
+		b.pending[model] = batch
+	}
+
+	batch.requests = append(batch.requests, request)
+	batch.tokens += tokens
+
+	if len(batch.requests) >= b.options.MaxBatchSize || batch.tokens >= b.options.MaxBatchTokens {
+		b.flushLocked(model, batch)
+	}
+}
+
+// flushLocked detaches the batch and sends it in the background, the caller must hold the lock
+func (b *EmbeddingBatcher) flushLocked(model models.EmbeddingModel, batch *embeddingBatch) {
+	batch.timer.Stop()
+	delete(b.pending, model)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), EmbeddingBatchTimeout)
+		defer cancel()
+
+		b.send(ctx, model, batch.requests)
+	}()
+}
+
+// send embeds the requests in a single request, batches rejected because of their input are split in half and retried until the failure is attributed to a single text
+func (b *EmbeddingBatcher) send(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	requests []*embeddingRequest,
+) {
+	texts := make([]string, 0, len(requests))
+	for _, request := range requests {
+		texts = append(texts, request.text)
+	}
+
+	vectors, err := b.embedWithRetries(ctx, model, texts)
+	if err != nil {
+		if len(requests) == 1 || ctx.Err() != nil || !isInputError(err) {
+			for _, request := range requests {
+				request.result <- embeddingResult{vector: nil, err: err}
+			}
+			return
+		}
+
+		log.Warn().
+			Err(err).
+			Str("model", model.Name).
+			Int("size", len(requests)).
+			Msg("embedding batch failed, retrying in halves")
+
+		middle := len(requests) / 2
+		b.send(ctx, model, requests[:middle])
+		b.send(ctx, model, requests[middle:])
+		return
+	}
+
+	for i, request := range requests {
+		if len(vectors[i]) == 0 {
+			request.result <- embeddingResult{vector: nil, err: ErrMissingEmbedding}
+			continue
+		}
+
+		request.result <- embeddingResult{vector: vectors[i], err: nil}
+	}
+}
+
+// embedWithRetries sends a single request, transient errors are retried with an exponential backoff
+func (b *EmbeddingBatcher) embedWithRetries(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	texts []string,
+) ([][]float32, error) {
+	backoff := b.options.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		vectors, err := b.embed(ctx, model, texts)
+		if err == nil && len(vectors) != len(texts) {
+			err = fmt.Errorf("%w: expected %d, got %d", errEmbeddingCount, len(texts), len(vectors))
+		}
+
+		retry := err != nil && isTransientError(err) && attempt < EmbeddingBatchRetries
+		if !retry || ctx.Err() != nil {
+			return vectors, err
+		}
+
+		log.Warn().
+			Err(err).
+			Str("model", model.Name).
+			Int("size", len(texts)).
+			Dur("backoff", backoff).
+			Msg("embedding request failed, retrying")
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// isInputError reports whether the request was rejected because of its input (e.g. a text over the model's context length), these are the only failures that can be narrowed down to some of the texts
+func isInputError(err error) bool {
+	if errors.Is(err, errEmbeddingCount) || errors.Is(err, ErrMissingEmbedding) {
+		return true
+	}
+
+	switch statusCode(err) {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTransientError reports whether the same request could succeed if it is sent again
+func isTransientError(err error) bool {
+	if code := statusCode(err); code != 0 {
+		return code == http.StatusRequestTimeout ||
+			code == http.StatusTooManyRequests ||
+			code >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
+
+// statusCode returns the HTTP status code of a failed request to the server, 0 is returned if the request did not get a response
+func statusCode(err error) int {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.HTTPStatusCode
+	}
+
+	return 0
+}
@@ -0,0 +1,172 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func Test_EmbeddingBatcher(t *testing.T) {
+	model := models.EmbeddingModel{Name: "embedding-model", Dimensions: 2}
+	errBadInput := &openai.APIError{HTTPStatusCode: 400, Message: "bad input"}
+	errUnavailable := &openai.APIError{HTTPStatusCode: 503, Message: "unavailable"}
+	errUnauthorized := &openai.APIError{HTTPStatusCode: 401, Message: "unauthorized"}
+
+	tests := []struct {
+		name        string
+		texts       []string
+		options     llm.EmbeddingBatcherOptions
+		unavailable int
+		requests    int
+		failed      []string
+	}{
+		{
+			name:     "sends concurrent texts in one request",
+			texts:    []string{"a", "b", "c", "d"},
+			options:  llm.EmbeddingBatcherOptions{MaxBatchSize: 4, FlushInterval: time.Minute},
+			requests: 1,
+			failed:   []string{},
+		},
+		{
+			name:     "flushes partial batches after the interval",
+			texts:    []string{"a", "b", "c"},
+			options:  llm.EmbeddingBatcherOptions{MaxBatchSize: 10, FlushInterval: 10 * time.Millisecond},
+			requests: 1,
+			failed:   []string{},
+		},
+		{
+			name:  "splits batches over the token budget",
+			texts: []string{"aaaa", "bbbb", "cccc"},
+			options: llm.EmbeddingBatcherOptions{
+				MaxBatchSize:   10,
+				MaxBatchTokens: 1,
+				FlushInterval:  time.Minute,
+			},
+			requests: 3,
+			failed:   []string{},
+		},
+		{
+			// [a b bad d] fails, then [a b] succeeds, [bad d] fails, [bad] fails and [d] succeeds
+			name:     "attributes failures to the failing text",
+			texts:    []string{"a", "b", "bad", "d"},
+			options:  llm.EmbeddingBatcherOptions{MaxBatchSize: 4, FlushInterval: time.Minute},
+			requests: 5,
+			failed:   []string{"bad"},
+		},
+		{
+			name:  "retries transient errors without splitting",
+			texts: []string{"a", "b", "c", "d"},
+			options: llm.EmbeddingBatcherOptions{
+				MaxBatchSize:  4,
+				FlushInterval: time.Minute,
+				RetryBackoff:  time.Millisecond,
+			},
+			unavailable: 2,
+			requests:    3,
+			failed:      []string{},
+		},
+		{
+			name:  "fails the batch once the retries are exhausted",
+			texts: []string{"a", "b"},
+			options: llm.EmbeddingBatcherOptions{
+				MaxBatchSize:  2,
+				FlushInterval: time.Minute,
+				RetryBackoff:  time.Millisecond,
+			},
+			unavailable: 10,
+			requests:    llm.EmbeddingBatchRetries + 1,
+			failed:      []string{"a", "b"},
+		},
+		{
+			name:     "does not split batches on errors unrelated to the input",
+			texts:    []string{"a", "denied"},
+			options:  llm.EmbeddingBatcherOptions{MaxBatchSize: 2, FlushInterval: time.Minute},
+			requests: 1,
+			failed:   []string{"a", "denied"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests int
+			)
+
+			embed := func(_ context.Context, _ models.EmbeddingModel, texts []string) ([][]float32, error) {
+				mu.Lock()
+				requests++
+				attempt := requests
+				mu.Unlock()
+
+				if attempt <= test.unavailable {
+					return nil, errUnavailable
+				}
+
+				if slices.Contains(texts, "bad") {
+					return nil, errBadInput
+				}
+
+				if slices.Contains(texts, "denied") {
+					return nil, errUnauthorized
+				}
+
+				vectors := make([][]float32, 0, len(texts))
+				for range texts {
+					vectors = append(vectors, []float32{1, 0})
+				}
+				return vectors, nil
+			}
+
+			batcher := llm.NewEmbeddingBatcher(embed, test.options)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var (
+				wg     sync.WaitGroup
+				failed = make([]string, 0)
+			)
+			for _, text := range test.texts {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					vector, err := batcher.Embed(ctx, model, text)
+
+					mu.Lock()
+					defer mu.Unlock()
+					if err != nil {
+						if !errors.Is(err, errBadInput) && !errors.Is(err, errUnavailable) &&
+							!errors.Is(err, errUnauthorized) {
+							t.Errorf("unexpected error for %q: %v", text, err)
+						}
+						failed = append(failed, text)
+						return
+					}
+
+					if len(vector) != 2 {
+						t.Errorf("expected a vector for %q, got %v", text, vector)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if requests != test.requests {
+				t.Errorf("expected %d requests, got %d", test.requests, requests)
+			}
+
+			slices.Sort(failed)
+			if !slices.Equal(failed, test.failed) {
+				t.Errorf("expected %v to fail, got %v", test.failed, failed)
+			}
+		})
+	}
+}
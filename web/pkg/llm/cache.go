@@ -0,0 +1,332 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/internal/kv"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/internal/repository"
+)
+
+const (
+	DefaultEmbeddingCacheMaxEntries = 100000
+
+	// EmbeddingCacheHitsBatchSize is the number of hit keys kept in memory before they are written to the cache's table
+	EmbeddingCacheHitsBatchSize = 500
+)
+
+// EmbeddingCacheStore persists the cached embeddings by their content address
+type EmbeddingCacheStore interface {
+	// Get returns the embedding of a key, false is returned on a cache miss
+	Get(key string) ([]float32, bool, error)
+
+	// Set stores the embedding of a key
+	Set(key string, model models.EmbeddingModel, vector []float32) error
+
+	// Evict removes the embeddings over the limit and returns the number of evicted embeddings
+	Evict(maxEntries int) (int64, error)
+}
+
+type EmbeddingCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+/*
+EmbeddingCache caches embeddings by model and content, so that requeued entries, identical chunks and repeated queries are not embedded again.
+
+The cache is best-effort: store errors are logged and treated as misses so that a broken cache never fails an embedding. A nil cache is a valid, disabled cache.
+*/
+type EmbeddingCache struct {
+	store      EmbeddingCacheStore
+	maxEntries int
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewEmbeddingCache creates the cache configured in `driver.embedding_cache`, nil is returned if caching is disabled
+func NewEmbeddingCache(conf *config.Config, repo repository.Repository, store kv.Store) *EmbeddingCache {
+	// Hashed embeddings are cheaper to compute than to look up
+	if conf.Drivers.Embedder == config.EmbeddingDriverHashed {
+		return nil
+	}
+
+	maxEntries := conf.LLM.EmbeddingCacheMaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultEmbeddingCacheMaxEntries
+	}
+
+	var cacheStore EmbeddingCacheStore
+	switch conf.Drivers.EmbeddingCache {
+	case config.EmbeddingCacheDriverPostgres:
+		if repo == nil {
+			return nil
+		}
+		cacheStore = &postgresEmbeddingCacheStore{
+			repo: repo,
+			mu:   sync.Mutex{},
+			hits: make(map[string]int32),
+		}
+
+	case config.EmbeddingCacheDriverKv:
+		if store == nil {
+			return nil
+		}
+		cacheStore = NewKVEmbeddingCacheStore(store, maxEntries)
+
+	default:
+		return nil
+	}
+
+	return NewEmbeddingCacheWithStore(cacheStore, maxEntries)
+}
+
+func NewEmbeddingCacheWithStore(store EmbeddingCacheStore, maxEntries int) *EmbeddingCache {
+	return &EmbeddingCache{
+		store:      store,
+		maxEntries: maxEntries,
+		hits:       atomic.Int64{},
+		misses:     atomic.Int64{},
+	}
+}
+
+// EmbeddingCacheKey returns the content address of a text for a model, texts that only differ in whitespace share the same address
+func EmbeddingCacheKey(model models.EmbeddingModel, text string) string {
+	normalised := strings.Join(strings.Fields(text), " ")
+
+	hash := sha256.New()
+	hash.Write([]byte(model.Name))
+	hash.Write([]byte{0})
+	hash.Write([]byte(strconv.Itoa(int(model.Dimensions))))
+	hash.Write([]byte{0})
+	hash.Write([]byte(normalised))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached embedding of a text, false is returned on a miss
+func (c *EmbeddingCache) Get(model models.EmbeddingModel, text string) ([]float32, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	vector, found, err := c.store.Get(EmbeddingCacheKey(model, text))
+	if err != nil {
+		log.Warn().Err(err).Str("model", model.Name).Msg("failed to read embedding cache")
+	}
+
+	// Vectors with the wrong dimensions can not be compared with the rest of the workspace
+	if err != nil || !found || len(vector) != int(model.Dimensions) {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return vector, true
+}
+
+// Set caches the embedding of a text
+func (c *EmbeddingCache) Set(model models.EmbeddingModel, text string, vector []float32) {
+	if c == nil || len(vector) == 0 {
+		return
+	}
+
+	if err := c.store.Set(EmbeddingCacheKey(model, text), model, vector); err != nil {
+		log.Warn().Err(err).Str("model", model.Name).Msg("failed to write embedding cache")
+	}
+}
+
+// Evict removes the embeddings over the configured size
+func (c *EmbeddingCache) Evict() (int64, error) {
+	if c == nil {
+		return 0, nil
+	}
+
+	return c.store.Evict(c.maxEntries)
+}
+
+// Stats returns the hits and misses since the application started
+func (c *EmbeddingCache) Stats() EmbeddingCacheStats {
+	if c == nil {
+		return EmbeddingCacheStats{Hits: 0, Misses: 0}
+	}
+
+	return EmbeddingCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+/*
+postgresEmbeddingCacheStore stores the embeddings in the `embedding_cache` table and evicts the least recently used ones.
+
+Lookups are plain reads, the hits are counted in memory and written in batches once enough keys have been hit and before the least recently used embeddings are evicted. Hits that have not been written yet are lost on shutdown, which only makes their embeddings look older than they are.
+*/
+type postgresEmbeddingCacheStore struct {
+	repo repository.Repository
+
+	mu   sync.Mutex
+	hits map[string]int32
+}
+
+func (p *postgresEmbeddingCacheStore) Get(key string) ([]float32, bool, error) {
+	vector, found, err := p.repo.EmbeddingRepository().FindCachedEmbedding(key)
+	if err != nil || !found {
+		return vector, found, err
+	}
+
+	p.mu.Lock()
+	p.hits[key]++
+	full := len(p.hits) >= EmbeddingCacheHitsBatchSize
+	p.mu.Unlock()
+
+	if full {
+		if err := p.flushHits(); err != nil {
+			log.Warn().Err(err).Msg("failed to record embedding cache hits")
+		}
+	}
+
+	return vector, true, nil
+}
+
+func (p *postgresEmbeddingCacheStore) Set(
+	key string,
+	model models.EmbeddingModel,
+	vector []float32,
+) error {
+	return p.repo.EmbeddingRepository().CacheEmbedding(&repository.CacheEmbeddingArgs{
+		Key:    key,
+		Model:  model,
+		Vector: vector,
+	})
+}
+
+func (p *postgresEmbeddingCacheStore) Evict(maxEntries int) (int64, error) {
+	// The recent hits decide which embeddings are the least recently used
+	if err := p.flushHits(); err != nil {
+		log.Warn().Err(err).Msg("failed to record embedding cache hits")
+	}
+
+	return p.repo.EmbeddingRepository().EvictCachedEmbeddings(int32(maxEntries))
+}
+
+// flushHits writes the hits counted since the last flush
+func (p *postgresEmbeddingCacheStore) flushHits() error {
+	p.mu.Lock()
+	hits := p.hits
+	p.hits = make(map[string]int32)
+	p.mu.Unlock()
+
+	return p.repo.EmbeddingRepository().RecordCachedEmbeddingHits(hits)
+}
+
+/*
+KVEmbeddingCacheStore stores the embeddings in the key-value store for small deployments.
+
+Key-value stores can not list or sort their keys, so the cache is a ring of `maxEntries` slots: each new embedding takes the next slot and evicts the embedding that was stored in it, the oldest embeddings are evicted first.
+*/
+type KVEmbeddingCacheStore struct {
+	store      kv.Store
+	maxEntries int
+
+	mu sync.Mutex
+}
+
+func NewKVEmbeddingCacheStore(store kv.Store, maxEntries int) *KVEmbeddingCacheStore {
+	return &KVEmbeddingCacheStore{store: store, maxEntries: maxEntries, mu: sync.Mutex{}}
+}
+
+func (k *KVEmbeddingCacheStore) Get(key string) ([]float32, bool, error) {
+	value, err := k.store.Get(kv.KeyEmbeddingCache(key))
+	if err != nil {
+		if errors.Is(err, kv.ErrKeyNotFound) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	return decodeVector(value), true, nil
+}
+
+func (k *KVEmbeddingCacheStore) Set(key string, _ models.EmbeddingModel, vector []float32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	exists, err := k.store.Exists(kv.KeyEmbeddingCache(key))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	cursor := 0
+	value, err := k.store.Get(kv.KeyEmbeddingCacheCursor())
+	if err != nil && !errors.Is(err, kv.ErrKeyNotFound) {
+		return err
+	}
+	if err == nil {
+		if cursor, err = strconv.Atoi(string(value)); err != nil {
+			cursor = 0
+		}
+	}
+
+	slot := cursor % k.maxEntries
+
+	// Evict the embedding that was stored in the slot
+	previous, err := k.store.Get(kv.KeyEmbeddingCacheSlot(slot))
+	if err != nil && !errors.Is(err, kv.ErrKeyNotFound) {
+		return err
+	}
+	if err == nil {
+		if err := k.store.Delete(kv.KeyEmbeddingCache(string(previous))); err != nil &&
+			!errors.Is(err, kv.ErrKeyNotFound) {
+			return err
+		}
+	}
+
+	if err := k.store.Set(kv.KeyEmbeddingCache(key), encodeVector(vector)); err != nil {
+		return err
+	}
+
+	if err := k.store.Set(kv.KeyEmbeddingCacheSlot(slot), []byte(key)); err != nil {
+		return err
+	}
+
+	return k.store.Set(kv.KeyEmbeddingCacheCursor(), []byte(strconv.Itoa((slot+1)%k.maxEntries)))
+}
+
+// Evict is a no-op, embeddings are evicted as their slots are reused
+func (k *KVEmbeddingCacheStore) Evict(int) (int64, error) {
+	return 0, nil
+}
+
+func encodeVector(vector []float32) []byte {
+	encoded := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(encoded[i*4:], math.Float32bits(value))
+	}
+	return encoded
+}
+
+func decodeVector(encoded []byte) []float32 {
+	vector := make([]float32, len(encoded)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(encoded[i*4:]))
+	}
+	return vector
+}
+
+var (
+	_ EmbeddingCacheStore = (*postgresEmbeddingCacheStore)(nil)
+	_ EmbeddingCacheStore = (*KVEmbeddingCacheStore)(nil)
+)
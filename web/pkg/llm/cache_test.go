@@ -0,0 +1,88 @@
+package llm_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.trulyao.dev/hubble/web/internal/kv"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func Test_EmbeddingCacheKey(t *testing.T) {
+	model := models.EmbeddingModel{Name: "nomic-embed-text:latest", Dimensions: 768}
+
+	tests := []struct {
+		name  string
+		a     string
+		b     string
+		model models.EmbeddingModel
+		same  bool
+	}{
+		{name: "same content", a: "hello world", b: "hello world", model: model, same: true},
+		{name: "whitespace is normalised", a: "hello   world\n", b: " hello world", model: model, same: true},
+		{name: "different content", a: "hello world", b: "hello there", model: model, same: false},
+		{
+			name:  "different dimensions",
+			a:     "hello world",
+			b:     "hello world",
+			model: models.EmbeddingModel{Name: model.Name, Dimensions: 384},
+			same:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := llm.EmbeddingCacheKey(model, test.a)
+			b := llm.EmbeddingCacheKey(test.model, test.b)
+			if (a == b) != test.same {
+				t.Errorf("expected keys to match: %v, got %q and %q", test.same, a, b)
+			}
+		})
+	}
+}
+
+func Test_KVEmbeddingCache(t *testing.T) {
+	store, err := kv.NewBadgerDbStore(&kv.BadgerDbStoreConfig{
+		Path: filepath.Join(t.TempDir(), "kv.db"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	model := models.EmbeddingModel{Name: "embedding-model", Dimensions: 2}
+	cache := llm.NewEmbeddingCacheWithStore(llm.NewKVEmbeddingCacheStore(store, 2), 2)
+
+	if _, found := cache.Get(model, "first"); found {
+		t.Fatalf("expected a miss on an empty cache")
+	}
+
+	cache.Set(model, "first", []float32{1, 0})
+	cache.Set(model, "second", []float32{0, 1})
+
+	if vector, found := cache.Get(model, "first"); !found || !slices.Equal(vector, []float32{1, 0}) {
+		t.Errorf("expected the first embedding to be cached, got %v", vector)
+	}
+
+	// The cache only holds 2 embeddings, the oldest one is evicted
+	cache.Set(model, "third", []float32{0.5, 0.5})
+
+	if _, found := cache.Get(model, "first"); found {
+		t.Errorf("expected the first embedding to be evicted")
+	}
+
+	if _, found := cache.Get(model, "third"); !found {
+		t.Errorf("expected the third embedding to be cached")
+	}
+
+	// Vectors from another model are never returned
+	if _, found := cache.Get(models.EmbeddingModel{Name: "embedding-model", Dimensions: 3}, "third"); found {
+		t.Errorf("expected a miss for another model")
+	}
+
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 3 {
+		t.Errorf("expected 2 hits and 3 misses, got %+v", stats)
+	}
+}
@@ -2,6 +2,7 @@
 package llmcron
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"slices"
@@ -11,16 +12,25 @@ import (
 	"github.com/rs/zerolog/log"
 	"go.trulyao.dev/hubble/web/internal/config"
 	"go.trulyao.dev/hubble/web/internal/job"
+	"go.trulyao.dev/hubble/web/internal/models"
 	"go.trulyao.dev/hubble/web/internal/queue"
 	"go.trulyao.dev/hubble/web/internal/repository"
+	"go.trulyao.dev/hubble/web/pkg/llm"
 )
 
-const DefaultEmbeddingInterval = time.Minute * 30 // Default interval for embedding jobs
+const (
+	DefaultEmbeddingInterval = time.Minute * 30 // Default interval for embedding jobs
+	DefaultReembedBatchSize  = 32               // Default number of chunks re-embedded per run of a migration
+	ReembedBatchTimeout      = time.Minute      // Maximum time spent embedding a single batch
+
+	EmbeddingCacheEvictionInterval = time.Minute * 15 // Interval between two evictions of the embedding cache
+)
 
 type Cron struct {
 	config     *config.Config
 	queue      *queue.Queue
 	repository repository.Repository
+	llm        *llm.LLM
 
 	scheduler    gocron.Scheduler
 	queuedChunks []int32 // We need to keep track of chunks we have queued already so we don't keep sending the same ones over and over
@@ -30,6 +40,7 @@ func NewCron(
 	config *config.Config,
 	repo repository.Repository,
 	queue *queue.Queue,
+	llm *llm.LLM,
 ) (*Cron, error) {
 	if config == nil {
 		return nil, errors.New("config is nil")
@@ -43,6 +54,10 @@ func NewCron(
 		return nil, errors.New("queue is nil")
 	}
 
+	if llm == nil {
+		return nil, errors.New("llm is nil")
+	}
+
 	scheduler, err := gocron.NewScheduler()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scheduler: %w", err)
@@ -51,6 +66,7 @@ func NewCron(
 	c := Cron{
 		config:       config,
 		repository:   repo,
+		llm:          llm,
 		scheduler:    scheduler,
 		queue:        queue,
 		queuedChunks: []int32{},
@@ -65,6 +81,11 @@ func (c *Cron) Start() error {
 		return nil
 	}
 
+	// Vectors generated before the models were tracked can only have come from the configured model
+	if err := c.repository.EmbeddingRepository().AdoptModel(c.llm.EmbeddingModel()); err != nil {
+		return fmt.Errorf("failed to adopt embedding model: %w", err)
+	}
+
 	_, err := c.scheduler.NewJob(
 		gocron.DurationJob(DefaultEmbeddingInterval),
 		gocron.NewTask(c.loadUnindexedChunks),
@@ -73,6 +94,26 @@ func (c *Cron) Start() error {
 		return fmt.Errorf("failed to create job: %w", err)
 	}
 
+	_, err = c.scheduler.NewJob(
+		gocron.DurationJob(c.config.LLM.ReembedInterval),
+		gocron.NewTask(c.runEmbeddingMigrations),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if c.llm.EmbeddingCache() != nil {
+		_, err = c.scheduler.NewJob(
+			gocron.DurationJob(EmbeddingCacheEvictionInterval),
+			gocron.NewTask(c.evictEmbeddingCache),
+			gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create job: %w", err)
+		}
+	}
+
 	c.scheduler.Start()
 	return nil
 }
@@ -110,6 +151,105 @@ func (c *Cron) loadUnindexedChunks() {
 	}
 }
 
+// runEmbeddingMigrations re-embeds the next batch of chunks of every running migration, a migration is completed once it has no chunks left
+func (c *Cron) runEmbeddingMigrations() {
+	migrations, err := c.repository.EmbeddingRepository().FindRunningMigrations()
+	if err != nil {
+		log.Error().Err(err).Str("source", "reembed_cron").Msg("failed to find running migrations")
+		return
+	}
+
+	for i := range migrations {
+		migration := &migrations[i]
+		if err := c.runEmbeddingMigrationBatch(migration); err != nil {
+			// The migration was cancelled since it was loaded
+			if errors.Is(err, repository.ErrNoRunningEmbeddingMigration) {
+				continue
+			}
+
+			log.Error().
+				Err(err).
+				Str("source", "reembed_cron").
+				Int32("migration_id", migration.ID).
+				Msg("failed to re-embed batch")
+
+			status, recordErr := c.repository.EmbeddingRepository().RecordMigrationError(migration.ID, err)
+			if recordErr != nil {
+				log.Error().
+					Err(recordErr).
+					Str("source", "reembed_cron").
+					Int32("migration_id", migration.ID).
+					Msg("failed to record migration error")
+				continue
+			}
+
+			log.Debug().
+				Str("source", "reembed_cron").
+				Int32("migration_id", migration.ID).
+				Str("status", string(status)).
+				Msg("recorded migration error")
+		}
+	}
+}
+
+func (c *Cron) runEmbeddingMigrationBatch(migration *models.EmbeddingMigration) error {
+	batchSize := c.config.LLM.ReembedBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultReembedBatchSize
+	}
+
+	chunks, err := c.repository.EmbeddingRepository().FindMigrationChunks(migration, int32(batchSize))
+	if err != nil {
+		return err
+	}
+
+	if len(chunks) == 0 {
+		return c.repository.EmbeddingRepository().CompleteMigration(migration)
+	}
+
+	texts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		texts = append(texts, chunk.Content)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ReembedBatchTimeout)
+	defer cancel()
+
+	vectors, err := c.llm.GenerateEmbeddings(ctx, migration.ToModel, texts)
+	if err != nil {
+		return err
+	}
+
+	if len(vectors) != len(chunks) {
+		return fmt.Errorf("expected %d embeddings, got %d", len(chunks), len(vectors))
+	}
+
+	return c.repository.EmbeddingRepository().SaveMigrationBatch(&repository.SaveEmbeddingMigrationBatchArgs{
+		MigrationID: migration.ID,
+		Chunks:      chunks,
+		Vectors:     vectors,
+	})
+}
+
+// evictEmbeddingCache keeps the embedding cache under its size limit and reports its hit rate
+func (c *Cron) evictEmbeddingCache() {
+	cache := c.llm.EmbeddingCache()
+
+	evicted, err := cache.Evict()
+	if err != nil {
+		log.Error().Err(err).Str("source", "embedding_cache_cron").Msg("failed to evict cached embeddings")
+		return
+	}
+
+	stats := cache.Stats()
+	log.Info().
+		Str("source", "embedding_cache_cron").
+		Int64("evicted", evicted).
+		Int64("hits", stats.Hits).
+		Int64("misses", stats.Misses).
+		Msg("evicted cached embeddings")
+}
+
 func (c *Cron) isChunkQueued(id int32) bool {
 	return slices.Contains(c.queuedChunks, id)
 }
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/internal/models"
+)
+
+// ErrEmbeddingDimensions is returned when the server returns vectors that do not have the model's dimensions
+var ErrEmbeddingDimensions = errors.New("unexpected embedding dimensions")
+
+// Embedder generates the semantic vectors of texts, the vectors are returned in the same order as the texts and have the model's dimensions
+type Embedder interface {
+	Embed(ctx context.Context, model models.EmbeddingModel, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder creates the embedder configured in `driver.embedder`
+func NewEmbedder(conf *config.Config, client *openai.Client) Embedder {
+	switch conf.Drivers.Embedder {
+	case config.EmbeddingDriverHashed:
+		return NewHashedEmbedder()
+
+	default:
+		return NewOpenAIEmbedder(client)
+	}
+}
+
+// OpenAIEmbedder generates embeddings with an OpenAI-compatible server (e.g. Ollama, LocalAI, OpenAI)
+type OpenAIEmbedder struct {
+	client *openai.Client
+}
+
+func NewOpenAIEmbedder(client *openai.Client) *OpenAIEmbedder {
+	return &OpenAIEmbedder{client: client}
+}
+
+// Embed implements Embedder.
+func (o *OpenAIEmbedder) Embed(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	texts []string,
+) ([][]float32, error) {
+	response, err := o.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input:          texts,
+		Model:          openai.EmbeddingModel(model.Name),
+		EncodingFormat: openai.EmbeddingEncodingFormatFloat,
+		Dimensions:     int(model.Dimensions),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, data := range response.Data {
+		if data.Index < 0 || data.Index >= len(embeddings) {
+			continue
+		}
+
+		if model.Dimensions > 0 && len(data.Embedding) != int(model.Dimensions) {
+			return nil, fmt.Errorf(
+				"%w: expected %d, got %d",
+				ErrEmbeddingDimensions,
+				model.Dimensions,
+				len(data.Embedding),
+			)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	// A vector missing from the response would otherwise be stored or searched as if the text had no embedding
+	for i := range embeddings {
+		if len(embeddings[i]) == 0 {
+			return nil, fmt.Errorf("%w: text %d of %d", ErrMissingEmbedding, i+1, len(texts))
+		}
+	}
+
+	return embeddings, nil
+}
+
+var _ Embedder = (*OpenAIEmbedder)(nil)
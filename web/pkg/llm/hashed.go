@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"go.trulyao.dev/hubble/web/internal/models"
+)
+
+const (
+	// HashedNgramSize is the size of the character n-grams, they make the embedding tolerant to typos and inflections (e.g. "search" and "searching")
+	HashedNgramSize = 3
+
+	hashedWordWeight   = 1.0
+	hashedBigramWeight = 0.75
+	hashedNgramWeight  = 0.5
+)
+
+/*
+HashedEmbedder is a deterministic bag-of-ngrams embedder that runs in-process.
+
+Every word, pair of consecutive words and character trigram of the text is hashed into one of the model's dimensions (the feature hashing trick), with a hashed sign so that collisions cancel out instead of piling up. The vector is L2-normalised, so the cosine similarity of two texts grows with the words and word fragments they share.
+
+It does not understand synonyms like a neural model would, but it needs no network and gives stable, meaningful rankings for development, CI and air-gapped deployments.
+*/
+type HashedEmbedder struct{}
+
+func NewHashedEmbedder() *HashedEmbedder {
+	return &HashedEmbedder{}
+}
+
+// Embed implements Embedder.
+func (h *HashedEmbedder) Embed(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	texts []string,
+) ([][]float32, error) {
+	dimensions := int(model.Dimensions)
+	if dimensions <= 0 {
+		dimensions = DefaultDimensions
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		embeddings = append(embeddings, HashedEmbedding(text, dimensions))
+	}
+
+	return embeddings, nil
+}
+
+// HashedEmbedding returns the normalised hashed bag-of-ngrams vector of a text
+func HashedEmbedding(text string, dimensions int) []float32 {
+	vector := make([]float32, dimensions)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for i, word := range words {
+		addHashedFeature(vector, "w:"+word, hashedWordWeight)
+
+		if i > 0 {
+			addHashedFeature(vector, "b:"+words[i-1]+" "+word, hashedBigramWeight)
+		}
+
+		// The word boundaries are part of the n-grams, so prefixes and suffixes are told apart
+		runes := []rune("^" + word + "$")
+		for start := 0; start+HashedNgramSize <= len(runes); start++ {
+			addHashedFeature(vector, "n:"+string(runes[start:start+HashedNgramSize]), hashedNgramWeight)
+		}
+	}
+
+	// The cosine distance to a zero vector is undefined, texts without words (e.g. only punctuation) are hashed as a whole instead
+	if len(words) == 0 {
+		addHashedFeature(vector, "t:"+text, hashedWordWeight)
+	}
+
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+
+	return vector
+}
+
+func addHashedFeature(vector []float32, feature string, weight float32) {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(feature))
+	sum := hasher.Sum64()
+
+	index := sum % uint64(len(vector))
+	if (sum>>63)&1 == 1 {
+		weight = -weight
+	}
+
+	vector[index] += weight
+}
+
+var _ Embedder = (*HashedEmbedder)(nil)
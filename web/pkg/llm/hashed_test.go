@@ -0,0 +1,92 @@
+package llm_test
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func Test_HashedEmbedding(t *testing.T) {
+	query := llm.HashedEmbedding("how to bake sourdough bread", 256)
+
+	if !slices.Equal(query, llm.HashedEmbedding("how to bake sourdough bread", 256)) {
+		t.Errorf("expected the embedding to be deterministic")
+	}
+
+	if len(query) != 256 {
+		t.Fatalf("expected 256 dimensions, got %d", len(query))
+	}
+
+	if norm := dot(query, query); math.Abs(norm-1) > 1e-5 {
+		t.Errorf("expected a normalised vector, got a norm of %f", norm)
+	}
+
+	related := llm.HashedEmbedding("Baking bread with a sourdough starter", 256)
+	unrelated := llm.HashedEmbedding("Quarterly revenue grew in the European market", 256)
+	if dot(query, related) <= dot(query, unrelated) {
+		t.Errorf(
+			"expected the related text to be closer (%f) than the unrelated text (%f)",
+			dot(query, related),
+			dot(query, unrelated),
+		)
+	}
+
+	if punctuation := llm.HashedEmbedding("...", 256); dot(punctuation, punctuation) == 0 {
+		t.Errorf("expected a non-zero vector for a text without words")
+	}
+}
+
+func Test_HashedEmbedder(t *testing.T) {
+	// No server is configured, the hashed driver must not make any request
+	//nolint:exhaustruct
+	conf := &config.Config{}
+	conf.Drivers.Embedder = config.EmbeddingDriverHashed
+	conf.LLM.EmbeddingsModel = config.DefaultHashedEmbeddingModel
+	conf.LLM.EmbeddingDimensions = 128
+
+	service, err := llm.NewService(conf, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	model := service.EmbeddingModel()
+	vector, err := service.GenerateEmbedding(context.Background(), model, "apple orchards")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(vector) != 128 {
+		t.Errorf("expected 128 dimensions, got %d", len(vector))
+	}
+
+	//nolint:exhaustruct
+	results := []models.CollapsedSearchResult{
+		{
+			Matches: []models.MatchedChunk{
+				{Text: "Bananas are yellow. Apple orchards need pruning.", SemanticScore: 0.4},
+			},
+		},
+	}
+
+	if err := service.SemanticSnippets(context.Background(), model, vector, results); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if snippet := results[0].Matches[0].Snippet; !strings.Contains(snippet, "Apple orchards") {
+		t.Errorf("expected the apple sentence to be picked, got %q", snippet)
+	}
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -5,40 +5,143 @@ import (
 
 	openai "github.com/sashabaranov/go-openai"
 	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/internal/kv"
+	"go.trulyao.dev/hubble/web/internal/models"
 	"go.trulyao.dev/hubble/web/internal/repository"
 )
 
 type LLM struct {
 	config *config.LLM
 	client *openai.Client
+
+	embedder Embedder
+	cache    *EmbeddingCache
+
+	reranker      Reranker
+	rerankOptions RerankOptions
+
+	batcher *EmbeddingBatcher
 }
 
+// DefaultDimensions is the number of dimensions requested from the embedding model if none has been configured
 const DefaultDimensions = 768
 
-func NewService(conf *config.Config, repo repository.Repository) (*LLM, error) {
+func NewService(
+	conf *config.Config,
+	repo repository.Repository,
+	store kv.Store,
+) (*LLM, error) {
 	clientConfig := openai.DefaultConfig(conf.LLM.ApiKey)
 	clientConfig.BaseURL = conf.LLM.BaseURL
 
-	return &LLM{
-		config: &conf.LLM,
-		client: openai.NewClientWithConfig(clientConfig),
-	}, nil
-}
+	client := openai.NewClientWithConfig(clientConfig)
 
-func (l *LLM) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
-	response, err := l.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
-		Input:          []string{text},
-		Model:          openai.EmbeddingModel(l.config.EmbeddingsModel),
-		EncodingFormat: openai.EmbeddingEncodingFormatFloat,
-		Dimensions:     DefaultDimensions,
+	l := &LLM{
+		config:   &conf.LLM,
+		client:   client,
+		embedder: NewEmbedder(conf, client),
+		cache:    NewEmbeddingCache(conf, repo, store),
+		reranker: NewReranker(conf, client),
+		rerankOptions: RerankOptions{
+			TopN:    conf.Search.Reranker.TopN,
+			Timeout: conf.Search.Reranker.Timeout,
+		},
+		batcher: nil,
+	}
+
+	l.batcher = NewEmbeddingBatcher(l.GenerateEmbeddings, EmbeddingBatcherOptions{
+		MaxBatchSize:   conf.LLM.EmbeddingBatchSize,
+		MaxBatchTokens: conf.LLM.EmbeddingBatchTokens,
+		FlushInterval:  conf.LLM.EmbeddingFlushInterval,
 	})
+
+	return l, nil
+}
+
+// RerankEnabled returns true if a second-stage reranker has been configured
+func (l *LLM) RerankEnabled() bool {
+	return l.reranker != nil
+}
+
+// Rerank reorders the top results with the configured reranker, the results are returned as-is if reranking is disabled or fails
+func (l *LLM) Rerank(
+	ctx context.Context,
+	query string,
+	results []models.CollapsedSearchResult,
+) []models.CollapsedSearchResult {
+	return RerankResults(ctx, l.reranker, l.rerankOptions, query, results)
+}
+
+// EmbeddingModel returns the configured embedding model, workspaces that were embedded with another model keep using it until they are re-embedded
+func (l *LLM) EmbeddingModel() models.EmbeddingModel {
+	dimensions := l.config.EmbeddingDimensions
+	if dimensions <= 0 {
+		dimensions = DefaultDimensions
+	}
+
+	return models.EmbeddingModel{
+		Name:       l.config.EmbeddingsModel,
+		Dimensions: int32(dimensions),
+	}
+}
+
+// GenerateEmbedding generates the embedding of a text with the given model, vectors are only comparable with vectors from the same model
+func (l *LLM) GenerateEmbedding(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	text string,
+) ([]float32, error) {
+	if cached, found := l.cache.Get(model, text); found {
+		return cached, nil
+	}
+
+	embeddings, err := l.embedder.Embed(ctx, model, []string{text})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(response.Data) == 0 {
-		return []float32{}, nil
+	if len(embeddings) == 0 || len(embeddings[0]) == 0 {
+		return nil, ErrMissingEmbedding
+	}
+
+	l.cache.Set(model, text, embeddings[0])
+	return embeddings[0], nil
+}
+
+// EmbedChunk embeds a chunk as part of a batch with the other chunks being embedded at the same time, this is preferred over `GenerateEmbedding` for background jobs
+func (l *LLM) EmbedChunk(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	text string,
+) ([]float32, error) {
+	// Requeued entries and identical chunks do not need to be embedded again
+	if cached, found := l.cache.Get(model, text); found {
+		return cached, nil
+	}
+
+	embedding, err := l.batcher.Embed(ctx, model, text)
+	if err != nil {
+		return nil, err
+	}
+
+	l.cache.Set(model, text, embedding)
+	return embedding, nil
+}
+
+// EmbeddingCache returns the embedding cache, nil is returned if caching is disabled
+func (l *LLM) EmbeddingCache() *EmbeddingCache {
+	return l.cache
+}
+
+// GenerateEmbeddings generates the embeddings for multiple texts in a single request, the embeddings are returned in the same order as the texts
+func (l *LLM) GenerateEmbeddings(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	texts []string,
+) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
 	}
 
-	return response.Data[0].Embedding, nil
+	return l.embedder.Embed(ctx, model, texts)
 }
@@ -0,0 +1,154 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func Test_EmbeddingModel(t *testing.T) {
+	tests := []struct {
+		name       string
+		dimensions int
+		expected   models.EmbeddingModel
+	}{
+		{
+			name:       "configured dimensions",
+			dimensions: 1024,
+			expected:   models.EmbeddingModel{Name: "embedding-model", Dimensions: 1024},
+		},
+		{
+			name:       "default dimensions",
+			dimensions: 0,
+			expected: models.EmbeddingModel{
+				Name:       "embedding-model",
+				Dimensions: llm.DefaultDimensions,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			//nolint:exhaustruct
+			conf := &config.Config{}
+			conf.LLM.EmbeddingsModel = "embedding-model"
+			conf.LLM.EmbeddingDimensions = test.dimensions
+
+			service, err := llm.NewService(conf, nil, nil)
+			if err != nil {
+				t.Fatalf("failed to create service: %v", err)
+			}
+
+			if model := service.EmbeddingModel(); !model.Equal(test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, model)
+			}
+		})
+	}
+}
+
+func Test_GenerateEmbedding(t *testing.T) {
+	var request struct {
+		Model      string `json:"model"`
+		Dimensions int    `json:"dimensions"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"object": "list",
+			"data": []map[string]any{
+				{
+					"object":    "embedding",
+					"index":     0,
+					"embedding": make([]float32, request.Dimensions),
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	//nolint:exhaustruct
+	conf := &config.Config{}
+	conf.LLM.BaseURL = server.URL
+	conf.LLM.EmbeddingsModel = "new-model"
+
+	service, err := llm.NewService(conf, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	// Workspaces that have not been re-embedded yet keep using their previous model
+	previous := models.EmbeddingModel{Name: "old-model", Dimensions: 384}
+	if _, err := service.GenerateEmbedding(context.Background(), previous, "query"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if request.Model != "old-model" || request.Dimensions != 384 {
+		t.Errorf(
+			"expected the workspace's model to be used, got %q with %d dimensions",
+			request.Model,
+			request.Dimensions,
+		)
+	}
+}
+
+func Test_GenerateEmbedding_InvalidResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []map[string]any
+		expected error
+	}{
+		{
+			name:     "missing embedding",
+			data:     []map[string]any{},
+			expected: llm.ErrMissingEmbedding,
+		},
+		{
+			name: "wrong dimensions",
+			data: []map[string]any{
+				{"object": "embedding", "index": 0, "embedding": []float32{1, 0}},
+			},
+			expected: llm.ErrEmbeddingDimensions,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{"object": "list", "data": test.data})
+			}
+			server := httptest.NewServer(http.HandlerFunc(handler))
+			defer server.Close()
+
+			//nolint:exhaustruct
+			conf := &config.Config{}
+			conf.LLM.BaseURL = server.URL
+			conf.LLM.EmbeddingsModel = "embedding-model"
+
+			service, err := llm.NewService(conf, nil, nil)
+			if err != nil {
+				t.Fatalf("failed to create service: %v", err)
+			}
+
+			model := models.EmbeddingModel{Name: "embedding-model", Dimensions: 384}
+			vector, err := service.GenerateEmbedding(context.Background(), model, "query")
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, err)
+			}
+			if vector != nil {
+				t.Errorf("expected no vector, got %v", vector)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	openai "github.com/sashabaranov/go-openai"
+	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/seer"
+)
+
+const (
+	DefaultRerankTopN    = 20
+	DefaultRerankTimeout = 3 * time.Second
+
+	// MaxRerankDocumentLength is the maximum number of characters of a document sent to the reranker
+	MaxRerankDocumentLength = 2048
+)
+
+var ErrInvalidRerankResponse = errors.New("invalid rerank response")
+
+// Reranker scores documents against a query, the returned scores are in the same order as the documents and higher is more relevant
+type Reranker interface {
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+}
+
+type RerankOptions struct {
+	// TopN is the number of results sent to the reranker, the rest keep their original order after the reranked ones
+	TopN int
+
+	// Timeout is the time budget for the rerank request
+	Timeout time.Duration
+}
+
+// NewReranker creates the reranker configured in `search.rerank`, nil is returned if reranking is disabled
+func NewReranker(conf *config.Config, client *openai.Client) Reranker {
+	rerankConfig := conf.Search.Reranker
+	if !rerankConfig.Enabled() {
+		return nil
+	}
+
+	switch rerankConfig.Driver {
+	case config.RerankDriverApi:
+		baseURL := rerankConfig.BaseURL
+		if baseURL == "" {
+			baseURL = conf.LLM.BaseURL
+		}
+
+		apiKey := rerankConfig.ApiKey
+		if apiKey == "" {
+			apiKey = conf.LLM.ApiKey
+		}
+
+		return NewAPIReranker(baseURL, apiKey, rerankConfig.Model)
+
+	case config.RerankDriverChat:
+		return NewChatReranker(client, rerankConfig.Model)
+
+	default:
+		return nil
+	}
+}
+
+// RerankResults reorders the top N collapsed results using the reranker, the original order is returned if the reranker fails or runs out of time
+func RerankResults(
+	ctx context.Context,
+	reranker Reranker,
+	opts RerankOptions,
+	query string,
+	results []models.CollapsedSearchResult,
+) []models.CollapsedSearchResult {
+	if reranker == nil || len(results) < 2 {
+		return results
+	}
+
+	if opts.TopN <= 0 {
+		opts.TopN = DefaultRerankTopN
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultRerankTimeout
+	}
+
+	n := min(opts.TopN, len(results))
+	documents := make([]string, 0, n)
+	for i := range n {
+		documents = append(documents, rerankDocument(&results[i]))
+	}
+
+	rerankCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	scores, err := reranker.Rerank(rerankCtx, query, documents)
+	if err == nil && len(scores) != n {
+		err = fmt.Errorf(
+			"%w: expected %d scores, got %d",
+			ErrInvalidRerankResponse,
+			n,
+			len(scores),
+		)
+	}
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("source", "reranker").
+			Msg("failed to rerank results, falling back to the original order")
+		return results
+	}
+
+	reranked := make([]models.CollapsedSearchResult, n, len(results))
+	copy(reranked, results[:n])
+	for i := range reranked {
+		reranked[i].RerankScore = scores[i]
+	}
+
+	slices.SortStableFunc(reranked, func(a, b models.CollapsedSearchResult) int {
+		switch {
+		case a.RerankScore > b.RerankScore:
+			return -1
+		case a.RerankScore < b.RerankScore:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return append(reranked, results[n:]...)
+}
+
+// rerankDocument builds the text sent to the reranker from the entry's name and its best matching chunk
+func rerankDocument(result *models.CollapsedSearchResult) string {
+	var sb strings.Builder
+	sb.WriteString(result.Name)
+
+	if len(result.Matches) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(result.Matches[0].Text)
+	}
+
+	document := []rune(sb.String())
+	if len(document) > MaxRerankDocumentLength {
+		document = document[:MaxRerankDocumentLength]
+	}
+
+	return string(document)
+}
+
+// MARK: API reranker
+
+type (
+	apiReranker struct {
+		baseURL string
+		apiKey  string
+		model   string
+		client  *http.Client
+	}
+
+	apiRerankRequest struct {
+		Model     string   `json:"model"`
+		Query     string   `json:"query"`
+		Documents []string `json:"documents"`
+		TopN      int      `json:"top_n"`
+	}
+
+	apiRerankResponse struct {
+		Results []struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		} `json:"results"`
+	}
+)
+
+// NewAPIReranker creates a reranker that calls a Cohere/Jina-compatible `/rerank` endpoint
+func NewAPIReranker(baseURL, apiKey, model string) Reranker {
+	return &apiReranker{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		client:  &http.Client{}, //nolint:exhaustruct
+	}
+}
+
+func (a *apiReranker) Rerank(
+	ctx context.Context,
+	query string,
+	documents []string,
+) ([]float64, error) {
+	body, err := json.Marshal(apiRerankRequest{
+		Model:     a.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      len(documents),
+	})
+	if err != nil {
+		return nil, seer.Wrap("marshal_rerank_request", err)
+	}
+
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		a.baseURL+"/rerank",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, seer.Wrap("create_rerank_request", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if a.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	response, err := a.client.Do(request)
+	if err != nil {
+		return nil, seer.Wrap("send_rerank_request", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"%w: unexpected status code %d",
+			ErrInvalidRerankResponse,
+			response.StatusCode,
+		)
+	}
+
+	var result apiRerankResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, seer.Wrap("decode_rerank_response", err)
+	}
+
+	scores := make([]float64, len(documents))
+	for _, r := range result.Results {
+		if r.Index < 0 || r.Index >= len(scores) {
+			return nil, fmt.Errorf("%w: index %d out of range", ErrInvalidRerankResponse, r.Index)
+		}
+		scores[r.Index] = r.RelevanceScore
+	}
+
+	return scores, nil
+}
+
+// MARK: Chat reranker
+
+const chatRerankPrompt = `You are a search relevance judge. Given a search query and a numbered list of documents, rate how relevant each document is to the query on a scale from 0 (irrelevant) to 10 (perfect match).
+
+Respond ONLY with a JSON object in the form {"scores": [<score for document 0>, <score for document 1>, ...]} with exactly one score per document, in the same order as the documents.`
+
+type chatReranker struct {
+	client *openai.Client
+	model  string
+}
+
+// NewChatReranker creates a reranker that asks a chat-completion model to score the documents
+func NewChatReranker(client *openai.Client, model string) Reranker {
+	return &chatReranker{client: client, model: model}
+}
+
+func (c *chatReranker) Rerank(
+	ctx context.Context,
+	query string,
+	documents []string,
+) ([]float64, error) {
+	var sb strings.Builder
+	sb.WriteString("Query: ")
+	sb.WriteString(query)
+	sb.WriteString("\n\nDocuments:\n")
+	for i, document := range documents {
+		fmt.Fprintf(&sb, "[%d] %s\n\n", i, strings.ReplaceAll(document, "\n", " "))
+	}
+
+	//nolint:exhaustruct
+	response, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       c.model,
+		Temperature: 0,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: chatRerankPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: sb.String()},
+		},
+	})
+	if err != nil {
+		return nil, seer.Wrap("create_rerank_chat_completion", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("%w: no choices returned", ErrInvalidRerankResponse)
+	}
+
+	return parseChatScores(response.Choices[0].Message.Content)
+}
+
+// parseChatScores extracts the scores from the model's response, models tend to wrap JSON in prose or code fences so we only look at the outermost object
+func parseChatScores(content string) ([]float64, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start == -1 || end <= start {
+		return nil, fmt.Errorf("%w: no JSON object found", ErrInvalidRerankResponse)
+	}
+
+	var result struct {
+		Scores []float64 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &result); err != nil {
+		return nil, seer.Wrap("decode_chat_rerank_scores", err)
+	}
+
+	return result.Scores, nil
+}
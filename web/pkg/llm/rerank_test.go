@@ -0,0 +1,171 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func collapsedResults(names ...string) []models.CollapsedSearchResult {
+	results := make([]models.CollapsedSearchResult, 0, len(names))
+	for _, name := range names {
+		//nolint:exhaustruct
+		results = append(results, models.CollapsedSearchResult{
+			Name:    name,
+			Matches: []models.MatchedChunk{{Text: "content of " + name}}, //nolint:exhaustruct
+		})
+	}
+
+	return results
+}
+
+func names(results []models.CollapsedSearchResult) []string {
+	out := make([]string, 0, len(results))
+	for _, result := range results {
+		out = append(out, result.Name)
+	}
+
+	return out
+}
+
+func assertOrder(t *testing.T, results []models.CollapsedSearchResult, want ...string) {
+	t.Helper()
+
+	got := names(results)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d results, got %d (%v)", len(want), len(got), got)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+}
+
+func Test_APIReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		var body struct {
+			Query     string   `json:"query"`
+			Documents []string `json:"documents"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		if body.Query != "hubble" || len(body.Documents) != 2 {
+			t.Errorf("unexpected request body: %+v", body)
+		}
+
+		_, _ = w.Write(
+			[]byte(`{"results":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.1}]}`),
+		)
+	}))
+	defer server.Close()
+
+	reranker := llm.NewAPIReranker(server.URL, "secret", "rerank-model")
+	results := llm.RerankResults(
+		context.Background(),
+		reranker,
+		llm.RerankOptions{TopN: 2, Timeout: time.Second},
+		"hubble",
+		collapsedResults("a", "b", "c"),
+	)
+
+	assertOrder(t, results, "b", "a", "c")
+	if results[0].RerankScore != 0.9 {
+		t.Errorf("expected rerank score 0.9, got %f", results[0].RerankScore)
+	}
+}
+
+func Test_ChatReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"choices": [{
+				"index": 0,
+				"message": {"role": "assistant", "content": "Sure! {\"scores\": [2, 7, 9]}"},
+				"finish_reason": "stop"
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	clientConfig := openai.DefaultConfig("secret")
+	clientConfig.BaseURL = server.URL
+
+	reranker := llm.NewChatReranker(openai.NewClientWithConfig(clientConfig), "chat-model")
+	results := llm.RerankResults(
+		context.Background(),
+		reranker,
+		llm.RerankOptions{TopN: 3, Timeout: time.Second},
+		"hubble",
+		collapsedResults("a", "b", "c"),
+	)
+
+	assertOrder(t, results, "c", "b", "a")
+}
+
+func Test_RerankResults_Fallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+		},
+		{
+			name: "timeout exceeded",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+				_, _ = w.Write([]byte(`{"results":[{"index":1,"relevance_score":1}]}`))
+			},
+		},
+		{
+			name: "missing scores",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"results":[{"index":5,"relevance_score":1}]}`))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			results := llm.RerankResults(
+				context.Background(),
+				llm.NewAPIReranker(server.URL, "", "rerank-model"),
+				llm.RerankOptions{TopN: 3, Timeout: 50 * time.Millisecond},
+				"hubble",
+				collapsedResults("a", "b", "c"),
+			)
+
+			assertOrder(t, results, "a", "b", "c")
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/jonathanhecl/chunker"
+	"go.trulyao.dev/hubble/web/internal/models"
+)
+
+const (
+	// MaxSnippetResults is the number of top results that get a semantic snippet, every snippet requires the sentences of the chunk to be embedded
+	MaxSnippetResults = 10
+
+	// MaxSnippetSentences is the maximum number of sentences embedded for a single search
+	MaxSnippetSentences = 128
+)
+
+type snippetTarget struct {
+	result    int
+	match     int
+	sentences []string
+	offset    int // offset of the first sentence in the batch
+}
+
+/*
+SemanticSnippets picks the sentence that is the closest to the query as the snippet for semantic-only matches.
+
+Full-text matches already have a highlighted snippet from `ts_headline`, so only the matches without a snippet in the top `MaxSnippetResults` results are considered. The chunk is split into sentences the same way the `chunk_by_sentence` host function does and all the sentences are embedded in a single request, with the model that generated the query vector.
+*/
+func (l *LLM) SemanticSnippets(
+	ctx context.Context,
+	model models.EmbeddingModel,
+	queryVector []float32,
+	results []models.CollapsedSearchResult,
+) error {
+	if len(queryVector) == 0 {
+		return nil
+	}
+
+	var (
+		targets   []snippetTarget
+		sentences []string
+	)
+
+collect:
+	for i := range min(len(results), MaxSnippetResults) {
+		for j := range results[i].Matches {
+			match := &results[i].Matches[j]
+			if match.Snippet != "" || match.SemanticScore == 0 {
+				continue
+			}
+
+			chunkSentences := SplitSentences(match.Text)
+			if len(chunkSentences) == 0 {
+				continue
+			}
+
+			if len(sentences)+len(chunkSentences) > MaxSnippetSentences {
+				break collect
+			}
+
+			targets = append(targets, snippetTarget{
+				result:    i,
+				match:     j,
+				sentences: chunkSentences,
+				offset:    len(sentences),
+			})
+			sentences = append(sentences, chunkSentences...)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	embeddings, err := l.GenerateEmbeddings(ctx, model, sentences)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		best, bestScore := -1, math.Inf(-1)
+		for k := range target.sentences {
+			embedding := embeddings[target.offset+k]
+			if len(embedding) == 0 {
+				continue
+			}
+
+			if score := CosineSimilarity(queryVector, embedding); score > bestScore {
+				best, bestScore = k, score
+			}
+		}
+
+		if best == -1 {
+			continue
+		}
+
+		match := &results[target.result].Matches[target.match]
+		match.Snippet = target.sentences[best]
+		match.Highlights = []models.Highlight{}
+	}
+
+	return nil
+}
+
+// SplitSentences splits the text into trimmed, non-empty sentences
+func SplitSentences(text string) []string {
+	sentences := make([]string, 0)
+	for _, sentence := range chunker.ChunkSentences(text) {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+
+	return sentences
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, 0 is returned if they have different dimensions or either of them is empty
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
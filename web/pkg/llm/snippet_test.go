@@ -0,0 +1,74 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func Test_SemanticSnippets(t *testing.T) {
+	// Sentences about apples point in the same direction as the query, everything else is orthogonal
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		data := make([]map[string]any, 0, len(body.Input))
+		for i, input := range body.Input {
+			embedding := []float32{0, 1}
+			if strings.Contains(strings.ToLower(input), "apple") {
+				embedding = []float32{1, 0}
+			}
+			data = append(data, map[string]any{"object": "embedding", "index": i, "embedding": embedding})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"object": "list", "data": data})
+	}))
+	defer server.Close()
+
+	//nolint:exhaustruct
+	conf := &config.Config{}
+	conf.LLM.BaseURL = server.URL
+	conf.LLM.EmbeddingsModel = "embedding-model"
+
+	service, err := llm.NewService(conf, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	//nolint:exhaustruct
+	results := []models.CollapsedSearchResult{
+		{
+			Matches: []models.MatchedChunk{
+				{Text: "Bananas are yellow. Apples grow on trees.", SemanticScore: 0.4},
+				{Text: "A full-text match.", Snippet: "A full-text match.", SemanticScore: 0.2},
+			},
+		},
+	}
+
+	// The server returns vectors with two dimensions
+	model := models.EmbeddingModel{Name: "embedding-model", Dimensions: 2}
+	err = service.SemanticSnippets(context.Background(), model, []float32{1, 0}, results)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if snippet := results[0].Matches[0].Snippet; !strings.Contains(snippet, "Apples grow on trees") {
+		t.Errorf("expected the apple sentence to be picked, got %q", snippet)
+	}
+
+	if snippet := results[0].Matches[1].Snippet; snippet != "A full-text match." {
+		t.Errorf("expected the existing snippet to be kept, got %q", snippet)
+	}
+}
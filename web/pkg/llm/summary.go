@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.trulyao.dev/hubble/web/internal/models"
+	"go.trulyao.dev/seer"
+)
+
+const (
+	// SectionSummaryTokens is the maximum length of the summary of a section of an entry
+	SectionSummaryTokens = 300
+	// LongSummaryTokens is the maximum length of the long summary of an entry
+	LongSummaryTokens = 600
+	// ShortSummaryTokens is the maximum length of the short summary of an entry
+	ShortSummaryTokens = 80
+)
+
+var ErrNothingToSummarise = errors.New("entry has no content to summarise")
+
+const sectionSummaryPrompt = `You summarise a section of a document from the user's knowledge base.
+
+Write a dense summary of the section that keeps its key facts, names, numbers and conclusions. Only use the provided text, do not add any outside knowledge or commentary.`
+
+const longSummaryPrompt = `You summarise a document from the user's knowledge base. The text is either the document itself or the summaries of its sections in order.
+
+Write a summary of a few short paragraphs covering the main points of the document. Only use the provided text, do not add any outside knowledge or commentary and do not mention that the text was summarised.`
+
+const shortSummaryPrompt = `You write the short description of a document from the user's knowledge base using its summary.
+
+Describe what the document is about in one or two sentences. Do not start with "This document" and do not add any outside knowledge.`
+
+/*
+Summarise map-reduces the chunks of an entry into a short and a long summary.
+
+The chunks are packed into sections that fit in the context budget and every section is summarised (map), the section summaries are packed and summarised again until they fit in a single prompt (reduce). The long summary is generated from the last sections and the short summary from the long one. An entry that fits in a single prompt is summarised directly.
+*/
+func (l *LLM) Summarise(
+	ctx context.Context,
+	title string,
+	chunks []string,
+) (*models.EntrySummary, error) {
+	if !l.ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+
+	budget := l.maxContextTokens()
+	sections := GroupSections(chunks, budget)
+	if len(sections) == 0 {
+		return nil, ErrNothingToSummarise
+	}
+
+	for len(sections) > 1 {
+		summaries := make([]string, 0, len(sections))
+		for _, section := range sections {
+			summary, err := l.summarise(ctx, sectionSummaryPrompt, title, section, SectionSummaryTokens)
+			if err != nil {
+				return nil, seer.Wrap("summarise_entry_section", err)
+			}
+
+			summaries = append(summaries, summary)
+		}
+
+		reduced := GroupSections(summaries, budget)
+
+		// The summaries are always shorter than a full section, this only guards against a budget that is too small to hold two of them
+		if len(reduced) >= len(sections) {
+			return nil, seer.New("summarise_entry", "the context budget is too small to summarise the entry")
+		}
+
+		sections = reduced
+	}
+
+	long, err := l.summarise(ctx, longSummaryPrompt, title, sections[0], LongSummaryTokens)
+	if err != nil {
+		return nil, seer.Wrap("summarise_entry_long", err)
+	}
+
+	short, err := l.summarise(ctx, shortSummaryPrompt, title, long, ShortSummaryTokens)
+	if err != nil {
+		return nil, seer.Wrap("summarise_entry_short", err)
+	}
+
+	return &models.EntrySummary{Short: short, Long: long, Version: 0}, nil
+}
+
+func (l *LLM) summarise(
+	ctx context.Context,
+	prompt string,
+	title string,
+	text string,
+	maxTokens int,
+) (string, error) {
+	//nolint:exhaustruct
+	response, err := l.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       l.config.ChatModel,
+		Temperature: 0,
+		MaxTokens:   maxTokens,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: prompt},
+			{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf("Title: %s\n\n%s", title, text)},
+		},
+	})
+	if err != nil {
+		return "", seer.Wrap("create_summary_chat_completion", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return "", seer.New("create_summary_chat_completion", "no choices returned")
+	}
+
+	summary := strings.TrimSpace(response.Choices[0].Message.Content)
+	if summary == "" {
+		return "", seer.New("create_summary_chat_completion", "empty summary returned")
+	}
+
+	return summary, nil
+}
+
+// GroupSections packs consecutive texts into sections of at most `maxTokens` tokens, texts that are longer than the budget on their own are truncated
+func GroupSections(texts []string, maxTokens int) []string {
+	var (
+		sections = make([]string, 0)
+		current  strings.Builder
+		tokens   int
+	)
+
+	for _, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		if EstimateTokens(text) > maxTokens {
+			text = string([]rune(text)[:maxTokens*4])
+		}
+
+		size := EstimateTokens(text)
+		if current.Len() > 0 && tokens+size > maxTokens {
+			sections = append(sections, current.String())
+			current.Reset()
+			tokens = 0
+		}
+
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+
+		current.WriteString(text)
+		tokens += size
+	}
+
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+
+	return sections
+}
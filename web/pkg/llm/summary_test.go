@@ -0,0 +1,135 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.trulyao.dev/hubble/web/internal/config"
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func Test_GroupSections(t *testing.T) {
+	tests := []struct {
+		name      string
+		texts     []string
+		maxTokens int
+		expected  []string
+	}{
+		{
+			name:      "packs texts that fit together",
+			texts:     []string{"aaaa", "bbbb", "cccc"},
+			maxTokens: 2,
+			expected:  []string{"aaaa\n\nbbbb", "cccc"},
+		},
+		{
+			name:      "skips empty texts",
+			texts:     []string{"  ", "aaaa", ""},
+			maxTokens: 10,
+			expected:  []string{"aaaa"},
+		},
+		{
+			name:      "truncates texts over the budget",
+			texts:     []string{"aaaabbbbcccc", "dddd"},
+			maxTokens: 2,
+			expected:  []string{"aaaabbbb", "dddd"},
+		},
+		{
+			name:      "returns no sections without content",
+			texts:     []string{},
+			maxTokens: 10,
+			expected:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sections := llm.GroupSections(test.texts, test.maxTokens)
+			if !slices.Equal(sections, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, sections)
+			}
+		})
+	}
+}
+
+func Test_Summarise(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		content := "The section covers sourdough."
+		switch prompt := body.Messages[0].Content; {
+		case strings.Contains(prompt, "one or two sentences"):
+			content = "A guide to baking sourdough bread."
+		case strings.Contains(prompt, "a few short paragraphs"):
+			content = "The guide covers starters, proofing and baking."
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"object": "chat.completion",
+			"choices": []map[string]any{
+				{
+					"index":         0,
+					"finish_reason": "stop",
+					"message":       map[string]any{"role": "assistant", "content": content},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	//nolint:exhaustruct
+	conf := &config.Config{}
+	conf.LLM.BaseURL = server.URL
+	conf.LLM.ChatModel = "chat-model"
+	conf.LLM.MaxContextTokens = 100
+
+	service, err := llm.NewService(conf, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	// Every chunk fills more than half of the budget, so each one is summarised on its own
+	chunks := []string{
+		strings.Repeat("starter ", 30),
+		strings.Repeat("proofing ", 30),
+		strings.Repeat("baking ", 30),
+	}
+
+	summary, err := service.Summarise(context.Background(), "Sourdough", chunks)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if summary.Short != "A guide to baking sourdough bread." {
+		t.Errorf("unexpected short summary: %q", summary.Short)
+	}
+
+	if summary.Long != "The guide covers starters, proofing and baking." {
+		t.Errorf("unexpected long summary: %q", summary.Long)
+	}
+
+	// 3 section summaries, then the long and the short summaries
+	if count := requests.Load(); count != 5 {
+		t.Errorf("expected 5 requests, got %d", count)
+	}
+
+	if _, err := service.Summarise(context.Background(), "Empty", []string{""}); err == nil {
+		t.Errorf("expected an error for an entry without content")
+	}
+}
@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.trulyao.dev/seer"
+)
+
+const (
+	// MaxTagSuggestions is the maximum number of tags suggested for an entry
+	MaxTagSuggestions = 5
+	// MaxRejectedTagExamples is the maximum number of rejected suggestions sent as negative examples
+	MaxRejectedTagExamples = 20
+	// MaxTagNameLength matches the maximum length of a tag's name
+	MaxTagNameLength = 72
+
+	tagSuggestionTokens = 300
+)
+
+var ErrInvalidTagSuggestions = errors.New("invalid tag suggestions returned by the chat model")
+
+const tagSuggestionPrompt = `You classify documents from the user's knowledge base with tags.
+
+Suggest at most %d tags that describe what the document is about, with a confidence between 0 and 1 for each of them. Strongly prefer the existing tags of the collection, only suggest a new tag if none of them fits and keep new tags short (one to three lowercase words). Do not suggest tags the document already has, and do not suggest tags similar to the rejected examples for similar documents.
+
+Respond ONLY with a JSON object in the form {"tags": [{"name": "<tag>", "confidence": <0 to 1>}, ...]}, the object may have an empty list if no tag fits.`
+
+type (
+	// RejectedTag is a tag suggestion that was rejected by a user, it is sent as a negative example
+	RejectedTag struct {
+		EntryName string
+		Tag       string
+	}
+
+	TagSuggestionInput struct {
+		Title string
+		// Text is the summary of the entry, or its first chunks if it has not been summarised
+		Text string
+		// Vocabulary is the names of the existing tags of the entry's collection
+		Vocabulary []string
+		// Assigned is the names of the tags the entry already has
+		Assigned []string
+		Rejected []RejectedTag
+	}
+
+	// SuggestedTag is a tag suggested by the chat model, the name matches the casing of the vocabulary if it is an existing tag
+	SuggestedTag struct {
+		Name       string
+		Confidence float64
+	}
+)
+
+// SuggestTags asks the chat model to classify an entry with the tags of its collection (or new ones), the suggestions are ordered by confidence
+func (l *LLM) SuggestTags(ctx context.Context, input *TagSuggestionInput) ([]SuggestedTag, error) {
+	if !l.ChatEnabled() {
+		return nil, ErrChatDisabled
+	}
+
+	text := GroupSections([]string{input.Text}, l.maxContextTokens())
+	if len(text) == 0 {
+		return nil, ErrNothingToSummarise
+	}
+	input.Text = text[0]
+
+	//nolint:exhaustruct
+	response, err := l.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       l.config.ChatModel,
+		Temperature: 0,
+		MaxTokens:   tagSuggestionTokens,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: fmt.Sprintf(tagSuggestionPrompt, MaxTagSuggestions),
+			},
+			{Role: openai.ChatMessageRoleUser, Content: BuildTagSuggestionPrompt(input)},
+		},
+	})
+	if err != nil {
+		return nil, seer.Wrap("create_tag_suggestion_chat_completion", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("%w: no choices returned", ErrInvalidTagSuggestions)
+	}
+
+	return ParseTagSuggestions(response.Choices[0].Message.Content, input)
+}
+
+// BuildTagSuggestionPrompt builds the user message with the entry, the collection's vocabulary and the rejected examples
+func BuildTagSuggestionPrompt(input *TagSuggestionInput) string {
+	var sb strings.Builder
+
+	sb.WriteString("Existing tags: ")
+	if len(input.Vocabulary) == 0 {
+		sb.WriteString("(none)")
+	} else {
+		sb.WriteString(strings.Join(input.Vocabulary, ", "))
+	}
+
+	if len(input.Assigned) > 0 {
+		sb.WriteString("\n\nTags the document already has: ")
+		sb.WriteString(strings.Join(input.Assigned, ", "))
+	}
+
+	if len(input.Rejected) > 0 {
+		sb.WriteString("\n\nRejected examples (do not suggest tags like these for similar documents):\n")
+		for _, rejected := range input.Rejected[:min(len(input.Rejected), MaxRejectedTagExamples)] {
+			fmt.Fprintf(&sb, "- %q was rejected for %q\n", rejected.Tag, rejected.EntryName)
+		}
+	}
+
+	fmt.Fprintf(&sb, "\n\nTitle: %s\n\n%s", input.Title, input.Text)
+	return sb.String()
+}
+
+/*
+ParseTagSuggestions extracts the suggestions from the model's response.
+
+Names are trimmed and matched case-insensitively against the vocabulary, duplicates and tags the entry already has are dropped and the confidences are clamped to [0, 1]. At most MaxTagSuggestions are returned, ordered by confidence.
+*/
+func ParseTagSuggestions(content string, input *TagSuggestionInput) ([]SuggestedTag, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start == -1 || end <= start {
+		return nil, fmt.Errorf("%w: no JSON object found", ErrInvalidTagSuggestions)
+	}
+
+	var result struct {
+		Tags []struct {
+			Name       string  `json:"name"`
+			Confidence float64 `json:"confidence"`
+		} `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &result); err != nil {
+		return nil, seer.Wrap("decode_tag_suggestions", err)
+	}
+
+	vocabulary := make(map[string]string, len(input.Vocabulary))
+	for _, name := range input.Vocabulary {
+		vocabulary[strings.ToLower(name)] = name
+	}
+
+	seen := make(map[string]bool, len(result.Tags)+len(input.Assigned))
+	for _, name := range input.Assigned {
+		seen[strings.ToLower(name)] = true
+	}
+
+	suggestions := make([]SuggestedTag, 0, len(result.Tags))
+	for _, tag := range result.Tags {
+		name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag.Name), "#"))
+		if name == "" || len([]rune(name)) > MaxTagNameLength || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		if existing, ok := vocabulary[strings.ToLower(name)]; ok {
+			name = existing
+		}
+
+		suggestions = append(suggestions, SuggestedTag{
+			Name:       name,
+			Confidence: max(0, min(1, tag.Confidence)),
+		})
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b SuggestedTag) int {
+		switch {
+		case a.Confidence > b.Confidence:
+			return -1
+		case a.Confidence < b.Confidence:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return suggestions[:min(len(suggestions), MaxTagSuggestions)], nil
+}
@@ -0,0 +1,104 @@
+package llm_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go.trulyao.dev/hubble/web/pkg/llm"
+)
+
+func Test_ParseTagSuggestions(t *testing.T) {
+	input := &llm.TagSuggestionInput{
+		Title:      "Sourdough",
+		Text:       "A guide to baking sourdough bread.",
+		Vocabulary: []string{"Baking", "Recipes"},
+		Assigned:   []string{"recipes"},
+		Rejected:   []llm.RejectedTag{},
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []llm.SuggestedTag
+		wantErr  bool
+	}{
+		{
+			name:    "matches the vocabulary and orders by confidence",
+			content: `{"tags": [{"name": "bread", "confidence": 0.6}, {"name": "baking", "confidence": 0.9}]}`,
+			expected: []llm.SuggestedTag{
+				{Name: "Baking", Confidence: 0.9},
+				{Name: "bread", Confidence: 0.6},
+			},
+		},
+		{
+			name:     "drops assigned and duplicate tags",
+			content:  `{"tags": [{"name": "Recipes", "confidence": 0.9}, {"name": "#bread", "confidence": 0.5}, {"name": "Bread", "confidence": 0.4}]}`,
+			expected: []llm.SuggestedTag{{Name: "bread", Confidence: 0.5}},
+		},
+		{
+			name:     "clamps confidences and skips empty names",
+			content:  "Sure! ```json\n{\"tags\": [{\"name\": \"bread\", \"confidence\": 1.4}, {\"name\": \" \", \"confidence\": 0.5}]}\n```",
+			expected: []llm.SuggestedTag{{Name: "bread", Confidence: 1}},
+		},
+		{
+			name:     "caps the number of suggestions",
+			content:  `{"tags": [{"name": "a"}, {"name": "b"}, {"name": "c"}, {"name": "d"}, {"name": "e"}, {"name": "f"}]}`,
+			expected: []llm.SuggestedTag{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}},
+		},
+		{
+			name:     "accepts an empty list",
+			content:  `{"tags": []}`,
+			expected: []llm.SuggestedTag{},
+		},
+		{
+			name:    "rejects responses without JSON",
+			content: "no tags fit this document",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suggestions, err := llm.ParseTagSuggestions(test.content, input)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", suggestions)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !slices.Equal(suggestions, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, suggestions)
+			}
+		})
+	}
+}
+
+func Test_BuildTagSuggestionPrompt(t *testing.T) {
+	prompt := llm.BuildTagSuggestionPrompt(&llm.TagSuggestionInput{
+		Title:      "Sourdough",
+		Text:       "A guide to baking sourdough bread.",
+		Vocabulary: []string{},
+		Assigned:   []string{},
+		Rejected:   []llm.RejectedTag{{EntryName: "Focaccia", Tag: "pizza"}},
+	})
+
+	for _, expected := range []string{
+		"Existing tags: (none)",
+		`"pizza" was rejected for "Focaccia"`,
+		"Title: Sourdough",
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("expected the prompt to contain %q, got %q", expected, prompt)
+		}
+	}
+
+	if strings.Contains(prompt, "already has") {
+		t.Errorf("expected no assigned tags in the prompt, got %q", prompt)
+	}
+}
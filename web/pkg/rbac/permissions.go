@@ -15,6 +15,8 @@ const (
 	PermRenameWorkspace            Permission = "workspace:rename"
 	PermChangeWorkspaceSlug        Permission = "workspace:slug:update"
 	PermChangeWorkspaceDescription Permission = "workspace:description:update"
+	PermViewWorkspaceSettings      Permission = "workspace:settings:view"
+	PermUpdateWorkspaceSettings    Permission = "workspace:settings:update"
 
 	PermInviteUsersToWorkspace Permission = "workspace:members:invite"
 	PermListWorkspaceMembers   Permission = "workspace:members:list"
@@ -40,11 +42,27 @@ const (
 	PermListCollectionEntries Permission = "collection:entries:list"
 	PermListCollectionMembers Permission = "collection:members:list"
 
+	PermListTags  Permission = "collection:tags:list"
+	PermCreateTag Permission = "collection:tags:create"
+	PermUpdateTag Permission = "collection:tags:update"
+	PermDeleteTag Permission = "collection:tags:delete"
+
 	PermCreateEntry  Permission = "entry:create"
 	PermReadEntry    Permission = "entry:read"
+	PermUpdateEntry  Permission = "entry:update"
 	PermDeleteEntry  Permission = "entry:delete"
+	PermArchiveEntry Permission = "entry:archive"
+	PermPurgeEntry   Permission = "entry:purge"
+	PermMoveEntry    Permission = "entry:move"
+	PermCopyEntry    Permission = "entry:copy"
 	PermRequeueEntry Permission = "entry:requeue"
 	PermSearchEntry  Permission = "entry:search"
+	PermTagEntry     Permission = "entry:tag"
+
+	PermListComments  Permission = "comment:list"
+	PermCreateComment Permission = "comment:create"
+	// PermDeleteComment allows deleting the comments of other members, authors can always edit and delete their own comments
+	PermDeleteComment Permission = "comment:delete"
 
 	PermAddPluginSource    Permission = "plugin:source:add"
 	PermRemovePluginSource Permission = "plugin:source:remove"
@@ -63,6 +81,8 @@ var permissions = Permissions{
 	PermRenameWorkspace:            RoleOwner,
 	PermChangeWorkspaceSlug:        RoleOwner,
 	PermChangeWorkspaceDescription: CombineRoles(RoleAdmin, RoleOwner),
+	PermViewWorkspaceSettings:      CombineRoles(RoleAdmin, RoleOwner, RoleUser),
+	PermUpdateWorkspaceSettings:    CombineRoles(RoleAdmin, RoleOwner),
 
 	PermInviteUsersToWorkspace: CombineRoles(RoleAdmin, RoleOwner),
 	PermListWorkspaceMembers:   CombineRoles(RoleAdmin, RoleOwner, RoleUser),
@@ -89,12 +109,28 @@ var permissions = Permissions{
 	PermListCollectionEntries: CombineRoles(RoleAdmin, RoleOwner, RoleUser),
 	PermListCollectionMembers: CombineRoles(RoleAdmin, RoleOwner, RoleUser),
 
+	PermListTags:  CombineRoles(RoleAdmin, RoleOwner, RoleUser, RoleGuest),
+	PermCreateTag: CombineRoles(RoleAdmin, RoleOwner, RoleUser),
+	PermUpdateTag: CombineRoles(RoleAdmin, RoleOwner, RoleUser),
+	PermDeleteTag: CombineRoles(RoleAdmin, RoleOwner),
+
 	// Entry
 	PermCreateEntry:  CombineRoles(RoleAdmin, RoleOwner, RoleUser),
 	PermReadEntry:    CombineRoles(RoleAdmin, RoleOwner, RoleUser, RoleGuest),
+	PermUpdateEntry:  CombineRoles(RoleAdmin, RoleOwner, RoleUser),
 	PermDeleteEntry:  CombineRoles(RoleAdmin, RoleOwner),
+	PermArchiveEntry: CombineRoles(RoleAdmin, RoleOwner, RoleUser),
+	PermPurgeEntry:   CombineRoles(RoleAdmin, RoleOwner),
+	PermMoveEntry:    CombineRoles(RoleAdmin, RoleOwner),
+	PermCopyEntry:    CombineRoles(RoleAdmin, RoleOwner, RoleUser),
 	PermRequeueEntry: CombineRoles(RoleAdmin, RoleOwner, RoleUser),
 	PermSearchEntry:  CombineRoles(RoleAdmin, RoleOwner, RoleUser, RoleGuest),
+	PermTagEntry:     CombineRoles(RoleAdmin, RoleOwner, RoleUser),
+
+	// Comment
+	PermListComments:  CombineRoles(RoleAdmin, RoleOwner, RoleUser, RoleGuest),
+	PermCreateComment: CombineRoles(RoleAdmin, RoleOwner, RoleUser),
+	PermDeleteComment: CombineRoles(RoleAdmin, RoleOwner),
 
 	// Plugin
 	PermAddPluginSource:    CombineRoles(RoleAdmin, RoleOwner),
@@ -69,6 +69,114 @@ func Test_Can(t *testing.T) {
 			perm: rbac.PermInviteUsersToWorkspace,
 			want: false,
 		},
+		{
+			name: "admin can update workspace settings",
+			role: rbac.RoleAdmin,
+			perm: rbac.PermUpdateWorkspaceSettings,
+			want: true,
+		},
+		{
+			name: "user cannot update workspace settings",
+			role: rbac.RoleUser,
+			perm: rbac.PermUpdateWorkspaceSettings,
+			want: false,
+		},
+		{
+			name: "guest can list tags",
+			role: rbac.RoleGuest,
+			perm: rbac.PermListTags,
+			want: true,
+		},
+		{
+			name: "user can tag entries",
+			role: rbac.RoleUser,
+			perm: rbac.PermTagEntry,
+			want: true,
+		},
+		{
+			name: "guest cannot tag entries",
+			role: rbac.RoleGuest,
+			perm: rbac.PermTagEntry,
+			want: false,
+		},
+		{
+			name: "user can update entries",
+			role: rbac.RoleUser,
+			perm: rbac.PermUpdateEntry,
+			want: true,
+		},
+		{
+			name: "guest cannot update entries",
+			role: rbac.RoleGuest,
+			perm: rbac.PermUpdateEntry,
+			want: false,
+		},
+		{
+			name: "user can archive entries",
+			role: rbac.RoleUser,
+			perm: rbac.PermArchiveEntry,
+			want: true,
+		},
+		{
+			name: "guest cannot archive entries",
+			role: rbac.RoleGuest,
+			perm: rbac.PermArchiveEntry,
+			want: false,
+		},
+		{
+			name: "admin can purge entries",
+			role: rbac.RoleAdmin,
+			perm: rbac.PermPurgeEntry,
+			want: true,
+		},
+		{
+			name: "user cannot purge entries",
+			role: rbac.RoleUser,
+			perm: rbac.PermPurgeEntry,
+			want: false,
+		},
+		{
+			name: "user cannot move entries",
+			role: rbac.RoleUser,
+			perm: rbac.PermMoveEntry,
+			want: false,
+		},
+		{
+			name: "user can copy entries",
+			role: rbac.RoleUser,
+			perm: rbac.PermCopyEntry,
+			want: true,
+		},
+		{
+			name: "guest cannot copy entries",
+			role: rbac.RoleGuest,
+			perm: rbac.PermCopyEntry,
+			want: false,
+		},
+		{
+			name: "guest can list comments",
+			role: rbac.RoleGuest,
+			perm: rbac.PermListComments,
+			want: true,
+		},
+		{
+			name: "guest cannot comment",
+			role: rbac.RoleGuest,
+			perm: rbac.PermCreateComment,
+			want: false,
+		},
+		{
+			name: "user cannot delete comments of others",
+			role: rbac.RoleUser,
+			perm: rbac.PermDeleteComment,
+			want: false,
+		},
+		{
+			name: "user cannot delete tags",
+			role: rbac.RoleUser,
+			perm: rbac.PermDeleteTag,
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
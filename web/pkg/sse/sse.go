@@ -0,0 +1,50 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrStreamingUnsupported = errors.New("streaming is not supported by the response writer")
+
+// Writer writes Server-Sent Events to an HTTP response, every event is flushed to the client as soon as it is written
+type Writer struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// NewWriter sets the event stream headers and returns a writer for the response, nothing is written until the first event is sent
+func NewWriter(w http.ResponseWriter) (*Writer, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable response buffering in nginx
+
+	return &Writer{w: w, flusher: flusher}, nil
+}
+
+// Send writes a named event with the JSON-encoded data
+func (s *Writer) Send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return s.write(fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload))
+}
+
+func (s *Writer) write(message string) error {
+	if _, err := s.w.Write([]byte(message)); err != nil {
+		return err
+	}
+
+	s.flusher.Flush()
+	return nil
+}
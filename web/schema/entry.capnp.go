@@ -513,12 +513,12 @@ type Entry capnp.Struct
 const Entry_TypeID = 0xa2f47213c9289046
 
 func NewEntry(s *capnp.Segment) (Entry, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 8})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 9})
 	return Entry(st), err
 }
 
 func NewRootEntry(s *capnp.Segment) (Entry, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 8})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 9})
 	return Entry(st), err
 }
 
@@ -748,12 +748,36 @@ func (s Entry) SetUrl(v string) error {
 	return capnp.Struct(s).SetText(6, v)
 }
 
+func (s Entry) Tags() (capnp.TextList, error) {
+	p, err := capnp.Struct(s).Ptr(8)
+	return capnp.TextList(p.List()), err
+}
+
+func (s Entry) HasTags() bool {
+	return capnp.Struct(s).HasPtr(8)
+}
+
+func (s Entry) SetTags(v capnp.TextList) error {
+	return capnp.Struct(s).SetPtr(8, v.ToPtr())
+}
+
+// NewTags sets the tags field to a newly
+// allocated capnp.TextList, preferring placement in s's segment.
+func (s Entry) NewTags(n int32) (capnp.TextList, error) {
+	l, err := capnp.NewTextList(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return capnp.TextList{}, err
+	}
+	err = capnp.Struct(s).SetPtr(8, l.ToPtr())
+	return l, err
+}
+
 // Entry_List is a list of Entry.
 type Entry_List = capnp.StructList[Entry]
 
 // NewEntry creates a new list of Entry.
 func NewEntry_List(s *capnp.Segment, sz int32) (Entry_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 8}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 9}, sz)
 	return capnp.StructList[Entry](l), err
 }
 
@@ -1388,132 +1412,133 @@ func (f CreateChunksRequest_Future) Struct() (CreateChunksRequest, error) {
 	return CreateChunksRequest(p.Struct()), err
 }
 
-const schema_89f9fd74f165e666 = "x\xda\xb4W\x7f\x8cTW\xf5?\xe7\xdd\x99\x9d\xfd1" +
-	"\xb3oo\xde|\xf3\xb5\x8d\xcdXR\x92\xb2*?\x16" +
-	"\xab\xb0\xd8\x0c\xbb\x80\x02\x02\xee\xdd\x01\xa5\x04\x1a\x1e\xf3" +
-	".;\xaf;\xf3\xde\xec\xfb\xc12\x04\xdcR!\x01l" +
-	"\x03%V\x0b\x82DS\x82\xc4b\xad\xdaDHIZ" +
-	"\x021(m\xfa\x87M\xec\x1fU\x8bQ\x13\xd2?\xb4" +
-	"\xb6\x89\x92\xd8g\xce\x9d\x9d\x99\xc7\xec\x92\xd4\xc4\xfe\xb5" +
-	"o\xce\xfb\xbc{?\xe7s>\xe7\xdc\xbb\x0b7$\x97" +
-	"'\x16e\xcet\x82&\xacdGt\xff\xb7\x96\x0d\xee" +
-	"\x1f\x7f\xef\x9b\xc0\xd3\x18\xed\xfc\x8b\xfc{\xf0\xef\x7f\x1d" +
-	"\x86$K\x01\x18\x0f%\x8e\x1b\x0f'\xe8ii\xe2\x05" +
-	"\xc0h\xf9\x93\x9b+c\xd7_?\xd2\x86EB\xbc\x9d" +
-	"\xb8b\xfcYa\xdfI\xe4\x01\xa3\xa37\xee\xcd>Z" +
-	"\xbe}Ba\x97}0<ub\xcb\x92K\x90\xd4\x08" +
-	"\xc1\x93W\x8c{\x92\xf4\xf4\x7f\xc9I\xc0\xe8\xf2\x1f\x92" +
-	"\xdf)\xee\xd9}\x12D\x1aY;\x89\x89\xe4%\xa3F" +
-	"\xe0\xc5a\xf2(\x02F_:\xf6\xe0u\xc3{\xff\x87" +
-	"\xed\xe8NBw\xa5\x8e\x1b<E\xe8L\xea\x9c\x06\x18" +
-	"\xbd\xe1\x15~\x1d|c\xffs\x84\xd6Zh\xc5\xf4\xcd" +
-	"\xae\xe3\xc6\xdb]\xf4\xf4V\x17\xf1\x98xd\xcd\x85n" +
-	"\xf3\xe5s\xc0{1\xfa\xffK\x8bN\xac\xf8q\xfa\xf9" +
-	"i\xceK\xbbo\x1a\xab\xba\xe9i\xa8\x9b\xb0+O\xba" +
-	"\xa3;N\xd5\x9eo\xd3Beu\xba\xfb\xb6q\x9e\xb0" +
-	"\x8b\xcfv\xe7\x88\xf2\xd4\xf1\xfb\x16\x9e\xfa\xd3;\x17f" +
-	"\x13\xf9Z\xcf%\xe3\xb5\x1ez\xba\xdeC\"\x7f\xdd`" +
-	"\xf3\x8c\x05\x8f\xbe0\x8b\xc8\x8b\xcd\xb4\x86F%M`" +
-	";M*\xaf\xfa\xde\xd1\xe1\xb7\xce-x\x11D/\xce" +
-	"\xa0|\"\xfd\x9eqV\x81\x7f\x90\xa6\x95\xdf\xfd\xe7\xd5" +
-	"\x9f\x9d_\xf2\xfd\x17\x81\xa7cJ\x00\x1ak2'\x0d" +
-	"\x91!\xe0\xfa\xccw\x01\xa3\xf3\x0b\xde\xb5\x9f\xf9\xd1\xaf" +
-	"~N\x9a\xcd\xe0{:s\xdb8\x9fQ\xc9eTr" +
-	"Us\xf4\xd3O\xcc\xff\xc7of\xab\xf4\xe5\xde\x9b\xc6" +
-	"\xf5^\x95f/\xa9\xb6\xe0\xccB\x99\xfa\xd4\x81\x1b3" +
-	"(\xdc\xaf\x1f1\xe6\xe9\xb4\xea\\\xfd\x0c\xad\xba\xec\\" +
-	"\xd7\xa6\x9f\\t\xfe\xd6\x9e\x99\xe2\xb0\xa8\xef\x96\xf1p" +
-	"\x9f*K\xdf_\x01\xa3\xf4\x07\xb9-\xe7^\xbf\xf2~" +
-	"\x1b\x05e\xcc\xfb\xf8-c\x1e\xa7\xa7\xb9\x9c${\xec" +
-	"\xf8\xe4\xe7\xef\xdf\xc7>\xac'\xd7\x06\xde\xc4o\x19\xa6" +
-	"\x02o\xe3\xc4w\xc7\xef\xef\xd92rx\xe2C\xe0\xbd" +
-	"Z\x8b\x04\xa0q\x91\xdf4\xae)\xe0\xab\xfc\x0b\xb0\"" +
-	"\x92N\xe0\xd5\xe6\x17M\xac:\xd5\xc1\xafN:\x12\xbd" +
-	"\x11D\x91f\x09\x80\x04\x02\xf0Uk\x01\xc4J\x86b" +
-	"DC\x8e\x98E\x0a\xae\x1f\x05\x10\xeb\x18\x8a\xcd\x1ar" +
-	"M\xcb\xa2\x06\xc07\x11r#C\xb1]\xc3(\xf4\xa5" +
-	"\xe7\x98\x15\x09\x00\x98\x06\x0d\xd3\x80\xd1N\xdb\xf3\x83\x0d" +
-	"f\x05P6ceS\x85\xee\xc058i\xc4iE" +
-	")t\xc6G\xa5\x1f\x96\x03 f\x89&\xb3\xcc \x80" +
-	"\xe8d(\x1e\xd00_$\x98\x8f\xbd\x80#\x0c\xd5J" +
-	"\xbd\x80\x91\x1f\xb8\x9el\xacT\xa0\x1f_\xd95b\xda" +
-	"\x9eZ\xa9\xb3\xb9\xd2\xbc9\x00\xe2\x01\x86ba,\xc7" +
-	"\xcf\x0e\x00\x88\x07\x19\x8a\xcfi\x98\x1a\x97\xb5\x06\xbd\xdc" +
-	".\xb3\x1c\xca\x19d\x95\x80\xab\x9c\xc0\xcb\xd7\x14e\xda" +
-	"\xe1\x93\xcd\x1d^\xba\x17@\xfc\x94\xa1xY\xc3\xc6\x06" +
-	"\x17\x87\x01\xc4/\x18\x8aWHD\xac\x8bx\x99v\xfd" +
-	"%CqUC\xce\xb4,2\x00\xfe\xea\x1e\x00\xf1\x0a" +
-	"CqCC\x9e\xc0,&\x00\xf8u\xfa\xfc*C\xf1" +
-	"\x86\x86<\xa9e1\x09\xc0_\xa3\x1a\xdc`(~\xa7" +
-	"!\xef\xd0\xb2\xd8\x01\xc0\xdf\xa4j\xfd\x96\xa1\xf8\xa3\x86" +
-	"\xcc\xb60\x01\x1a&\x00\xa7\x14\xf55V31\xdb\xb1" +
-	"\xe4\xee\xc6\xdb\xa8b;v%\xac|\x0d\xf2\xd2\xf3m" +
-	"\xd7i~Vt\x9d@:A\xac\x84\xceXh\x8e\xdd" +
-	"Q\xc2\xa2'\xcd@ZC\x80\x01&A\xc3\xe4lJ" +
-	"a\x8dDZ\xd8\x10\xc9\x18\xc2{\x01\x0a_D\x86\x85" +
-	"\xd5\xd8\xaa\x84\xb1\x0a\xfb\x01\x0a\xcb)\xbe\x0e[\x863" +
-	"\xd6\xe0Z\x80\xc2j\x8aoD\x0d1\xa1\xd42\x04\x0e" +
-	"\x03\x14\xd6Qx3Nk\x93\xa0\x1eQ\xcb\x8cP|" +
-	"+\xc5;\x98\xd2\xccx\x04\xb7\x00\x146S\xdc\xa2x" +
-	"*\xa1d3L\x1c\x00(l\xa5x\x89\xe2\x9d\x98U" +
-	"\xbd&q\x14\xa0`Q\xbcJ\xf1\xaed\x16;\x01\x8c" +
-	"\x8a\xc2\x97(\x1eP\xbc[\xcbb\x17\x1d\x04\xe8\x01\x14" +
-	"\xaa\x14\xdfK\xf1\x9e\x8e,v\x03\x185\x9c\x03P\x08" +
-	"(\xfe8\xc5Y*\x8b=\x00\xc6>\xb5\xfe^\x8a\x1f" +
-	"\xc2z\xc5\xa6e\xd5\xa9\xa3\x9a\x1aWLo\xdcr'" +
-	"\x9d\x98\xeeS\xbb\xee\xac\x95\x1e\xd4\xaa\x12\xf5\xd6\xf8\x02" +
-	"D\x9d\xca\xe3\x96\xcb\xb2\x18\xd8\xc0\\\x07\xfbZc\x1e" +
-	"\x10\xfb\x00s\xee\xa4#=\xeck\x9d\xb1\xf5\xf8lU" +
-	"\xcdM\x842\x94\xd8\xd7:\xaf\xa6\xb1;\xed\xb2\xf4\xed" +
-	"=\x12r\xc3\xb5@\xfa\x0d|*\xf4\xca\xcd\x0c\xaae" +
-	"\xd3v6\xca\xdd\xb4\xde\xac\xfd$B\x19\xa2lk\xd6" +
-	"\xc1\xd9\x9a\x95|\xff\x19\x86b\x89\x86y?0\x83\xd0" +
-	"G\xbdupL\xa7\xad\xb8ZC\x01)\xd6p\xa5#" +
-	"\x83I\xd7\x1b\x9f\xaf\x15M\xdaqC\xfd\xe7ji\xa6" +
-	",\xe9\xfd\xef\xc7\x84\x9aD\x9b\xaa\x96\x19Hj\x81\xda" +
-	"h^N\x84\xd2\x0fh\xa7ls\xa7}4.v3" +
-	"\x14\x07b;\xed\xef\x07\x10{\x19\x8aC\xb1\xa1{\xf0" +
-	"\x09\x00q\x80\xa18F\xa3\x81\xd5\xe7\xc5S\xa4\xc6\x93" +
-	"\x0c\xc5\xb3d\xabD}^<s\x04@<\xcbP<" +
-	"\xf7\x11<\x85+\xeaM\x1e\xeb\xe8\x92,\x8e\xfba%" +
-	"\xde\xe5\x8d\xfaa\xd0\x80\xc3\xecu\\\xe1\x96\xcby2" +
-	"\x9c\xeb\xb4\x9d.\x94\xe8r\x86b],\xd15\xfd\xb1" +
-	"#\xa7\x91\xe8z\x0a\xaef(6\xde\x9d\xbe\xee\x97\xc3" +
-	"\xb1\x19\x0c\x98b\xa0\xac\xab&\xb3?Z\x97\xfc\xa3\x1f" +
-	"'}\xad\x9b\x05\xa0:Xfw\xcd\xa8\xf4sU\xd7" +
-	"\xf1e[\x92\x83\xad$\x9b9\x0eO\xe7\xb8=6\xfc" +
-	"\xb7Q\x8e\x9b\x19\x0a\xab\xe5\xe2\xc6\xd4-I\xd3\x92^" +
-	"\x8cR\xf3\xd2W\xa7\xa4\xefp\xad\x1af@\xc3L\xbb" +
-	"\xfa\x85\xc0\x0cX\xe8\x13\xa9O\xa8}\x86\x06\xe9#\xbe" +
-	"t\x0b\x00j\xfc\xa1Q\x00d|\x11\x05\x13|\xdeZ" +
-	"j\x0e>w\x10 _\xef\x97\xa8\xea\xb9E\xe9\xfb6" +
-	"0g,*\xba\x95jY\x06\x12\xd0\xca\xef4\xed\xb2" +
-	"\xb4\xa2\xa2\xe9\x14eYZ\x00\x90\xaf\x9a\xa1/\xad\xb6" +
-	"#\xbc\xa5\xfd]\xec>\xdc\xb2{\xd3\xed\x03q\xb7O" +
-	"\x0btpO\xcc\xed\x0c\xa7\xddN_\x1fb(\xbeM" +
-	"-P\x1f\xf6\xfcij\x81c\x0c\xc5)\xedc=\xe8" +
-	"f\xde0\x0a2 \x83\xa5\xa6\xb3\xfc\x18n\x19\x1bk" +
-	"U\x09\xca\xbd+\x95*\xd7\xfaU9\xe9\xea\x80\x1a\x7f" +
-	"i@\x95\xf3\xc2\x80*\xe7\xd99\xaa\x9c\xa7w\x00`" +
-	"\x07?A\xc8\x14\x7f\xfa1\x00\xec\xe4O\xd1\x9f.~" +
-	"\x98\xdeu\xf3\x83\xf4\xae\x87\xef\xa3\xfa\xa7y\x8dL\x91" +
-	"\xe1\xe10\x00\xf6\xf2\x0a\xbd\xd3\xb9\xa4_}|\xdb\x00" +
-	"\x80^\xb6\x9d\xf1\x9c\x19Z\xb6\x9b\xdbe[\xd2\xcd\xd9" +
-	"\x15sL\xa6\xaa\xd6\xce\xc8v\x02\xe9\x15K&\xa4\x9c" +
-	"1\xa9\xcbj\xb8#\x9at=k\xa5[\x0cA\xafH" +
-	"'\x88\xaa\x9e\xf4\xa5\x13\x98\xa0\xd3H\x88\xfc\xaa'M" +
-	"\xcb/AJ\xca@/\x05\x95r\xfcp\x8b\x1f\x13S" +
-	"\xa6W,\xd9\xbb\xa4^t-9Ut+\xb4\\\xce" +
-	"\x0dJ\xd2\xbbkON\xe8\x0d\xc7\xf55kaRK" +
-	"ne(J-\xc7I\xaa\xcfv\x86\xa2\x1cs\x9cM" +
-	"\xe6\xb2\x18\x8a\xc7c\xf7\xb1}\xfd-\xbf\xe6+2(" +
-	"\xb9\x16\xea\xad;w\xfd\xb4\x89\x9ft\xffe\xf7\xce4" +
-	"\xd5P\xb9<*\xfd\xaa\xde\x18.\xb1\xb15\xd0\x1a[" +
-	"\xb9\xaai\xc77i\xfe/;=\xb5f\xae\xfbe2" +
-	"kl\xdd\x98[\x07Znm\x9au\xb0e\xd6;\x0d" +
-	"\x9a\x97\xbbm?\xf0\x11AC\xbc\xeb|\\/\x83T" +
-	"\xc9\xb5\xd4FJ]>G9\xb7\xab\x1f 5&\x03" +
-	"\xbd\xea\xfa\xc1\x7f\x02\x00\x00\xff\xff\xbf\x1d\xadr"
+const schema_89f9fd74f165e666 = "x\xda\xb4W\x7f\x8cTW\x15>\xe7\xdd\x99\x9d\xdde" +
+	"f\xdf^\xde4\x0a\xd1\x8c\x906)\xab\xf2ci\x95" +
+	".1\x03\x0bh\xc1R\xb9;\xa0HJ\xc3c\xdee" +
+	"\xe7ug\xde\x9b}?\xd8\x1d\xe2\xbaP!)\x08\xe1" +
+	"GD\x01\xa9D\x83Ab\xb1V%)\xa4M*\xc1" +
+	"\x18\x14\x9b\xfe\xa1\x89\xfe\xa1\xb5\x98jJj\"\xb5$" +
+	"Jb\x9f9wvf\x1e\xb3KR\x13\xfb\xd7\xce\x9c" +
+	"\xf9\xde\xbd\xdf\xf9\xcew\xcey\xbb\xd8L\xaeH,\xc9" +
+	"\x9c\xe9\x04MX\xc9\x8eh\xde\xd7\x97\x0f\xec\x19y\xe7" +
+	"k\xc0\xd3\x18\xed\xf8\xab\xbc\x15\xfc\xe7\xdf\xfb!\xc9R" +
+	"\x00\xc6\xc3\x89c\xc6g\x12\xf4\xe9\x91\xc4\xf3\x80\xd1\x8a" +
+	"\x83\x9b+\xc3\xd7^=\xd0\x86EB\xfc1q\xc5x" +
+	"Sa\xdfH\xe4\x01\xa3\xc3\xd7\xe7f\x9f,\xdf9\xa9" +
+	"\xb0\xcbo\x0fN\x9e\xdc\xb2\xec2$5B\xf0\xe4\x15" +
+	"cN\x92>\xdd\x97\x1c\x03\x8c^~=\xf9\xcd\xe2\xae" +
+	"\xf1S \xd2\xc8\xdaI\x8c&/\x1b5\x02/\x0d\x93" +
+	"\x87\x110\xfa\xec\x91\x07\xaf\x19\xde\xbb\xdfkGw\x11" +
+	"\xba+u\xcc\xe0)BgR\xafk\x80\xd1k^\xe1" +
+	"W\xc1W\xf7\x9c%\xb4\xd6B+\xa6\xf3\xba\x8f\x19\x0b" +
+	"\xba\xe9\xd3\x03\xdd\xc4c\xf4\xcbk/t\x9b/\x9d\x03" +
+	"\xde\x83\xd1\x87./9\xb9\xea\x87\xe9\xe7\xa68\xef\xeb" +
+	"\xbea\x1cU\xd8C\x0a\xbb\xfa\x94;\xb4\xfdt\xed\xb9" +
+	"6-TVou\xdf1n\x13v\xe9\xad\xee\x1cQ" +
+	"\x9e<\xf6\xd1\xc5\xa7\xff\xf2\xc6\x85\x99D\xe6\xe9\xcb\xc6" +
+	"\x9c\xb4\x12#M\"\x7f\xc9`\x0b\x8cEO>?\x83" +
+	"\xc8K/\xa454.)\xf0\xc54\xa9\xbc\xe6\xdb\x87" +
+	"\x07\xffpn\xd1\x0b zp\x1a\xe57\xd3\xef\x18\xb7" +
+	"\x14\xf8\xef\xea\xe4\xb7\xffu\xf5'\xe7\x97}\xe7\x05\xe0" +
+	"\xe9\x98\x12\x80\xc6\xf1\xcc)\xe3\xd9\x0c\x01Of\xbe\x05" +
+	"\x18\x9d_\xf4\xb6}\xfc\x07\xbf\xfc)i6\x8d\xef[" +
+	"\x99;\xc6\xed\x8cJ.\xa3\x92\xab\x9aC\x1f\x7fz\xe1" +
+	"?\x7f=S\xa5\xbb\xf4\x1b\xc6}\xbaJS'\xd5\x16" +
+	"\x9dY,S\x1f\xdb{}\x1a\x85\x8a~\xc0\x08\x09\xb8" +
+	"tT?C\xa7.?\xd7\xb5\xe9G\x97\x9c\x7f\xb4g" +
+	"\xa68L\xf4\xde4\xf6\xf7\xaa\xb2\xf4\xfe\x0d0J\xdf" +
+	"\xcem9\xf7\xea\x95w\xdb((c\xda\xfc\xa6\x11r" +
+	"\xe5$N\x92=ul\xecS\xf3&\xd8{\xf5\xe4\xda" +
+	"\xc0\xdf\xe57\x8d\x0b\x0a|\x9e\x13\xdf\xed\x7f\x9a\xb3e" +
+	"\xc3\xfe\xd1\xf7\x80\xf7h-\x12\x80Fr\xf6\x0d\x83\xcf" +
+	"&`f\xf6\xa7aU$\x9d\xc0\xab-,\x9aXu" +
+	"\xaa\x03_\x18s$z\x1b\x10E\x9a%\x00\x12\x08\xc0" +
+	"\xd7\xac\x03\x10\xab\x19\x8a\x0d\x1ar\xc4,Rp\xfd\x10" +
+	"\x80x\x8c\xa1\xd8\xac!\xd7\xb4,j\x00|\x13!7" +
+	"2\x14\xdb4\x8cB_z\x8eY\x91\x00\x80i\xd00" +
+	"\x0d\x18\xed\xb0=?x\xdc\xac\x00\xcaf\xacl\xaa\xd0" +
+	"]\xb8\x06'\x8d8\xad*\x85\xce\xc8\x90\xf4\xc3r\x00" +
+	"\xc4,\xd1d\x96\x19\x00\x10\x9d\x0c\xc5\xfd\x1a\xe6\x8b\x04" +
+	"\xf3\xb1\x07p\x03CuR\x0f`\xe4\x07\xae'\x1b'" +
+	"\x15\xe8\xcb\xe7wn0mO\x9d\xd4\xd9<i\xc1|" +
+	"\x00q?C\xb18\x96\xe3'\xfb\x01\xc4\x83\x0c\xc5C" +
+	"\x1a\xa6Fd\xadA/\xb7\xd3,\x87r\x1aY%\xe0" +
+	"\x1a'\xf0\xf25E\x99n\xf8H\xf3\x86\x8bs\x01\xc4" +
+	"\x8f\x19\x8a\x974l\\pi\x10@\xfc\x8c\xa1x\x85" +
+	"D\xc4\xba\x88/\xd3\xad/2\x14W5\xe4L\xcb\"" +
+	"\x03\xe0?\xdf\x05 ^a(\xaek\xc8\x13\x98\xc5\x04" +
+	"\x00\xbfF\x8f_e(^\xd3\x90'\xb5,&\x01\xf8" +
+	"o\xa8\x06\xd7\x19\x8a\xdfk\xc8;\xb4,v\x00\xf0\xdf" +
+	"Q\xb5~\xcbP\xfcYCf[\x98\x00\x0d\x13\x80\x93" +
+	"\x8a\xfaZ\xab\x99\x98\xedXr\xbc\xf1kT\xb1\x1d\xbb" +
+	"\x12V\xbe\x08y\xe9\xf9\xb6\xeb4\x1f+\xbaN \x9d" +
+	" VBg84\x87\xef*a\xd1\x93f \xad\x95" +
+	"\x80\x01&A\xc3\xe4LJa\x8dDz\xa8!\x92\xb1" +
+	"\x15\xe7\x02\x146#\xc3\x82\x85\xadJ\x18&\xf6\x01\x14" +
+	"\x9e\xa0x\x09[\x863$\xae\x03(X\x14\xaf\xa2\x86" +
+	"\x98Pj\x19\x15\x1c\x04(\x94(\x1c\xe0\x946\x09j" +
+	"#uL\x99\xe2\xe3\x14\xef`J3#\xc4-\x00\x85" +
+	"\x80\xe2\xbb)\x9eJ(\xd9\x8c\x09\xec\x07(\x8cS|" +
+	"/\xc5;1\xabzm\x0f\x0e\x01\x14vS\xfc \xc5" +
+	"\xbb\x92Y\xec\x040\xf6+\xfc^\x8a\x1f\xa1x\xb7\x96" +
+	"\xc5.\x9a\xbb\xe8\x01\x14\x0eR\xfc\x04\xc5gud\xb1" +
+	"\x1b\xc08\x8e\xf3\x01\x0aG(~\x9a\xe2,\x95\xc5Y" +
+	"4\xcb\xd4\xf9'(~\x96\xe2\xe9\xce,\xa6\xa9\xc7\x15" +
+	"\xff\xd3\x14\x7f\x11\xeb\x95\x9c\x92[\xa7Nkj_1" +
+	"\xbd\x11\xcb\x1dsb\xf5\x98\xdcyw\x0d\xf5\xa0V\x95" +
+	"\xa8\xb7\xc6\x1a \xeaT6\xb7\\\x96\xc5\xc0\x06\xe6:" +
+	"\xd8\xdb\x1a\xff\x80\xd8\x0b\x98s\xc7\x1c\xe9aok\xf7" +
+	"\xd6\xe33U;7\x1a\xcaPbok\x8fMaw" +
+	"\xd8e\xe9\xdb\xbb$\xe4\x06k\x81\xf4\x1b\xf8T\xe8\x95" +
+	"\x9b\x19T\xcb\xa6\xedl\x94\xe3t^#\xc5\xc0\x1c\x9e" +
+	"\xd6\xdew9J\x842D\xd9\xd6\xd8\x03356\xf5" +
+	"\xc8'\x18\x8ae\x1a\xe6\xfd\xc0\x0cB\x1f\xf5\xd6\x92\x99" +
+	"\x92B\xf1\xb7V\x06\xa4b\xc3\xc1\x8e\x0c\xc6\\od" +
+	"\xa1V4\xe9\xc6\xc7\xeb_\x1f\x95f\xca\x92\xde\xff\x7f" +
+	"\xa4\xa8\xa9\xb5\xa9j\x99\x81\xa4v\xa9\x0d\xe5\xe5h(" +
+	"\xfd\x80n\xca6o\x9a\xa0\xd12\xceP\xec\x8d\xdd\xb4" +
+	"\xa7\x0f@|\x85\xa1x&6\xa0\xf7=\x0d \xf62" +
+	"\x14Gh\x8c\xb0\xfal9Dj\x1cd(N\x90\x05" +
+	"\x13\xf5\xd9r\xfc\x00\x808\xc1P\x9c}\x1f>\xc3U" +
+	"\xf5\x81\x10\xeb\xfe\x92,\x8e\xf8a%>\x11\x1a5\xc5" +
+	"\xa0\x01\x87\x99g\xe8*\xb7\\\xce\x93\x09]\xa7m\x13" +
+	"Q\xa2+\x18\x8a\xc7b\x89\xae\xed\x8b\xad\xa7F\xa2\xeb" +
+	")\xf8(C\xb1\xf1\xde\xf4u\xbf\x1c\x0eOc\xc0\x14" +
+	"\x03eg5\xc5\xfd\xa1\xba\xe4\xef\x7f\xf5\xf4\xb6\xdeB" +
+	"\x00\x95Kgv\xcd\x90\xf4sU\xd7\xf1e[\x92\x03" +
+	"\xad$\x9b9\x0eN\xe5\xb8-\xb6(\xb6R\x8e\x9b\x19" +
+	"\x0a\xab\xe5\xe2\xc6\x84.I\xd3\x92^\x8cR\xf3\x05\xb1" +
+	"NI\xdf\xeeZ5\xcc\x80\x86\x99v\xf5\x0b\x81\x19\xb0" +
+	"\xd0'R\x1fV\xf7\xac\x1c\xa0\x87\xf8#[\x00P\xe3" +
+	"\x0f\x0f\x01 \xe3K(\x98\xe0\x0b\xd6Qs\xf0\x07\x06" +
+	"\x00\xf2\xf5~\x89\xaa\x9e[\x94\xbeo\x03s\x86\xa3\xa2" +
+	"[\xa9\x96e \x01\xad\xfc\x0e\xd3.K+*\x9aN" +
+	"Q\x96\xa5\x05\x00\xf9\xaa\x19\xfa\xd2j[\xf7-\xed\xef" +
+	"a\xf7\xc1\x96\xdd\x9bn\xef\x8f\xbb}J\xa0}\xbbb" +
+	"ng8\xe5vz\xfa\x19\x86\xe2\x1b\xd4\x02\xf5\xc5\xc0" +
+	"\x8fR\x0b\x1ca(Nk\x1f\xe8R\x9c\xfe6R\x90" +
+	"\x01\x19,5\x95\xe5\x07\xf0F\xb2\xb1V\x95\xa0\xdc\xbb" +
+	"Z\xa9\xf2\x8b>UNz\xcd@\x8d_\xecW\xe5\xbc" +
+	"\xd0\xaf\xca\xf9\xfd\xf9\xaa\x9c\xcfn\x07\xc0\x0e~\x92\x90" +
+	")~\xf4)\x00\xec\xe4\x87\xe8O\x17\xdfO\xbfu\xf3" +
+	"}\xf4\xdb,>A\xf5O\xf3\x1a\x99\"\xc3\xc3A\x00" +
+	"\xec\xe1\x15\xfaM\xe7\x92\xbe\xf5\xf2\xad\xfd\x00z\xd9v" +
+	"Frfh\xd9nn\xa7mI7gW\xcca\x99" +
+	"\xaaZ;\"\xdb\x09\xa4W,\x99\x90r\x86\xa5.\xab" +
+	"\xe1\xf6h\xcc\xf5\xac\xd5n1\x04\xbd\"\x9d \xaaz" +
+	"\xd2\x97N`\x82N#!\xf2\xab\x9e4-\xbf\x04)" +
+	")\x03\xbd\x14T\xca\xf1\x85\x17_\x1d\x93\xa6W,\xd9" +
+	";\xa5^t-9Yt+t\\\xce\x0dJ\xd2\xbb" +
+	"gO\x8e\xea\x0d\xc7\xf56kaRK>\xc1P\x94" +
+	"Z\x8e\x93T\x9fm\x0cE9\xe68\x9b\xcce1\x14" +
+	"\xbbc\xefn\x13}-\xbf\xe6+2(\xb9\x16\xea\xad" +
+	"\xf7\xf3\xfa\xb6\x89o\xbf\xff\xb1{\xa7\x9bje\xb9<" +
+	"$\xfd\xaa\xde\x18.\xb1\xb1\xd5\xdf\x1a[\xb9\xaai\xc7" +
+	"/i\xfe\xdf;5\xb5\xa6\x9f\xfb92k\xec\xdc\x98" +
+	"[\xfb[nm\x9au\xa0e\xd6\xbb\x0d\x9a\x97\xe3\xb6" +
+	"\x1f\xf8\x88\xa0!\xdes>\xae\x97A\xaa\xe4Z\xea\"" +
+	"\xa5.\x9f\xaf\x9c\xdb\xd5\x07\x90\x1a\x96\x81^u\xfd\xe0" +
+	"\xbf\x01\x00\x00\xff\xff7=\xb7\x9f"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{